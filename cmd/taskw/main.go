@@ -1,17 +1,106 @@
 package taskw
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/nkaewam/taskw/internal/cli"
+	"github.com/nkaewam/taskw/internal/cli/doctor"
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
+	"github.com/nkaewam/taskw/internal/cli/graph"
+	"github.com/nkaewam/taskw/internal/cli/lastrun"
+	"github.com/nkaewam/taskw/internal/cli/lock"
+	"github.com/nkaewam/taskw/internal/cli/routes"
+	"github.com/nkaewam/taskw/internal/cli/scan"
+	"github.com/nkaewam/taskw/internal/cli/score"
+	"github.com/nkaewam/taskw/internal/cli/ui"
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/generator"
+	"github.com/nkaewam/taskw/internal/pipeline"
+	"github.com/nkaewam/taskw/internal/scanner"
 	"github.com/spf13/cobra"
 )
 
 var (
 	configPath string
+	noCache    bool
+	verbose    bool
+	quiet      bool
+	noColor    bool
+	jsonLogs   bool
 	container  *cli.Container
+
+	initNoExec    bool
+	initGit       bool
+	initBatteries bool
+	initGoProxy   string
+	initGoPrivate string
+	initOffline   bool
+	initLogger    string
+
+	auditCodeowners  string
+	auditFailUnowned bool
+
+	fmtStdin bool
+
+	scanProviders bool
+	scanOrder     bool
+	scanChanged   bool
+	scanFormat    string
+	scanOnly      string
+	scanPackages  []string
+	scanStrict    bool
+
+	generateSince       string
+	generateLockTimeout time.Duration
+	generateOnly        []string
+	generatePackages    []string
+	generateCheck       bool
+	generateDryRun      bool
+	generateForce       bool
+	generateEnv         string
+	generateOutput      *generator.Output
+	projectLock         *lock.Lock
+
+	cleanDryRun bool
+
+	migrateStatus      int
+	migrateProxy       bool
+	migrateRemoveAfter string
+
+	syncServerStruct string
+
+	devMainPackage string
+	devDebounce    string
+	watchSwagger   bool
+	watchDebounce  string
+
+	notesSince  string
+	notesOutput string
+
+	routesFormat string
+
+	graphFormat string
+
+	scoreFormat string
+)
+
+// Build information, set at build time with
+// -ldflags "-X github.com/nkaewam/taskw/cmd/taskw.version=v1.2.3 -X ...commit=abc1234 -X ...date=2025-01-02T15:04:05Z"
+var (
+	version = "dev"
+	commit  = ""
+	date    = ""
 )
 
 var rootCmd = &cobra.Command{
@@ -24,39 +113,181 @@ var rootCmd = &cobra.Command{
 
 It scans your Go code for special annotations and generates boilerplate code to wire everything together.`,
 	PersistentPreRunE: initializeContainer,
+	// Execute prints the error itself, as JSON with --json-logs
+	SilenceErrors: true,
 }
 
 func initializeContainer(cmd *cobra.Command, args []string) error {
 	var err error
 	container, err = cli.InitializeContainer(configPath)
 	if err != nil {
-		return fmt.Errorf("failed to initialize container: %w", err)
+		return exitcode.New(exitcode.Config, fmt.Errorf("failed to initialize container: %w", err))
 	}
+	if noCache {
+		container.Config.Scanning.Cache = false
+	}
+	ui.Debugf("Project root: %s", container.Config.Root)
 	return nil
 }
 
+// configureOutput applies the output flags, before any command runs: some skip container initialization
+func configureOutput() {
+	ui.Configure(ui.Options{Verbose: verbose, Quiet: quiet, NoColor: noColor, JSON: jsonLogs})
+}
+
 func init() {
+	cobra.OnInitialize(configureOutput)
+
 	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Path to taskw.yaml config file")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Re-parse every file instead of reusing cached scan results from "+scanner.CacheFile)
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Print debug messages, e.g. how long each step took")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only print warnings and errors")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Never color the output (color is already off outside a terminal or with NO_COLOR set)")
+	rootCmd.PersistentFlags().BoolVar(&jsonLogs, "json-logs", false, "Print progress, warnings and errors as JSON lines on stderr")
+	rootCmd.MarkFlagsMutuallyExclusive("verbose", "quiet")
+
+	// Setup init flags
+	initCmd.Flags().BoolVar(&initNoExec, "no-exec", false, "Skip running go mod tidy and task generate after scaffolding")
+	initCmd.Flags().BoolVar(&initGit, "git", false, "Initialize a git repository with a .gitignore and an initial commit")
+	initCmd.Flags().BoolVar(&initBatteries, "batteries", false, "Include request validation, an error model, standard middleware and an example CRUD domain")
+	initCmd.Flags().StringVar(&initGoProxy, "goproxy", "", "GOPROXY for go mod tidy and task generate, e.g. https://proxy.corp.example,direct")
+	initCmd.Flags().StringVar(&initGoPrivate, "goprivate", "", "GOPRIVATE for go mod tidy and task generate, e.g. github.com/corp/*")
+	initCmd.Flags().BoolVar(&initOffline, "offline", false, "Resolve dependencies from the module cache only, deferring them when it lacks some")
+	initCmd.MarkFlagsMutuallyExclusive("goproxy", "offline")
+	initCmd.Flags().StringVar(&initLogger, "logger", generator.Loggers[0], "Logging library of the logger provider and request logging middleware: "+strings.Join(generator.Loggers, ", "))
+	auditOwnersCmd.Flags().StringVar(&auditCodeowners, "codeowners", "", "Path to the CODEOWNERS file (default: ownership.codeowners_file or the standard locations)")
+	scanCmd.Flags().BoolVar(&scanProviders, "providers", false, "Only show providers")
+	scanCmd.Flags().BoolVar(&scanOrder, "order", false, "Show the provider initialization order and which provider pulls in which")
+	scanCmd.Flags().BoolVar(&scanChanged, "changed", false, "Only re-parse packages with uncommitted changes, reusing cached results for the rest")
+	scanCmd.Flags().StringVar(&scanFormat, "format", scan.FormatText, "Output format: text, json or yaml, json and yaml print the full scan result for other tools")
+	scanCmd.Flags().StringVar(&scanOnly, "only", "", "Only show one section of the results: routes, providers or handlers")
+	scanCmd.Flags().BoolVar(&scanStrict, "warnings-as-errors", false, "Exit with an error on validation warnings too, same as validation.fail_on: warning")
+	scanCmd.Flags().StringSliceVar(&scanPackages, "package", nil, "Only show the results of these packages, by name, import name or directory (repeatable)")
+	generateCmd.PersistentFlags().StringVar(&generateSince, "since", "", "Only re-parse packages changed since a git revision, reusing cached results for the rest")
+	generateCmd.PersistentFlags().StringSliceVar(&generatePackages, "package", nil, "Only write per-package files such as doc.go into these packages, by name, import name or directory (repeatable)")
+	generateAllCmd.Flags().StringSliceVar(&generateOnly, "only", nil, "Only run these steps, e.g. routes,deps: "+strings.Join(pipeline.Steps, ", "))
+	generateCmd.PersistentFlags().BoolVar(&generateCheck, "check", false, "Write nothing, fail with a diff when the generated files on disk are out of date")
+	generateCmd.PersistentFlags().BoolVar(&generateDryRun, "dry-run", false, "Write nothing, print a diff of what would change in each generated file")
+	generateCmd.MarkFlagsMutuallyExclusive("check", "dry-run")
+	generateCmd.PersistentFlags().StringVar(&generateEnv, "env", "", "Environment to generate providers for, leaving out the ones tagged @Provider env=... for others (default: generation.dependencies.env)")
+	generateCmd.PersistentFlags().BoolVar(&generateForce, "force", false, "Overwrite generated files edited by hand since taskw wrote them")
+	generateCmd.PersistentFlags().DurationVar(&generateLockTimeout, "lock-timeout", lock.DefaultTimeout, "How long to wait for another taskw command generating code in the project, 0 fails right away")
+	cleanCmd.Flags().BoolVar(&cleanDryRun, "dry-run", false, "Delete nothing, list the files that would be deleted")
+	fmtCmd.Flags().BoolVar(&fmtStdin, "stdin", false, "Format source read from stdin and write the result to stdout")
+	migratePathCmd.Flags().IntVar(&migrateStatus, "status", 308, "Redirect status code: 308 (keeps the method and body) or 301")
+	migratePathCmd.Flags().BoolVar(&migrateProxy, "proxy", false, "Serve old paths with the new handlers instead of redirecting")
+	migratePathCmd.Flags().StringVar(&migrateRemoveAfter, "remove-after", "", "Last day the old paths are served, e.g. 2026-12-31 (default: 90 days from today)")
+	syncServerCmd.Flags().StringVar(&syncServerStruct, "struct", "Server", "Name of the hand-written server struct")
+	devCmd.Flags().StringVar(&devMainPackage, "main", "", "Package built into the server binary (default: dev.main_package)")
+	devCmd.Flags().StringVar(&devDebounce, "debounce", "", "Quiet period after the last change before rebuilding, e.g. 500ms (default: dev.debounce)")
+	watchCmd.Flags().BoolVar(&watchSwagger, "swagger", false, "Regenerate the swagger documentation as well")
+	watchCmd.Flags().StringVar(&watchDebounce, "debounce", "", "Quiet period after the last change before regenerating, e.g. 500ms (default: dev.debounce)")
+	notesCmd.Flags().StringVar(&notesSince, "since", "", "Git tag, branch or commit to compare the routes against")
+	notesCmd.Flags().StringVarP(&notesOutput, "output", "o", "", "Write the notes to a file instead of stdout")
+	bugreportCmd.Flags().StringVarP(&bugreportOutput, "output", "o", "", "Write the bug report to a file instead of stdout")
+
+	routesCmd.Flags().StringVar(&routesFormat, "format", routes.FormatTable, "Output format: table, json or markdown")
+
+	graphCmd.Flags().StringVar(&graphFormat, "format", graph.FormatDOT, "Output format: dot or mermaid")
+
+	scoreCmd.Flags().StringVar(&scoreFormat, "format", score.FormatText, "Output format: text or json")
+	notesCmd.MarkFlagRequired("since")
+
+	// Flag values offered by shell completion
+	scanCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{scan.FormatText, scan.FormatJSON, scan.FormatYAML}, cobra.ShellCompDirectiveNoFileComp))
+	scanCmd.RegisterFlagCompletionFunc("only", cobra.FixedCompletions([]string{"routes", "providers", "handlers"}, cobra.ShellCompDirectiveNoFileComp))
+	generateAllCmd.RegisterFlagCompletionFunc("only", cobra.FixedCompletions(pipeline.Steps, cobra.ShellCompDirectiveNoFileComp))
+	routesCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{routes.FormatTable, routes.FormatJSON, routes.FormatMarkdown}, cobra.ShellCompDirectiveNoFileComp))
+	graphCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{graph.FormatDOT, graph.FormatMermaid}, cobra.ShellCompDirectiveNoFileComp))
+	scoreCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{score.FormatText, score.FormatJSON}, cobra.ShellCompDirectiveNoFileComp))
+	auditOwnersCmd.Flags().BoolVar(&auditFailUnowned, "fail-unowned", false, "Exit with an error if any route has no owner (implied by ownership.require_owners)")
 
 	// Setup generate subcommands
 	generateCmd.AddCommand(generateAllCmd)
 	generateCmd.AddCommand(generateRoutesCmd)
+	generateCmd.AddCommand(generateServerCmd)
 	generateCmd.AddCommand(generateDepsCmd)
+	generateCmd.AddCommand(generatePackageDocsCmd)
+	generateCmd.AddCommand(generateParamsCmd)
+	generateCmd.AddCommand(generatePortsCmd)
+	generateCmd.AddCommand(generateRecordingCmd)
+	generateCmd.AddCommand(generateAlertsCmd)
+	generateCmd.AddCommand(generateChaosCmd)
+	generateCmd.AddCommand(generateEnvelopeCmd)
+	generateCmd.AddCommand(generateRedirectsCmd)
+	generateCmd.AddCommand(generateSwaggerUICmd)
+	generateCmd.AddCommand(generateGatewayCmd)
+	generateCmd.AddCommand(generatePIICmd)
 
 	// Set "all" as the default command when just "generate" is called
-	generateCmd.Run = generateAllCmd.Run
+	generateCmd.RunE = generateAllCmd.RunE
 
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(generateCmd)
 	rootCmd.AddCommand(scanCmd)
 	rootCmd.AddCommand(cleanCmd)
+	rootCmd.AddCommand(fmtCmd)
+	rootCmd.AddCommand(devCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(notesCmd)
+	rootCmd.AddCommand(routesCmd)
+	rootCmd.AddCommand(graphCmd)
+	rootCmd.AddCommand(explainCmd)
+	rootCmd.AddCommand(scoreCmd)
+	rootCmd.AddCommand(doctorCmd)
+
+	auditCmd.AddCommand(auditTrafficCmd)
+	auditCmd.AddCommand(auditOwnersCmd)
+	rootCmd.AddCommand(auditCmd)
+
+	migrateCmd.AddCommand(migratePathCmd)
+	rootCmd.AddCommand(migrateCmd)
+
+	syncCmd.AddCommand(syncServerCmd)
+	rootCmd.AddCommand(syncCmd)
+
+	templatesCmd.AddCommand(templatesCheckCmd)
+	rootCmd.AddCommand(templatesCmd)
+
+	rootCmd.AddCommand(completionCmd)
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(bugreportCmd)
+	// completionCmd replaces cobra's default, which would initialize the container
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	// --version prints the same version as taskw version
+	rootCmd.Version = buildVersion()
 }
 
 // Execute runs the root command
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	var recorder *lastrun.Recorder
+	if cmd, _, err := rootCmd.Find(os.Args[1:]); err == nil && recorded(cmd) {
+		recorder = lastrun.Begin(cmd.CommandPath(), os.Args[1:], buildVersion())
+	}
+
+	// Ctrl-C and SIGTERM cancel the command: scans and external tools stop, and the files generated so
+	// far are rolled back. Once cancelled, a second signal exits right away
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	projectLock.Release()
+	if recorder != nil {
+		if recordErr := recorder.End(err); recordErr != nil {
+			ui.Debugf("Couldn't record the run in %s: %v", lastrun.File, recordErr)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			ui.Errorf("Interrupted before completing")
+		} else {
+			ui.Errorf("%v", err)
+		}
+		os.Exit(exitcode.Code(err))
 	}
 }
 
@@ -72,16 +303,35 @@ var initCmd = &cobra.Command{
 - internal/api/server.go - Server struct and providers
 - internal/api/wire.go - Wire dependency injection setup
 - internal/health/handler.go - Example health check handler
-- .air.toml - Live reload configuration
+- internal/logging/logger.go - Logger provider and request logging middleware,
+  using log/slog, zap or zerolog as chosen with --logger (default: slog)
 - Taskfile.yml - Task runner configuration
 - taskw.yaml - Taskw configuration
 - go.mod - Go module file
 
+With --batteries, the scaffold also includes:
+- internal/apperror - Error model rendered as JSON by the Fiber error handler
+- internal/validation - Request validation with go-playground/validator
+- internal/middleware - Request ID, recover and CORS middleware
+- internal/todo - Example CRUD domain using all of the above
+
 Requires a full Go module path (e.g., github.com/user/project-name).
 
+After scaffolding, init runs 'go mod tidy' and 'task generate'. Use --no-exec to
+skip these steps (e.g. in hermetic CI); failures are reported with a recovery command
+instead of aborting init. go mod tidy is retried with backoff, behind a corporate proxy
+pass --goproxy and --goprivate. --offline only uses the module cache and defers the
+dependencies missing from it.
+
 Examples:
   taskw init                                    # Interactive prompt for module
-  taskw init github.com/user/my-api             # Create project with specified module`,
+  taskw init github.com/user/my-api             # Create project with specified module
+  taskw init github.com/user/my-api --no-exec   # Scaffold only, don't run external commands
+  taskw init github.com/user/my-api --logger zap # Log with zap instead of log/slog
+  taskw init github.com/user/my-api --goproxy https://proxy.corp.example --goprivate 'github.com/corp/*'
+  taskw init github.com/user/my-api --offline   # Resolve dependencies from the module cache only
+  taskw init github.com/user/my-api --git       # Also run git init and create an initial commit
+  taskw init github.com/user/my-api --batteries # Include validation, error model, middleware and a CRUD example`,
 	RunE: handleInit,
 }
 
@@ -106,7 +356,7 @@ func handleInit(cmd *cobra.Command, args []string) error {
 
 	// Extract project name from module path
 	projectName := container.Project.ExtractProjectName(module)
-	projectPath := filepath.Join(".", projectName)
+	projectPath := container.Config.RelativeToRoot(projectName)
 
 	// Validate project directory
 	if err := container.Project.ValidateProjectPath(projectPath); err != nil {
@@ -116,7 +366,18 @@ func handleInit(cmd *cobra.Command, args []string) error {
 	stopSpinner := container.UI.ShowSpinner(fmt.Sprintf("Creating project %s...", projectName))
 
 	// Generate the project
-	if err := container.Project.InitProject(projectPath, module, projectName); err != nil {
+	opts := generator.InitOptions{
+		NoExec:    initNoExec,
+		Git:       initGit,
+		Batteries: initBatteries,
+		GoProxy:   initGoProxy,
+		GoPrivate: initGoPrivate,
+		Offline:   initOffline,
+		Logger:    initLogger,
+
+		ToolTimeout: container.Config.ToolTimeout(),
+	}
+	if err := container.Project.InitProject(cmd.Context(), projectPath, module, projectName, opts); err != nil {
 		stopSpinner("Project creation failed")
 		return fmt.Errorf("failed to create project: %w", err)
 	}
@@ -130,8 +391,80 @@ var generateCmd = &cobra.Command{
 	Short: "Generate code",
 	Long: `Generate various types of code from your annotated Go files:
 - all: Generate routes and dependencies (default)
-- routes: Generate Fiber route registration
-- deps/dependencies: Generate Wire dependency injection`,
+- routes: Generate route registration (Fiber, Gin, chi or net/http)
+- server: Generate the Server struct wiring the app to the router
+- deps/dependencies: Generate Wire dependency injection
+- pkgdocs: Generate per-package doc.go files
+- params: Generate UUID path parameter parsing helpers
+- ports: Generate service interfaces from the methods handlers call
+- recording: Generate request/response recording middleware
+- alerts: Generate Prometheus SLO alerting rules
+- chaos: Generate failure injection wrappers for chaos testing
+- envelope: Generate response envelope helpers
+- redirects: Generate redirects for paths moved by 'taskw migrate path'
+- swaggerui: Generate the swagger middleware serving the documentation
+- gateway: Generate Fiber routes serving @RPC annotated gRPC methods over HTTP
+- pii: Generate the report of endpoints handling personal data
+
+With --since <ref>, only packages with files changed since the git revision are parsed
+again, the others reuse their cached scan results. 'generate all --only routes,deps' runs
+some of the steps only, --package limits per-package files such as doc.go to some packages.
+
+With --check, nothing is written: the files are generated in memory and compared with the
+files on disk, and the command fails with exit code 8 and a diff of the stale ones.
+--dry-run prints the same diff without failing, to review what new annotations change.
+Files written by wire and swag are left out of both.
+
+Providers tagged "@Provider env=dev" are only generated with --env dev, or when
+generation.dependencies.env is dev, untagged providers are generated for every environment.
+
+Generated files record a checksum of their content after the header. A file edited by
+hand since is not overwritten, the command fails instead; --force overwrites it.
+
+Only one taskw command generates code in a project at a time, others wait for it up to
+--lock-timeout.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := initializeContainer(cmd, args); err != nil {
+			return err
+		}
+		if err := pipeline.CheckSteps(generateOnly); err != nil {
+			return err
+		}
+		container.Config.Generation.Only = generateOnly
+		container.Config.Generation.Packages = generatePackages
+		if cmd.Flags().Changed("env") {
+			container.Config.Generation.Dependencies.Env = generateEnv
+		}
+
+		// Concurrent runs would interleave their writes to the same generated files
+		var err error
+		if projectLock, err = lock.Acquire(cmd.Context(), container.Config.Root, generateLockTimeout, lock.Notify); err != nil {
+			return err
+		}
+		if err := limitScanToChanges(cmd.Context(), generateSince); err != nil {
+			return err
+		}
+
+		generateOutput = &generator.Output{Force: generateForce}
+		if generateCheck || generateDryRun {
+			generateOutput.Preview = generator.NewPreview()
+		} else {
+			generateOutput.Recording = generator.NewRecording()
+		}
+		container.Generation.SetOutput(generateOutput)
+		return nil
+	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		if generateOutput.Recording != nil {
+			return container.Generation.WriteManifest(cmd.Context(), generateOutput.Recording.Paths())
+		}
+		if generateDryRun {
+			return container.Generation.ShowChanges(generateOutput.Preview)
+		}
+		// Stale files aren't a usage error, the diff is what CI logs should show
+		cmd.SilenceUsage = true
+		return container.Generation.CheckGenerated(generateOutput.Preview)
+	},
 }
 
 var generateAllCmd = &cobra.Command{
@@ -139,16 +472,29 @@ var generateAllCmd = &cobra.Command{
 	Short: "Generate routes and dependencies",
 	Long:  `Generate both route registration and dependency injection code, plus Swagger documentation.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return container.Generation.GenerateAll()
+		return container.Generation.GenerateAll(cmd.Context())
 	},
 }
 
 var generateRoutesCmd = &cobra.Command{
 	Use:   "routes",
-	Short: "Generate Fiber route registration",
-	Long:  `Generate route registration code from handler functions with @Router annotations.`,
+	Short: "Generate route registration",
+	Long:  `Generate route registration code (Fiber, Gin, chi or net/http, see generation.routes.framework) from handler functions with @Router annotations.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return container.Generation.GenerateRoutes()
+		return container.Generation.GenerateRoutes(cmd.Context())
+	},
+}
+
+var generateServerCmd = &cobra.Command{
+	Use:   "server",
+	Short: "Generate the Server struct",
+	Long: `Generate a Server struct bundling the application with the generated router, plus
+ProvideServer and RegisterRoutes, into generation.server.output_file. Handler packages are
+wired through the router, so adding one never requires editing the server by hand.
+
+Enable with generation.server.enabled in taskw.yaml.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return container.Generation.GenerateServer(cmd.Context())
 	},
 }
 
@@ -158,7 +504,167 @@ var generateDepsCmd = &cobra.Command{
 	Short:   "Generate Wire dependency injection",
 	Long:    `Generate Wire dependency injection setup from provider functions.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return container.Generation.GenerateDependencies()
+		return container.Generation.GenerateDependencies(cmd.Context())
+	},
+}
+
+var generatePackageDocsCmd = &cobra.Command{
+	Use:   "pkgdocs",
+	Short: "Generate per-package documentation files",
+	Long: `Generate a doc.go file in every scanned package summarizing the routes, handlers, and
+providers it exposes, keeping GoDoc navigable without manual upkeep.
+
+Enable with generation.package_docs.enabled in taskw.yaml. Existing doc files that were
+not generated by taskw are never overwritten.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return container.Generation.GeneratePackageDocs(cmd.Context())
+	},
+}
+
+var generateParamsCmd = &cobra.Command{
+	Use:   "params",
+	Short: "Generate UUID path parameter parsing helpers",
+	Long: `Generate a Parse<Handler>Params helper for every route documenting UUID path parameters
+with @Param, e.g. @Param id path string true "User ID" format(uuid). The helper parses the
+parameters and answers 400 Bad Request when one isn't a valid UUID.
+
+Enable with generation.params.enabled in taskw.yaml. Helpers are written into the handler's
+package; existing files that were not generated by taskw are never overwritten.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return container.Generation.GenerateParams(cmd.Context())
+	},
+}
+
+var generatePortsCmd = &cobra.Command{
+	Use:   "ports",
+	Short: "Generate service interfaces from handler calls",
+	Long: `Generate a port interface for every concrete service a handler depends on, listing the
+methods the handler calls on its service field, e.g. h.service.GetUser(ctx, id). Switching
+the field to the interface decouples the handler from the implementation, e.g. for tests.
+
+Enable with generation.ports.enabled in taskw.yaml. Fields whose type ends with one of
+generation.ports.service_suffixes are services ("Service" by default). Interfaces are written
+into the handler's package; existing files that were not generated by taskw are never overwritten.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return container.Generation.GeneratePorts(cmd.Context())
+	},
+}
+
+var generateRecordingCmd = &cobra.Command{
+	Use:   "recording",
+	Short: "Generate request/response recording middleware",
+	Long: `Generate Fiber middleware that samples real request/response pairs of every scanned
+route into JSON fixtures, to seed tests and examples from production-like traffic.
+
+Enable with generation.recording.enabled in taskw.yaml. Fields listed in a handler's
+@Scrub annotation (e.g. // @Scrub password, token) are redacted from captured bodies.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return container.Generation.GenerateRecording(cmd.Context())
+	},
+}
+
+var generateAlertsCmd = &cobra.Command{
+	Use:   "alerts",
+	Short: "Generate Prometheus SLO alerting rules",
+	Long: `Generate a Prometheus alerting rules file from @SLO annotations on handlers,
+e.g. // @SLO p99=200ms, with one rule per route and latency target.
+
+Enable with generation.slo.enabled in taskw.yaml. The rules query a latency histogram
+labelled with the route pattern and HTTP method (see generation.slo.metric).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return container.Generation.GenerateSLOAlerts(cmd.Context())
+	},
+}
+
+var generateChaosCmd = &cobra.Command{
+	Use:   "chaos",
+	Short: "Generate failure injection wrappers for chaos testing",
+	Long: `Generate wrapper providers that add latency and error injection around providers
+annotated with // @ChaosWrap. The annotated providers must return an interface declared
+in their own package.
+
+The wrappers are only compiled with the chaos build tag (see generation.chaos.build_tag);
+regular builds call the original providers. Enable with generation.chaos.enabled in
+taskw.yaml. Latency and error rate can be overridden at runtime with the
+TASKW_CHAOS_LATENCY and TASKW_CHAOS_ERROR_RATE environment variables.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return container.Generation.GenerateChaos(cmd.Context())
+	},
+}
+
+var generateEnvelopeCmd = &cobra.Command{
+	Use:   "envelope",
+	Short: "Generate response envelope helpers",
+	Long: `Generate helpers wrapping JSON responses in a standard envelope, e.g.
+{"data": ..., "meta": ...} for successful responses and {"error": {"code", "message"}}
+for failed ones, into generation.envelope.package_dir.
+
+Enable with generation.envelope.enabled in taskw.yaml. Swagger generation then documents
+every response with the envelope, so handler annotations keep describing the payload only.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return container.Generation.GenerateEnvelope(cmd.Context())
+	},
+}
+
+var generatePIICmd = &cobra.Command{
+	Use:   "pii",
+	Short: "Generate the personal data report",
+	Long: `Generate a Markdown report (generation.pii.output_file) of the endpoints handling
+personal data, from @PII annotations on handlers and on the fields of their request and
+response types, e.g.:
+
+  Email string ` + "`json:\"email\"`" + ` // @PII email
+
+Enable with generation.pii.enabled in taskw.yaml. Swagger generation then marks the
+operations and schema properties involved with the x-pii extension.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return container.Generation.GeneratePIIReport(cmd.Context())
+	},
+}
+
+var generateRedirectsCmd = &cobra.Command{
+	Use:   "redirects",
+	Short: "Generate redirects for migrated paths",
+	Long: `Generate RegisterRedirects, serving the old paths recorded by 'taskw migrate path'
+(generation.redirects.migrations_file) until their removal date. Migrations past
+their removal date are no longer registered.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return container.Generation.GenerateRedirects(cmd.Context())
+	},
+}
+
+var generateSwaggerUICmd = &cobra.Command{
+	Use:   "swaggerui",
+	Short: "Generate the swagger middleware serving the documentation",
+	Long: `Generate RegisterSwaggerUI, mounting the gofiber/contrib/swagger middleware with the
+path, title, deep linking and cache settings of generation.swagger_ui, instead of
+configuring it by hand in main.go. The generated RegisterRoutes calls it before
+registering the handlers.
+
+Enable with generation.swagger_ui.enabled in taskw.yaml (Fiber v2 only).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return container.Generation.GenerateSwaggerUI(cmd.Context())
+	},
+}
+
+var generateGatewayCmd = &cobra.Command{
+	Use:   "gateway",
+	Short: "Generate Fiber routes serving gRPC methods over HTTP",
+	Long: `Generate the Gateway struct, serving every gRPC service method annotated with @RPC
+at the path of its @Router annotation, so a service exposed over both gRPC and HTTP
+keeps a single implementation:
+
+  // @RPC
+  // @Router /users/{id} [get]
+  func (s *Server) GetUser(ctx context.Context, req *userv1.GetUserRequest) (*userv1.User, error)
+
+Requests are decoded from the JSON body, query and path parameters into the request
+message, and responses and gRPC status errors are written as JSON. The generated
+RegisterRoutes registers the gateway routes after the handlers.
+
+Enable with generation.gateway.enabled in taskw.yaml (Fiber v2 only).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return container.Generation.GenerateGateway(cmd.Context())
 	},
 }
 
@@ -166,24 +672,118 @@ var scanCmd = &cobra.Command{
 	Use:   "scan",
 	Short: "Show what will be generated",
 	Long: `Scan the codebase and display what handlers, routes, and providers would be generated.
-This is useful for previewing changes before running generate.`,
+This is useful for previewing changes before running generate.
+
+With --format json or --format yaml, the full scan result and its validation findings are
+written to stdout instead, for CI pipelines and other tools. Exit codes are the same.
+
+--only and --package narrow the results down to one section and to some packages, e.g.
+'taskw scan --only routes --package user'. Validation still covers the whole project.
+
+Validation errors exit with code 4. validation.fail_on in taskw.yaml sets the lowest
+severity failing the scan (error, warning or none), --warnings-as-errors fails on
+warnings for one run.`,
 	RunE: handleScan,
 }
 
+// limitScanToChanges only re-parses the packages with files changed since a git revision,
+// the other packages reuse their cached scan results
+func limitScanToChanges(ctx context.Context, since string) error {
+	if since == "" {
+		return nil
+	}
+	files, err := container.Scan.ChangedFiles(ctx, since)
+	if err != nil {
+		return err
+	}
+	revision, err := container.Scan.RevisionFiles(ctx, since)
+	if err != nil {
+		return err
+	}
+	container.Config.Scanning.Changed = files
+	container.Config.Scanning.Revision = revision
+	return nil
+}
+
 func handleScan(cmd *cobra.Command, args []string) error {
+	// --providers predates --only
+	section := scanOnly
+	if scanProviders && section == "" {
+		section = scan.SectionProviders
+	}
+	if err := scan.CheckSection(section); err != nil {
+		return err
+	}
+	if scanStrict {
+		container.Config.Validation.FailOn = config.SeverityWarning
+	}
+
+	if scanChanged {
+		if err := limitScanToChanges(cmd.Context(), "HEAD"); err != nil {
+			return err
+		}
+	}
+
+	if scanFormat != scan.FormatText {
+		return writeScanReport(cmd.Context(), scanFormat, section)
+	}
+
 	// Scan all configured directories
-	result, err := container.Scan.ScanAll()
+	result, err := container.Scan.ScanAll(cmd.Context())
 	if err != nil {
 		return fmt.Errorf("scan failed: %w", err)
 	}
 
 	// Display results
-	if err := container.Scan.ShowScanResults(result); err != nil {
+	shown, err := container.Scan.Filter(result, scanPackages)
+	if err != nil {
+		return err
+	}
+	if err := container.Scan.ShowScanResults(shown, section); err != nil {
 		return fmt.Errorf("failed to show results: %w", err)
 	}
 
-	// Validate results
-	return container.Scan.ValidateScanResults(result)
+	if scanOrder {
+		if err := container.Scan.ShowProviderOrder(shown); err != nil {
+			return fmt.Errorf("failed to show provider order: %w", err)
+		}
+	}
+
+	// Validate results, annotations that failed to parse take precedence over convention violations
+	validateErr := container.Scan.ValidateScanResults(result)
+	if failures := result.Failures(); len(failures) > 0 {
+		return exitcode.New(exitcode.Scan, fmt.Errorf("%d scan error(s) found", len(failures)))
+	}
+	return validateErr
+}
+
+// writeScanReport scans and validates without progress output, and writes everything found as JSON or YAML,
+// narrowed down to the --package packages and the section
+func writeScanReport(ctx context.Context, format, section string) error {
+	if format != scan.FormatJSON && format != scan.FormatYAML {
+		return fmt.Errorf("unsupported format %q, use %s, %s or %s", format, scan.FormatText, scan.FormatJSON, scan.FormatYAML)
+	}
+
+	result, err := container.Scan.Scan(ctx)
+	if err != nil {
+		return err
+	}
+	validation, err := container.Scan.Validate(result)
+	if err != nil {
+		return err
+	}
+	shown, err := container.Scan.Filter(result, scanPackages)
+	if err != nil {
+		return err
+	}
+	if err := container.Scan.WriteReport(os.Stdout, scan.OnlySection(shown, section), validation, format); err != nil {
+		return err
+	}
+
+	if failures := result.Failures(); len(failures) > 0 {
+		return exitcode.New(exitcode.Scan, fmt.Errorf("%d scan error(s) found", len(failures)))
+	}
+	return container.Scan.CheckValidation(validation)
 }
 
 var cleanCmd = &cobra.Command{
@@ -193,20 +793,27 @@ var cleanCmd = &cobra.Command{
 - Route registration files
 - Dependency injection files  
 - Swagger documentation files
+- Any other file starting with a "Code generated by taskw" header, wherever the
+  settings of the run that wrote it put it
 
-This helps clean up the workspace when regenerating code or switching configurations.`,
+This helps clean up the workspace when regenerating code or switching configurations.
+--dry-run lists the files without deleting them.`,
 	RunE: handleClean,
 }
 
 func handleClean(cmd *cobra.Command, args []string) error {
-	deletedFiles, skippedFiles, err := container.Clean.Clean()
+	deletedFiles, skippedFiles, err := container.Clean.Clean(cleanDryRun)
 	if err != nil {
 		return fmt.Errorf("clean failed: %w", err)
 	}
 
 	// Report results
 	if len(deletedFiles) > 0 {
-		fmt.Printf("● Deleted %d files:\n", len(deletedFiles))
+		if cleanDryRun {
+			fmt.Printf("● Would delete %d files:\n", len(deletedFiles))
+		} else {
+			fmt.Printf("● Deleted %d files:\n", len(deletedFiles))
+		}
 		for _, file := range deletedFiles {
 			fmt.Printf("  - %s\n", file)
 		}
@@ -225,3 +832,700 @@ func handleClean(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [files...]",
+	Short: "Normalize taskw and swagger annotations",
+	Long: `Normalize taskw and swagger annotations and gofmt Go source files:
+- "//@router" becomes "// @Router"
+- @Router methods are lowercased ("[GET]" becomes "[get]")
+- list annotations are comma separated ("@Middleware auth audit" becomes "@Middleware auth, audit")
+- repeated whitespace between annotation arguments is collapsed
+
+With --stdin a single file is read from stdin and written to stdout, for editor
+format-on-save hooks. No taskw.yaml is needed.
+
+Examples:
+  taskw fmt internal/user/handler.go
+  taskw fmt --stdin < internal/user/handler.go`,
+	SilenceUsage: true,
+	// Formatting needs no config, skip container initialization so editor hooks stay fast
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	RunE:              handleFmt,
+}
+
+func handleFmt(cmd *cobra.Command, args []string) error {
+	if fmtStdin {
+		if len(args) > 0 {
+			return fmt.Errorf("--stdin does not accept file arguments")
+		}
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("error reading stdin: %w", err)
+		}
+		formatted, err := scanner.FormatAnnotations(src)
+		if err != nil {
+			return exitcode.New(exitcode.Scan, err)
+		}
+		_, err = os.Stdout.Write(formatted)
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("no files given (pass files or use --stdin)")
+	}
+
+	for _, path := range args {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", path, err)
+		}
+		formatted, err := scanner.FormatAnnotations(src)
+		if err != nil {
+			return exitcode.New(exitcode.Scan, fmt.Errorf("%s: %w", path, err))
+		}
+		if string(formatted) == string(src) {
+			continue
+		}
+		if err := os.WriteFile(path, formatted, 0644); err != nil {
+			return fmt.Errorf("error writing %s: %w", path, err)
+		}
+		ui.Detailf("Formatted: %s", path)
+	}
+
+	return nil
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Audit scanned routes against runtime data",
+	Long: `Audit the scanned route model against data collected at runtime:
+- traffic: Compare an access log with scanned routes
+- owners: Map routes to owning teams using CODEOWNERS`,
+}
+
+var auditTrafficCmd = &cobra.Command{
+	Use:   "traffic <access.log>",
+	Short: "Report orphan traffic and routes without traffic",
+	Long: `Parse an access log and match every request against the scanned routes. Reports
+requests hitting paths with no matching route (orphan traffic) and routes that
+received no requests at all.
+
+Supported log formats:
+- Common/Combined Log Format (nginx, Apache)
+- Fiber logger format used by the taskw scaffold
+- Any line containing "METHOD /path"
+
+Examples:
+  taskw audit traffic /var/log/nginx/access.log`,
+	Args: cobra.ExactArgs(1),
+	RunE: handleAuditTraffic,
+}
+
+func handleAuditTraffic(cmd *cobra.Command, args []string) error {
+	report, err := container.Audit.AuditTraffic(cmd.Context(), container.Config.RelativeToRoot(args[0]))
+	if err != nil {
+		return fmt.Errorf("audit failed: %w", err)
+	}
+
+	return container.Audit.ShowTrafficReport(report)
+}
+
+var auditOwnersCmd = &cobra.Command{
+	Use:   "owners",
+	Short: "Report the owning team of every route",
+	Long: `Combine the scanned routes with a CODEOWNERS file to report which team owns each
+route, based on the file declaring its handler. Routes in files no CODEOWNERS rule
+assigns are listed as unowned.
+
+With --fail-unowned (or ownership.require_owners in taskw.yaml) the command exits with
+an error when unowned routes exist, so CI can block new routes in unowned packages.
+
+Examples:
+  taskw audit owners
+  taskw audit owners --codeowners .github/CODEOWNERS --fail-unowned`,
+	RunE: handleAuditOwners,
+}
+
+func handleAuditOwners(cmd *cobra.Command, args []string) error {
+	report, err := container.Audit.AuditOwners(cmd.Context(), container.Config.RelativeToRoot(auditCodeowners))
+	if err != nil {
+		return fmt.Errorf("audit failed: %w", err)
+	}
+
+	if err := container.Audit.ShowOwnersReport(report); err != nil {
+		return err
+	}
+
+	unowned := report.UnownedRoutes()
+	if len(unowned) > 0 && (auditFailUnowned || container.Config.Ownership.RequireOwners) {
+		return exitcode.New(exitcode.Validation, fmt.Errorf("%d routes have no owner in %s", len(unowned), report.CodeOwnersPath))
+	}
+
+	return nil
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate routes without touching handlers",
+	Long: `Migrate routes while keeping existing clients working:
+- path: Serve an old path prefix from the routes now under a new one`,
+}
+
+var migratePathCmd = &cobra.Command{
+	Use:   "path <old>:<new>",
+	Short: "Redirect an old path prefix to its new location",
+	Long: `Record a path migration in generation.redirects.migrations_file and generate
+RegisterRedirects, which registers every scanned route under the new prefix at its
+old path as well. Old paths answer with a 308 (or 301) redirect to the new path, or
+with --proxy are served by the new handler directly. Responses carry Deprecation,
+Sunset and Link headers announcing the removal date.
+
+Move the @Router annotations to the new paths first; the migration fails when no
+scanned route lives under the new prefix or an old path still has a handler.
+
+Examples:
+  taskw migrate path /old/users:/api/v1/users
+  taskw migrate path /v1/orders:/v2/orders --status 301 --remove-after 2026-12-31
+  taskw migrate path /legacy:/api --proxy`,
+	Args: cobra.ExactArgs(1),
+	RunE: handleMigratePath,
+}
+
+func handleMigratePath(cmd *cobra.Command, args []string) error {
+	from, to, err := generator.ParsePathMigration(args[0])
+	if err != nil {
+		return err
+	}
+
+	migration := generator.RouteMigration{
+		From:        from,
+		To:          to,
+		Mode:        generator.MigrationRedirect,
+		Status:      migrateStatus,
+		RemoveAfter: migrateRemoveAfter,
+	}
+	if migrateProxy {
+		migration.Mode = generator.MigrationProxy
+	}
+	if migration.RemoveAfter == "" {
+		migration.RemoveAfter = time.Now().AddDate(0, 0, 90).Format("2006-01-02")
+	}
+
+	if err := container.Migrate.AddPathMigration(cmd.Context(), migration); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	return nil
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Update hand-written code for newly scanned handlers",
+	Long: `Patch hand-written code that taskw doesn't generate:
+- server: Add fields and constructor parameters for new handlers to a manual Server struct,
+  and remove those of handlers no route uses anymore`,
+}
+
+var syncServerCmd = &cobra.Command{
+	Use:   "server [file]",
+	Short: "Add newly scanned handlers to a hand-written Server struct, remove stale ones",
+	Long: `Rewrite a hand-written server file (default: server.go in paths.output_dir) so its
+Server struct holds a field for every scanned handler. For each missing handler the
+command adds the struct field, a parameter of the constructor returning *Server and
+its key in the constructor's composite literal, plus the import of the handler package.
+Handler fields no scanned route uses anymore are removed the same way and listed.
+Existing code and comments are kept as written.
+
+Projects without a manual server can let taskw own it with generation.server instead.
+
+Examples:
+  taskw sync server
+  taskw sync server internal/api/app.go --struct App`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: handleSyncServer,
+}
+
+func handleSyncServer(cmd *cobra.Command, args []string) error {
+	filePath := filepath.Join(container.Config.Paths.OutputDir, "server.go")
+	if len(args) == 1 {
+		filePath = container.Config.RelativeToRoot(args[0])
+	}
+
+	return container.Generation.SyncServer(cmd.Context(), filePath, syncServerStruct)
+}
+
+var devCmd = &cobra.Command{
+	Use:   "dev [-- server args]",
+	Short: "Regenerate, rebuild and restart the server on every change",
+	Long: `Run the server with live reloading, without Air. taskw dev generates code, builds
+dev.main_package and starts the binary with its output streamed to the terminal. Every
+change to a Go source file, go.mod or go.sum then regenerates code, rebuilds and restarts
+the server. Saves in quick succession are debounced into a single rebuild; generated files
+and tests are not watched.
+
+When generation or the build fails the errors are printed and the previous server keeps
+running until the next change. With the Wire backend, wire runs after every generation.
+
+Examples:
+  taskw dev
+  taskw dev --main ./cmd/api --debounce 500ms
+  taskw dev -- --port 8080`,
+	RunE: handleDev,
+}
+
+func handleDev(cmd *cobra.Command, args []string) error {
+	if devMainPackage != "" {
+		container.Config.Dev.MainPackage = container.Config.RelativeToRoot(devMainPackage)
+	}
+	if devDebounce != "" {
+		container.Config.Dev.Debounce = devDebounce
+	}
+
+	return container.Dev.Run(cmd.Context(), args)
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Regenerate code on every change to the scanned sources",
+	Long: `Watch paths.scan_dirs and regenerate routes, dependencies and the other enabled
+artifacts on every change to a Go source file, without building or running the server.
+Saves in quick succession are debounced into a single generation; generated files, tests
+and dev.exclude_dirs are not watched.
+
+Use it alongside your own build or reload tooling when taskw dev doesn't fit. Generation
+errors are printed and watching goes on until the next change.
+
+Examples:
+  taskw watch
+  taskw watch --swagger --debounce 500ms`,
+	RunE: handleWatch,
+}
+
+func handleWatch(cmd *cobra.Command, args []string) error {
+	if watchDebounce != "" {
+		container.Config.Dev.Debounce = watchDebounce
+	}
+
+	return container.Dev.Watch(cmd.Context(), watchSwagger)
+}
+
+var notesCmd = &cobra.Command{
+	Use:   "notes --since <tag>",
+	Short: "Write release notes for API changes since a git tag",
+	Long: `Compare the routes scanned at a git tag, branch or commit with the working tree and
+write a markdown release note section for API consumers:
+- New endpoints: routes added since, with their @Summary
+- Deprecated: routes marked @Deprecated since
+- Changed parameters: @Param annotations added, removed, retyped or made (not) required
+- Removed endpoints: routes that no longer exist
+
+Routes are matched by method and path, ignoring path parameter names. The old revision
+is scanned with the current taskw.yaml.
+
+Examples:
+  taskw notes --since v1.2.0
+  taskw notes --since v1.2.0 -o docs/api-changes.md`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         handleNotes,
+}
+
+func handleNotes(cmd *cobra.Command, args []string) error {
+	notes, err := container.Notes.Compare(cmd.Context(), notesSince)
+	if err != nil {
+		return err
+	}
+
+	if notesOutput == "" {
+		fmt.Print(notes.Markdown())
+		return nil
+	}
+
+	outputPath := container.Config.RelativeToRoot(notesOutput)
+	if err := os.WriteFile(outputPath, []byte(notes.Markdown()), 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", outputPath, err)
+	}
+	ui.Infof("✅ Wrote release notes to %s", outputPath)
+	return nil
+}
+
+var routesCmd = &cobra.Command{
+	Use:   "routes",
+	Short: "Print the route table of the generated router",
+	Long: `List every route the generated router registers, in registration order, with its
+method, path in the router's syntax, handler and the file and line of its @Router
+annotation. Routes matching the same requests as a route registered earlier, e.g.
+GET /users/:id and GET /users/:userId, are flagged as shadowed: the earlier one wins.
+
+Packages left out by generation.routes are left out of the table too.
+
+Examples:
+  taskw routes
+  taskw routes --format markdown > docs/routes.md
+  taskw routes --format json | jq '.[] | select(.shadowed_by)'`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         handleRoutes,
+}
+
+func handleRoutes(cmd *cobra.Command, args []string) error {
+	table, err := container.Routes.Table(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return container.Routes.Write(os.Stdout, table, routesFormat)
+}
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Draw the dependency graph of providers, handlers and the server",
+	Long: `Draw the dependency graph of the scanned providers as Graphviz DOT or a Mermaid flowchart.
+Every provider points to the providers of its parameters, labelled with the parameter type.
+Handler providers, the generated Router and, with generation.server enabled, the generated
+Server are highlighted. Parameter types no scanned provider returns are drawn as dashed red
+nodes: wire fails on them unless they are provided outside taskw.
+
+Providers tagged for another environment than generation.dependencies.env are left out.
+
+Examples:
+  taskw graph | dot -Tsvg > graph.svg
+  taskw graph --format mermaid > docs/wiring.mmd`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         handleGraph,
+}
+
+func handleGraph(cmd *cobra.Command, args []string) error {
+	dependencies, err := container.Graph.Build(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if err := container.Graph.Write(os.Stdout, dependencies, graphFormat); err != nil {
+		return err
+	}
+
+	// Reported on stderr so the graph can be piped
+	if missing := dependencies.Missing(); len(missing) > 0 {
+		types := make([]string, len(missing))
+		for i, node := range missing {
+			types[i], _, _ = strings.Cut(node.Label, "\n")
+		}
+		fmt.Fprintf(os.Stderr, "⚠️  No scanned provider returns %s\n", strings.Join(types, ", "))
+	}
+	return nil
+}
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <file>:<function>",
+	Short: "Explain why a function is or isn't detected as a handler, route or provider",
+	Long: `Run the scanner on a single function and report every detection rule it evaluates:
+whether the file is scanned (scan_dirs, ignore patterns, build constraints, taskw:ignore),
+the receiver suffix and signature of handlers, the @Router annotation of routes and the prefix
+of providers, followed by what the function was detected as.
+
+Methods can be qualified by their receiver type when several share a name.
+
+Examples:
+  taskw explain ./internal/user/handler.go:GetUser
+  taskw explain ./internal/user/handler.go:Handler.GetUser
+  taskw explain ./internal/user/service.go:ProvideService`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         handleExplain,
+}
+
+func handleExplain(cmd *cobra.Command, args []string) error {
+	explanations, err := container.Scan.Explain(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+	container.Scan.ShowExplanations(explanations)
+	return nil
+}
+
+var scoreCmd = &cobra.Command{
+	Use:   "score",
+	Short: "Rate the project's health against taskw conventions",
+	Long: `Aggregate annotation coverage, validation errors, orphan providers, route conflicts and the
+staleness of generated files into a score out of 100, listing what each metric counted.
+
+Annotation coverage is the share of handlers with @Router and @Summary annotations. Staleness
+compares the routes, providers and generated files recorded in .taskw/manifest.json by the last
+taskw generate with the project as it is now.
+
+The command fails when the score or a metric breaks a threshold of the score section of taskw.yaml,
+so it can gate CI.
+
+Examples:
+  taskw score
+  taskw score --format json > score.json`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         handleScore,
+}
+
+func handleScore(cmd *cobra.Command, args []string) error {
+	report, err := container.Score.Report(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if err := container.Score.Write(os.Stdout, report, scoreFormat); err != nil {
+		return err
+	}
+	return container.Score.Check(report)
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the tools and project setup taskw needs",
+	Long: `Check everything taskw relies on and print a fix for each problem:
+- go, wire, swag, task, air and git: installed in PATH, with their versions
+- taskw.yaml: found and valid
+- go.mod: found, with the module set as project.module
+- paths.scan_dirs: every directory exists
+
+Missing go, a missing wire with the wire backend, an invalid taskw.yaml, a module
+mismatch or a missing scan directory fail the command; anything else is reported
+as a warning. Exits with code 6 when the first failure is a tool, 2 otherwise.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	// A broken taskw.yaml is one of the things to diagnose, skip container initialization that would fail on it
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	RunE:              handleDoctor,
+}
+
+func handleDoctor(cmd *cobra.Command, args []string) error {
+	service := doctor.ProvideDoctorService()
+	return service.Report(service.Diagnose())
+}
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Manage the code generation templates",
+	Long: `Manage the code generation templates:
+- check: Render every template with synthetic data and validate the output
+
+A file in paths.templates_dir named like an embedded template, e.g. routes.tmpl,
+replaces that template in every generate command.`,
+}
+
+var templatesCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Validate embedded and overridden templates",
+	Long: `Render every template, embedded or overridden from paths.templates_dir, against a
+synthetic project for each route framework and dependency backend, then check the output:
+- Go files must parse and gofmt, and carry the "Code generated by taskw" header
+- Imports must be used and declared once, top-level declarations must be unique
+- YAML files must parse
+
+Run it after editing a template override, before it breaks real generation.
+
+Examples:
+  taskw templates check`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         handleTemplatesCheck,
+}
+
+func handleTemplatesCheck(cmd *cobra.Command, args []string) error {
+	return container.Templates.Check(cmd.Context())
+}
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate the autocompletion script for the specified shell",
+	Long: `Write the autocompletion script of taskw for the given shell to stdout.
+
+Examples:
+  # bash, for the current session, then for every new one
+  source <(taskw completion bash)
+  taskw completion bash > /etc/bash_completion.d/taskw
+
+  # zsh, needs "autoload -U compinit; compinit" in ~/.zshrc
+  taskw completion zsh > "${fpath[1]}/_taskw"
+
+  # fish
+  taskw completion fish > ~/.config/fish/completions/taskw.fish
+
+  # PowerShell, add the output to your profile
+  taskw completion powershell | Out-String | Invoke-Expression`,
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	DisableFlagsInUseLine: true,
+	SilenceUsage:          true,
+	// Completion scripts don't depend on the project, skip container initialization so they work anywhere
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	RunE:              handleCompletion,
+}
+
+func handleCompletion(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	switch args[0] {
+	case "bash":
+		return rootCmd.GenBashCompletionV2(out, true)
+	case "zsh":
+		return rootCmd.GenZshCompletion(out)
+	case "fish":
+		return rootCmd.GenFishCompletion(out, true)
+	case "powershell":
+		return rootCmd.GenPowerShellCompletionWithDesc(out)
+	}
+	return fmt.Errorf("unsupported shell %q", args[0])
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version, commit and build date of taskw",
+	Long: `Print the version, commit and build date of taskw, with the Go version and platform it
+was built for. Release binaries get them at build time; binaries installed with go install
+report the module version and the commit from the Go build information.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	// The version is needed outside of projects too, e.g. in bug reports
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	Run: func(cmd *cobra.Command, args []string) {
+		rev, built := buildCommit()
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "taskw %s\n", buildVersion())
+		fmt.Fprintf(out, "  commit:     %s\n", orUnknown(rev))
+		fmt.Fprintf(out, "  built:      %s\n", orUnknown(built))
+		fmt.Fprintf(out, "  go version: %s\n", runtime.Version())
+		fmt.Fprintf(out, "  platform:   %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	},
+}
+
+// buildVersion returns the version set at build time, or the module version of go install builds
+func buildVersion() string {
+	if version != "dev" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return version
+}
+
+// buildCommit returns the commit and build date set at build time, falling back to the VCS
+// revision and commit time Go records when building from a checkout
+func buildCommit() (string, string) {
+	rev, built := commit, date
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && rev == "":
+				rev = setting.Value
+			case setting.Key == "vcs.time" && built == "":
+				built = setting.Value
+			case setting.Key == "vcs.modified" && setting.Value == "true" && commit == "" && rev != "":
+				rev += "-dirty"
+			}
+		}
+	}
+	return rev, built
+}
+
+// recorded reports whether a run of cmd is left in .taskw/last-run.json. Help, completion, version
+// and bugreport don't touch the project, recording them would hide the run worth reporting
+func recorded(cmd *cobra.Command) bool {
+	switch {
+	case cmd == rootCmd, cmd == completionCmd, cmd == versionCmd, cmd == bugreportCmd:
+		return false
+	case cmd.Name() == "help", strings.HasPrefix(cmd.Name(), cobra.ShellCompRequestCmd):
+		return false
+	}
+	return true
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
+
+var bugreportOutput string
+
+var bugreportCmd = &cobra.Command{
+	Use:   "bugreport",
+	Short: "Bundle version, environment and the last run into a bug report",
+	Long: `Print a Markdown bug report to attach to an issue or support request:
+- taskw version, commit and build date, Go version and platform
+- The tool and project checks of taskw doctor
+- taskw.yaml
+- .taskw/last-run.json: the command run last in the project, how it ended and every scan
+  error and validation finding it hit
+
+Run the failing command first, then taskw bugreport from the same project.
+
+Examples:
+  taskw generate all
+  taskw bugreport -o bugreport.md`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	// A broken taskw.yaml is worth reporting, skip container initialization that would fail on it
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	RunE:              handleBugreport,
+}
+
+func handleBugreport(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+	if bugreportOutput != "" {
+		file, err := os.Create(bugreportOutput)
+		if err != nil {
+			return fmt.Errorf("error creating %s: %w", bugreportOutput, err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := writeBugreport(out); err != nil {
+		return err
+	}
+	if bugreportOutput != "" {
+		ui.Infof("📝 Bug report written to %s", bugreportOutput)
+	}
+	return nil
+}
+
+// writeBugreport writes the bug report of the project of the working directory as Markdown
+func writeBugreport(out io.Writer) error {
+	rev, built := buildCommit()
+	var b strings.Builder
+	b.WriteString("## taskw bug report\n\n")
+	fmt.Fprintf(&b, "- taskw: %s\n", buildVersion())
+	fmt.Fprintf(&b, "- commit: %s\n", orUnknown(rev))
+	fmt.Fprintf(&b, "- built: %s\n", orUnknown(built))
+	fmt.Fprintf(&b, "- go version: %s\n", runtime.Version())
+	fmt.Fprintf(&b, "- platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+
+	b.WriteString("\n### Doctor\n\n")
+	for _, check := range doctor.ProvideDoctorService().Diagnose() {
+		fmt.Fprintf(&b, "- [%s] %s: %s\n", check.Status, check.Name, check.Detail)
+	}
+
+	root, ok := lastrun.ProjectRoot()
+	if !ok {
+		b.WriteString("\nNo project found in the working directory or its parents.\n")
+		_, err := io.WriteString(out, b.String())
+		return err
+	}
+
+	b.WriteString("\n### taskw.yaml\n\n")
+	if content, err := os.ReadFile(filepath.Join(root, config.ConfigFileName)); err == nil {
+		fmt.Fprintf(&b, "```yaml\n%s\n```\n", strings.TrimRight(string(content), "\n"))
+	} else {
+		b.WriteString("Not found, the defaults are used.\n")
+	}
+
+	fmt.Fprintf(&b, "\n### Last run (%s)\n\n", lastrun.File)
+	if content, err := os.ReadFile(filepath.Join(root, lastrun.File)); err == nil {
+		fmt.Fprintf(&b, "```json\n%s\n```\n", strings.TrimRight(string(content), "\n"))
+	} else if os.IsNotExist(err) {
+		b.WriteString("No command has run in this project yet, run the failing command and try again.\n")
+	} else {
+		return fmt.Errorf("error reading %s: %w", lastrun.File, err)
+	}
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
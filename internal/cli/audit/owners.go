@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// OwnersReport maps scanned routes to the owners of the files declaring them
+type OwnersReport struct {
+	CodeOwnersPath string
+	Routes         []RouteOwnership
+}
+
+// RouteOwnership lists the owners of a scanned route
+type RouteOwnership struct {
+	Route  scanner.RouteMapping
+	Owners []string // Empty if no CODEOWNERS rule assigns the route's file
+}
+
+// UnownedRoutes returns the routes without an owner
+func (r *OwnersReport) UnownedRoutes() []scanner.RouteMapping {
+	var unowned []scanner.RouteMapping
+	for _, ownership := range r.Routes {
+		if len(ownership.Owners) == 0 {
+			unowned = append(unowned, ownership.Route)
+		}
+	}
+	return unowned
+}
+
+// RoutesByOwner groups routes by owner; a route with several owners appears under each of them
+func (r *OwnersReport) RoutesByOwner() map[string][]scanner.RouteMapping {
+	byOwner := make(map[string][]scanner.RouteMapping)
+	for _, ownership := range r.Routes {
+		for _, owner := range ownership.Owners {
+			byOwner[owner] = append(byOwner[owner], ownership.Route)
+		}
+	}
+	return byOwner
+}
+
+// AuditOwners maps scanned routes to their owners using a CODEOWNERS file
+// An empty path falls back to ownership.codeowners_file, then the standard CODEOWNERS locations
+func (s *service) AuditOwners(ctx context.Context, codeownersPath string) (*OwnersReport, error) {
+	stopSpinner := s.ui.ShowSpinner("Mapping routes to code owners...")
+
+	if codeownersPath == "" {
+		codeownersPath = s.config.Ownership.CodeownersFile
+	}
+
+	owners, err := scanner.LoadCodeOwners(s.config.Root, codeownersPath)
+	if err != nil {
+		stopSpinner("Audit failed")
+		return nil, exitcode.New(exitcode.Config, err)
+	}
+
+	result, err := s.scanner.ScanAll(ctx)
+	if err != nil {
+		stopSpinner("Scan failed")
+		return nil, exitcode.New(exitcode.Scan, fmt.Errorf("error scanning: %w", err))
+	}
+
+	report := &OwnersReport{CodeOwnersPath: owners.Path}
+	for _, route := range result.Routes {
+		report.Routes = append(report.Routes, RouteOwnership{
+			Route:  route,
+			Owners: owners.Owners(route.FilePath),
+		})
+	}
+	sort.Slice(report.Routes, func(i, j int) bool {
+		a, b := report.Routes[i].Route, report.Routes[j].Route
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.HTTPMethod < b.HTTPMethod
+	})
+
+	stopSpinner("Ownership audit completed")
+	return report, nil
+}
+
+// ShowOwnersReport displays a route ownership report to the user
+func (s *service) ShowOwnersReport(report *OwnersReport) error {
+	unowned := report.UnownedRoutes()
+	byOwner := report.RoutesByOwner()
+
+	fmt.Printf("\nRoute Ownership (%s):\n", report.CodeOwnersPath)
+	fmt.Printf("  • Routes scanned: %d\n", len(report.Routes))
+	fmt.Printf("  • Owners: %d\n", len(byOwner))
+	fmt.Printf("  • Unowned routes: %d\n", len(unowned))
+
+	if len(byOwner) > 0 {
+		owners := make([]string, 0, len(byOwner))
+		for owner := range byOwner {
+			owners = append(owners, owner)
+		}
+		sort.Strings(owners)
+
+		fmt.Println("\nRoutes by Owner:")
+		for _, owner := range owners {
+			fmt.Printf("  %s\n", owner)
+			for _, route := range byOwner[owner] {
+				fmt.Printf("    - %s %s -> %s\n", route.HTTPMethod, route.Path, route.HandlerRef)
+			}
+		}
+	}
+
+	if len(unowned) > 0 {
+		fmt.Println("\nUnowned Routes:")
+		for _, route := range unowned {
+			fmt.Printf("  - %s %s -> %s (%s)\n", route.HTTPMethod, route.Path, route.HandlerRef, route.FilePath)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,250 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
+	"github.com/nkaewam/taskw/internal/cli/ui"
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// Service handles auditing scanned routes against runtime data
+type Service interface {
+	// AuditTraffic matches requests from an access log against scanned routes
+	AuditTraffic(ctx context.Context, logPath string) (*TrafficReport, error)
+	// ShowTrafficReport displays a traffic audit report to the user
+	ShowTrafficReport(report *TrafficReport) error
+	// AuditOwners maps scanned routes to their owners using a CODEOWNERS file
+	AuditOwners(ctx context.Context, codeownersPath string) (*OwnersReport, error)
+	// ShowOwnersReport displays a route ownership report to the user
+	ShowOwnersReport(report *OwnersReport) error
+}
+
+// TrafficReport contains the result of matching access log requests against scanned routes
+type TrafficReport struct {
+	LinesRead    int
+	LinesSkipped int             // Lines that couldn't be parsed as requests
+	RouteHits    []RouteHits     // Traffic per scanned route
+	Orphans      []OrphanTraffic // Requests that match no scanned route
+}
+
+// RouteHits counts requests that matched a scanned route
+type RouteHits struct {
+	Route scanner.RouteMapping
+	Hits  int
+}
+
+// OrphanTraffic counts requests to a method and path with no matching scanned route
+type OrphanTraffic struct {
+	Method string
+	Path   string
+	Hits   int
+}
+
+// UnusedRoutes returns the routes that received no traffic
+func (r *TrafficReport) UnusedRoutes() []scanner.RouteMapping {
+	var unused []scanner.RouteMapping
+	for _, hits := range r.RouteHits {
+		if hits.Hits == 0 {
+			unused = append(unused, hits.Route)
+		}
+	}
+	return unused
+}
+
+// service implements Service interface
+type service struct {
+	config  *config.Config
+	scanner *scanner.Scanner
+	ui      ui.Service
+}
+
+// ProvideAuditService creates a new audit service
+// @Provider
+func ProvideAuditService(config *config.Config, uiService ui.Service) Service {
+	scan := scanner.NewScanner(config)
+	scan.SetObserver(ui.NewScanProgress())
+	return &service{
+		config:  config,
+		scanner: scan,
+		ui:      uiService,
+	}
+}
+
+// accessLogPatterns recognizes request lines in common access log formats
+var accessLogPatterns = []*regexp.Regexp{
+	// Common/Combined Log Format: 127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /users HTTP/1.1" 200 2326
+	regexp.MustCompile(`"([A-Z]+) (\S+) HTTP/[0-9.]+"`),
+	// Fiber logger format used by the scaffold: [15:04:05] 200 - GET /users - 1.2ms
+	regexp.MustCompile(`\]\s+\d{3}\s+-\s+([A-Z]+)\s+(\S+)`),
+	// Fallback: any METHOD /path pair on the line
+	regexp.MustCompile(`\b(GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS|TRACE|CONNECT)\s+(/\S*)`),
+}
+
+// AuditTraffic matches requests from an access log against scanned routes
+func (s *service) AuditTraffic(ctx context.Context, logPath string) (*TrafficReport, error) {
+	stopSpinner := s.ui.ShowSpinner("Auditing traffic against scanned routes...")
+
+	result, err := s.scanner.ScanAll(ctx)
+	if err != nil {
+		stopSpinner("Scan failed")
+		return nil, exitcode.New(exitcode.Scan, fmt.Errorf("error scanning: %w", err))
+	}
+
+	file, err := os.Open(logPath)
+	if err != nil {
+		stopSpinner("Audit failed")
+		return nil, fmt.Errorf("error opening access log: %w", err)
+	}
+	defer file.Close()
+
+	matchers := make([]routeMatcher, len(result.Routes))
+	report := &TrafficReport{RouteHits: make([]RouteHits, len(result.Routes))}
+	for i, route := range result.Routes {
+		matchers[i] = newRouteMatcher(route)
+		report.RouteHits[i].Route = route
+	}
+
+	// Try routes with fewer parameters first so /users/me wins over /users/{id}
+	order := make([]int, len(matchers))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return matchers[order[i]].params < matchers[order[j]].params
+	})
+
+	orphans := make(map[string]*OrphanTraffic)
+	lines := bufio.NewScanner(file)
+	lines.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lines.Scan() {
+		report.LinesRead++
+
+		method, path, ok := parseAccessLogLine(lines.Text())
+		if !ok {
+			report.LinesSkipped++
+			continue
+		}
+
+		matched := false
+		for _, i := range order {
+			if matchers[i].matches(method, path) {
+				report.RouteHits[i].Hits++
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			key := method + " " + path
+			if _, exists := orphans[key]; !exists {
+				orphans[key] = &OrphanTraffic{Method: method, Path: path}
+			}
+			orphans[key].Hits++
+		}
+	}
+	if err := lines.Err(); err != nil {
+		stopSpinner("Audit failed")
+		return nil, fmt.Errorf("error reading access log: %w", err)
+	}
+
+	for _, orphan := range orphans {
+		report.Orphans = append(report.Orphans, *orphan)
+	}
+	sort.Slice(report.Orphans, func(i, j int) bool {
+		if report.Orphans[i].Hits != report.Orphans[j].Hits {
+			return report.Orphans[i].Hits > report.Orphans[j].Hits
+		}
+		return report.Orphans[i].Method+report.Orphans[i].Path < report.Orphans[j].Method+report.Orphans[j].Path
+	})
+
+	stopSpinner("Traffic audit completed")
+	return report, nil
+}
+
+// ShowTrafficReport displays a traffic audit report to the user
+func (s *service) ShowTrafficReport(report *TrafficReport) error {
+	unused := report.UnusedRoutes()
+
+	fmt.Printf("\nTraffic Audit:\n")
+	fmt.Printf("  • Log lines read: %d\n", report.LinesRead)
+	if report.LinesSkipped > 0 {
+		fmt.Printf("  • Unrecognized lines: %d\n", report.LinesSkipped)
+	}
+	fmt.Printf("  • Routes scanned: %d\n", len(report.RouteHits))
+	fmt.Printf("  • Routes without traffic: %d\n", len(unused))
+	fmt.Printf("  • Orphan request paths: %d\n", len(report.Orphans))
+
+	if len(report.Orphans) > 0 {
+		fmt.Println("\nOrphan Traffic (no matching route):")
+		for _, orphan := range report.Orphans {
+			fmt.Printf("  - %s %s (%d requests)\n", orphan.Method, orphan.Path, orphan.Hits)
+		}
+	}
+
+	if len(unused) > 0 {
+		fmt.Println("\nRoutes Without Traffic:")
+		for _, route := range unused {
+			fmt.Printf("  - %s %s -> %s\n", route.HTTPMethod, route.Path, route.HandlerRef)
+		}
+	}
+
+	return nil
+}
+
+// parseAccessLogLine extracts the request method and path (without query string) from a log line
+func parseAccessLogLine(line string) (string, string, bool) {
+	for _, pattern := range accessLogPatterns {
+		if matches := pattern.FindStringSubmatch(line); matches != nil {
+			path := matches[2]
+			if index := strings.IndexAny(path, "?#"); index >= 0 {
+				path = path[:index]
+			}
+			return strings.ToUpper(matches[1]), path, true
+		}
+	}
+	return "", "", false
+}
+
+// routeMatcher matches request paths against a scanned route pattern
+type routeMatcher struct {
+	method  string
+	pattern *regexp.Regexp
+	params  int // Number of parameter and wildcard segments
+}
+
+// newRouteMatcher builds a matcher for a route, supporting {param}, :param and * wildcard segments
+func newRouteMatcher(route scanner.RouteMapping) routeMatcher {
+	params := 0
+	segments := strings.Split(strings.Trim(route.Path, "/"), "/")
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, "*") || strings.HasSuffix(segment, "...}"):
+			segments[i] = ".*"
+			params++
+		case strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "{"):
+			segments[i] = "[^/]+"
+			params++
+		default:
+			segments[i] = regexp.QuoteMeta(segment)
+		}
+	}
+
+	return routeMatcher{
+		method:  route.HTTPMethod,
+		pattern: regexp.MustCompile("^/" + strings.Join(segments, "/") + "/?$"),
+		params:  params,
+	}
+}
+
+// matches checks if a request method and path are served by the route
+func (m routeMatcher) matches(method, path string) bool {
+	return m.method == method && m.pattern.MatchString(path)
+}
@@ -1,18 +1,21 @@
 package clean
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/nkaewam/taskw/internal/cli/file"
 	"github.com/nkaewam/taskw/internal/cli/ui"
 	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/generator"
+	"github.com/nkaewam/taskw/internal/scanner"
 )
 
 // Service handles cleanup of generated files
 type Service interface {
-	// Clean removes all generated files and reports what was cleaned
-	Clean() (deletedFiles []string, skippedFiles []string, err error)
+	// Clean removes all generated files and reports what was cleaned, with dryRun only what would be
+	Clean(dryRun bool) (deletedFiles []string, skippedFiles []string, err error)
 }
 
 // service implements Service interface
@@ -32,66 +35,102 @@ func ProvideCleanService(config *config.Config, uiService ui.Service, fileServic
 	}
 }
 
-// Clean removes all generated files and reports what was cleaned
-func (s *service) Clean() ([]string, []string, error) {
+// Clean removes the files named by the configuration, every file bearing the taskw header wherever it
+// was generated, the swagger documentation and the .taskw state, and reports what was cleaned.
+// With dryRun nothing is removed, the files that would be are reported as deleted
+func (s *service) Clean(dryRun bool) ([]string, []string, error) {
 	stopSpinner := s.ui.ShowSpinner("Cleaning generated files...")
 
 	var deletedFiles []string
 	var skippedFiles []string
+	seen := make(map[string]bool)
 
-	// Clean routes file
-	if s.config.Generation.Routes.Enabled {
-		routesPath := filepath.Join(s.config.Paths.OutputDir, s.config.Generation.Routes.OutputFile)
-		if deleted, err := s.fileService.DeleteIfExists(routesPath); err != nil {
-			stopSpinner("Clean completed with errors")
-			return deletedFiles, skippedFiles, err
-		} else if deleted {
-			deletedFiles = append(deletedFiles, routesPath)
-		} else {
-			skippedFiles = append(skippedFiles, routesPath)
+	remove := func(path string) (bool, error) {
+		if dryRun {
+			_, err := os.Stat(path)
+			return err == nil, nil
+		}
+		return s.fileService.DeleteIfExists(path)
+	}
+	// clean removes a file, reporting it as skipped when reportMissing and it doesn't exist
+	clean := func(path string, reportMissing bool) (bool, error) {
+		path = filepath.Clean(path)
+		if seen[path] {
+			return false, nil
+		}
+		seen[path] = true
+		deleted, err := remove(path)
+		if err != nil {
+			return false, err
 		}
+		if deleted {
+			deletedFiles = append(deletedFiles, path)
+		} else if reportMissing {
+			skippedFiles = append(skippedFiles, path)
+		}
+		return deleted, nil
 	}
 
-	// Clean dependencies file
+	var paths []string
+	if s.config.Generation.Routes.Enabled {
+		paths = append(paths, filepath.Join(s.config.Paths.OutputDir, s.config.Generation.Routes.OutputFile))
+	}
 	if s.config.Generation.Dependencies.Enabled {
-		depsPath := filepath.Join(s.config.Paths.OutputDir, s.config.Generation.Dependencies.OutputFile)
-		if deleted, err := s.fileService.DeleteIfExists(depsPath); err != nil {
-			stopSpinner("Clean completed with errors")
-			return deletedFiles, skippedFiles, err
-		} else if deleted {
-			deletedFiles = append(deletedFiles, depsPath)
-		} else {
-			skippedFiles = append(skippedFiles, depsPath)
-		}
+		paths = append(paths, filepath.Join(s.config.Paths.OutputDir, s.config.Generation.Dependencies.OutputFile))
 	}
 
-	// Clean swagger documentation
+	// Swagger documentation, written by swag and converted to OpenAPI 3 without the taskw header
 	docsDir := "docs"
-	swaggerFiles := []string{
+	paths = append(paths,
 		filepath.Join(docsDir, "docs.go"),
 		filepath.Join(docsDir, "swagger.json"),
 		filepath.Join(docsDir, "swagger.yaml"),
+		filepath.Join(docsDir, "openapi.json"),
+		filepath.Join(docsDir, "openapi.yaml"),
+	)
+
+	for _, path := range paths {
+		if _, err := clean(path, true); err != nil {
+			stopSpinner("Clean completed with errors")
+			return deletedFiles, skippedFiles, err
+		}
 	}
 
-	for _, swaggerFile := range swaggerFiles {
-		if deleted, err := s.fileService.DeleteIfExists(swaggerFile); err != nil {
+	// Files generated under other settings, e.g. before output_file or output_dir changed
+	generated, err := generator.FindGeneratedFiles(".")
+	if err != nil {
+		stopSpinner("Clean completed with errors")
+		return deletedFiles, skippedFiles, fmt.Errorf("failed to search generated files: %w", err)
+	}
+	for _, path := range generated {
+		if _, err := clean(path, false); err != nil {
 			stopSpinner("Clean completed with errors")
 			return deletedFiles, skippedFiles, err
-		} else if deleted {
-			deletedFiles = append(deletedFiles, swaggerFile)
-		} else {
-			skippedFiles = append(skippedFiles, swaggerFile)
 		}
 	}
 
 	// Try to remove docs directory if it's empty
-	if _, err := os.Stat(docsDir); err == nil {
+	if _, err := os.Stat(docsDir); err == nil && !dryRun {
 		if err := os.Remove(docsDir); err == nil {
 			deletedFiles = append(deletedFiles, docsDir+"/")
 		}
 		// Ignore error if directory is not empty - that's fine
 	}
 
+	// The scan cache is rebuilt by the next scan and the manifest describes the files just removed.
+	// The lock file is kept: a generate waiting for the lock may already have it open, and removing it
+	// would let the next generate lock a new file and run alongside it
+	for _, path := range []string{scanner.CacheFile, generator.ManifestFile} {
+		deleted, err := clean(path, false)
+		if err != nil {
+			stopSpinner("Clean completed with errors")
+			return deletedFiles, skippedFiles, err
+		}
+		if deleted && !dryRun {
+			os.Remove(filepath.Dir(path))
+		}
+	}
+
 	stopSpinner("Clean completed successfully")
 	return deletedFiles, skippedFiles, nil
 }
@@ -4,11 +4,20 @@ package cli
 
 import (
 	"github.com/google/wire"
+	"github.com/nkaewam/taskw/internal/cli/audit"
 	"github.com/nkaewam/taskw/internal/cli/clean"
+	"github.com/nkaewam/taskw/internal/cli/dev"
+	"github.com/nkaewam/taskw/internal/cli/doctor"
 	"github.com/nkaewam/taskw/internal/cli/file"
 	"github.com/nkaewam/taskw/internal/cli/generation"
+	"github.com/nkaewam/taskw/internal/cli/graph"
+	"github.com/nkaewam/taskw/internal/cli/migrate"
+	"github.com/nkaewam/taskw/internal/cli/notes"
 	"github.com/nkaewam/taskw/internal/cli/project"
+	"github.com/nkaewam/taskw/internal/cli/routes"
 	"github.com/nkaewam/taskw/internal/cli/scan"
+	"github.com/nkaewam/taskw/internal/cli/score"
+	"github.com/nkaewam/taskw/internal/cli/templates"
 	"github.com/nkaewam/taskw/internal/cli/ui"
 	"github.com/nkaewam/taskw/internal/config"
 )
@@ -16,24 +25,51 @@ import (
 // GeneratedProviderSet contains all discovered Provide* functions
 var GeneratedProviderSet = wire.NewSet(
 
+	// audit module providers
+	audit.ProvideAuditService,
+
 	// clean module providers
 	clean.ProvideCleanService,
 
 	// config module providers
 	config.ProvideConfig,
 
+	// dev module providers
+	dev.ProvideDevService,
+
+	// doctor module providers
+	doctor.ProvideDoctorService,
+
 	// file module providers
 	file.ProvideFileService,
 
 	// generation module providers
 	generation.ProvideGenerationService,
 
+	// graph module providers
+	graph.ProvideGraphService,
+
+	// migrate module providers
+	migrate.ProvideMigrateService,
+
+	// notes module providers
+	notes.ProvideNotesService,
+
 	// project module providers
 	project.ProvideProjectService,
 
+	// routes module providers
+	routes.ProvideRoutesService,
+
 	// scan module providers
 	scan.ProvideScanService,
 
+	// score module providers
+	score.ProvideScoreService,
+
+	// templates module providers
+	templates.ProvideTemplatesService,
+
 	// ui module providers
 	ui.ProvideUIService,
 )
@@ -0,0 +1,94 @@
+package dev
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// stopTimeout is how long the server gets to shut down gracefully before it is killed
+const stopTimeout = 5 * time.Second
+
+// server is a running server binary streaming its output to the terminal
+type server struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+// startServer runs the binary with the given arguments
+func startServer(binary string, args []string) (*server, error) {
+	cmd := exec.Command(binary, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting %s: %w", binary, err)
+	}
+
+	s := &server{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		cmd.Wait()
+		close(s.done)
+	}()
+	return s, nil
+}
+
+// round is a generation or rebuild running in the background, so a change arriving meanwhile can
+// cancel it instead of waiting for an outdated result
+type round struct {
+	cancel context.CancelFunc
+	done   chan struct{} // Closed once the round has finished
+	ok     bool          // Whether the round succeeded, set before done is closed
+}
+
+// startRound runs work in the background until it returns, ctx is done or the round is stopped
+func startRound(ctx context.Context, work func(ctx context.Context) bool) *round {
+	ctx, cancel := context.WithCancel(ctx)
+	r := &round{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(r.done)
+		defer cancel()
+		r.ok = work(ctx)
+	}()
+	return r
+}
+
+// stop cancels the round and waits until it has rolled back what it wrote
+func (r *round) stop() {
+	r.cancel()
+	<-r.done
+}
+
+// exited reports whether the server stopped on its own, e.g. after a panic
+func (s *server) exited() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop interrupts the server, killing it if it doesn't exit within stopTimeout
+func (s *server) stop() {
+	if s.exited() {
+		return
+	}
+
+	// Windows can't deliver interrupts to child processes
+	if runtime.GOOS == "windows" {
+		s.cmd.Process.Kill()
+	} else {
+		s.cmd.Process.Signal(os.Interrupt)
+	}
+
+	select {
+	case <-s.done:
+	case <-time.After(stopTimeout):
+		s.cmd.Process.Kill()
+		<-s.done
+	}
+}
@@ -0,0 +1,304 @@
+package dev
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
+	"github.com/nkaewam/taskw/internal/cli/generation"
+	"github.com/nkaewam/taskw/internal/cli/lock"
+	"github.com/nkaewam/taskw/internal/cli/ui"
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/generator"
+	"github.com/nkaewam/taskw/internal/tools"
+)
+
+// Service runs the development loop: regenerate, rebuild and restart the server on every change
+type Service interface {
+	// Run generates code, builds and starts the server, then does it again on every source change
+	// until ctx is done. args are passed to the server, overriding dev.args
+	Run(ctx context.Context, args []string) error
+	// Watch generates code, then regenerates it on every change to the sources in scan_dirs until ctx is done,
+	// without building or running anything. swagger regenerates the swagger documentation as well
+	Watch(ctx context.Context, swagger bool) error
+}
+
+// service implements Service interface
+type service struct {
+	config     *config.Config
+	ui         ui.Service
+	generation generation.Service
+}
+
+// ProvideDevService creates a new dev service
+// @Provider
+func ProvideDevService(config *config.Config, uiService ui.Service, generationService generation.Service) Service {
+	return &service{
+		config:     config,
+		ui:         uiService,
+		generation: generationService,
+	}
+}
+
+// Run generates code, builds and starts the server, then does it again on every source change
+// until ctx is done. args are passed to the server, overriding dev.args
+func (s *service) Run(ctx context.Context, args []string) error {
+	debounce, err := s.debounce()
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		args = s.config.Dev.Args
+	}
+
+	binary, err := filepath.Abs(s.config.Dev.Binary)
+	if err != nil {
+		return exitcode.New(exitcode.Config, fmt.Errorf("invalid dev.binary %q: %w", s.config.Dev.Binary, err))
+	}
+	if runtime.GOOS == "windows" && !strings.HasSuffix(binary, ".exe") {
+		binary += ".exe"
+	}
+
+	// wire_gen.go has to follow the generated providers for the server to build
+	if s.config.DependencyBackend() == config.BackendWire {
+		s.config.Generation.Dependencies.RunWire = true
+	}
+
+	w, err := newWatcher(".", s.config.Dev.ExcludeDirs)
+	if err != nil {
+		return fmt.Errorf("error watching project: %w", err)
+	}
+	defer w.Close()
+
+	var running *server
+	defer func() {
+		if running != nil {
+			running.stop()
+		}
+	}()
+
+	// The previous server keeps running while the next one is rebuilt, and if generation or the build fails
+	var current *round
+	defer func() {
+		if current != nil {
+			current.stop()
+		}
+	}()
+	rebuild := func(generate func(ctx context.Context) error) *round {
+		return startRound(ctx, func(ctx context.Context) bool {
+			return s.rebuild(ctx, generate, binary)
+		})
+	}
+
+	// The first round also generates the swagger docs the server embeds, like taskw generate does
+	ui.Infof("👀 Watching for changes (Ctrl+C to stop)")
+	current = rebuild(s.generation.GenerateAll)
+
+	var changed <-chan time.Time
+	for {
+		// Nil channels never fire, so exits and rounds are only watched while running
+		var exited, rebuilt chan struct{}
+		if running != nil {
+			exited = running.done
+		}
+		if current != nil {
+			rebuilt = current.done
+		}
+
+		select {
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return nil
+			}
+			if w.relevant(event) {
+				changed = time.After(debounce) // Debounce bursts of saves into one rebuild
+			}
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return nil
+			}
+			ui.Warnf("Watch error: %v", err)
+		case <-changed:
+			changed = nil
+			if current != nil {
+				// The round in progress works from sources that changed since, start over
+				current.stop()
+				ui.Infof("🔄 Change detected, restarting the rebuild...")
+			} else {
+				ui.Infof("🔄 Change detected, rebuilding...")
+			}
+			current = rebuild(s.generation.GenerateCode)
+		case <-rebuilt:
+			built := current.ok
+			current = nil
+			if !built {
+				ui.Infof("⏳ Waiting for changes...")
+				continue
+			}
+			if running != nil {
+				running.stop()
+				running = nil
+			}
+			started, err := startServer(binary, args)
+			if err != nil {
+				ui.Errorf("❌ %v", err)
+				continue
+			}
+			running = started
+		case <-exited:
+			ui.Infof("💥 Server exited (%s), waiting for changes...", running.cmd.ProcessState)
+			running = nil
+		case <-ctx.Done():
+			ui.Infof("\n👋 Stopping dev server")
+			return nil
+		}
+	}
+}
+
+// Watch generates code, then regenerates it on every change to the sources in scan_dirs until ctx is done,
+// without building or running anything. swagger regenerates the swagger documentation as well
+func (s *service) Watch(ctx context.Context, swagger bool) error {
+	debounce, err := s.debounce()
+	if err != nil {
+		return err
+	}
+	generate := s.generation.GenerateCode
+	if swagger {
+		generate = s.generation.GenerateAll
+	}
+
+	w, err := newWatcher(".", s.config.Dev.ExcludeDirs, s.config.Paths.ScanDirs...)
+	if err != nil {
+		return fmt.Errorf("error watching scan directories: %w", err)
+	}
+	defer w.Close()
+
+	// Failures are reported and watching goes on, the next change likely fixes them
+	regenerate := func() *round {
+		return startRound(ctx, func(ctx context.Context) bool {
+			err := s.locked(ctx, generate)
+			if ctx.Err() != nil {
+				return false // Restarted or stopped, the files it wrote are rolled back
+			}
+			if err != nil {
+				ui.Errorf("❌ %v", err)
+			}
+			ui.Infof("⏳ Waiting for changes...")
+			return err == nil
+		})
+	}
+
+	ui.Infof("👀 Watching %s for changes (Ctrl+C to stop)", strings.Join(s.config.Paths.ScanDirs, ", "))
+	current := regenerate()
+	defer func() {
+		if current != nil {
+			current.stop()
+		}
+	}()
+
+	var changed <-chan time.Time
+	for {
+		// A nil channel never fires, so rounds are only watched while one runs
+		var generated chan struct{}
+		if current != nil {
+			generated = current.done
+		}
+
+		select {
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return nil
+			}
+			if w.relevant(event) {
+				changed = time.After(debounce) // Debounce bursts of saves into one generation
+			}
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return nil
+			}
+			ui.Warnf("Watch error: %v", err)
+		case <-changed:
+			changed = nil
+			if current != nil {
+				// The round in progress scanned sources that changed since, start over
+				current.stop()
+				ui.Infof("🔄 Change detected, restarting generation...")
+			} else {
+				ui.Infof("🔄 Change detected, regenerating...")
+			}
+			current = regenerate()
+		case <-generated:
+			current = nil
+		case <-ctx.Done():
+			ui.Infof("\n👋 Stopped watching")
+			return nil
+		}
+	}
+}
+
+// debounce returns the quiet period after the last change before acting on it, from dev.debounce
+func (s *service) debounce() (time.Duration, error) {
+	debounce, err := time.ParseDuration(s.config.Dev.Debounce)
+	if err != nil || debounce < 0 {
+		return 0, exitcode.New(exitcode.Config, fmt.Errorf("invalid dev.debounce %q (expected a duration such as 300ms)", s.config.Dev.Debounce))
+	}
+	return debounce, nil
+}
+
+// locked holds the project lock while generating, a taskw generate started meanwhile waits for the round to finish.
+// The files the round wrote are recorded in the manifest
+func (s *service) locked(ctx context.Context, generate func(ctx context.Context) error) error {
+	projectLock, err := lock.Acquire(ctx, s.config.Root, lock.DefaultTimeout, lock.Notify)
+	if err != nil {
+		return err
+	}
+	defer projectLock.Release()
+
+	recording := generator.NewRecording()
+	s.generation.SetOutput(&generator.Output{Recording: recording})
+	if err := generate(ctx); err != nil {
+		return err
+	}
+	return s.generation.WriteManifest(ctx, recording.Paths())
+}
+
+// rebuild regenerates code and builds the server binary, reporting failures. Reports false without
+// a word when ctx is done first
+func (s *service) rebuild(ctx context.Context, generate func(ctx context.Context) error, binary string) bool {
+	if err := s.locked(ctx, generate); err != nil {
+		if ctx.Err() == nil {
+			ui.Errorf("❌ %v", err)
+		}
+		return false
+	}
+
+	// go build reads a path without ./ as an import path
+	pkg := filepath.ToSlash(s.config.Dev.MainPackage)
+	if !filepath.IsAbs(pkg) && !strings.HasPrefix(pkg, ".") {
+		pkg = "./" + pkg
+	}
+
+	// Build with the tags the scan matched files against, so the generated code compiles in
+	buildArgs := []string{"build", "-o", binary}
+	if len(s.config.Scanning.BuildTags) > 0 {
+		buildArgs = append(buildArgs, "-tags="+strings.Join(s.config.Scanning.BuildTags, ","))
+	}
+
+	stopSpinner := s.ui.ShowSpinner(fmt.Sprintf("Building %s...", pkg))
+	output, err := tools.Go.Run(ctx, append(buildArgs, pkg)...)
+	if ctx.Err() != nil {
+		stopSpinner("Build cancelled")
+		return false
+	}
+	if err != nil {
+		stopSpinner("Build failed")
+		ui.Errorf("%s", strings.TrimRight(string(output), "\n"))
+		return false
+	}
+	stopSpinner(fmt.Sprintf("Built %s", pkg))
+	return true
+}
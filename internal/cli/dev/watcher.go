@@ -0,0 +1,116 @@
+package dev
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watcher reports changes to the Go sources of a project, recursively
+type watcher struct {
+	fs      *fsnotify.Watcher
+	exclude map[string]bool // Directory names or root relative paths that aren't watched
+	root    string
+}
+
+// newWatcher watches every directory under dirs except hidden and excluded ones, or under root when no dirs
+// are given. Excluded paths are relative to root
+func newWatcher(root string, excludeDirs []string, dirs ...string) (*watcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &watcher{fs: fsWatcher, exclude: make(map[string]bool), root: root}
+	for _, dir := range excludeDirs {
+		w.exclude[filepath.Clean(dir)] = true
+	}
+
+	if len(dirs) == 0 {
+		dirs = []string{root}
+	}
+	for _, dir := range dirs {
+		if err := w.addTree(dir); err != nil {
+			fsWatcher.Close()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// addTree watches dir and its subdirectories
+func (w *watcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if path != dir && w.skipDir(path) {
+			return filepath.SkipDir
+		}
+		return w.fs.Add(path)
+	})
+}
+
+// skipDir checks if a directory is hidden or excluded by name or by path
+func (w *watcher) skipDir(path string) bool {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, ".") || w.exclude[name] {
+		return true
+	}
+	rel, err := filepath.Rel(w.root, path)
+	return err == nil && w.exclude[rel]
+}
+
+// relevant checks if an event should trigger a rebuild, adding directories created since the start
+// Generated files are ignored so the files written by taskw and wire don't trigger another round
+func (w *watcher) relevant(event fsnotify.Event) bool {
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if !w.skipDir(event.Name) {
+				w.addTree(event.Name)
+			}
+			return false
+		}
+	}
+	if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+		return false
+	}
+
+	name := filepath.Base(event.Name)
+	switch {
+	case name == "go.mod" || name == "go.sum":
+		return true
+	case !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go"):
+		return false
+	}
+	return !isGeneratedFile(event.Name)
+}
+
+// isGeneratedFile checks if a Go file starts with a "// Code generated ... DO NOT EDIT." comment.
+// Empty files count as generated
+func isGeneratedFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	line, err := bufio.NewReader(file).ReadString('\n')
+	if line == "" && err != nil {
+		// Truncated by a generator about to write it, the write that follows is checked again
+		return true
+	}
+	return strings.HasPrefix(line, "// Code generated ") && strings.Contains(line, "DO NOT EDIT")
+}
+
+// Close stops watching
+func (w *watcher) Close() error {
+	return w.fs.Close()
+}
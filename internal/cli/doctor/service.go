@@ -0,0 +1,261 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/tools"
+)
+
+// Check statuses, from fine to broken
+const (
+	StatusOK   = "ok"
+	StatusInfo = "info" // Missing but optional, nothing breaks
+	StatusWarn = "warn" // Some commands or generation steps won't work
+	StatusFail = "fail" // taskw generate can't work until fixed
+)
+
+// Check groups
+const (
+	GroupTools   = "Tools"
+	GroupProject = "Project"
+)
+
+// Check is the outcome of one diagnostic
+type Check struct {
+	Group  string // GroupTools or GroupProject
+	Name   string // e.g. "wire" or "go.mod"
+	Status string
+	Detail string // What was found, e.g. "v0.6.0 (/home/me/go/bin/wire)"
+	Fix    string // What to do about it, empty when the check passed
+}
+
+// Service diagnoses the environment taskw runs in
+type Service interface {
+	// Diagnose checks the external tools and the project of the working directory
+	Diagnose() []Check
+	// Report prints the checks with their fixes and fails when any check failed
+	Report(checks []Check) error
+}
+
+// service implements Service interface
+type service struct{}
+
+// ProvideDoctorService creates a new doctor service
+// @Provider
+func ProvideDoctorService() Service {
+	return &service{}
+}
+
+// Diagnose checks the external tools and the project of the working directory. The configuration is
+// loaded here rather than injected, a broken taskw.yaml is one of the things to diagnose
+func (s *service) Diagnose() []Check {
+	cfg, configCheck := s.checkConfig()
+
+	checks := s.checkTools(cfg)
+	checks = append(checks, configCheck)
+	if cfg != nil {
+		checks = append(checks, s.checkModule(cfg))
+		checks = append(checks, s.checkScanDirs(cfg)...)
+	}
+	return checks
+}
+
+// checkTools checks the external commands are installed, how much a missing one matters depends on the configuration
+func (s *service) checkTools(cfg *config.Config) []Check {
+	wireStatus := StatusFail
+	wireNote := "needed to build the generated provider sets"
+	if cfg != nil && (!cfg.Generation.Dependencies.Enabled || cfg.DependencyBackend() != config.BackendWire) {
+		wireStatus = StatusInfo
+		wireNote = "not needed, generation.dependencies doesn't use the wire backend"
+	}
+
+	missing := []struct {
+		tool   tools.Tool
+		status string
+		note   string
+	}{
+		{tools.Go, StatusFail, "taskw can't scan or build anything without it"},
+		{tools.Wire, wireStatus, wireNote},
+		{tools.Swag, StatusWarn, "taskw generate swagger installs it on first use, which needs network access"},
+		{tools.Task, StatusWarn, "projects created by taskw init run their generation through Task"},
+		{tools.Git, StatusWarn, "--since, --changed, taskw notes and init --git need it"},
+		{tools.Air, StatusInfo, "optional, taskw dev rebuilds and restarts the server itself"},
+	}
+
+	var checks []Check
+	for _, m := range missing {
+		check := Check{Group: GroupTools, Name: m.tool.Name}
+		path := m.tool.Path()
+		if path == "" {
+			check.Status = m.status
+			check.Detail = "not found in PATH, " + m.note
+			check.Fix = m.tool.InstallHint()
+			if m.tool.Package != "" {
+				check.Fix += ", then make sure $(go env GOPATH)/bin is in PATH"
+			}
+		} else {
+			check.Status = StatusOK
+			check.Detail = path
+			if version := m.tool.Version(); version != "" {
+				check.Detail = fmt.Sprintf("%s (%s)", version, path)
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// checkConfig loads taskw.yaml, nil when it is invalid
+func (s *service) checkConfig() (*config.Config, Check) {
+	check := Check{Group: GroupProject, Name: config.ConfigFileName}
+	cfg, err := config.ProvideConfig()
+	if err != nil {
+		check.Status = StatusFail
+		check.Detail = err.Error()
+		check.Fix = "Fix taskw.yaml as the error describes, docs/content/docs/config lists every key"
+		return nil, check
+	}
+
+	path := filepath.Join(cfg.Root, config.ConfigFileName)
+	if _, err := os.Stat(path); err != nil {
+		check.Status = StatusWarn
+		check.Detail = "not found, the defaults are used"
+		check.Fix = "Run taskw init to scaffold a project, or add a taskw.yaml to the project root"
+		return cfg, check
+	}
+	check.Status = StatusOK
+	check.Detail = path
+	return cfg, check
+}
+
+// checkModule compares the module of go.mod with project.module, generated imports are built from the latter
+func (s *service) checkModule(cfg *config.Config) Check {
+	check := Check{Group: GroupProject, Name: "go.mod"}
+	goMod := filepath.Join(cfg.ModuleDir, "go.mod")
+	module, err := config.DetectGoModule(cfg.ModuleDir)
+	switch {
+	case err != nil:
+		check.Status = StatusFail
+		check.Detail = err.Error()
+		check.Fix = "Add a module directive to " + goMod
+	case module == "":
+		check.Status = StatusFail
+		check.Detail = "no go.mod found in the project root or its parents"
+		check.Fix = "Run go mod init " + fallback(cfg.Project.Module, "<module>")
+	case cfg.Project.Module != module:
+		check.Status = StatusFail
+		check.Detail = fmt.Sprintf("project.module is %q but go.mod declares %q, generated imports would not resolve", cfg.Project.Module, module)
+		check.Fix = fmt.Sprintf("Set project.module to %q in taskw.yaml, or remove it to use the module of go.mod", module)
+	default:
+		check.Status = StatusOK
+		check.Detail = fmt.Sprintf("module %s matches project.module", module)
+	}
+	return check
+}
+
+// checkScanDirs checks every directory of paths.scan_dirs exists
+func (s *service) checkScanDirs(cfg *config.Config) []Check {
+	var checks []Check
+	for _, dir := range cfg.Paths.ScanDirs {
+		check := Check{Group: GroupProject, Name: "scan_dirs"}
+		info, err := os.Stat(dir)
+		switch {
+		case err != nil:
+			check.Status = StatusFail
+			check.Detail = fmt.Sprintf("%s does not exist", dir)
+			check.Fix = fmt.Sprintf("Create %s or remove it from paths.scan_dirs", dir)
+		case !info.IsDir():
+			check.Status = StatusFail
+			check.Detail = fmt.Sprintf("%s is not a directory", dir)
+			check.Fix = fmt.Sprintf("Point paths.scan_dirs at the directory holding %s", dir)
+		default:
+			check.Status = StatusOK
+			check.Detail = dir
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// Report prints the checks with their fixes and fails when any check failed, with the exit code of the
+// first failing group: 6 for tools, 2 for the project
+func (s *service) Report(checks []Check) error {
+	width := 0
+	for _, check := range checks {
+		width = max(width, len(check.Name))
+	}
+
+	group := ""
+	failed := 0
+	warned := 0
+	var firstFailure *Check
+	for i, check := range checks {
+		if check.Group != group {
+			group = check.Group
+			fmt.Printf("\n%s:\n", group)
+		}
+		fmt.Printf("  %s %-*s  %s\n", statusIcon(check.Status), width, check.Name, check.Detail)
+		if check.Fix != "" && check.Status != StatusOK {
+			fmt.Printf("     → %s\n", check.Fix)
+		}
+
+		switch check.Status {
+		case StatusFail:
+			failed++
+			if firstFailure == nil {
+				firstFailure = &checks[i]
+			}
+		case StatusWarn:
+			warned++
+		}
+	}
+	fmt.Println()
+
+	if failed == 0 {
+		if warned > 0 {
+			fmt.Printf("⚠️  %d warning(s), taskw generate works but some commands won't\n", warned)
+		} else {
+			fmt.Println("✅ Everything taskw needs is in place")
+		}
+		return nil
+	}
+
+	code := exitcode.Config
+	if firstFailure.Group == GroupTools {
+		code = exitcode.ExternalTool
+	}
+	var names []string
+	for _, check := range checks {
+		if check.Status == StatusFail {
+			names = append(names, check.Name)
+		}
+	}
+	return exitcode.New(code, fmt.Errorf("%d doctor check(s) failed: %s", failed, strings.Join(names, ", ")))
+}
+
+// statusIcon returns the marker printed before a check
+func statusIcon(status string) string {
+	switch status {
+	case StatusOK:
+		return "✅"
+	case StatusInfo:
+		return "• "
+	case StatusWarn:
+		return "⚠️ "
+	default:
+		return "❌"
+	}
+}
+
+// fallback returns value, or the placeholder when it is empty
+func fallback(value, placeholder string) string {
+	if value == "" {
+		return placeholder
+	}
+	return value
+}
@@ -0,0 +1,64 @@
+package exitcode
+
+import (
+	"context"
+	"errors"
+)
+
+// Exit codes returned by taskw, so wrapper scripts and CI can branch on the class of failure
+const (
+	Success      = 0 // Command completed
+	General      = 1 // Usage errors and failures not covered below
+	Config       = 2 // taskw.yaml or a file it references is missing or invalid
+	Scan         = 3 // Source files could not be scanned
+	Validation   = 4 // The scanned code violates taskw conventions (provider graph, naming, ownership)
+	Generation   = 5 // Generated code could not be rendered or written
+	ExternalTool = 6 // An external tool (wire, swag, go, git) failed
+	Locked       = 7 // Another taskw command kept holding the project lock
+	Stale        = 8 // Generated files on disk differ from what taskw would generate (generate --check)
+
+	Interrupted = 130 // Stopped by Ctrl-C or SIGTERM before completing, like shells report SIGINT
+)
+
+// Error attaches an exit code to an error
+type Error struct {
+	Code int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New attaches an exit code to err, keeping the code of an already classified error
+// so the most specific cause decides how taskw exits. Returns nil if err is nil
+func New(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	var coded *Error
+	if errors.As(err, &coded) {
+		return err
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Code returns the exit code for err: Success for nil, Interrupted for cancelled commands whatever
+// step they were stopped in, General for unclassified errors
+func Code(err error) int {
+	if err == nil {
+		return Success
+	}
+	if errors.Is(err, context.Canceled) {
+		return Interrupted
+	}
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return General
+}
@@ -1,8 +1,11 @@
 package file
 
 import (
+	"context"
 	"os"
 	"os/exec"
+
+	"github.com/nkaewam/taskw/internal/tools"
 )
 
 // Service handles file system operations
@@ -11,10 +14,10 @@ type Service interface {
 	DeleteIfExists(path string) (bool, error)
 	// IsCommandAvailable checks if a command is available in PATH
 	IsCommandAvailable(name string) bool
-	// InstallSwag installs the swag command for swagger generation
-	InstallSwag() error
-	// FindMainFile finds the main.go file in common locations
-	FindMainFile() string
+	// InstallSwag installs the swag command for swagger generation, stopping once ctx is done
+	InstallSwag(ctx context.Context) error
+	// InstallWire installs the wire command for dependency injection code generation, stopping once ctx is done
+	InstallWire(ctx context.Context) error
 }
 
 // service implements Service interface
@@ -48,26 +51,12 @@ func (s *service) IsCommandAvailable(name string) bool {
 	return err == nil
 }
 
-// InstallSwag installs the swag command for swagger generation
-func (s *service) InstallSwag() error {
-	cmd := exec.Command("go", "install", "github.com/swaggo/swag/cmd/swag@latest")
-	return cmd.Run()
+// InstallSwag installs the swag command for swagger generation, stopping once ctx is done
+func (s *service) InstallSwag(ctx context.Context) error {
+	return tools.Swag.Install(ctx)
 }
 
-// FindMainFile finds the main.go file in common locations
-func (s *service) FindMainFile() string {
-	// Common locations for main.go
-	candidates := []string{
-		"./cmd/server/main.go",
-		"./cmd/main.go",
-		"./main.go",
-	}
-
-	for _, candidate := range candidates {
-		if _, err := os.Stat(candidate); err == nil {
-			return candidate
-		}
-	}
-
-	return ""
+// InstallWire installs the wire command for dependency injection code generation, stopping once ctx is done
+func (s *service) InstallWire(ctx context.Context) error {
+	return tools.Wire.Install(ctx)
 }
@@ -1,172 +1,241 @@
 package generation
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
-	"path/filepath"
+	"strings"
 
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
 	"github.com/nkaewam/taskw/internal/cli/file"
 	"github.com/nkaewam/taskw/internal/cli/ui"
 	"github.com/nkaewam/taskw/internal/config"
 	"github.com/nkaewam/taskw/internal/generator"
+	"github.com/nkaewam/taskw/internal/pipeline"
 	"github.com/nkaewam/taskw/internal/scanner"
 )
 
 // Service handles code generation operations
 type Service interface {
 	// GenerateAll generates routes, dependencies, and swagger documentation
-	GenerateAll() error
+	GenerateAll(ctx context.Context) error
+	// GenerateCode generates every enabled Go artifact, leaving out the swagger documentation
+	GenerateCode(ctx context.Context) error
 	// GenerateRoutes generates only route registration code
-	GenerateRoutes() error
+	GenerateRoutes(ctx context.Context) error
+	// RegisteredRoutes returns the routes the generated router registers, in registration order
+	RegisteredRoutes(ctx context.Context) ([]scanner.RouteMapping, error)
+	// GenerateServer generates the Server struct wiring the application to the generated router
+	GenerateServer(ctx context.Context) error
 	// GenerateDependencies generates only dependency injection code
-	GenerateDependencies() error
+	GenerateDependencies(ctx context.Context) error
 	// GenerateSwagger generates swagger documentation
-	GenerateSwagger() error
+	GenerateSwagger(ctx context.Context) error
+	// GeneratePackageDocs generates per-package doc files summarizing handlers, routes, and providers
+	GeneratePackageDocs(ctx context.Context) error
+	// GenerateParams generates per-route helpers parsing the UUID path parameters documented with @Param
+	GenerateParams(ctx context.Context) error
+	// GeneratePorts generates service interfaces from the methods handlers call on their service fields
+	GeneratePorts(ctx context.Context) error
+	// GenerateRecording generates middleware that captures request/response fixtures
+	GenerateRecording(ctx context.Context) error
+	// GenerateSLOAlerts generates Prometheus alerting rules from @SLO annotations
+	GenerateSLOAlerts(ctx context.Context) error
+	// GenerateChaos generates failure injection wrappers around @ChaosWrap providers
+	GenerateChaos(ctx context.Context) error
+	// GenerateEnvelope generates helpers wrapping JSON responses in the response envelope
+	GenerateEnvelope(ctx context.Context) error
+	// GenerateRedirects generates the registrations serving the old paths of migrated routes
+	GenerateRedirects(ctx context.Context) error
+	// GenerateSwaggerUI generates the registration of the swagger middleware serving the documentation
+	GenerateSwaggerUI(ctx context.Context) error
+	// GenerateGateway generates the Fiber routes serving @RPC annotated gRPC methods over HTTP
+	GenerateGateway(ctx context.Context) error
+	// GeneratePIIReport generates a report of the endpoints handling personal data from @PII annotations
+	GeneratePIIReport(ctx context.Context) error
+	// SetOutput sets the state of the next generation runs: whether files edited by hand are overwritten,
+	// the preview capturing the generated files and the recording noting them for the manifest
+	SetOutput(out *generator.Output)
+	// CheckGenerated compares the files captured by preview with the files on disk, printing a diff of
+	// every stale one, and fails with exitcode.Stale when any is
+	CheckGenerated(preview *generator.Preview) error
+	// ShowChanges prints a diff of every file captured by preview that differs from the file on disk
+	ShowChanges(preview *generator.Preview) error
+	// WriteManifest records the files a run wrote and the sources of the scanned routes and providers in .taskw/manifest.json
+	WriteManifest(ctx context.Context, written []string) error
+	// SyncServer patches a hand-written server struct with fields and constructor parameters for newly scanned handlers
+	SyncServer(ctx context.Context, filePath, structName string) error
 }
 
-// service implements Service interface
+// service implements Service interface, the generation steps are run by the pipeline shared with pkg/gen
 type service struct {
-	config      *config.Config
-	scanner     *scanner.Scanner
-	ui          ui.Service
-	fileService file.Service
+	*pipeline.Runner
+	config  *config.Config
+	scanner *scanner.Scanner
+	ui      ui.Service
 }
 
 // ProvideGenerationService creates a new generation service
 // @Provider
 func ProvideGenerationService(config *config.Config, uiService ui.Service, fileService file.Service) Service {
+	scan := scanner.NewScanner(config)
+	scan.SetObserver(ui.NewScanProgress())
 	return &service{
-		config:      config,
-		scanner:     scanner.NewScanner(config),
-		ui:          uiService,
-		fileService: fileService,
+		Runner:  pipeline.NewRunner(config, scan, reporter{uiService}, fileService),
+		config:  config,
+		scanner: scan,
+		ui:      uiService,
 	}
 }
 
-// GenerateAll generates routes, dependencies, and swagger documentation
-func (s *service) GenerateAll() error {
-	if s.config.Generation.Routes.Enabled {
-		if err := s.GenerateRoutes(); err != nil {
-			return err
-		}
-	}
-	if s.config.Generation.Dependencies.Enabled {
-		if err := s.GenerateDependencies(); err != nil {
-			return err
-		}
-	}
-
-	// Generate Swagger documentation
-	return s.GenerateSwagger()
+// reporter prints the progress of the pipeline steps
+type reporter struct {
+	ui.Service
 }
 
-// GenerateRoutes generates only route registration code
-func (s *service) GenerateRoutes() error {
-	if !s.config.Generation.Routes.Enabled {
-		return nil
+func (reporter) Infof(format string, args ...interface{})   { ui.Infof(format, args...) }
+func (reporter) Detailf(format string, args ...interface{}) { ui.Detailf(format, args...) }
+func (reporter) Warnf(format string, args ...interface{})   { ui.Warnf(format, args...) }
+func (reporter) Errorf(format string, args ...interface{})  { ui.Errorf(format, args...) }
+
+// RegisteredRoutes returns the routes the generated router registers, in registration order, with their
+// paths in the router's syntax. Packages left out by generation.routes are left out here too
+func (s *service) RegisteredRoutes(ctx context.Context) ([]scanner.RouteMapping, error) {
+	handlers, routes, err := s.scanner.ScanRoutes(ctx, s.config.Paths.ScanDirs)
+	if err != nil {
+		return nil, exitcode.New(exitcode.Scan, fmt.Errorf("error scanning routes: %w", err))
+	}
+	_, routes, _, _ = scanner.SelectRoutePackages(s.config.Generation.Routes, handlers, routes)
+	routeGen, err := generator.NewRouteGenerator(s.config, nil)
+	if err != nil {
+		return nil, exitcode.New(exitcode.Config, err)
 	}
+	return routeGen.RegistrationOrder(routes), nil
+}
 
-	stopSpinner := s.ui.ShowSpinner("Generating routes...")
+// SyncServer patches a hand-written server struct with fields and constructor parameters for newly scanned handlers
+func (s *service) SyncServer(ctx context.Context, filePath, structName string) error {
+	stopSpinner := s.ui.ShowSpinner(fmt.Sprintf("Syncing %s...", filePath))
 
-	handlers, routes, err := s.scanner.ScanRoutes(s.config.Paths.ScanDirs)
+	handlers, routes, err := s.scanner.ScanRoutes(ctx, s.config.Paths.ScanDirs)
 	if err != nil {
 		stopSpinner("Error scanning routes")
-		return fmt.Errorf("error scanning routes: %w", err)
+		return exitcode.New(exitcode.Scan, fmt.Errorf("error scanning routes: %w", err))
 	}
 
-	if len(handlers) == 0 {
-		stopSpinner("No handlers found")
-		return nil
+	syncer, err := generator.NewServerSyncer(s.config, nil)
+	if err != nil {
+		stopSpinner("Error syncing server")
+		return exitcode.New(exitcode.Config, err)
+	}
+	result, err := syncer.SyncServer(filePath, structName, handlers, routes)
+	if err != nil {
+		stopSpinner("Error syncing server")
+		return exitcode.New(exitcode.Generation, fmt.Errorf("error syncing server: %w", err))
 	}
 
-	if len(routes) == 0 {
-		stopSpinner("No @Router annotations found")
+	if len(result.Added) == 0 && len(result.Removed) == 0 {
+		stopSpinner(fmt.Sprintf("%s already holds every scanned handler", structName))
+		s.showReferencedFields(filePath, result.Referenced)
 		return nil
 	}
 
-	// Generate routes using the RouteGenerator
-	routeGen := generator.NewRouteGenerator(s.config)
-	if err := routeGen.GenerateRoutes(handlers, routes); err != nil {
-		stopSpinner("Error generating routes")
-		return fmt.Errorf("error generating routes: %w", err)
+	stopSpinner(fmt.Sprintf("%s synced successfully", filePath))
+	for _, handler := range result.Added {
+		ui.Detailf("Added field: %s %s", handler.FieldName, handler.TypeName)
+	}
+	// Removed handlers usually mean decommissioned endpoints, list them so the removal is noticed
+	for _, field := range result.Removed {
+		ui.Detailf("Removed field: %s %s, no scanned route uses it anymore", field.FieldName, field.TypeName)
+	}
+	s.showReferencedFields(filePath, result.Referenced)
+	switch {
+	case len(result.Added) == 0:
+	case result.Constructor == "":
+		ui.Detailf("No constructor returning *%s found, set the new fields by hand", structName)
+	case result.Unassigned:
+		ui.Detailf("Added parameters to %s, assign them to the new fields by hand", result.Constructor)
+	default:
+		ui.Detailf("Added parameters to %s", result.Constructor)
 	}
-
-	outputPath := filepath.Join(s.config.Paths.OutputDir, s.config.Generation.Routes.OutputFile)
-	stopSpinner("Routes generated successfully")
-	fmt.Printf("  • Found %d handlers and %d routes\n", len(handlers), len(routes))
-	fmt.Printf("  • Generated: %s\n", outputPath)
 
 	return nil
 }
 
-// GenerateDependencies generates only dependency injection code
-func (s *service) GenerateDependencies() error {
-	if !s.config.Generation.Dependencies.Enabled {
-		return nil
+// showReferencedFields lists the handler fields no scanned route uses anymore that sync kept
+func (s *service) showReferencedFields(filePath string, fields []generator.StaleField) {
+	for _, field := range fields {
+		ui.Detailf("%s %s is no longer used by any route but still referenced in %s, remove it by hand", field.FieldName, field.TypeName, filePath)
 	}
+}
 
-	stopSpinner := s.ui.ShowSpinner("Generating dependencies...")
-
-	providers, err := s.scanner.ScanProviders(s.config.Paths.ScanDirs)
+// CheckGenerated compares the files captured by preview with the files on disk, printing a diff of
+// every stale one, and fails with exitcode.Stale when any is
+func (s *service) CheckGenerated(preview *generator.Preview) error {
+	changes, err := s.previewChanges(preview)
 	if err != nil {
-		stopSpinner("Error scanning providers")
-		return fmt.Errorf("error scanning providers: %w", err)
+		return err
 	}
-
-	if len(providers) == 0 {
-		stopSpinner("No provider functions found")
+	if len(changes) == 0 {
+		ui.Infof("✅ Generated files are up to date")
 		return nil
 	}
 
-	// Generate dependencies using the DependencyGenerator
-	depGen := generator.NewDependencyGenerator(s.config)
-	if err := depGen.GenerateDependencies(providers); err != nil {
-		stopSpinner("Error generating dependencies")
-		return fmt.Errorf("error generating dependencies: %w", err)
+	fmt.Printf("\n❌ %d generated file(s) are out of date:\n\n", len(changes))
+	printDiffs(changes)
+
+	paths := make([]string, len(changes))
+	for i, change := range changes {
+		paths[i] = change.Path
 	}
+	return exitcode.New(exitcode.Stale, fmt.Errorf("stale generated files: %s, run taskw generate to update them", strings.Join(paths, ", ")))
+}
 
-	outputPath := filepath.Join(s.config.Paths.OutputDir, s.config.Generation.Dependencies.OutputFile)
-	stopSpinner("Dependencies generated successfully")
-	fmt.Printf("  • Found %d providers\n", len(providers))
-	fmt.Printf("  • Generated: %s\n", outputPath)
+// ShowChanges prints a diff of every file captured by preview that differs from the file on disk
+func (s *service) ShowChanges(preview *generator.Preview) error {
+	changes, err := s.previewChanges(preview)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		ui.Infof("• No generated file would change")
+		return nil
+	}
 
+	fmt.Printf("\n• %d generated file(s) would change, nothing was written:\n\n", len(changes))
+	printDiffs(changes)
 	return nil
 }
 
-// GenerateSwagger generates swagger documentation
-func (s *service) GenerateSwagger() error {
-	stopSpinner := s.ui.ShowSpinner("Generating Swagger documentation...")
-
-	// Check if swag command is available
-	if !s.fileService.IsCommandAvailable("swag") {
-		stopSpinner("Installing swag command...")
-		installSpinner := s.ui.ShowSpinner("Installing swag...")
-
-		if err := s.fileService.InstallSwag(); err != nil {
-			installSpinner("Failed to install swag")
-			fmt.Printf("  Please install manually: go install github.com/swaggo/swag/cmd/swag@latest\n")
-			return nil
-		}
-		installSpinner("swag installed successfully")
-	}
-
-	// Generate swagger docs
-	mainFile := s.fileService.FindMainFile()
-	if mainFile == "" {
-		stopSpinner("Could not find main.go file for swagger generation")
+// WriteManifest records the files a run wrote and the sources of the scanned routes and providers in .taskw/manifest.json
+func (s *service) WriteManifest(ctx context.Context, written []string) error {
+	if len(written) == 0 {
 		return nil
 	}
 
-	docsDir := "docs"
-	cmd := exec.Command("swag", "init", "-g", mainFile, "-o", docsDir)
+	// The run just scanned every file, the results come from the cache
+	result, err := s.scanner.ScanAll(ctx)
+	if err != nil {
+		return exitcode.New(exitcode.Scan, fmt.Errorf("error scanning for the manifest: %w", err))
+	}
+	if err := generator.WriteManifest(written, result); err != nil {
+		return exitcode.New(exitcode.Generation, fmt.Errorf("error writing manifest: %w", err))
+	}
+	return nil
+}
 
-	output, err := cmd.CombinedOutput()
+// previewChanges returns the files captured by preview differing from the files on disk
+func (s *service) previewChanges(preview *generator.Preview) ([]generator.FileChange, error) {
+	changes, err := preview.Changes()
 	if err != nil {
-		stopSpinner("Error generating swagger docs")
-		fmt.Printf("Output: %s\n", string(output))
-		return fmt.Errorf("error generating swagger docs: %w", err)
+		return nil, exitcode.New(exitcode.Generation, fmt.Errorf("error comparing generated files: %w", err))
 	}
+	return changes, nil
+}
 
-	stopSpinner(fmt.Sprintf("Swagger documentation generated successfully at %s/", docsDir))
-	return nil
+// printDiffs prints the unified diff of every change
+func printDiffs(changes []generator.FileChange) {
+	for _, change := range changes {
+		fmt.Print(change.Diff())
+	}
+	fmt.Println()
 }
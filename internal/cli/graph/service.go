@@ -0,0 +1,300 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/cli/scan"
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/generator"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// Output formats of taskw graph
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+)
+
+// Kinds of graph nodes
+const (
+	KindProvider = "provider"
+	KindHandler  = "handler" // Provider of a handler the generated router is constructed with
+	KindRouter   = "router"  // The generated router
+	KindServer   = "server"  // The generated Server struct
+	KindMissing  = "missing" // Parameter type no scanned provider returns
+)
+
+// Node is a provider, handler, generated type or unprovided type of the dependency graph
+type Node struct {
+	ID    string // Identifier in the rendered graph, e.g. "user_ProvideService"
+	Label string // e.g. "user.ProvideService\n*user.Service"
+	Kind  string
+}
+
+// Edge points from a node to a node it depends on
+type Edge struct {
+	From  string
+	To    string
+	Label string // Qualified type of the dependency, e.g. "*config.Config"
+}
+
+// Graph is the dependency graph of the scanned providers, handlers and generated types
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+}
+
+// Missing returns the types no scanned provider returns, which wire fails on unless they are
+// provided outside taskw
+func (g *Graph) Missing() []Node {
+	var missing []Node
+	for _, node := range g.Nodes {
+		if node.Kind == KindMissing {
+			missing = append(missing, node)
+		}
+	}
+	return missing
+}
+
+// Service builds and renders the dependency graph
+type Service interface {
+	// Build scans the project and links every provider, handler, the generated router and server
+	// to the providers of their parameters
+	Build(ctx context.Context) (*Graph, error)
+	// Write renders the graph as Graphviz DOT or a Mermaid flowchart
+	Write(w io.Writer, graph *Graph, format string) error
+}
+
+// service implements Service interface
+type service struct {
+	config *config.Config
+	scan   scan.Service
+}
+
+// ProvideGraphService creates a new graph service
+// @Provider
+func ProvideGraphService(config *config.Config, scanService scan.Service) Service {
+	return &service{
+		config: config,
+		scan:   scanService,
+	}
+}
+
+// Build scans the project and links every provider, handler, the generated router and server
+// to the providers of their parameters. Providers declared in generated files are left out, the
+// router and server are drawn the way the next taskw generate writes them
+func (s *service) Build(ctx context.Context) (*Graph, error) {
+	result, err := s.scan.Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	providers, _ := scanner.ProvidersForEnv(result.Providers, s.config.Generation.Dependencies.Env)
+	var scanned []scanner.ProviderFunction
+	for _, provider := range providers {
+		if generated, _ := generator.IsGeneratedFile(provider.FilePath); !generated {
+			scanned = append(scanned, provider)
+		}
+	}
+	sort.SliceStable(scanned, func(i, j int) bool {
+		return providerName(scanned[i]) < providerName(scanned[j])
+	})
+
+	b := newBuilder()
+	for _, provider := range scanned {
+		// Handler types are referred to by their qualified names, parameters by their keys
+		b.providerOf[scanner.QualifyType(provider.ImportName, provider.ReturnType)] = nodeID(providerName(provider))
+		b.providerOf[provider.ReturnKey()] = nodeID(providerName(provider))
+	}
+
+	routeGen, err := generator.NewRouteGenerator(s.config, nil)
+	if err != nil {
+		return nil, err
+	}
+	handlers := routeGen.Handlers(result.Handlers, result.Routes)
+	routeCounts := make(map[string]int) // Handler type -> number of routes
+	for _, handler := range handlers {
+		for _, route := range result.Routes {
+			if !route.IsFunction && strings.HasPrefix(route.HandlerRef, handler.FieldName+".") {
+				routeCounts[handler.TypeName]++
+			}
+		}
+	}
+
+	for _, provider := range scanned {
+		node := Node{
+			ID:    nodeID(providerName(provider)),
+			Label: providerName(provider) + "\n" + scanner.QualifyType(provider.ImportName, provider.ReturnType),
+			Kind:  KindProvider,
+		}
+		if count, ok := routeCounts[scanner.QualifyType(provider.ImportName, provider.ReturnType)]; ok {
+			node.Kind = KindHandler
+			node.Label += fmt.Sprintf("\n%d route(s)", count)
+		}
+		b.add(node)
+		for i, param := range provider.Parameters {
+			b.dependOn(node.ID, provider.ParameterKey(i), scanner.QualifyType(provider.ImportName, param))
+		}
+	}
+
+	if s.config.Generation.Routes.Enabled && len(handlers) > 0 {
+		router := Node{ID: "taskw_Router", Label: "Router\n" + s.config.Generation.Routes.OutputFile, Kind: KindRouter}
+		b.add(router)
+		b.dependOn(router.ID, routeGen.AppType(), routeGen.AppType())
+		for _, handler := range handlers {
+			b.dependOn(router.ID, handler.TypeName, handler.TypeName)
+		}
+
+		if s.config.Generation.Server.Enabled {
+			server := Node{ID: "taskw_Server", Label: "Server\n" + s.config.Generation.Server.OutputFile, Kind: KindServer}
+			b.add(server)
+			b.dependOn(server.ID, routeGen.AppType(), routeGen.AppType())
+			b.edges = append(b.edges, Edge{From: server.ID, To: router.ID, Label: "*Router"})
+		}
+	}
+
+	return &Graph{Nodes: b.nodes, Edges: b.edges}, nil
+}
+
+// builder collects nodes and edges, adding a missing node for every type without a provider
+type builder struct {
+	nodes      []Node
+	edges      []Edge
+	providerOf map[string]string // Qualified type or type key -> ID of the node providing it
+	missing    map[string]bool   // IDs of the missing nodes added so far
+}
+
+// newBuilder creates an empty graph builder
+func newBuilder() *builder {
+	return &builder{
+		providerOf: make(map[string]string),
+		missing:    make(map[string]bool),
+	}
+}
+
+// add adds a node
+func (b *builder) add(node Node) {
+	b.nodes = append(b.nodes, node)
+}
+
+// dependOn links a node to the provider of a type, or to a missing node for the type
+// The type is looked up by key and shown by its qualified name
+func (b *builder) dependOn(from, key, typeName string) {
+	to, ok := b.providerOf[key]
+	if !ok {
+		to = nodeID("missing " + typeName)
+		if !b.missing[to] {
+			b.missing[to] = true
+			b.add(Node{ID: to, Label: typeName + "\nno provider", Kind: KindMissing})
+		}
+	}
+	b.edges = append(b.edges, Edge{From: from, To: to, Label: typeName})
+}
+
+// providerName returns the package qualified name of a provider, e.g. "user.ProvideService"
+func providerName(provider scanner.ProviderFunction) string {
+	return provider.ImportName + "." + provider.FunctionName
+}
+
+// nodeID turns a name into an identifier both DOT and Mermaid accept, e.g. "user.ProvideService" -> "user_ProvideService"
+func nodeID(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// Write renders the graph as Graphviz DOT or a Mermaid flowchart
+func (s *service) Write(w io.Writer, graph *Graph, format string) error {
+	var content string
+	switch format {
+	case FormatDOT:
+		content = renderDOT(graph)
+	case FormatMermaid:
+		content = renderMermaid(graph)
+	default:
+		return fmt.Errorf("unsupported format %q, use %s or %s", format, FormatDOT, FormatMermaid)
+	}
+	_, err := io.WriteString(w, content)
+	return err
+}
+
+// dotStyles are the DOT attributes of each kind of node
+var dotStyles = map[string]string{
+	KindProvider: `shape=box`,
+	KindHandler:  `shape=box, style=filled, fillcolor="#dbeafe"`,
+	KindRouter:   `shape=box, style="filled,bold", fillcolor="#dcfce7"`,
+	KindServer:   `shape=box, style="filled,bold", fillcolor="#dcfce7"`,
+	KindMissing:  `shape=box, style="dashed", color="#dc2626", fontcolor="#dc2626"`,
+}
+
+// renderDOT renders the graph for Graphviz, e.g. taskw graph | dot -Tsvg > graph.svg
+func renderDOT(graph *Graph) string {
+	var b strings.Builder
+	b.WriteString("digraph taskw {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\", fontsize=10];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=8];\n")
+	for _, node := range graph.Nodes {
+		fmt.Fprintf(&b, "  %s [label=%s, %s];\n", node.ID, dotString(node.Label), dotStyles[node.Kind])
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", edge.From, edge.To, dotString(edge.Label))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotString quotes text for DOT, line breaks become \n
+func dotString(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	text = strings.ReplaceAll(text, `"`, `\"`)
+	return `"` + strings.ReplaceAll(text, "\n", `\n`) + `"`
+}
+
+// mermaidClasses are the Mermaid class definitions of each kind of node, providers keep the default style
+var mermaidClasses = []struct{ kind, style string }{
+	{KindHandler, "fill:#dbeafe"},
+	{KindRouter, "fill:#dcfce7,stroke-width:2px"},
+	{KindServer, "fill:#dcfce7,stroke-width:2px"},
+	{KindMissing, "stroke:#dc2626,color:#dc2626,stroke-dasharray:4"},
+}
+
+// renderMermaid renders the graph as a Mermaid flowchart, for markdown files and pull requests
+func renderMermaid(graph *Graph) string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, node := range graph.Nodes {
+		fmt.Fprintf(&b, "  %s[%s]\n", node.ID, mermaidString(node.Label))
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", edge.From, mermaidString(edge.Label), edge.To)
+	}
+	for _, class := range mermaidClasses {
+		var ids []string
+		for _, node := range graph.Nodes {
+			if node.Kind == class.kind {
+				ids = append(ids, node.ID)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "  classDef %s %s\n", class.kind, class.style)
+		fmt.Fprintf(&b, "  class %s %s\n", strings.Join(ids, ","), class.kind)
+	}
+	return b.String()
+}
+
+// mermaidString quotes text for Mermaid, line breaks become <br/>. Quotes and the characters of
+// generic types are written as entities, Mermaid would read them as syntax
+func mermaidString(text string) string {
+	replacer := strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "\n", "<br/>")
+	return `"` + replacer.Replace(text) + `"`
+}
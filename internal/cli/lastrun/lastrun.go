@@ -0,0 +1,137 @@
+// Package lastrun leaves the outcome and diagnostics of the last taskw command in the project, so bug
+// reports and support requests can attach one machine readable file instead of copied terminal output
+package lastrun
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// File is where the last command is recorded, relative to the project root
+const File = ".taskw/last-run.json"
+
+// Run is the record of a command: what was run, how it ended and what the scans and validations found
+type Run struct {
+	Command    string    `json:"command"` // e.g. "taskw generate all"
+	Args       []string  `json:"args"`    // Arguments as given, flags included
+	Version    string    `json:"version"` // Version of taskw
+	GoVersion  string    `json:"go_version"`
+	Platform   string    `json:"platform"` // e.g. "linux/amd64"
+	WorkDir    string    `json:"work_dir"` // Directory taskw was run from
+	StartedAt  time.Time `json:"started_at"`
+	DurationMS int64     `json:"duration_ms"`
+	ExitCode   int       `json:"exit_code"`
+	Error      string    `json:"error,omitempty"`
+
+	ScanErrors []scanner.ScanError      `json:"scan_errors"` // Parse errors, malformed annotations, skipped files
+	Validation scanner.ValidationResult `json:"validation"`
+}
+
+// Recorder records a command from its start, collecting the diagnostics of the scans and validations
+// it runs
+type Recorder struct {
+	run         Run
+	diagnostics *scanner.Diagnostics
+}
+
+// Begin starts recording a command, e.g. Begin("taskw generate all", os.Args[1:], "v1.4.0")
+func Begin(command string, args []string, version string) *Recorder {
+	workDir, _ := os.Getwd()
+	return &Recorder{
+		run: Run{
+			Command:   command,
+			Args:      args,
+			Version:   version,
+			GoVersion: runtime.Version(),
+			Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+			WorkDir:   workDir,
+			StartedAt: time.Now(),
+		},
+		diagnostics: scanner.BeginDiagnostics(),
+	}
+}
+
+// End stops recording and writes the record into the project of the working directory. Outside of a
+// project, with neither taskw.yaml nor go.mod, nothing is written
+func (r *Recorder) End(err error) error {
+	r.run.ScanErrors, r.run.Validation = r.diagnostics.End()
+	if r.run.ScanErrors == nil {
+		r.run.ScanErrors = []scanner.ScanError{}
+	}
+	if r.run.Validation.Errors == nil {
+		r.run.Validation.Errors = []scanner.ValidationError{}
+	}
+	if r.run.Validation.Warnings == nil {
+		r.run.Validation.Warnings = []scanner.ValidationWarning{}
+	}
+	r.run.DurationMS = time.Since(r.run.StartedAt).Milliseconds()
+	r.run.ExitCode = exitcode.Code(err)
+	if err != nil {
+		r.run.Error = err.Error()
+	}
+
+	root, ok := ProjectRoot()
+	if !ok {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(r.run, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding %s: %w", File, err)
+	}
+	path := filepath.Join(root, File)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// ProjectRoot finds the root of the project of the working directory like the CLI does, commands
+// that skip loading the configuration included. Reports false outside of a project
+func ProjectRoot() (string, bool) {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	cfg, err := config.Load(workDir)
+	if err != nil {
+		// A broken taskw.yaml is worth reporting too, its directory is still the project
+		return findUp(workDir, config.ConfigFileName)
+	}
+	for _, name := range []string{config.ConfigFileName, "go.mod"} {
+		if _, err := os.Stat(filepath.Join(cfg.Root, name)); err == nil {
+			return cfg.Root, true
+		}
+	}
+	return "", false
+}
+
+// findUp returns the outermost directory holding a file named name, from dir up to the module directory
+func findUp(dir, name string) (string, bool) {
+	found := ""
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, name)); err == nil {
+			found = current
+		}
+		if _, err := os.Stat(filepath.Join(current, "go.mod")); err == nil {
+			break
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+	return found, found != ""
+}
@@ -0,0 +1,120 @@
+// Package lock keeps concurrent taskw commands from writing the generated files of a project at the same time,
+// e.g. an editor save hook and a CI script both running taskw generate
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
+	"github.com/nkaewam/taskw/internal/cli/ui"
+)
+
+// File is the lock held while generating, relative to the project root
+const File = ".taskw/generate.lock"
+
+// DefaultTimeout is how long a command waits for another one to release the lock
+const DefaultTimeout = 30 * time.Second
+
+// ErrLocked reports that another taskw command kept holding the lock until the timeout. Test for it with errors.Is
+var ErrLocked = errors.New("another taskw command is generating code in this project")
+
+// pollInterval is how often a waiting command retries taking the lock
+const pollInterval = 100 * time.Millisecond
+
+// Lock is the project lock held by this process. The operating system releases it when the process
+// exits, so a crashed taskw never leaves the project locked
+type Lock struct {
+	file *os.File
+}
+
+// Acquire takes the lock of the project at root, waiting up to timeout while another taskw command holds it.
+// A zero timeout fails right away when the lock is taken. Waiting stops with ctx's error once ctx is done.
+// wait, unless nil, is called with the description of the holder when Acquire starts waiting
+func Acquire(ctx context.Context, root string, timeout time.Duration, wait func(holder string)) (*Lock, error) {
+	path := filepath.Join(root, File)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("error creating %s: %w", filepath.Dir(path), err)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %w", path, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	waiting := false
+	for {
+		locked, err := tryLock(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("error locking %s: %w", path, err)
+		}
+		if locked {
+			break
+		}
+
+		holder := readHolder(file)
+		if !time.Now().Before(deadline) {
+			file.Close()
+			return nil, exitcode.New(exitcode.Locked, fmt.Errorf("%w (%s), run again once it has finished", ErrLocked, holder))
+		}
+		if !waiting && wait != nil {
+			wait(holder)
+		}
+		waiting = true
+		select {
+		case <-ctx.Done():
+			file.Close()
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+
+	// Describe the holder to the commands waiting for the lock, the file itself is never removed:
+	// a waiting command may already have it open
+	holder := fmt.Sprintf("pid %d: %s, started %s", os.Getpid(), commandLine(), time.Now().Format(time.TimeOnly))
+	if err := file.Truncate(0); err == nil {
+		file.WriteAt([]byte(holder), 0)
+	}
+	return &Lock{file: file}, nil
+}
+
+// Notify tells the user the command waits for the holder of the lock, the wait function of the CLI commands
+func Notify(holder string) {
+	ui.Infof("⏳ Waiting for another taskw command to finish (%s)...", holder)
+}
+
+// Release releases the lock, a nil lock is a no-op
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	unlockErr := unlock(l.file)
+	closeErr := l.file.Close()
+	l.file = nil
+	if unlockErr != nil {
+		return unlockErr
+	}
+	return closeErr
+}
+
+// readHolder returns the description of the process holding the lock
+func readHolder(file *os.File) string {
+	content := make([]byte, 256)
+	n, _ := file.ReadAt(content, 0)
+	if holder := strings.TrimSpace(string(content[:n])); holder != "" {
+		return holder
+	}
+	return "unknown process"
+}
+
+// commandLine returns the command this process runs, e.g. "taskw generate routes"
+func commandLine() string {
+	args := append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...)
+	return strings.Join(args, " ")
+}
@@ -0,0 +1,70 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
+	"github.com/nkaewam/taskw/internal/cli/generation"
+	"github.com/nkaewam/taskw/internal/cli/ui"
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/generator"
+)
+
+// Service handles route migrations between paths
+type Service interface {
+	// AddPathMigration records a path migration and regenerates the redirect registrations
+	AddPathMigration(ctx context.Context, migration generator.RouteMigration) error
+}
+
+// service implements Service interface
+type service struct {
+	config     *config.Config
+	ui         ui.Service
+	generation generation.Service
+}
+
+// ProvideMigrateService creates a new migrate service
+// @Provider
+func ProvideMigrateService(config *config.Config, uiService ui.Service, generationService generation.Service) Service {
+	return &service{
+		config:     config,
+		ui:         uiService,
+		generation: generationService,
+	}
+}
+
+// AddPathMigration records a path migration and regenerates the redirect registrations
+func (s *service) AddPathMigration(ctx context.Context, migration generator.RouteMigration) error {
+	migrationsFile := s.config.Generation.Redirects.MigrationsFile
+	migrations, err := generator.LoadRouteMigrations(s.config.Root, migrationsFile)
+	if err != nil {
+		return exitcode.New(exitcode.Config, err)
+	}
+
+	if err := migrations.Add(migration); err != nil {
+		return err
+	}
+
+	// Keep the previous file so a migration that doesn't match the scanned routes isn't recorded
+	previous, readErr := os.ReadFile(migrationsFile)
+	if err := migrations.Save(s.config.Root, migrationsFile); err != nil {
+		return err
+	}
+
+	if err := s.generation.GenerateRedirects(ctx); err != nil {
+		if readErr != nil {
+			os.Remove(migrationsFile)
+		} else {
+			os.WriteFile(migrationsFile, previous, 0644)
+		}
+		return err
+	}
+
+	fmt.Printf("✅ Recorded migration in %s\n", migrationsFile)
+	if !s.config.Generation.Server.Enabled {
+		fmt.Printf("💡 Call RegisterRedirects after %s to serve the old paths\n", s.config.RegisterFunc())
+	}
+	return nil
+}
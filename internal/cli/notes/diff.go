@@ -0,0 +1,186 @@
+package notes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// Notes contains the API changes between a git revision and the working tree
+type Notes struct {
+	Since      string                 // Revision the routes are compared against, e.g. "v1.2.0"
+	Added      []scanner.RouteMapping // Routes that didn't exist at Since
+	Deprecated []scanner.RouteMapping // Routes marked @Deprecated since Since
+	Changed    []RouteChange          // Routes whose @Param annotations changed
+	Removed    []scanner.RouteMapping // Routes that existed at Since and no longer do
+}
+
+// RouteChange lists the parameter changes of a route present in both revisions
+type RouteChange struct {
+	Route   scanner.RouteMapping
+	Changes []string // e.g. "Added query parameter `limit` (int, optional)"
+}
+
+// Empty checks if no API changes were found
+func (n *Notes) Empty() bool {
+	return len(n.Added) == 0 && len(n.Deprecated) == 0 && len(n.Changed) == 0 && len(n.Removed) == 0
+}
+
+// compareRoutes finds the routes added, deprecated, removed and with changed parameters
+func compareRoutes(since string, before, after []scanner.RouteMapping) *Notes {
+	notes := &Notes{Since: since}
+
+	previous := make(map[string]scanner.RouteMapping, len(before))
+	for _, route := range before {
+		previous[routeKey(route)] = route
+	}
+
+	current := make(map[string]bool, len(after))
+	for _, route := range after {
+		key := routeKey(route)
+		current[key] = true
+
+		old, existed := previous[key]
+		if !existed {
+			notes.Added = append(notes.Added, route)
+			continue
+		}
+		if route.Deprecated && !old.Deprecated {
+			notes.Deprecated = append(notes.Deprecated, route)
+		}
+		if changes := compareParams(old.Params, route.Params); len(changes) > 0 {
+			notes.Changed = append(notes.Changed, RouteChange{Route: route, Changes: changes})
+		}
+	}
+
+	for _, route := range before {
+		if !current[routeKey(route)] {
+			notes.Removed = append(notes.Removed, route)
+		}
+	}
+
+	sortRoutes(notes.Added)
+	sortRoutes(notes.Deprecated)
+	sortRoutes(notes.Removed)
+	sort.Slice(notes.Changed, func(i, j int) bool {
+		return routeLess(notes.Changed[i].Route, notes.Changed[j].Route)
+	})
+	return notes
+}
+
+// compareParams describes the parameters added, removed, retyped or made (not) required
+func compareParams(before, after []scanner.Param) []string {
+	paramKey := func(param scanner.Param) string { return param.In + " " + param.Name }
+
+	previous := make(map[string]scanner.Param, len(before))
+	for _, param := range before {
+		previous[paramKey(param)] = param
+	}
+
+	var changes []string
+	current := make(map[string]bool, len(after))
+	for _, param := range after {
+		current[paramKey(param)] = true
+
+		old, existed := previous[paramKey(param)]
+		switch {
+		case !existed:
+			changes = append(changes, fmt.Sprintf("Added %s parameter `%s` (%s, %s)", param.In, param.Name, param.Type, requiredText(param.Required)))
+			continue
+		case old.Type != param.Type:
+			changes = append(changes, fmt.Sprintf("Changed %s parameter `%s` from %s to %s", param.In, param.Name, old.Type, param.Type))
+		}
+		if old.Required != param.Required {
+			changes = append(changes, fmt.Sprintf("Made %s parameter `%s` %s", param.In, param.Name, requiredText(param.Required)))
+		}
+	}
+
+	for _, param := range before {
+		if !current[paramKey(param)] {
+			changes = append(changes, fmt.Sprintf("Removed %s parameter `%s`", param.In, param.Name))
+		}
+	}
+	return changes
+}
+
+// requiredText describes whether a parameter is required
+func requiredText(required bool) string {
+	if required {
+		return "required"
+	}
+	return "optional"
+}
+
+// routeKey identifies a route across revisions, ignoring parameter names and syntax
+func routeKey(route scanner.RouteMapping) string {
+	segments := strings.Split(strings.Trim(route.Path, "/"), "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || (strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")) {
+			segments[i] = "{}"
+		}
+	}
+	return route.HTTPMethod + " /" + strings.Join(segments, "/")
+}
+
+// sortRoutes orders routes by path, then method
+func sortRoutes(routes []scanner.RouteMapping) {
+	sort.Slice(routes, func(i, j int) bool {
+		return routeLess(routes[i], routes[j])
+	})
+}
+
+// routeLess orders routes by path, then method
+func routeLess(a, b scanner.RouteMapping) bool {
+	if a.Path != b.Path {
+		return a.Path < b.Path
+	}
+	return a.HTTPMethod < b.HTTPMethod
+}
+
+// Markdown renders the notes as a release note section, ready to paste into a changelog
+func (n *Notes) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## API changes since %s\n", n.Since)
+
+	if n.Empty() {
+		b.WriteString("\nNo API changes.\n")
+		return b.String()
+	}
+
+	writeRoutes := func(title string, routes []scanner.RouteMapping) {
+		if len(routes) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", title)
+		for _, route := range routes {
+			b.WriteString("- " + routeLine(route) + "\n")
+		}
+	}
+
+	writeRoutes("New endpoints", n.Added)
+	writeRoutes("Deprecated", n.Deprecated)
+
+	if len(n.Changed) > 0 {
+		b.WriteString("\n### Changed parameters\n\n")
+		for _, change := range n.Changed {
+			b.WriteString("- " + routeLine(change.Route) + "\n")
+			for _, line := range change.Changes {
+				b.WriteString("  - " + line + "\n")
+			}
+		}
+	}
+
+	writeRoutes("Removed endpoints", n.Removed)
+	return b.String()
+}
+
+// routeLine renders a route with its @Summary, e.g. "`GET /users/{id}`: Get a user"
+func routeLine(route scanner.RouteMapping) string {
+	line := fmt.Sprintf("`%s %s`", route.HTTPMethod, route.Path)
+	if route.Summary != "" {
+		line += ": " + route.Summary
+	}
+	return line
+}
@@ -0,0 +1,134 @@
+package notes
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
+)
+
+// resolveRef returns the commit a tag, branch or other revision points to
+func resolveRef(ctx context.Context, ref string) (string, error) {
+	commit, err := runGit(ctx, "rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return "", exitcode.New(exitcode.ExternalTool, fmt.Errorf("error resolving %s: %w", ref, err))
+	}
+	return commit, nil
+}
+
+// checkoutTree extracts the Go files of the project root at a commit, and the extra files given
+// relative to the root, into a temporary directory. The caller removes the directory
+func checkoutTree(ctx context.Context, commit string, extraFiles ...string) (string, error) {
+	// The project root may be a subdirectory of the repository
+	prefix, err := runGit(ctx, "rev-parse", "--show-prefix")
+	if err != nil {
+		return "", exitcode.New(exitcode.ExternalTool, fmt.Errorf("error locating the project in the git repository: %w", err))
+	}
+	topLevel, err := runGit(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", exitcode.New(exitcode.ExternalTool, fmt.Errorf("error locating the project in the git repository: %w", err))
+	}
+
+	dir, err := os.MkdirTemp("", "taskw-notes-")
+	if err != nil {
+		return "", fmt.Errorf("error creating temporary directory: %w", err)
+	}
+
+	wanted := make(map[string]bool)
+	for _, file := range extraFiles {
+		if file != "" {
+			wanted[filepath.ToSlash(filepath.Clean(file))] = true
+		}
+	}
+
+	// Run from the top level, git archive limits the archive to the working directory otherwise
+	cmd := exec.CommandContext(ctx, "git", "archive", "--format=tar", commit+":"+strings.TrimSuffix(prefix, "/"))
+	cmd.Dir = topLevel
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("error running git archive: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		return "", exitcode.New(exitcode.ExternalTool, fmt.Errorf("error running git archive: %w", err))
+	}
+
+	extractErr := extractTar(stdout, dir, func(name string) bool {
+		return strings.HasSuffix(name, ".go") || wanted[name]
+	})
+	io.Copy(io.Discard, stdout) // Let git finish writing if extraction stopped early
+	if err := cmd.Wait(); err != nil {
+		os.RemoveAll(dir)
+		return "", exitcode.New(exitcode.ExternalTool, fmt.Errorf("error running git archive: %s", strings.TrimSpace(stderr.String())))
+	}
+	if extractErr != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("error extracting %s: %w", commit, extractErr)
+	}
+
+	return dir, nil
+}
+
+// extractTar writes the regular files of a tar stream accepted by include into dir
+func extractTar(r io.Reader, dir string, include func(name string) bool) error {
+	archive := tar.NewReader(r)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg || !include(header.Name) {
+			continue
+		}
+
+		// Never write outside dir, whatever the archive contains
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, dir+string(filepath.Separator)) {
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		file, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(file, archive)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// runGit runs a git command in the project root and returns its trimmed output
+func runGit(ctx context.Context, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("%s", message)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,85 @@
+package notes
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// Service builds release notes from the route changes between git revisions
+type Service interface {
+	// Compare scans the project at a git revision and in the working tree, and returns the API changes between them
+	Compare(ctx context.Context, since string) (*Notes, error)
+}
+
+// service implements Service interface
+type service struct {
+	config  *config.Config
+	scanner *scanner.Scanner
+}
+
+// ProvideNotesService creates a new notes service
+// @Provider
+func ProvideNotesService(config *config.Config) Service {
+	return &service{
+		config:  config,
+		scanner: scanner.NewScanner(config),
+	}
+}
+
+// Compare scans the project at a git revision and in the working tree, and returns the API changes between them
+func (s *service) Compare(ctx context.Context, since string) (*Notes, error) {
+	commit, err := resolveRef(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := s.scanRevision(ctx, since, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := s.scanner.ScanAll(ctx)
+	if err != nil {
+		return nil, exitcode.New(exitcode.Scan, fmt.Errorf("error scanning: %w", err))
+	}
+
+	return compareRoutes(since, before.Routes, after.Routes), nil
+}
+
+// scanRevision scans the project as it was at a commit, with the current configuration
+func (s *service) scanRevision(ctx context.Context, since, commit string) (*scanner.ScanResult, error) {
+	dir, err := checkoutTree(ctx, commit, s.config.Paths.RoutesOverlay)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	// The old tree lives outside the module, type checking it isn't possible
+	cfg := *s.config
+	cfg.Scanning.Mode = config.ScanModeAST
+	cfg.Paths.ScanDirs = nil
+	for _, scanDir := range s.config.Paths.ScanDirs {
+		// Directories added since have no routes to compare against
+		if _, err := os.Stat(filepath.Join(dir, scanDir)); err == nil {
+			cfg.Paths.ScanDirs = append(cfg.Paths.ScanDirs, filepath.Join(dir, scanDir))
+		}
+	}
+	cfg.Paths.RoutesOverlay = ""
+	if overlay := s.config.Paths.RoutesOverlay; overlay != "" {
+		if _, err := os.Stat(filepath.Join(dir, overlay)); err == nil {
+			cfg.Paths.RoutesOverlay = filepath.Join(dir, overlay)
+		}
+	}
+
+	result, err := scanner.NewScanner(&cfg).ScanAll(ctx)
+	if err != nil {
+		return nil, exitcode.New(exitcode.Scan, fmt.Errorf("error scanning %s: %w", since, err))
+	}
+	return result, nil
+}
@@ -1,8 +1,10 @@
 package project
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
 	"github.com/nkaewam/taskw/internal/cli/ui"
 	"github.com/nkaewam/taskw/internal/generator"
 )
@@ -10,7 +12,7 @@ import (
 // Service handles project initialization and scaffolding
 type Service interface {
 	// InitProject creates a new project with full scaffolding
-	InitProject(projectPath, module, projectName string) error
+	InitProject(ctx context.Context, projectPath, module, projectName string, opts generator.InitOptions) error
 	// ValidateModule validates that the module path is a proper Go module format
 	ValidateModule(module string) error
 	// ExtractProjectName extracts the project name from a module path
@@ -33,7 +35,7 @@ func ProvideProjectService(uiService ui.Service) Service {
 }
 
 // InitProject creates a new project with full scaffolding
-func (s *service) InitProject(projectPath, module, projectName string) error {
+func (s *service) InitProject(ctx context.Context, projectPath, module, projectName string, opts generator.InitOptions) error {
 	// Validate project directory
 	initGen := generator.NewInitGenerator()
 	if err := initGen.ValidateProjectPath(projectPath); err != nil {
@@ -41,23 +43,23 @@ func (s *service) InitProject(projectPath, module, projectName string) error {
 	}
 
 	// Generate the project
-	if err := initGen.InitProject(projectPath, module, projectName); err != nil {
-		return fmt.Errorf("failed to initialize project: %w", err)
+	if err := initGen.InitProject(ctx, projectPath, module, projectName, opts); err != nil {
+		return exitcode.New(exitcode.Generation, fmt.Errorf("failed to initialize project: %w", err))
 	}
 
 	// Success message
-	fmt.Println("\n🎉 Project scaffolded successfully!")
-	fmt.Printf("📁 Created in: %s/\n", projectPath)
-	fmt.Printf("📦 Module: %s\n", module)
+	ui.Infof("\n🎉 Project scaffolded successfully!")
+	ui.Infof("📁 Created in: %s/", projectPath)
+	ui.Infof("📦 Module: %s", module)
 
-	fmt.Println("\nNext steps:")
-	fmt.Printf("  cd %s\n", projectName)
-	fmt.Println("  go mod tidy")
-	fmt.Println("  task setup           # Install dependencies and generate code")
-	fmt.Println("  task dev             # Start development server with live reload")
-	fmt.Println("\nOr run manually:")
-	fmt.Println("  taskw generate       # Generate routes and dependencies")
-	fmt.Println("  go run cmd/server/main.go  # Start the server")
+	ui.Infof("\nNext steps:")
+	ui.Infof("  cd %s", projectName)
+	ui.Infof("  go mod tidy")
+	ui.Infof("  task setup           # Install dependencies and generate code")
+	ui.Infof("  task dev             # Start development server with live reload")
+	ui.Infof("\nOr run manually:")
+	ui.Infof("  taskw generate       # Generate routes and dependencies")
+	ui.Infof("  go run cmd/server/main.go  # Start the server")
 
 	return nil
 }
@@ -0,0 +1,146 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nkaewam/taskw/internal/cli/generation"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// Output formats of taskw routes
+const (
+	FormatTable    = "table"
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+// Route is a row of the route table
+type Route struct {
+	Method     string `json:"method"`
+	Path       string `json:"path"`                  // In the router's syntax, e.g. "/users/:id" for Fiber
+	Handler    string `json:"handler"`               // e.g., "userHandler.GetUser"
+	Source     string `json:"source"`                // File and line of the @Router annotation, e.g. "internal/user/handler.go:42"
+	Summary    string `json:"summary,omitempty"`     // From @Summary
+	ShadowedBy string `json:"shadowed_by,omitempty"` // Earlier route matching every request this one would, which wins
+}
+
+// Service lists the routes the generated router registers
+type Service interface {
+	// Table returns the registered routes in registration order, with the route shadowing each
+	Table(ctx context.Context) ([]Route, error)
+	// Write writes the route table as an aligned table, JSON or a markdown table
+	Write(w io.Writer, routes []Route, format string) error
+}
+
+// service implements Service interface
+type service struct {
+	generation generation.Service
+}
+
+// ProvideRoutesService creates a new routes service
+// @Provider
+func ProvideRoutesService(generationService generation.Service) Service {
+	return &service{
+		generation: generationService,
+	}
+}
+
+// Table returns the registered routes in registration order, with the route shadowing each
+func (s *service) Table(ctx context.Context) ([]Route, error) {
+	registered, err := s.generation.RegisteredRoutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	table := make([]Route, 0, len(registered))
+	first := make(map[string]Route) // First route registered for each method and path shape
+	for _, mapping := range registered {
+		route := Route{
+			Method:  mapping.HTTPMethod,
+			Path:    mapping.Path,
+			Handler: mapping.HandlerRef,
+			Source:  fmt.Sprintf("%s:%d", filepath.ToSlash(filepath.Clean(mapping.FilePath)), mapping.Line),
+			Summary: mapping.Summary,
+		}
+		key := routeShape(mapping)
+		if earlier, ok := first[key]; ok {
+			route.ShadowedBy = fmt.Sprintf("%s %s (%s)", earlier.Method, earlier.Path, earlier.Handler)
+		} else {
+			first[key] = route
+		}
+		table = append(table, route)
+	}
+	return table, nil
+}
+
+// routeShape identifies the requests a route matches: its method and path with parameter names left out,
+// e.g. "GET /users/{}" for both "/users/:id" and "/users/:userId"
+func routeShape(route scanner.RouteMapping) string {
+	segments := strings.Split(strings.Trim(route.Path, "/"), "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || (strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")) {
+			segments[i] = "{}"
+		}
+	}
+	return route.HTTPMethod + " /" + strings.Join(segments, "/")
+}
+
+// Write writes the route table as an aligned table, JSON or a markdown table
+func (s *service) Write(w io.Writer, routes []Route, format string) error {
+	switch format {
+	case FormatTable:
+		return writeTable(w, routes)
+	case FormatJSON:
+		content, err := json.MarshalIndent(routes, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding route table: %w", err)
+		}
+		_, err = w.Write(append(content, '\n'))
+		return err
+	case FormatMarkdown:
+		return writeMarkdown(w, routes)
+	default:
+		return fmt.Errorf("unsupported format %q, use %s, %s or %s", format, FormatTable, FormatJSON, FormatMarkdown)
+	}
+}
+
+// writeTable writes the routes as columns aligned for terminals
+func writeTable(w io.Writer, routes []Route) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tMETHOD\tPATH\tHANDLER\tSOURCE\tNOTE")
+	for i, route := range routes {
+		note := route.Summary
+		if route.ShadowedBy != "" {
+			note = "⚠️ shadowed by " + route.ShadowedBy
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", i+1, route.Method, route.Path, route.Handler, route.Source, note)
+	}
+	return tw.Flush()
+}
+
+// writeMarkdown writes the routes as a markdown table, for pull requests and wikis
+func writeMarkdown(w io.Writer, routes []Route) error {
+	var b strings.Builder
+	b.WriteString("| # | Method | Path | Handler | Source | Summary |\n")
+	b.WriteString("|---|--------|------|---------|--------|---------|\n")
+	for i, route := range routes {
+		summary := markdownCell(route.Summary)
+		if route.ShadowedBy != "" {
+			summary = strings.TrimSpace(summary + " ⚠️ shadowed by `" + route.ShadowedBy + "`")
+		}
+		fmt.Fprintf(&b, "| %d | %s | `%s` | `%s` | %s | %s |\n", i+1, route.Method, route.Path, route.Handler, route.Source, summary)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell escapes the pipes of text, which would end the table cell
+func markdownCell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
@@ -0,0 +1,75 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
+)
+
+// ChangedFiles lists the files of the project changed since a git revision, committed or not,
+// untracked files included. Paths are relative to the project root
+func (s *service) ChangedFiles(ctx context.Context, since string) ([]string, error) {
+	files := []string{}
+	for _, args := range [][]string{
+		{"diff", "--name-only", "--relative", since, "--"},
+		{"ls-files", "--others", "--exclude-standard"},
+	} {
+		lines, err := gitLines(ctx, args...)
+		if err != nil {
+			return nil, exitcode.New(exitcode.ExternalTool, fmt.Errorf("error listing files changed since %s: %w", since, err))
+		}
+		files = append(files, lines...)
+	}
+	return files, nil
+}
+
+// RevisionFiles returns the git object IDs of the files of the project at a revision,
+// by slash-separated path relative to the project root
+func (s *service) RevisionFiles(ctx context.Context, revision string) (map[string]string, error) {
+	lines, err := gitLines(ctx, "ls-tree", "-r", revision)
+	if err != nil {
+		return nil, exitcode.New(exitcode.ExternalTool, fmt.Errorf("error listing files at %s: %w", revision, err))
+	}
+
+	// Each line is "<mode> <type> <object>\t<path>", paths with special characters are quoted and left out:
+	// their files are read like changed ones
+	files := make(map[string]string, len(lines))
+	for _, line := range lines {
+		info, path, ok := strings.Cut(line, "\t")
+		fields := strings.Fields(info)
+		if !ok || len(fields) != 3 || fields[1] != "blob" || strings.HasPrefix(path, `"`) {
+			continue
+		}
+		files[path] = fields[2]
+	}
+	return files, nil
+}
+
+// gitLines runs a git command in the project root and returns the non-empty lines of its output
+func gitLines(ctx context.Context, args ...string) ([]string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%s", message)
+		}
+		return nil, err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
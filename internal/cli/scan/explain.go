@@ -0,0 +1,64 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
+	"github.com/nkaewam/taskw/internal/generator"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// Explain evaluates the detection rules on the function a target names, e.g. "./internal/user/handler.go:GetUser"
+// or "./internal/user/handler.go:Handler.GetUser" for a method of a given receiver
+func (s *service) Explain(ctx context.Context, target string) ([]scanner.Explanation, error) {
+	index := strings.LastIndex(target, ":")
+	if index <= 0 || index == len(target)-1 {
+		return nil, exitcode.New(exitcode.General, fmt.Errorf("%q doesn't name a function, use <file>:<function>, e.g. ./internal/user/handler.go:GetUser", target))
+	}
+	filePath, name := s.config.RelativeToRoot(target[:index]), target[index+1:]
+	if _, err := os.Stat(filePath); err != nil {
+		return nil, exitcode.New(exitcode.General, fmt.Errorf("error reading %s: %w", filePath, err))
+	}
+
+	explanations, err := s.scanner.Explain(ctx, filePath, name)
+	if err != nil {
+		return nil, exitcode.New(exitcode.Scan, err)
+	}
+	return explanations, nil
+}
+
+// ShowExplanations displays every rule evaluated and what each function was detected as
+func (s *service) ShowExplanations(explanations []scanner.Explanation) {
+	for i, explanation := range explanations {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s (%s:%d)\n", explanation.Function, filepath.ToSlash(explanation.FilePath), explanation.Line)
+		for _, rule := range explanation.Rules {
+			mark := "✅"
+			if !rule.Passed {
+				mark = "❌"
+			}
+			fmt.Printf("  %s %s: %s\n", mark, rule.Name, rule.Detail)
+		}
+
+		fmt.Println("Detected as:")
+		switch {
+		case explanation.Route != nil:
+			route := explanation.Route
+			fmt.Printf("  • Route %s %s -> %s\n", route.HTTPMethod, generator.FormatRoutePath(s.config, route.Path), route.HandlerRef)
+		case explanation.Handler:
+			fmt.Println("  • Handler without a route")
+		}
+		if explanation.Provider {
+			fmt.Println("  • Provider")
+		}
+		if !explanation.Handler && !explanation.Provider {
+			fmt.Println("  • Nothing, taskw leaves it alone")
+		}
+	}
+}
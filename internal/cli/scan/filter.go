@@ -0,0 +1,56 @@
+package scan
+
+import (
+	"fmt"
+
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// Sections of the scan results --only narrows the output down to
+const (
+	SectionHandlers  = "handlers"
+	SectionRoutes    = "routes"
+	SectionProviders = "providers"
+)
+
+// CheckSection returns an error for a section --only doesn't know, an empty section selects all of them
+func CheckSection(section string) error {
+	switch section {
+	case "", SectionHandlers, SectionRoutes, SectionProviders:
+		return nil
+	}
+	return exitcode.New(exitcode.Config, fmt.Errorf("unknown section %q, use %s, %s or %s", section, SectionRoutes, SectionProviders, SectionHandlers))
+}
+
+// Filter narrows scan results down to the selected packages, matched by name, import name or directory.
+// A package that matches nothing is reported, so a typo doesn't pass for an empty package
+func (s *service) Filter(result *scanner.ScanResult, packages []string) (*scanner.ScanResult, error) {
+	for _, name := range packages {
+		filter := scanner.PackageFilter{name}
+		if filtered := filter.Apply(result); len(filtered.Handlers)+len(filtered.Routes)+len(filtered.Providers) == 0 {
+			return nil, exitcode.New(exitcode.Config, fmt.Errorf("no handlers, routes or providers found in package %q", name))
+		}
+	}
+	return scanner.PackageFilter(packages).Apply(result), nil
+}
+
+// OnlySection returns the scan results without the handlers, routes and providers outside the section,
+// the other data the generators work from is kept
+func OnlySection(result *scanner.ScanResult, section string) *scanner.ScanResult {
+	if section == "" {
+		return result
+	}
+
+	only := *result
+	if section != SectionHandlers {
+		only.Handlers = []scanner.HandlerFunction{}
+	}
+	if section != SectionRoutes {
+		only.Routes = []scanner.RouteMapping{}
+	}
+	if section != SectionProviders {
+		only.Providers = []scanner.ProviderFunction{}
+	}
+	return &only
+}
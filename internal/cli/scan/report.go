@@ -0,0 +1,81 @@
+package scan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// Output formats of taskw scan
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// Report is the machine readable output of taskw scan: every field of the scan result,
+// followed by the validation findings and statistics
+type Report struct {
+	*scanner.ScanResult
+	Validation *scanner.ValidationResult
+	Statistics scanner.ScanStatistics
+}
+
+// WriteReport writes the scan results and validation findings as JSON or YAML
+func (s *service) WriteReport(w io.Writer, result *scanner.ScanResult, validation *scanner.ValidationResult, format string) error {
+	report := Report{
+		ScanResult: result,
+		Validation: validation,
+		Statistics: s.scanner.GetStatistics(result),
+	}
+
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding scan report: %w", err)
+	}
+
+	switch format {
+	case FormatJSON:
+		_, err = w.Write(append(content, '\n'))
+		return err
+	case FormatYAML:
+		return writeYAML(w, content)
+	default:
+		return fmt.Errorf("unsupported format %q, use %s, %s or %s", format, FormatText, FormatJSON, FormatYAML)
+	}
+}
+
+// writeYAML converts a JSON document to YAML. JSON is valid YAML, decoding it into a node
+// keeps the field names and their order, only the flow style has to go
+func writeYAML(w io.Writer, content []byte) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return fmt.Errorf("error encoding scan report: %w", err)
+	}
+	blockStyle(&node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return fmt.Errorf("error encoding scan report: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("error encoding scan report: %w", err)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// blockStyle renders a node and its children in block style, strings keep the quotes they need
+func blockStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
@@ -1,22 +1,46 @@
 package scan
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"strings"
 
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
 	"github.com/nkaewam/taskw/internal/cli/ui"
 	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/generator"
 	"github.com/nkaewam/taskw/internal/scanner"
 )
 
 // Service handles codebase scanning operations
 type Service interface {
 	// ScanAll scans all configured directories and returns scan results
-	ScanAll() (*scanner.ScanResult, error)
-	// ShowScanResults displays scan results to the user
-	ShowScanResults(result *scanner.ScanResult) error
+	ScanAll(ctx context.Context) (*scanner.ScanResult, error)
+	// Scan scans all configured directories without progress output, for machine readable output
+	Scan(ctx context.Context) (*scanner.ScanResult, error)
+	// ShowScanResults displays scan results to the user, only one section of them when section is set
+	ShowScanResults(result *scanner.ScanResult, section string) error
+	// Filter narrows scan results down to the selected packages, for --package
+	Filter(result *scanner.ScanResult, packages []string) (*scanner.ScanResult, error)
+	// ShowProviderOrder displays the providers in initialization order with the providers each pulls in
+	ShowProviderOrder(result *scanner.ScanResult) error
 	// ValidateScanResults performs validation on scan results
 	ValidateScanResults(result *scanner.ScanResult) error
+	// Validate checks scan results without printing the findings
+	Validate(result *scanner.ScanResult) (*scanner.ValidationResult, error)
+	// CheckValidation returns an error for validation findings at or above the validation.fail_on severity
+	CheckValidation(validation *scanner.ValidationResult) error
+	// WriteReport writes the scan results and validation findings as JSON or YAML
+	WriteReport(w io.Writer, result *scanner.ScanResult, validation *scanner.ValidationResult, format string) error
+	// ChangedFiles lists the files of the project changed since a git revision, committed or not
+	ChangedFiles(ctx context.Context, since string) ([]string, error)
+	// RevisionFiles returns the git object IDs of the files of the project at a revision
+	RevisionFiles(ctx context.Context, revision string) (map[string]string, error)
+	// Explain evaluates the detection rules on the function a target names, e.g. "./internal/user/handler.go:GetUser"
+	Explain(ctx context.Context, target string) ([]scanner.Explanation, error)
+	// ShowExplanations displays every rule evaluated and what each function was detected as
+	ShowExplanations(explanations []scanner.Explanation)
 }
 
 // service implements Service interface
@@ -29,36 +53,65 @@ type service struct {
 // ProvideScanService creates a new scan service
 // @Provider
 func ProvideScanService(config *config.Config, uiService ui.Service) Service {
+	scan := scanner.NewScanner(config)
+	scan.SetObserver(ui.NewScanProgress())
 	return &service{
 		config:  config,
-		scanner: scanner.NewScanner(config),
+		scanner: scan,
 		ui:      uiService,
 	}
 }
 
 // ScanAll scans all configured directories and returns scan results
-func (s *service) ScanAll() (*scanner.ScanResult, error) {
+func (s *service) ScanAll(ctx context.Context) (*scanner.ScanResult, error) {
 	stopSpinner := s.ui.ShowSpinner("Scanning codebase...")
-	fmt.Println("• Using ignore patterns from .taskwignore")
+	ui.Infof("• Using ignore patterns from .taskwignore")
 
-	result, err := s.scanner.ScanAll()
+	result, err := s.Scan(ctx)
 	if err != nil {
 		stopSpinner("Scan failed")
-		return nil, fmt.Errorf("error scanning: %w", err)
+		return nil, err
 	}
 
 	stopSpinner("Codebase scanned successfully")
 	return result, nil
 }
 
-// ShowScanResults displays scan results to the user
-func (s *service) ShowScanResults(result *scanner.ScanResult) error {
+// Scan scans all configured directories without progress output, for machine readable output
+func (s *service) Scan(ctx context.Context) (*scanner.ScanResult, error) {
+	result, err := s.scanner.ScanAll(ctx)
+	if err != nil {
+		return nil, exitcode.New(exitcode.Scan, fmt.Errorf("error scanning: %w", err))
+	}
+	return result, nil
+}
+
+// ShowScanResults displays scan results to the user, only one section of them when section is set
+func (s *service) ShowScanResults(result *scanner.ScanResult, section string) error {
 	// Display results
 	stats := s.scanner.GetStatistics(result)
 	fmt.Printf("\nScan Results:\n")
-	fmt.Printf("  • Handlers found: %d\n", stats.HandlersFound)
-	fmt.Printf("  • Routes found: %d\n", stats.RoutesFound)
-	fmt.Printf("  • Providers found: %d\n", stats.ProvidersFound)
+	if section == "" || section == SectionHandlers {
+		fmt.Printf("  • Handlers found: %d\n", stats.HandlersFound)
+	}
+	if section == "" || section == SectionRoutes {
+		fmt.Printf("  • Routes found: %d\n", stats.RoutesFound)
+	}
+	if section == "" || section == SectionProviders {
+		fmt.Printf("  • Providers found: %d\n", stats.ProvidersFound)
+	}
+	switch section {
+	case SectionHandlers:
+		s.showHandlers(result)
+		return nil
+	case SectionRoutes:
+		s.showRoutes(result.Routes)
+		s.showRPCs(result.RPCs)
+		return nil
+	case SectionProviders:
+		s.showProviders(result.Providers)
+		return nil
+	}
 	fmt.Printf("  • Packages scanned: %d\n", stats.PackagesScanned)
 
 	if stats.ErrorsFound > 0 {
@@ -66,35 +119,158 @@ func (s *service) ShowScanResults(result *scanner.ScanResult) error {
 	}
 
 	// Show detailed results if requested
-	if len(result.Handlers) > 0 {
-		fmt.Println("\nHandlers:")
-		for _, h := range result.Handlers {
-			fmt.Printf("  - %s.%s (%s)\n", h.Package, h.FunctionName, h.HandlerName)
+	s.showHandlers(result)
+	s.showRoutes(result.Routes)
+	s.showRPCs(result.RPCs)
+	s.showProviders(result.Providers)
+
+	if failures := result.Failures(); len(failures) > 0 {
+		fmt.Println("\nErrors:")
+		for _, e := range failures {
+			fmt.Printf("  - %s: %s\n", e.Position(), e.Message)
+			if e.Suggestion != "" {
+				fmt.Printf("    did you mean: %s\n", e.Suggestion)
+			}
+		}
+	}
+
+	var warnings, skipped []scanner.ScanError
+	for _, e := range result.Errors {
+		switch {
+		case e.Warning():
+			warnings = append(warnings, e)
+		case e.Skipped():
+			skipped = append(skipped, e)
+		}
+	}
+	if len(warnings) > 0 {
+		fmt.Println("\nWarnings:")
+		for _, e := range warnings {
+			fmt.Printf("  - %s: %s\n", e.Position(), e.Message)
+			if e.Suggestion != "" {
+				fmt.Printf("    did you mean: %s\n", e.Suggestion)
+			}
+		}
+	}
+	if len(skipped) > 0 {
+		fmt.Println("\nSkipped:")
+		for _, e := range skipped {
+			fmt.Printf("  - %s: %s\n", e.Position(), e.Message)
 		}
 	}
 
-	if len(result.Routes) > 0 {
-		fmt.Println("\nRoutes:")
-		for _, r := range result.Routes {
-			// Convert {param} to :param for display consistency with generated routes
-			displayPath := strings.ReplaceAll(r.Path, "{", ":")
-			displayPath = strings.ReplaceAll(displayPath, "}", "")
-			fmt.Printf("  - %s %s -> %s\n", r.HTTPMethod, displayPath, r.HandlerRef)
+	return nil
+}
+
+// showHandlers lists handlers with the server field each is bound to
+func (s *service) showHandlers(result *scanner.ScanResult) {
+	if len(result.Handlers) == 0 {
+		return
+	}
+
+	fieldTypes := scanner.HandlerFieldTypes(result.Routes)
+	fmt.Println("\nHandlers:")
+	for _, h := range result.Handlers {
+		if h.IsFunction {
+			fmt.Printf("  - %s.%s (function)\n", h.Package, h.FunctionName)
+			continue
+		}
+
+		// Show the field the handler is bound to, and the types it is shared with
+		field := h.ServerField()
+		binding := "field " + field
+		if types := fieldTypes[field]; len(types) > 1 {
+			binding += fmt.Sprintf(", conflicts with %s", strings.Join(withoutValue(types, h.ImportName+"."+h.HandlerName), ", "))
+		}
+		fmt.Printf("  - %s.%s (%s) -> %s\n", h.Package, h.FunctionName, h.HandlerName, binding)
+	}
+}
+
+// showRoutes lists routes with their path formatted like the generated routes
+func (s *service) showRoutes(routes []scanner.RouteMapping) {
+	if len(routes) == 0 {
+		return
+	}
+
+	fmt.Println("\nRoutes:")
+	for _, r := range routes {
+		// Convert path parameters for display consistency with generated routes
+		displayPath := generator.FormatRoutePath(s.config, r.Path)
+		line := fmt.Sprintf("  - %s %s -> %s", r.HTTPMethod, displayPath, r.HandlerRef)
+		if r.Summary != "" {
+			line += ": " + r.Summary
 		}
+		fmt.Println(line)
 	}
+}
 
-	if len(result.Providers) > 0 {
-		fmt.Println("\nProviders:")
-		for _, p := range result.Providers {
-			fmt.Printf("  - %s() -> %s\n", p.FunctionName, p.ReturnType)
+// showRPCs lists the gRPC methods the gateway serves over HTTP
+func (s *service) showRPCs(rpcs []scanner.RPCMethod) {
+	if len(rpcs) == 0 {
+		return
+	}
+
+	fmt.Println("\nGateway Routes:")
+	for _, r := range rpcs {
+		line := fmt.Sprintf("  - %s %s -> %s.%s.%s (gRPC)", r.HTTPMethod, generator.FormatRoutePath(s.config, r.Path), r.ImportName, r.ServerName, r.MethodName)
+		if r.Summary != "" {
+			line += ": " + r.Summary
 		}
+		fmt.Println(line)
 	}
+}
 
-	if len(result.Errors) > 0 {
-		fmt.Println("\nErrors:")
-		for _, e := range result.Errors {
-			fmt.Printf("  - %s: %s\n", e.FilePath, e.Message)
+// showProviders lists providers with their full return tuple
+func (s *service) showProviders(providers []scanner.ProviderFunction) {
+	if len(providers) == 0 {
+		return
+	}
+
+	fmt.Println("\nProviders:")
+	for _, p := range providers {
+		returns := p.ReturnType
+		if len(p.Results) > 1 {
+			returns = "(" + strings.Join(p.Results, ", ") + ")"
+		}
+		if len(p.Envs) > 0 {
+			returns += fmt.Sprintf(" (env: %s)", strings.Join(p.Envs, ", "))
+		}
+		fmt.Printf("  - %s() -> %s\n", p.FunctionName, returns)
+	}
+}
+
+// ShowProviderOrder displays the providers in initialization order with the providers each pulls in
+func (s *service) ShowProviderOrder(result *scanner.ScanResult) error {
+	order, err := scanner.OrderProviders(result.Providers)
+	if err != nil {
+		return exitcode.New(exitcode.Validation, fmt.Errorf("error ordering providers: %w", err))
+	}
+	if len(order) == 0 {
+		return nil
+	}
+
+	fmt.Println("\nProvider Initialization Order:")
+	width := len(fmt.Sprint(len(order)))
+	for i, step := range order {
+		line := fmt.Sprintf("  %*d. %s.%s", width, i+1, step.Provider.Package, step.Provider.FunctionName)
+
+		var needs []string
+		for _, dep := range step.Dependencies {
+			needs = append(needs, dep.Package+"."+dep.FunctionName)
+		}
+		if len(needs) > 0 {
+			line += " ← " + strings.Join(needs, ", ")
+		}
+		if len(step.External) > 0 {
+			line += fmt.Sprintf(" (external: %s)", strings.Join(step.External, ", "))
 		}
+		if step.Transitive > len(step.Dependencies) {
+			line += fmt.Sprintf(" [pulls in %d providers]", step.Transitive)
+		}
+		if step.Provider.HasCleanup {
+			line += " [cleanup]"
+		}
+		fmt.Println(line)
 	}
 
 	return nil
@@ -102,22 +278,71 @@ func (s *service) ShowScanResults(result *scanner.ScanResult) error {
 
 // ValidateScanResults performs validation on scan results
 func (s *service) ValidateScanResults(result *scanner.ScanResult) error {
-	validator := scanner.NewValidator()
-	validation := validator.ValidateScanResult(result)
+	validation, err := s.Validate(result)
+	if err != nil {
+		return err
+	}
 
 	if validation.HasErrors() {
 		fmt.Println("\nValidation Errors:")
 		for _, err := range validation.Errors {
-			fmt.Printf("  • %s: %s\n", err.Type, err.Message)
+			fmt.Printf("  • %s\n", err)
 		}
 	}
 
 	if validation.HasWarnings() {
 		fmt.Println("\nValidation Warnings:")
 		for _, warn := range validation.Warnings {
-			fmt.Printf("  • %s: %s\n", warn.Type, warn.Message)
+			fmt.Printf("  • %s\n", warn)
 		}
 	}
 
+	return s.CheckValidation(validation)
+}
+
+// CheckValidation returns an error for validation findings at or above the validation.fail_on severity
+func (s *service) CheckValidation(validation *scanner.ValidationResult) error {
+	switch s.config.FailOn() {
+	case config.SeverityNone:
+		return nil
+	case config.SeverityWarning:
+		if validation.HasWarnings() {
+			return exitcode.New(exitcode.Validation, fmt.Errorf("%d validation error(s) and %d warning(s) found, warnings fail the scan", len(validation.Errors), len(validation.Warnings)))
+		}
+	}
+
+	if validation.HasErrors() {
+		return exitcode.New(exitcode.Validation, fmt.Errorf("%d validation error(s) found", len(validation.Errors)))
+	}
 	return nil
 }
+
+// Validate checks scan results without printing the findings
+func (s *service) Validate(result *scanner.ScanResult) (*scanner.ValidationResult, error) {
+	validator := scanner.NewValidator(s.config.Conventions)
+	validation := validator.ValidateScanResult(result)
+	validator.ValidateInterfaceBindings(result, validation)
+	validator.ValidateUnusedProviders(result, s.config.Root, s.config.Paths.OutputDir, validation)
+	validator.ValidatePartialGeneration(result, s.config.Generation, validation)
+
+	if s.config.Ownership.RequireOwners {
+		owners, err := scanner.LoadCodeOwners(s.config.Root, s.config.Ownership.CodeownersFile)
+		if err != nil {
+			return nil, exitcode.New(exitcode.Config, fmt.Errorf("error loading code owners: %w", err))
+		}
+		validator.ValidateRouteOwners(result.Routes, owners, validation)
+	}
+	scanner.RecordValidation(validation)
+	return validation, nil
+}
+
+// withoutValue returns the values other than value
+func withoutValue(values []string, value string) []string {
+	var others []string
+	for _, v := range values {
+		if v != value {
+			others = append(others, v)
+		}
+	}
+	return others
+}
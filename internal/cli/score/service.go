@@ -0,0 +1,288 @@
+package score
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
+	"github.com/nkaewam/taskw/internal/cli/routes"
+	"github.com/nkaewam/taskw/internal/cli/scan"
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/generator"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// Output formats of taskw score
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Points each metric contributes to the score, out of 100
+const (
+	coveragePoints   = 40
+	errorPoints      = 20
+	conflictPoints   = 15
+	stalePoints      = 15
+	orphanPoints     = 10
+	pointsPerFinding = 5 // Points lost per validation error, route conflict or stale change, down to 0
+	pointsPerOrphan  = 2
+)
+
+// Metric is one of the measures the score aggregates
+type Metric struct {
+	Name      string   `json:"name"`
+	Value     int      `json:"value"`               // Percentage for coverage, a count otherwise
+	Points    int      `json:"points"`              // Points earned
+	MaxPoints int      `json:"max_points"`          // Points earned by a perfect project
+	Threshold string   `json:"threshold,omitempty"` // Configured threshold, e.g. ">= 80%" or "<= 0"
+	Failed    bool     `json:"failed"`              // Whether the value breaks the threshold
+	Details   []string `json:"details,omitempty"`   // What the metric counted, e.g. the shadowed routes
+}
+
+// Report is the health score of a project
+type Report struct {
+	Score     int      `json:"score"` // Out of 100
+	Threshold string   `json:"threshold,omitempty"`
+	Failed    bool     `json:"failed"` // Whether the score or any metric breaks its threshold
+	Metrics   []Metric `json:"metrics"`
+}
+
+// Service rates how well a project follows taskw conventions
+type Service interface {
+	// Report scans the project and scores annotation coverage, validation errors, orphan providers,
+	// route conflicts and the staleness of generated files against the score thresholds of taskw.yaml
+	Report(ctx context.Context) (*Report, error)
+	// Write writes the report as text or JSON
+	Write(w io.Writer, report *Report, format string) error
+	// Check returns an error when the report breaks a threshold
+	Check(report *Report) error
+}
+
+// service implements Service interface
+type service struct {
+	config *config.Config
+	scan   scan.Service
+	routes routes.Service
+}
+
+// ProvideScoreService creates a new score service
+// @Provider
+func ProvideScoreService(config *config.Config, scanService scan.Service, routesService routes.Service) Service {
+	return &service{
+		config: config,
+		scan:   scanService,
+		routes: routesService,
+	}
+}
+
+// Report scans the project and scores annotation coverage, validation errors, orphan providers,
+// route conflicts and the staleness of generated files against the score thresholds of taskw.yaml
+func (s *service) Report(ctx context.Context) (*Report, error) {
+	result, err := s.scan.Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	validation, err := s.scan.Validate(result)
+	if err != nil {
+		return nil, err
+	}
+	table, err := s.routes.Table(ctx)
+	if err != nil {
+		return nil, err
+	}
+	thresholds := s.config.Score
+
+	coverage, uncovered := annotationCoverage(result)
+	coverageMetric := Metric{
+		Name:      "annotation coverage",
+		Value:     coverage,
+		Points:    coveragePoints * coverage / 100,
+		MaxPoints: coveragePoints,
+		Details:   uncovered,
+	}
+	if thresholds.MinCoverage > 0 {
+		coverageMetric.Threshold = fmt.Sprintf(">= %d%%", thresholds.MinCoverage)
+		coverageMetric.Failed = coverage < thresholds.MinCoverage
+	}
+
+	var errors, orphans []string
+	for _, validationErr := range validation.Errors {
+		errors = append(errors, validationErr.String())
+	}
+	for _, warning := range validation.Warnings {
+		if warning.Type == "unused_provider" {
+			orphans = append(orphans, warning.String())
+		}
+	}
+	var conflicts []string
+	for _, route := range table {
+		if route.ShadowedBy != "" {
+			conflicts = append(conflicts, fmt.Sprintf("%s %s (%s) is shadowed by %s", route.Method, route.Path, route.Handler, route.ShadowedBy))
+		}
+	}
+
+	stale, err := staleChanges(result)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Metrics: []Metric{
+		coverageMetric,
+		countMetric("validation errors", errors, errorPoints, pointsPerFinding, thresholds.MaxErrors),
+		countMetric("orphan providers", orphans, orphanPoints, pointsPerOrphan, thresholds.MaxOrphanProviders),
+		countMetric("route conflicts", conflicts, conflictPoints, pointsPerFinding, thresholds.MaxRouteConflicts),
+		countMetric("stale generated files", stale, stalePoints, pointsPerFinding, thresholds.MaxStale),
+	}}
+	for _, metric := range report.Metrics {
+		report.Score += metric.Points
+		report.Failed = report.Failed || metric.Failed
+	}
+	if thresholds.MinScore > 0 {
+		report.Threshold = fmt.Sprintf(">= %d", thresholds.MinScore)
+		report.Failed = report.Failed || report.Score < thresholds.MinScore
+	}
+	return report, nil
+}
+
+// countMetric scores findings that lose points each, checked against a maximum when it isn't negative
+func countMetric(name string, findings []string, maxPoints, pointsPerFinding, maximum int) Metric {
+	metric := Metric{
+		Name:      name,
+		Value:     len(findings),
+		Points:    max(maxPoints-pointsPerFinding*len(findings), 0),
+		MaxPoints: maxPoints,
+		Details:   findings,
+	}
+	if maximum >= 0 {
+		metric.Threshold = fmt.Sprintf("<= %d", maximum)
+		metric.Failed = len(findings) > maximum
+	}
+	return metric
+}
+
+// annotationCoverage returns the percentage of handler methods with a @Router and a @Summary
+// annotation, and the handlers missing either. Projects without handlers are fully covered
+func annotationCoverage(result *scanner.ScanResult) (int, []string) {
+	summaries := make(map[string]bool)
+	for _, route := range result.Routes {
+		summaries[route.FilePath+":"+route.HandlerName+"."+route.MethodName] = route.Summary != ""
+	}
+
+	total := 0
+	var uncovered []string
+	for _, handler := range result.Handlers {
+		// Interface-based handlers repeat the methods of their implementation
+		if handler.IsInterfaceBased {
+			continue
+		}
+		total++
+		summary, routed := summaries[handler.FilePath+":"+handler.HandlerName+"."+handler.FunctionName]
+		switch {
+		case !routed:
+			uncovered = append(uncovered, fmt.Sprintf("%s:%d: %s has no @Router annotation", handler.FilePath, handler.Line, handlerName(handler)))
+		case !summary:
+			uncovered = append(uncovered, fmt.Sprintf("%s:%d: %s has no @Summary annotation", handler.FilePath, handler.Line, handlerName(handler)))
+		}
+	}
+	if total == 0 {
+		return 100, nil
+	}
+	return int(math.Floor(float64(total-len(uncovered)) * 100 / float64(total))), uncovered
+}
+
+// handlerName names a handler by its package and receiver, e.g. "user.Handler.GetUser"
+func handlerName(handler scanner.HandlerFunction) string {
+	if handler.IsFunction {
+		return handler.Package + "." + handler.FunctionName
+	}
+	return handler.Package + "." + handler.HandlerName + "." + handler.FunctionName
+}
+
+// staleChanges lists the changes since the last taskw generate recorded in the manifest.
+// Projects generated before the manifest existed count as stale until they are regenerated
+func staleChanges(result *scanner.ScanResult) ([]string, error) {
+	manifest, err := generator.LoadManifest()
+	if err != nil {
+		return nil, exitcode.New(exitcode.Generation, fmt.Errorf("error reading %s: %w", generator.ManifestFile, err))
+	}
+	if manifest == nil {
+		return []string{fmt.Sprintf("no %s, run taskw generate to record the generated files", generator.ManifestFile)}, nil
+	}
+	return manifest.Drift(result), nil
+}
+
+// Write writes the report as text or JSON
+func (s *service) Write(w io.Writer, report *Report, format string) error {
+	switch format {
+	case FormatText:
+		return writeText(w, report)
+	case FormatJSON:
+		// Thresholds hold comparison operators, which aren't escaped for readability
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("error encoding score report: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q, use %s or %s", format, FormatText, FormatJSON)
+	}
+}
+
+// writeText writes the score, then each metric with what it counted
+func writeText(w io.Writer, report *Report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Project health score: %d/100", report.Score)
+	if report.Threshold != "" {
+		fmt.Fprintf(&b, " (threshold %s)", report.Threshold)
+	}
+	b.WriteString("\n\n")
+
+	for _, metric := range report.Metrics {
+		mark := "✅"
+		switch {
+		case metric.Failed:
+			mark = "❌"
+		case metric.Points < metric.MaxPoints:
+			mark = "⚠️ "
+		}
+		value := fmt.Sprintf("%d", metric.Value)
+		if metric.Name == "annotation coverage" {
+			value += "%"
+		}
+		fmt.Fprintf(&b, "%s %s: %s (%d/%d points)", mark, metric.Name, value, metric.Points, metric.MaxPoints)
+		if metric.Threshold != "" {
+			fmt.Fprintf(&b, ", threshold %s", metric.Threshold)
+		}
+		b.WriteString("\n")
+		for _, detail := range metric.Details {
+			fmt.Fprintf(&b, "  • %s\n", detail)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Check returns an error when the report breaks a threshold
+func (s *service) Check(report *Report) error {
+	if !report.Failed {
+		return nil
+	}
+
+	var failed []string
+	if report.Threshold != "" && report.Score < s.config.Score.MinScore {
+		failed = append(failed, fmt.Sprintf("score %d (threshold %s)", report.Score, report.Threshold))
+	}
+	for _, metric := range report.Metrics {
+		if metric.Failed {
+			failed = append(failed, fmt.Sprintf("%s %d (threshold %s)", metric.Name, metric.Value, metric.Threshold))
+		}
+	}
+	return exitcode.New(exitcode.Validation, fmt.Errorf("score thresholds not met: %s", strings.Join(failed, ", ")))
+}
@@ -0,0 +1,84 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
+	"github.com/nkaewam/taskw/internal/cli/ui"
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/generator"
+)
+
+// Service validates the code generation templates
+type Service interface {
+	// Check renders every embedded or overridden template with synthetic data and reports broken output
+	Check(ctx context.Context) error
+}
+
+// service implements Service interface
+type service struct {
+	config *config.Config
+	ui     ui.Service
+}
+
+// ProvideTemplatesService creates a new templates service
+// @Provider
+func ProvideTemplatesService(config *config.Config, uiService ui.Service) Service {
+	return &service{
+		config: config,
+		ui:     uiService,
+	}
+}
+
+// Check renders every embedded or overridden template with synthetic data and reports broken output
+func (s *service) Check(ctx context.Context) error {
+	unknown, err := generator.UnknownTemplateOverrides(s.config)
+	if err != nil {
+		return exitcode.New(exitcode.Config, err)
+	}
+
+	stopSpinner := s.ui.ShowSpinner("Checking templates...")
+	result, err := generator.CheckTemplates(ctx, s.config)
+	if err != nil {
+		stopSpinner("Error checking templates")
+		return exitcode.New(exitcode.Generation, err)
+	}
+
+	if len(result.Problems) > 0 {
+		stopSpinner(fmt.Sprintf("Found %d template problem(s)", len(result.Problems)))
+	} else {
+		stopSpinner("All templates render valid output")
+	}
+
+	fmt.Println("\nTemplates:")
+	for _, name := range result.Templates {
+		if override := generator.TemplateOverride(s.config, name); override != "" {
+			fmt.Printf("  - %s (overridden by %s)\n", name, override)
+		} else {
+			fmt.Printf("  - %s\n", name)
+		}
+	}
+
+	fmt.Println("\nRendered With:")
+	for _, variant := range result.Variants {
+		fmt.Printf("  - %s\n", variant)
+	}
+
+	if len(unknown) > 0 {
+		fmt.Println("\nIgnored Overrides (no template with this name):")
+		for _, file := range unknown {
+			fmt.Printf("  - %s\n", file)
+		}
+	}
+
+	if len(result.Problems) > 0 {
+		fmt.Println("\nProblems:")
+		for _, problem := range result.Problems {
+			fmt.Printf("  • %s\n", problem)
+		}
+		return exitcode.New(exitcode.Generation, fmt.Errorf("%d template problem(s) found", len(result.Problems)))
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Options configures how every command reports progress, set from the global flags
+type Options struct {
+	Verbose bool // Print debug messages, e.g. how long each step took
+	Quiet   bool // Only print warnings and errors
+	NoColor bool // Never color the output, even on a terminal
+	JSON    bool // Print messages as JSON lines on stderr for machine consumption
+}
+
+// ANSI escape codes of the colors messages are printed in
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorDim    = "\033[2m"
+	clearLine   = "\033[K"
+)
+
+var (
+	mu      sync.Mutex
+	options Options
+	stdout  io.Writer = os.Stdout
+	stderr  io.Writer = os.Stderr
+	logger  *slog.Logger
+
+	spinners int    // Spinners animating on stdout
+	status   string // Shown after the message of the running spinner, e.g. a progress bar
+)
+
+// Configure sets how messages are printed. Without it, messages are printed as text, in color
+// on a terminal, and debug messages are left out
+func Configure(opts Options) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	options = opts
+	logger = nil
+	if opts.JSON {
+		level := slog.LevelInfo
+		if opts.Verbose {
+			level = slog.LevelDebug
+		}
+		logger = slog.New(slog.NewJSONHandler(stderr, &slog.HandlerOptions{Level: level}))
+	}
+}
+
+// Interactive reports whether stdout is a terminal a person is watching, so spinners can animate.
+// Quiet and JSON output never are
+func Interactive() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return !options.Quiet && !options.JSON && isTerminal(stdout)
+}
+
+// Debugf prints a message only shown with --verbose
+func Debugf(format string, args ...interface{}) {
+	logf(slog.LevelDebug, stdout, "  ", colorDim, format, args...)
+}
+
+// Infof prints a progress message, left out with --quiet
+func Infof(format string, args ...interface{}) {
+	logf(slog.LevelInfo, stdout, "", "", format, args...)
+}
+
+// Detailf prints a detail of the last step as an indented bullet, left out with --quiet
+func Detailf(format string, args ...interface{}) {
+	logf(slog.LevelInfo, stdout, "  • ", "", format, args...)
+}
+
+// Successf prints a completed step with a check mark, left out with --quiet
+func Successf(format string, args ...interface{}) {
+	logf(slog.LevelInfo, stdout, "✔ ", colorGreen, format, args...)
+}
+
+// Warnf prints a warning on stderr
+func Warnf(format string, args ...interface{}) {
+	logf(slog.LevelWarn, stderr, "⚠️  ", colorYellow, format, args...)
+}
+
+// Errorf prints an error on stderr
+func Errorf(format string, args ...interface{}) {
+	logf(slog.LevelError, stderr, "", colorRed, format, args...)
+}
+
+// logf writes a message at a level, as a JSON line with --json-logs, or as text with its prefix,
+// colored when w is a terminal and color isn't disabled
+func logf(level slog.Level, w io.Writer, prefix, color, format string, args ...interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	message := fmt.Sprintf(format, args...)
+	if logger != nil {
+		logger.Log(context.Background(), level, strings.TrimSpace(message))
+		return
+	}
+	if (level == slog.LevelDebug && !options.Verbose) || (level < slog.LevelWarn && options.Quiet) {
+		return
+	}
+
+	text := prefix + message
+	if color != "" && colored(w) {
+		text = color + text + colorReset
+	}
+	fmt.Fprintln(w, text)
+}
+
+// colored reports whether to color the output written to w: it must be a terminal, and neither
+// --no-color, NO_COLOR nor TERM=dumb disable color
+func colored(w io.Writer) bool {
+	if options.NoColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+// isTerminal reports whether w is a character device, pipes, files and CI logs aren't
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// checkMark is the mark of a completed spinner, green when stdout is colored
+func checkMark() string {
+	mu.Lock()
+	defer mu.Unlock()
+	if colored(stdout) {
+		return colorGreen + "✔" + colorReset
+	}
+	return "✔"
+}
+
+// trimMessage drops the decoration of a message meant for a terminal, e.g. a trailing "..."
+func trimMessage(message string) string {
+	return strings.TrimSuffix(message, "...")
+}
@@ -0,0 +1,131 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Settings of the scan progress bar
+const (
+	progressWidth    = 24                     // Cells of the bar
+	progressDelay    = 300 * time.Millisecond // Scans finishing sooner never show the bar
+	progressInterval = 100 * time.Millisecond // Minimum time between redraws
+	slowestDirs      = 10                     // Directories listed by the timing stats under --verbose
+)
+
+// ScanProgress draws a bar of the files scanned so far and, under --verbose, reports the time spent
+// in each directory once the scan finishes. It implements scanner.ScanObserver
+type ScanProgress struct {
+	mu       sync.Mutex
+	total    int
+	done     int
+	started  time.Time
+	drawn    time.Time // Last redraw, zero until the bar is shown
+	ownsLine bool      // Whether the bar was drawn on a line of its own, outside a spinner
+	dirs     map[string]*dirTiming
+}
+
+// dirTiming is the time spent parsing the files of a directory
+type dirTiming struct {
+	dir     string
+	files   int
+	elapsed time.Duration
+}
+
+// NewScanProgress creates a scan progress reporter
+func NewScanProgress() *ScanProgress {
+	return &ScanProgress{}
+}
+
+// ScanStarted resets the progress for a scan of files
+func (p *ScanProgress) ScanStarted(files int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.total = files
+	p.done = 0
+	p.started = time.Now()
+	p.drawn = time.Time{}
+	p.ownsLine = false
+	p.dirs = make(map[string]*dirTiming)
+}
+
+// FileScanned counts a scanned file and redraws the bar, at most every progressInterval
+func (p *ScanProgress) FileScanned(filePath string, elapsed time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done++
+	dir := filepath.Dir(filePath)
+	timing, ok := p.dirs[dir]
+	if !ok {
+		timing = &dirTiming{dir: dir}
+		p.dirs[dir] = timing
+	}
+	timing.files++
+	timing.elapsed += elapsed
+
+	now := time.Now()
+	if now.Sub(p.started) < progressDelay || now.Sub(p.drawn) < progressInterval || !Interactive() {
+		return
+	}
+	p.drawn = now
+	p.draw()
+}
+
+// ScanFinished clears the bar and reports the slowest directories under --verbose
+func (p *ScanProgress) ScanFinished() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.drawn.IsZero() {
+		mu.Lock()
+		status = ""
+		if p.ownsLine {
+			fmt.Fprint(stdout, "\r"+clearLine)
+		}
+		mu.Unlock()
+	}
+
+	timings := make([]*dirTiming, 0, len(p.dirs))
+	for _, timing := range p.dirs {
+		timings = append(timings, timing)
+	}
+	sort.Slice(timings, func(i, j int) bool {
+		if timings[i].elapsed != timings[j].elapsed {
+			return timings[i].elapsed > timings[j].elapsed
+		}
+		return timings[i].dir < timings[j].dir
+	})
+
+	Debugf("Scanned %d files in %d directories in %s", p.done, len(timings), time.Since(p.started).Round(time.Millisecond))
+	for i, timing := range timings {
+		if i == slowestDirs {
+			Debugf("  ... and %d more directories", len(timings)-slowestDirs)
+			break
+		}
+		Debugf("  %s: %d files, %s", timing.dir, timing.files, timing.elapsed.Round(time.Microsecond))
+	}
+}
+
+// draw shows the bar after the message of the running spinner, or on a line of its own
+func (p *ScanProgress) draw() {
+	filled := 0
+	if p.total > 0 {
+		filled = progressWidth * p.done / p.total
+	}
+	bar := fmt.Sprintf("[%s%s] %d/%d files", strings.Repeat("█", filled), strings.Repeat("░", progressWidth-filled), p.done, p.total)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if spinners > 0 {
+		status = " " + bar
+		return
+	}
+	p.ownsLine = true
+	fmt.Fprintf(stdout, "\r%s%s", bar, clearLine)
+}
@@ -27,12 +27,22 @@ func ProvideUIService() Service {
 	return &service{}
 }
 
-// ShowSpinner displays a spinner with a message and returns a stop function
+// ShowSpinner displays a spinner with a message and returns a stop function. Outside a terminal,
+// and with --quiet or --json-logs, nothing animates: only the completed message is printed
 func (s *service) ShowSpinner(message string) func(completedMessage string) {
+	started := time.Now()
+	if !Interactive() {
+		return func(completedMessage string) {
+			Successf("%s", completedMessage)
+			Debugf("%s took %s", trimMessage(message), time.Since(started).Round(time.Millisecond))
+		}
+	}
+
 	spinner := NewSpinner()
 	spinner.Start(message)
 	return func(completedMessage string) {
 		spinner.Stop(completedMessage)
+		Debugf("%s took %s", trimMessage(message), time.Since(started).Round(time.Millisecond))
 	}
 }
 
@@ -86,6 +96,10 @@ func NewSpinner() *Spinner {
 }
 
 func (s *Spinner) Start(message string) {
+	mu.Lock()
+	spinners++
+	mu.Unlock()
+
 	go func() {
 		i := 0
 		for {
@@ -94,7 +108,10 @@ func (s *Spinner) Start(message string) {
 				return
 			default:
 				s.mu.Lock()
-				fmt.Printf("\r%s %s", s.chars[i%len(s.chars)], message)
+				mu.Lock()
+				suffix := status
+				mu.Unlock()
+				fmt.Printf("\r%s %s%s%s", s.chars[i%len(s.chars)], message, suffix, clearLine)
 				s.mu.Unlock()
 				i++
 				time.Sleep(s.delay)
@@ -112,6 +129,10 @@ func (s *Spinner) Stop(message string) {
 		return
 	}
 	s.stopped = true
+	mu.Lock()
+	spinners--
+	status = ""
+	mu.Unlock()
 
 	// Send stop signal (non-blocking with buffered channel)
 	select {
@@ -120,7 +141,7 @@ func (s *Spinner) Stop(message string) {
 		// Channel already has a value, that's fine
 	}
 
-	fmt.Printf("\r✔ %s\n", message)
+	fmt.Printf("\r%s %s%s\n", checkMark(), message, clearLine)
 }
 
 // ValidateModule validates that the module path is a proper Go module format
@@ -5,11 +5,20 @@ package cli
 
 import (
 	"github.com/google/wire"
+	"github.com/nkaewam/taskw/internal/cli/audit"
 	"github.com/nkaewam/taskw/internal/cli/clean"
+	"github.com/nkaewam/taskw/internal/cli/dev"
+	"github.com/nkaewam/taskw/internal/cli/doctor"
 	"github.com/nkaewam/taskw/internal/cli/file"
 	"github.com/nkaewam/taskw/internal/cli/generation"
+	"github.com/nkaewam/taskw/internal/cli/graph"
+	"github.com/nkaewam/taskw/internal/cli/migrate"
+	"github.com/nkaewam/taskw/internal/cli/notes"
 	"github.com/nkaewam/taskw/internal/cli/project"
+	"github.com/nkaewam/taskw/internal/cli/routes"
 	"github.com/nkaewam/taskw/internal/cli/scan"
+	"github.com/nkaewam/taskw/internal/cli/score"
+	"github.com/nkaewam/taskw/internal/cli/templates"
 	"github.com/nkaewam/taskw/internal/cli/ui"
 	"github.com/nkaewam/taskw/internal/config"
 )
@@ -22,6 +31,15 @@ type Container struct {
 	Generation generation.Service
 	Clean      clean.Service
 	File       file.Service
+	Audit      audit.Service
+	Migrate    migrate.Service
+	Dev        dev.Service
+	Notes      notes.Service
+	Templates  templates.Service
+	Doctor     doctor.Service
+	Routes     routes.Service
+	Graph      graph.Service
+	Score      score.Service
 	Config     *config.Config
 }
 
@@ -8,11 +8,20 @@ package cli
 
 import (
 	"github.com/google/wire"
+	"github.com/nkaewam/taskw/internal/cli/audit"
 	"github.com/nkaewam/taskw/internal/cli/clean"
+	"github.com/nkaewam/taskw/internal/cli/dev"
+	"github.com/nkaewam/taskw/internal/cli/doctor"
 	"github.com/nkaewam/taskw/internal/cli/file"
 	"github.com/nkaewam/taskw/internal/cli/generation"
+	"github.com/nkaewam/taskw/internal/cli/graph"
+	"github.com/nkaewam/taskw/internal/cli/migrate"
+	"github.com/nkaewam/taskw/internal/cli/notes"
 	"github.com/nkaewam/taskw/internal/cli/project"
+	"github.com/nkaewam/taskw/internal/cli/routes"
 	"github.com/nkaewam/taskw/internal/cli/scan"
+	"github.com/nkaewam/taskw/internal/cli/score"
+	"github.com/nkaewam/taskw/internal/cli/templates"
 	"github.com/nkaewam/taskw/internal/cli/ui"
 	"github.com/nkaewam/taskw/internal/config"
 )
@@ -31,6 +40,15 @@ func InitializeContainer(configPath string) (*Container, error) {
 	fileService := file.ProvideFileService()
 	generationService := generation.ProvideGenerationService(configConfig, service, fileService)
 	cleanService := clean.ProvideCleanService(configConfig, service, fileService)
+	auditService := audit.ProvideAuditService(configConfig, service)
+	migrateService := migrate.ProvideMigrateService(configConfig, service, generationService)
+	devService := dev.ProvideDevService(configConfig, service, generationService)
+	notesService := notes.ProvideNotesService(configConfig)
+	templatesService := templates.ProvideTemplatesService(configConfig, service)
+	doctorService := doctor.ProvideDoctorService()
+	routesService := routes.ProvideRoutesService(generationService)
+	graphService := graph.ProvideGraphService(configConfig, scanService)
+	scoreService := score.ProvideScoreService(configConfig, scanService, routesService)
 	container := &Container{
 		UI:         service,
 		Project:    projectService,
@@ -38,6 +56,15 @@ func InitializeContainer(configPath string) (*Container, error) {
 		Generation: generationService,
 		Clean:      cleanService,
 		File:       fileService,
+		Audit:      auditService,
+		Migrate:    migrateService,
+		Dev:        devService,
+		Notes:      notesService,
+		Templates:  templatesService,
+		Doctor:     doctorService,
+		Routes:     routesService,
+		Graph:      graphService,
+		Score:      scoreService,
 		Config:     configConfig,
 	}
 	return container, nil
@@ -53,10 +80,19 @@ type Container struct {
 	Generation generation.Service
 	Clean      clean.Service
 	File       file.Service
+	Audit      audit.Service
+	Migrate    migrate.Service
+	Dev        dev.Service
+	Notes      notes.Service
+	Templates  templates.Service
+	Doctor     doctor.Service
+	Routes     routes.Service
+	Graph      graph.Service
+	Score      score.Service
 	Config     *config.Config
 }
 
-// ServiceSet is the Wire provider set for all CLI services
+// ProviderSet is the Wire provider set for all CLI services
 var ProviderSet = wire.NewSet(
 	GeneratedProviderSet,
 )
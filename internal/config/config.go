@@ -2,18 +2,32 @@ package config
 
 import (
 	"fmt"
+	"go/token"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Version    string     `mapstructure:"version"`
-	Project    Project    `mapstructure:"project"`
-	Paths      Paths      `mapstructure:"paths"`
-	Generation Generation `mapstructure:"generation"`
+	Version     string      `mapstructure:"version"`
+	Project     Project     `mapstructure:"project"`
+	Paths       Paths       `mapstructure:"paths"`
+	Generation  Generation  `mapstructure:"generation"`
+	Scanning    Scanning    `mapstructure:"scanning"`
+	Ownership   Ownership   `mapstructure:"ownership"`
+	OpenAPI     OpenAPI     `mapstructure:"openapi"`
+	Conventions Conventions `mapstructure:"conventions"`
+	Validation  Validation  `mapstructure:"validation"`
+	Dev         Dev         `mapstructure:"dev"`
+	Tools       Tools       `mapstructure:"tools"`
+	Score       Score       `mapstructure:"score"`
+
+	Root      string `mapstructure:"-"` // Project root holding the outermost taskw.yaml, configured paths are relative to it
+	WorkDir   string `mapstructure:"-"` // Directory taskw was run from
+	ModuleDir string `mapstructure:"-"` // Directory holding go.mod, import paths are Project.Module plus the path from here
 }
 
 type Project struct {
@@ -21,59 +35,523 @@ type Project struct {
 }
 
 type Paths struct {
-	ScanDirs  []string `mapstructure:"scan_dirs"`
-	OutputDir string   `mapstructure:"output_dir"`
+	ScanDirs      []string `mapstructure:"scan_dirs"`
+	OutputDir     string   `mapstructure:"output_dir"`
+	RoutesOverlay string   `mapstructure:"routes_overlay"` // Optional YAML file overriding route middleware and tags
+	TemplatesDir  string   `mapstructure:"templates_dir"`  // Optional directory of templates overriding the embedded ones by file name
+}
+
+type Scanning struct {
+	Mode      string   `mapstructure:"mode"`       // "ast" (default) parses files one by one, "packages" type-checks them with go/packages
+	BuildTags []string `mapstructure:"build_tags"` // Tags files are built with, e.g. "integration", files excluded by //go:build are skipped
+	GOOS      string   `mapstructure:"goos"`       // Target platform, defaults to $GOOS or the host platform
+	GOARCH    string   `mapstructure:"goarch"`     // Target architecture, defaults to $GOARCH or the host architecture
+	Cache     bool     `mapstructure:"cache"`      // Reuse the results of unchanged files from .taskw/cache.json
+
+	RespectGitignore bool     `mapstructure:"respect_gitignore"` // Skip files excluded by the project's .gitignore files
+	FollowSymlinks   bool     `mapstructure:"follow_symlinks"`   // Scan the targets of symlinks, skipped by default
+	Ignore           []string `mapstructure:"ignore"`            // More .taskwignore patterns, applied after the file's
+	Include          []string `mapstructure:"include"`           // Patterns re-including ignored files, applied last
+
+	// How annotations taskw can't use are reported, e.g. a @Router with a misspelled method: warn lists
+	// them and continues, error fails the scan
+	MalformedAnnotations string `mapstructure:"malformed_annotations"`
+
+	// Files changed since a git revision, set by --since and --changed: the files of other packages reuse
+	// their cached results without being read, when they were scanned from the content the file has in
+	// Revision. nil reads every file
+	Changed []string `mapstructure:"-"`
+	// Git object IDs of the files at that revision by slash-separated path relative to the project root
+	Revision map[string]string `mapstructure:"-"`
+}
+
+// Values of scanning.malformed_annotations
+const (
+	AnnotationsWarn  = "warn"
+	AnnotationsError = "error"
+)
+
+// Supported scanning modes
+const (
+	ScanModeAST      = "ast"
+	ScanModePackages = "packages" // Resolves qualified types, aliases, generics and interface satisfaction
+)
+
+// ScanMode returns the configured scanning mode, defaulting to AST scanning
+func (c *Config) ScanMode() string {
+	if c == nil || c.Scanning.Mode == "" {
+		return ScanModeAST
+	}
+	return strings.ToLower(c.Scanning.Mode)
+}
+
+type Ownership struct {
+	CodeownersFile string `mapstructure:"codeowners_file"` // Defaults to .github/CODEOWNERS, CODEOWNERS or docs/CODEOWNERS
+	RequireOwners  bool   `mapstructure:"require_owners"`  // Report routes in unowned files as validation errors
+}
+
+// Validation configures which validation findings fail taskw scan
+type Validation struct {
+	FailOn string `mapstructure:"fail_on"` // Lowest severity setting a non-zero exit code: error, warning or none
+}
+
+// Severities of validation findings, for validation.fail_on
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityNone    = "none" // Findings are reported without failing
+)
+
+// FailOn returns the lowest severity of validation findings failing taskw scan, defaulting to errors
+func (c *Config) FailOn() string {
+	if c == nil || c.Validation.FailOn == "" {
+		return SeverityError
+	}
+	return strings.ToLower(c.Validation.FailOn)
+}
+
+// Score holds the thresholds taskw score fails on, for CI gating. Zero minimums and negative
+// maximums aren't checked
+type Score struct {
+	MinScore           int `mapstructure:"min_score"`            // Lowest overall score, out of 100
+	MinCoverage        int `mapstructure:"min_coverage"`         // Lowest percentage of handlers with @Router and @Summary annotations
+	MaxErrors          int `mapstructure:"max_errors"`           // Most validation errors
+	MaxOrphanProviders int `mapstructure:"max_orphan_providers"` // Most providers whose return type nothing consumes
+	MaxRouteConflicts  int `mapstructure:"max_route_conflicts"`  // Most routes shadowed by a route registered before them
+	MaxStale           int `mapstructure:"max_stale"`            // Most changes the generated files don't reflect yet
+}
+
+// Dev configures the build-and-restart loop of taskw dev
+type Dev struct {
+	MainPackage string   `mapstructure:"main_package"` // Package built into the server binary
+	Binary      string   `mapstructure:"binary"`       // Path the server binary is built to
+	Args        []string `mapstructure:"args"`         // Arguments passed to the server
+	Debounce    string   `mapstructure:"debounce"`     // Quiet period after the last change before rebuilding, e.g. "300ms"
+	ExcludeDirs []string `mapstructure:"exclude_dirs"` // Directories not watched, hidden directories are always skipped
+}
+
+// Tools configures how taskw runs external tools: swag, wire, and go install when either is missing
+type Tools struct {
+	Timeout string `mapstructure:"timeout"` // How long a run may take before it is stopped, e.g. "5m", "0" for no limit
+}
+
+// ToolTimeout returns how long an external tool may run, zero for no limit
+func (c *Config) ToolTimeout() time.Duration {
+	if c == nil {
+		return 0
+	}
+	timeout, err := time.ParseDuration(c.Tools.Timeout)
+	if err != nil {
+		return 0
+	}
+	return timeout
+}
+
+// Conventions defines the naming conventions used to recognize providers and handlers
+type Conventions struct {
+	ProviderPrefixes []string `mapstructure:"provider_prefixes"` // Provider function name prefixes, e.g. ["Provide", "New"]
+	HandlerSuffixes  []string `mapstructure:"handler_suffixes"`  // Handler receiver type suffixes, e.g. ["Handler", "Controller"]
+}
+
+// Default naming conventions
+var (
+	DefaultProviderPrefixes = []string{"Provide"}
+	DefaultHandlerSuffixes  = []string{"Handler"}
+)
+
+// ProviderPrefix returns the longest configured provider prefix a function name starts with
+func (c Conventions) ProviderPrefix(name string) (string, bool) {
+	prefixes := c.ProviderPrefixes
+	if len(prefixes) == 0 {
+		prefixes = DefaultProviderPrefixes
+	}
+
+	match, found := "", false
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) && (!found || len(prefix) > len(match)) {
+			match, found = prefix, true
+		}
+	}
+	return match, found
+}
+
+// HandlerSuffix returns the longest configured handler suffix a receiver type name ends with
+func (c Conventions) HandlerSuffix(name string) (string, bool) {
+	suffixes := c.HandlerSuffixes
+	if len(suffixes) == 0 {
+		suffixes = DefaultHandlerSuffixes
+	}
+
+	match, found := "", false
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(name, suffix) && (!found || len(suffix) > len(match)) {
+			match, found = suffix, true
+		}
+	}
+	return match, found
+}
+
+// OpenAPI holds the general API information injected into the generated Swagger spec,
+// replacing the swag general annotations (@title, @contact.name, @host, ...) in main.go
+type OpenAPI struct {
+	Title          string          `mapstructure:"title"`
+	Version        string          `mapstructure:"version"`
+	Description    string          `mapstructure:"description"`
+	TermsOfService string          `mapstructure:"terms_of_service"`
+	Contact        OpenAPIContact  `mapstructure:"contact"`
+	License        OpenAPILicense  `mapstructure:"license"`
+	Servers        []OpenAPIServer `mapstructure:"servers"`      // The first server also sets host, basePath and schemes
+	SpecVersion    string          `mapstructure:"spec_version"` // "2.0" (default, as generated by swag), "3.0" or "3.1"
+}
+
+// Supported versions of the generated spec, for openapi.spec_version
+const (
+	SpecVersionSwagger2  = "2.0"
+	SpecVersionOpenAPI30 = "3.0"
+	SpecVersionOpenAPI31 = "3.1" // Component schemas follow the JSON Schema 2020-12 dialect
+)
+
+// Version returns the configured spec version, defaulting to the Swagger 2.0 spec generated by swag
+// Unquoted versions such as spec_version: 3.0 are read as numbers and lose their trailing zero
+func (c *Config) SpecVersion() string {
+	if c == nil {
+		return SpecVersionSwagger2
+	}
+	switch c.OpenAPI.SpecVersion {
+	case "", "2":
+		return SpecVersionSwagger2
+	case "3":
+		return SpecVersionOpenAPI30
+	}
+	return c.OpenAPI.SpecVersion
+}
+
+type OpenAPIContact struct {
+	Name  string `mapstructure:"name"`
+	URL   string `mapstructure:"url"`
+	Email string `mapstructure:"email"`
+}
+
+type OpenAPILicense struct {
+	Name string `mapstructure:"name"`
+	URL  string `mapstructure:"url"`
+}
+
+type OpenAPIServer struct {
+	URL         string                           `mapstructure:"url"` // May hold {variables}, e.g. "https://{region}.example.com/v1"
+	Description string                           `mapstructure:"description"`
+	Variables   map[string]OpenAPIServerVariable `mapstructure:"variables"`
+}
+
+type OpenAPIServerVariable struct {
+	Default     string   `mapstructure:"default"`
+	Enum        []string `mapstructure:"enum"`
+	Description string   `mapstructure:"description"`
+}
+
+// IsSet reports whether any API information is configured
+// Specs of projects without an openapi section are left as generated by swag
+func (o OpenAPI) IsSet() bool {
+	return o.Title != "" || o.Version != "" || o.Description != "" || o.TermsOfService != "" ||
+		o.Contact != (OpenAPIContact{}) || o.License != (OpenAPILicense{}) || len(o.Servers) > 0
 }
 
 type Generation struct {
-	Routes       RouteConfig `mapstructure:"routes"`
-	Dependencies DepConfig   `mapstructure:"dependencies"`
+	Routes       RouteConfig      `mapstructure:"routes"`
+	Server       ServerConfig     `mapstructure:"server"`
+	Dependencies DepConfig        `mapstructure:"dependencies"`
+	PackageDocs  PackageDocConfig `mapstructure:"package_docs"`
+	Recording    RecordingConfig  `mapstructure:"recording"`
+	SLO          SLOConfig        `mapstructure:"slo"`
+	Chaos        ChaosConfig      `mapstructure:"chaos"`
+	Envelope     EnvelopeConfig   `mapstructure:"envelope"`
+	Redirects    RedirectConfig   `mapstructure:"redirects"`
+	PII          PIIConfig        `mapstructure:"pii"`
+	Params       ParamsConfig     `mapstructure:"params"`
+	Ports        PortsConfig      `mapstructure:"ports"`
+	SwaggerUI    SwaggerUIConfig  `mapstructure:"swagger_ui"`
+	Gateway      GatewayConfig    `mapstructure:"gateway"`
+
+	// Steps of taskw generate all to run, set by --only. nil runs every enabled step
+	Only []string `mapstructure:"-"`
+	// Packages the per-package files such as doc.go are written into, set by --package, by name, import
+	// name or directory. nil writes them into every package
+	Packages []string `mapstructure:"-"`
 }
 
 type RouteConfig struct {
+	Enabled      bool                   `mapstructure:"enabled"`
+	OutputFile   string                 `mapstructure:"output_file"`
+	Framework    string                 `mapstructure:"framework"`     // "fiber" (default), "gin", "chi" or "nethttp"
+	FiberVersion int                    `mapstructure:"fiber_version"` // Fiber major version: 2 (default) or 3
+	Hooks        bool                   `mapstructure:"hooks"`         // Generate OnRouteRegistered callbacks (Fiber only)
+	Methods      map[string]RouteMethod `mapstructure:"methods"`       // Custom registrations by HTTP method, e.g. PURGE
+
+	RouteIDHeader string `mapstructure:"route_id_header"` // Response header set to the ID of the route, e.g. "X-Route-Id", empty for none
+
+	Style        string `mapstructure:"style"`         // Shape of the registration function: "router" (default), "function" or "server"
+	RegisterFunc string `mapstructure:"register_func"` // Name of the registration function, empty for the style's default
+	Receiver     string `mapstructure:"receiver"`      // Hand-written struct the server style declares the registration method on
+
+	// Packages whose routes are registered, by name, import name or directory, empty for every package
+	IncludePackages []string `mapstructure:"include_packages"`
+	// Packages whose routes are left out, e.g. admin handlers of a public API binary
+	ExcludePackages []string `mapstructure:"exclude_packages"`
+}
+
+// RouteMethod registers the routes of an HTTP method with a snippet instead of the framework's router method
+type RouteMethod struct {
+	Register string   `mapstructure:"register"` // Go template, e.g. 'cache.Purge({{.Router}}, "{{.Path}}", {{.Handler}})'
+	Imports  []string `mapstructure:"imports"`  // Imports the snippet refers to, e.g. ["github.com/acme/api/pkg/cache"]
+}
+
+// Method returns the custom registration configured for an HTTP method, if any
+// Methods are matched case-insensitively, viper lowercases map keys
+func (r RouteConfig) Method(method string) (RouteMethod, bool) {
+	for name, routeMethod := range r.Methods {
+		if strings.EqualFold(name, method) {
+			return routeMethod, true
+		}
+	}
+	return RouteMethod{}, false
+}
+
+type ServerConfig struct {
 	Enabled    bool   `mapstructure:"enabled"`
-	OutputFile string `mapstructure:"output_file"`
+	OutputFile string `mapstructure:"output_file"` // Server struct, ProvideServer and RegisterRoutes, written to output_dir
+}
+
+// Supported routing frameworks for route generation
+const (
+	FrameworkFiber   = "fiber"
+	FrameworkGin     = "gin"
+	FrameworkChi     = "chi"
+	FrameworkNetHTTP = "nethttp" // net/http ServeMux with Go 1.22 method+pattern routing
+)
+
+// RouteFramework returns the configured routing framework, defaulting to Fiber
+func (c *Config) RouteFramework() string {
+	if c == nil || c.Generation.Routes.Framework == "" {
+		return FrameworkFiber
+	}
+	return strings.ToLower(c.Generation.Routes.Framework)
+}
+
+// Route registration styles
+const (
+	RouteStyleRouter   = "router"   // Router struct built by ProvideRouter, func (ar *Router) RegisterHandlers()
+	RouteStyleFunction = "function" // Standalone func RegisterRoutes(app, deps *RouteDeps)
+	RouteStyleServer   = "server"   // Method of a hand-written struct holding the handlers, func (s *Server) RegisterHandlers(app)
+)
+
+// RouteStyle returns the configured shape of the route registration function, defaulting to a Router struct
+func (c *Config) RouteStyle() string {
+	if c == nil || c.Generation.Routes.Style == "" {
+		return RouteStyleRouter
+	}
+	return strings.ToLower(c.Generation.Routes.Style)
+}
+
+// RegisterFunc returns the name of the route registration function, RegisterRoutes for the function
+// style and RegisterHandlers for the others unless generation.routes.register_func is set
+func (c *Config) RegisterFunc() string {
+	if c != nil && c.Generation.Routes.RegisterFunc != "" {
+		return c.Generation.Routes.RegisterFunc
+	}
+	if c.RouteStyle() == RouteStyleFunction {
+		return "RegisterRoutes"
+	}
+	return "RegisterHandlers"
+}
+
+// RouteReceiver returns the hand-written struct the server style declares the registration method on
+func (c *Config) RouteReceiver() string {
+	if c == nil || c.Generation.Routes.Receiver == "" {
+		return "Server"
+	}
+	return c.Generation.Routes.Receiver
+}
+
+// FiberVersion returns the targeted Fiber major version, defaulting to 2
+func (c *Config) FiberVersion() int {
+	if c != nil && c.Generation.Routes.FiberVersion == 3 {
+		return 3
+	}
+	return 2
 }
 
 type DepConfig struct {
 	Enabled    bool   `mapstructure:"enabled"`
 	OutputFile string `mapstructure:"output_file"`
+	Backend    string `mapstructure:"backend"`  // "wire" (default), "fx" or "plain"
+	RunWire    bool   `mapstructure:"run_wire"` // Run wire on output_dir after generating (wire backend only)
+	// Emit one provider set per scanned package plus the aggregate GeneratedProviderSet (wire backend only)
+	PerPackageSets bool `mapstructure:"per_package_sets"`
+	// Address the Fiber app started by the fx lifecycle hook listens on (fx backend only)
+	ListenAddr string `mapstructure:"listen_addr"`
+	// Environment the providers are generated for, e.g. "dev". Providers tagged "@Provider env=..." for
+	// other environments are left out, untagged ones are always generated. Overridden by --env
+	Env string `mapstructure:"env"`
 }
 
-// ProvideConfig loads taskw.yaml from current directory or creates default config using Viper
-func ProvideConfig() (*Config, error) {
-	v := viper.New()
+// Supported dependency injection backends
+const (
+	BackendWire  = "wire"
+	BackendFx    = "fx"
+	BackendPlain = "plain" // Explicit constructor calls, no DI framework
+)
 
-	// Set config file details
-	configDir := filepath.Dir(".")
-	configName := strings.TrimSuffix(filepath.Base("taskw.yaml"), filepath.Ext("taskw.yaml"))
-	configType := strings.TrimPrefix(filepath.Ext("taskw.yaml"), ".")
+// DependencyBackend returns the configured dependency injection backend, defaulting to Wire
+func (c *Config) DependencyBackend() string {
+	if c == nil || c.Generation.Dependencies.Backend == "" {
+		return BackendWire
+	}
+	return strings.ToLower(c.Generation.Dependencies.Backend)
+}
+
+type PackageDocConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	OutputFile string `mapstructure:"output_file"` // Written into every scanned package directory
+}
+
+type ParamsConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	OutputFile string `mapstructure:"output_file"` // Written into every package declaring routes with UUID path parameters
+}
+
+type PortsConfig struct {
+	Enabled         bool     `mapstructure:"enabled"`
+	OutputFile      string   `mapstructure:"output_file"`      // Written into every handler package depending on a concrete service
+	ServiceSuffixes []string `mapstructure:"service_suffixes"` // Field type suffixes of the services handlers depend on, e.g. ["Service", "UseCase"]
+}
+
+// DefaultServiceSuffixes recognize the services handlers depend on when generation.ports doesn't list any
+var DefaultServiceSuffixes = []string{"Service"}
+
+// ServiceSuffix checks if a field type name ends with one of the configured service suffixes
+func (c PortsConfig) ServiceSuffix(name string) bool {
+	suffixes := c.ServiceSuffixes
+	if len(suffixes) == 0 {
+		suffixes = DefaultServiceSuffixes
+	}
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+type RecordingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	OutputFile  string  `mapstructure:"output_file"`
+	FixturesDir string  `mapstructure:"fixtures_dir"` // Directory the generated middleware writes fixtures to at runtime
+	SampleRate  float64 `mapstructure:"sample_rate"`  // Fraction of requests captured, between 0 and 1
+}
+
+type ChaosConfig struct {
+	Enabled    bool    `mapstructure:"enabled"`
+	OutputFile string  `mapstructure:"output_file"` // Wrappers, built with the chaos tag; the passthrough file gets an _off suffix
+	BuildTag   string  `mapstructure:"build_tag"`   // Build tag enabling failure injection
+	Latency    string  `mapstructure:"latency"`     // Delay added to every call, overridden by TASKW_CHAOS_LATENCY
+	ErrorRate  float64 `mapstructure:"error_rate"`  // Share of failing calls (0-1), overridden by TASKW_CHAOS_ERROR_RATE
+}
+
+type EnvelopeConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	PackageDir string `mapstructure:"package_dir"` // Package holding the helpers, kept apart from output_dir so handlers can import it
+	OutputFile string `mapstructure:"output_file"`
+	DataField  string `mapstructure:"data_field"`  // JSON field holding the result of successful responses
+	ErrorField string `mapstructure:"error_field"` // JSON field holding the error of failed responses
+	MetaField  string `mapstructure:"meta_field"`  // JSON field holding pagination and other metadata
+}
+
+type RedirectConfig struct {
+	OutputFile     string `mapstructure:"output_file"`
+	MigrationsFile string `mapstructure:"migrations_file"` // Path migrations recorded by taskw migrate path, relative to the project root
+}
 
-	if configDir == "." {
-		v.AddConfigPath(".")
-	} else {
-		v.AddConfigPath(configDir)
+// SwaggerUIConfig configures the gofiber/contrib/swagger middleware serving the documentation,
+// generated into output_dir instead of being hand-written in main.go
+type SwaggerUIConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	OutputFile  string `mapstructure:"output_file"`
+	Path        string `mapstructure:"path"`         // Path of the UI under base_path, e.g. "docs"
+	BasePath    string `mapstructure:"base_path"`    // Prefix of the UI and spec URLs, e.g. "/api"
+	FilePath    string `mapstructure:"file_path"`    // Spec served to the UI, empty for the spec of openapi.spec_version
+	Title       string `mapstructure:"title"`        // Title of the documentation page, empty for openapi.title
+	DeepLinking bool   `mapstructure:"deep_linking"` // Link to operations and tags from the URL fragment
+	CacheAge    int    `mapstructure:"cache_age"`    // Cache-Control max-age of the spec in seconds, 0 for the middleware default (3600)
+}
+
+// GatewayConfig configures the Fiber routes serving @RPC annotated gRPC methods over HTTP, so a
+// service exposed on both transports keeps a single implementation
+type GatewayConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	OutputFile string `mapstructure:"output_file"`
+}
+
+type PIIConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	OutputFile string `mapstructure:"output_file"` // Markdown report of endpoints handling personal data, relative to the project root
+}
+
+type SLOConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	OutputFile  string `mapstructure:"output_file"`  // Relative to the project root
+	Metric      string `mapstructure:"metric"`       // Latency histogram name, without the _bucket suffix
+	RouteLabel  string `mapstructure:"route_label"`  // Histogram label holding the route pattern
+	MethodLabel string `mapstructure:"method_label"` // Histogram label holding the HTTP method
+	Window      string `mapstructure:"window"`       // Rate window, e.g. "5m"
+	For         string `mapstructure:"for"`          // How long the budget must be exceeded before firing
+}
+
+// ProvideConfig loads taskw.yaml or creates default config using Viper
+// Like git, the project is found by walking up from the working directory (see discoverProject).
+// The project root becomes the working directory, so configured paths resolve from any subdirectory
+func ProvideConfig() (*Config, error) {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("error getting working directory: %w", err)
 	}
-	v.SetConfigName(configName)
-	v.SetConfigType(configType)
+	config, err := Load(workDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chdir(config.Root); err != nil {
+		return nil, fmt.Errorf("error entering project root %s: %w", config.Root, err)
+	}
+	return config, nil
+}
+
+// Load loads the config of the project dir belongs to, found like ProvideConfig does, without
+// changing the working directory. Configured paths stay relative to Root
+func Load(dir string) (*Config, error) {
+	workDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving %s: %w", dir, err)
+	}
+	layout := discoverProject(workDir)
+
+	v := viper.New()
+	v.SetConfigType("yaml")
 
 	// Set defaults
-	if err := setDefaults(v); err != nil {
+	if err := setDefaults(v, layout.ModuleDir); err != nil {
 		return nil, fmt.Errorf("error setting defaults: %w", err)
 	}
 
-	// Try to read config file
-	if err := v.ReadInConfig(); err != nil {
-		// If config doesn't exist, create it with defaults
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			config := &Config{}
-			if err := v.Unmarshal(config); err != nil {
-				return nil, fmt.Errorf("error unmarshaling default config: %w", err)
+	// Read the root config file, then let taskw.yaml files in subdirectories override it
+	if len(layout.ConfigFiles) > 0 {
+		v.SetConfigFile(layout.ConfigFiles[0])
+		if err := v.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("error reading config file: %w", err)
+		}
+		for _, path := range layout.ConfigFiles[1:] {
+			if err := mergeNestedConfig(v, layout.Root, path); err != nil {
+				return nil, err
 			}
-
-			return config, nil
 		}
-		return nil, fmt.Errorf("error reading config file: %w", err)
 	}
 
 	var config Config
@@ -81,13 +559,128 @@ func ProvideConfig() (*Config, error) {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
 
+	if mode := config.ScanMode(); mode != ScanModeAST && mode != ScanModePackages {
+		return nil, fmt.Errorf("unknown scanning.mode %q (use %s or %s)", config.Scanning.Mode, ScanModeAST, ScanModePackages)
+	}
+	if value := config.Scanning.MalformedAnnotations; value != AnnotationsWarn && value != AnnotationsError {
+		return nil, fmt.Errorf("unknown scanning.malformed_annotations %q (use %s or %s)", value, AnnotationsWarn, AnnotationsError)
+	}
+	if severity := config.FailOn(); severity != SeverityError && severity != SeverityWarning && severity != SeverityNone {
+		return nil, fmt.Errorf("unknown validation.fail_on %q (use %s, %s or %s)", config.Validation.FailOn, SeverityError, SeverityWarning, SeverityNone)
+	}
+	if version := config.SpecVersion(); version != SpecVersionSwagger2 && version != SpecVersionOpenAPI30 && version != SpecVersionOpenAPI31 {
+		return nil, fmt.Errorf("unknown openapi.spec_version %q (use %s, %s or %s)", version, SpecVersionSwagger2, SpecVersionOpenAPI30, SpecVersionOpenAPI31)
+	}
+	if framework := config.RouteFramework(); framework != FrameworkFiber && framework != FrameworkGin && framework != FrameworkChi && framework != FrameworkNetHTTP {
+		return nil, fmt.Errorf("unknown generation.routes.framework %q (use %s, %s, %s or %s)", config.Generation.Routes.Framework, FrameworkFiber, FrameworkGin, FrameworkChi, FrameworkNetHTTP)
+	}
+	if style := config.RouteStyle(); style != RouteStyleRouter && style != RouteStyleFunction && style != RouteStyleServer {
+		return nil, fmt.Errorf("unknown generation.routes.style %q (use %s, %s or %s)", config.Generation.Routes.Style, RouteStyleRouter, RouteStyleFunction, RouteStyleServer)
+	}
+	if backend := config.DependencyBackend(); backend != BackendWire && backend != BackendFx && backend != BackendPlain {
+		return nil, fmt.Errorf("unknown generation.dependencies.backend %q (use %s, %s or %s)", config.Generation.Dependencies.Backend, BackendWire, BackendFx, BackendPlain)
+	}
+	if name := config.RegisterFunc(); !token.IsIdentifier(name) {
+		return nil, fmt.Errorf("invalid generation.routes.register_func %q (expected a Go identifier)", name)
+	}
+	if name := config.RouteReceiver(); !token.IsIdentifier(name) {
+		return nil, fmt.Errorf("invalid generation.routes.receiver %q (expected a Go identifier)", name)
+	}
+	if timeout, err := time.ParseDuration(config.Tools.Timeout); err != nil || timeout < 0 {
+		return nil, fmt.Errorf("invalid tools.timeout %q (expected a duration such as 5m, or 0 for no limit)", config.Tools.Timeout)
+	}
+
+	config.Root = layout.Root
+	config.WorkDir = workDir
+	config.ModuleDir = layout.ModuleDir
+	if config.ModuleDir == "" {
+		config.ModuleDir = layout.Root
+	}
 	return &config, nil
 }
 
+// RelativeToRoot resolves a path given relative to the directory taskw was run from against the
+// project root, e.g. "handler.go" run from internal/user becomes "internal/user/handler.go"
+func (c *Config) RelativeToRoot(path string) string {
+	if path == "" || filepath.IsAbs(path) || c.WorkDir == "" || c.Root == "" {
+		return path
+	}
+	rel, err := filepath.Rel(c.Root, filepath.Join(c.WorkDir, path))
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// ResolvePath resolves a path relative to the project root, as configured paths are, against Root,
+// so files are found whatever the working directory. Absolute paths are returned as is, and so is
+// every path of a config without a Root
+func (c *Config) ResolvePath(path string) string {
+	if c == nil {
+		return path
+	}
+	return JoinRoot(c.Root, path)
+}
+
+// JoinRoot resolves a path relative to the project root against root, for code given the root
+// rather than its config. Absolute paths and an empty root leave path as is
+func JoinRoot(root, path string) string {
+	if root == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, path)
+}
+
+// PackageImportPath derives the import path of the package in a directory from its path relative to
+// go.mod, e.g. internal/domain/user/v2 -> github.com/acme/api/internal/domain/user/v2.
+// Returns an empty string for directories outside the module
+func (c *Config) PackageImportPath(dir string) string {
+	absDir, err := filepath.Abs(c.ResolvePath(dir))
+	if err != nil {
+		return ""
+	}
+
+	moduleDir := c.ModuleDir
+	if moduleDir == "" {
+		moduleDir = "."
+	}
+	moduleDir, err = filepath.Abs(moduleDir)
+	if err != nil {
+		return ""
+	}
+
+	rel, err := filepath.Rel(moduleDir, absDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	if rel == "." {
+		return c.Project.Module
+	}
+	return c.Project.Module + "/" + filepath.ToSlash(rel)
+}
+
+// PackageDir returns the directory of a package of the module from its import path, the reverse of
+// PackageImportPath, e.g. github.com/acme/api/internal/user -> internal/user under the module directory.
+// Returns false for packages outside the module
+func (c *Config) PackageDir(importPath string) (string, bool) {
+	moduleDir := c.ModuleDir
+	if moduleDir == "" {
+		moduleDir = "."
+	}
+	if importPath == c.Project.Module {
+		return moduleDir, true
+	}
+	rel, ok := strings.CutPrefix(importPath, c.Project.Module+"/")
+	if !ok || c.Project.Module == "" {
+		return "", false
+	}
+	return filepath.Join(moduleDir, filepath.FromSlash(rel)), true
+}
+
 // setDefaults sets default values using Viper
-func setDefaults(v *viper.Viper) error {
+func setDefaults(v *viper.Viper, moduleDir string) error {
 	// Auto-detect Go module
-	module, err := detectGoModule()
+	module, err := DetectGoModule(moduleDir)
 	if err != nil {
 		return fmt.Errorf("error detecting Go module: %w", err)
 	}
@@ -96,18 +689,114 @@ func setDefaults(v *viper.Viper) error {
 	v.SetDefault("project.module", module)
 	v.SetDefault("paths.scan_dirs", []string{"."})
 	v.SetDefault("paths.output_dir", ".")
+	v.SetDefault("paths.routes_overlay", "")
+	v.SetDefault("paths.templates_dir", "")
 	v.SetDefault("generation.routes.enabled", true)
 	v.SetDefault("generation.routes.output_file", "routes_gen.go")
+	v.SetDefault("generation.routes.framework", FrameworkFiber)
+	v.SetDefault("generation.routes.fiber_version", 2)
+	v.SetDefault("generation.routes.hooks", false)
+	v.SetDefault("generation.routes.route_id_header", "")
+	v.SetDefault("generation.routes.style", RouteStyleRouter)
+	v.SetDefault("generation.routes.register_func", "")
+	v.SetDefault("generation.routes.receiver", "Server")
+	v.SetDefault("generation.routes.include_packages", []string{})
+	v.SetDefault("generation.routes.exclude_packages", []string{})
+	v.SetDefault("generation.routes.methods", map[string]interface{}{})
 	v.SetDefault("generation.dependencies.enabled", true)
 	v.SetDefault("generation.dependencies.output_file", "dependencies_gen.go")
+	v.SetDefault("generation.dependencies.backend", BackendWire)
+	v.SetDefault("generation.dependencies.run_wire", false)
+	v.SetDefault("generation.dependencies.per_package_sets", false)
+	v.SetDefault("generation.dependencies.listen_addr", ":3000")
+	v.SetDefault("generation.dependencies.env", "")
+	v.SetDefault("generation.package_docs.enabled", false)
+	v.SetDefault("generation.package_docs.output_file", "doc.go")
+	v.SetDefault("generation.params.enabled", false)
+	v.SetDefault("generation.params.output_file", "params_gen.go")
+	v.SetDefault("generation.ports.enabled", false)
+	v.SetDefault("generation.ports.output_file", "ports_gen.go")
+	v.SetDefault("generation.ports.service_suffixes", DefaultServiceSuffixes)
+	v.SetDefault("generation.recording.enabled", false)
+	v.SetDefault("generation.recording.output_file", "recording_gen.go")
+	v.SetDefault("generation.recording.fixtures_dir", "testdata/fixtures")
+	v.SetDefault("generation.recording.sample_rate", 0.01)
+	v.SetDefault("generation.slo.enabled", false)
+	v.SetDefault("generation.slo.output_file", "monitoring/slo_alerts.yml")
+	v.SetDefault("generation.slo.metric", "http_request_duration_seconds")
+	v.SetDefault("generation.slo.route_label", "route")
+	v.SetDefault("generation.slo.method_label", "method")
+	v.SetDefault("generation.slo.window", "5m")
+	v.SetDefault("generation.slo.for", "5m")
+	v.SetDefault("generation.chaos.enabled", false)
+	v.SetDefault("generation.chaos.output_file", "chaos_gen.go")
+	v.SetDefault("generation.chaos.build_tag", "chaos")
+	v.SetDefault("generation.chaos.latency", "100ms")
+	v.SetDefault("generation.chaos.error_rate", 0.1)
+	v.SetDefault("generation.server.enabled", false)
+	v.SetDefault("generation.server.output_file", "server_gen.go")
+	v.SetDefault("generation.envelope.enabled", false)
+	v.SetDefault("generation.envelope.package_dir", "./internal/envelope")
+	v.SetDefault("generation.envelope.output_file", "envelope_gen.go")
+	v.SetDefault("generation.envelope.data_field", "data")
+	v.SetDefault("generation.envelope.error_field", "error")
+	v.SetDefault("generation.envelope.meta_field", "meta")
+	v.SetDefault("generation.pii.enabled", false)
+	v.SetDefault("generation.pii.output_file", "docs/pii_report.md")
+	v.SetDefault("generation.redirects.output_file", "redirects_gen.go")
+	v.SetDefault("generation.redirects.migrations_file", "route_migrations.yaml")
+	v.SetDefault("generation.swagger_ui.enabled", false)
+	v.SetDefault("generation.swagger_ui.output_file", "swagger_ui_gen.go")
+	v.SetDefault("generation.swagger_ui.path", "docs")
+	v.SetDefault("generation.swagger_ui.base_path", "")
+	v.SetDefault("generation.swagger_ui.file_path", "")
+	v.SetDefault("generation.swagger_ui.title", "")
+	v.SetDefault("generation.swagger_ui.deep_linking", true)
+	v.SetDefault("generation.swagger_ui.cache_age", 0)
+	v.SetDefault("generation.gateway.enabled", false)
+	v.SetDefault("generation.gateway.output_file", "gateway_gen.go")
+	v.SetDefault("scanning.mode", ScanModeAST)
+	v.SetDefault("scanning.build_tags", []string{})
+	v.SetDefault("scanning.goos", "")
+	v.SetDefault("scanning.goarch", "")
+	v.SetDefault("scanning.cache", true)
+	v.SetDefault("scanning.respect_gitignore", false)
+	v.SetDefault("scanning.follow_symlinks", false)
+	v.SetDefault("scanning.malformed_annotations", AnnotationsWarn)
+	v.SetDefault("scanning.ignore", []string{})
+	v.SetDefault("scanning.include", []string{})
+	v.SetDefault("ownership.codeowners_file", "")
+	v.SetDefault("ownership.require_owners", false)
+	v.SetDefault("conventions.provider_prefixes", DefaultProviderPrefixes)
+	v.SetDefault("validation.fail_on", SeverityError)
+	v.SetDefault("dev.main_package", "./cmd/server")
+	v.SetDefault("dev.binary", "tmp/server")
+	v.SetDefault("dev.args", []string{})
+	v.SetDefault("dev.debounce", "300ms")
+	v.SetDefault("score.min_score", 0)
+	v.SetDefault("score.min_coverage", 0)
+	v.SetDefault("score.max_errors", -1)
+	v.SetDefault("score.max_orphan_providers", -1)
+	v.SetDefault("score.max_route_conflicts", -1)
+	v.SetDefault("score.max_stale", -1)
+	v.SetDefault("dev.exclude_dirs", []string{"bin", "tmp", "vendor", "node_modules", "testdata"})
+	v.SetDefault("tools.timeout", "5m")
+	v.SetDefault("conventions.handler_suffixes", DefaultHandlerSuffixes)
+	v.SetDefault("openapi.title", "")
+	v.SetDefault("openapi.version", "")
+	v.SetDefault("openapi.description", "")
+	v.SetDefault("openapi.terms_of_service", "")
 
 	return nil
 }
 
-// detectGoModule reads go.mod to extract the module name
+// DetectGoModule reads go.mod in moduleDir to extract the module name
 // Returns empty string if go.mod doesn't exist (e.g., during init)
-func detectGoModule() (string, error) {
-	data, err := os.ReadFile("go.mod")
+func DetectGoModule(moduleDir string) (string, error) {
+	if moduleDir == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(filepath.Join(moduleDir, "go.mod"))
 	if err != nil {
 		// If go.mod doesn't exist, return empty string (will be handled during init)
 		if os.IsNotExist(err) {
@@ -130,7 +819,7 @@ func detectGoModule() (string, error) {
 // Save writes the config to a YAML file
 func (c *Config) Save(path string) error {
 	if path == "" {
-		path = "taskw.yaml"
+		path = ConfigFileName
 	}
 
 	v := viper.New()
@@ -142,10 +831,114 @@ func (c *Config) Save(path string) error {
 	v.Set("project.module", c.Project.Module)
 	v.Set("paths.scan_dirs", c.Paths.ScanDirs)
 	v.Set("paths.output_dir", c.Paths.OutputDir)
+	v.Set("paths.routes_overlay", c.Paths.RoutesOverlay)
+	v.Set("paths.templates_dir", c.Paths.TemplatesDir)
 	v.Set("generation.routes.enabled", c.Generation.Routes.Enabled)
 	v.Set("generation.routes.output_file", c.Generation.Routes.OutputFile)
+	v.Set("generation.routes.framework", c.Generation.Routes.Framework)
+	v.Set("generation.routes.fiber_version", c.Generation.Routes.FiberVersion)
+	v.Set("generation.routes.hooks", c.Generation.Routes.Hooks)
+	v.Set("generation.routes.methods", routeMethodValues(c.Generation.Routes.Methods))
+	v.Set("generation.routes.route_id_header", c.Generation.Routes.RouteIDHeader)
+	v.Set("generation.routes.style", c.Generation.Routes.Style)
+	v.Set("generation.routes.register_func", c.Generation.Routes.RegisterFunc)
+	v.Set("generation.routes.receiver", c.Generation.Routes.Receiver)
+	v.Set("generation.routes.include_packages", c.Generation.Routes.IncludePackages)
+	v.Set("generation.routes.exclude_packages", c.Generation.Routes.ExcludePackages)
 	v.Set("generation.dependencies.enabled", c.Generation.Dependencies.Enabled)
 	v.Set("generation.dependencies.output_file", c.Generation.Dependencies.OutputFile)
+	v.Set("generation.dependencies.backend", c.Generation.Dependencies.Backend)
+	v.Set("generation.dependencies.run_wire", c.Generation.Dependencies.RunWire)
+	v.Set("generation.dependencies.per_package_sets", c.Generation.Dependencies.PerPackageSets)
+	v.Set("generation.dependencies.listen_addr", c.Generation.Dependencies.ListenAddr)
+	v.Set("generation.dependencies.env", c.Generation.Dependencies.Env)
+	v.Set("generation.package_docs.enabled", c.Generation.PackageDocs.Enabled)
+	v.Set("generation.package_docs.output_file", c.Generation.PackageDocs.OutputFile)
+	v.Set("generation.params.enabled", c.Generation.Params.Enabled)
+	v.Set("generation.params.output_file", c.Generation.Params.OutputFile)
+	v.Set("generation.ports.enabled", c.Generation.Ports.Enabled)
+	v.Set("generation.ports.output_file", c.Generation.Ports.OutputFile)
+	v.Set("generation.ports.service_suffixes", c.Generation.Ports.ServiceSuffixes)
+	v.Set("generation.recording.enabled", c.Generation.Recording.Enabled)
+	v.Set("generation.recording.output_file", c.Generation.Recording.OutputFile)
+	v.Set("generation.recording.fixtures_dir", c.Generation.Recording.FixturesDir)
+	v.Set("generation.recording.sample_rate", c.Generation.Recording.SampleRate)
+	v.Set("generation.slo.enabled", c.Generation.SLO.Enabled)
+	v.Set("generation.slo.output_file", c.Generation.SLO.OutputFile)
+	v.Set("generation.slo.metric", c.Generation.SLO.Metric)
+	v.Set("generation.slo.route_label", c.Generation.SLO.RouteLabel)
+	v.Set("generation.slo.method_label", c.Generation.SLO.MethodLabel)
+	v.Set("generation.slo.window", c.Generation.SLO.Window)
+	v.Set("generation.slo.for", c.Generation.SLO.For)
+	v.Set("generation.chaos.enabled", c.Generation.Chaos.Enabled)
+	v.Set("generation.chaos.output_file", c.Generation.Chaos.OutputFile)
+	v.Set("generation.chaos.build_tag", c.Generation.Chaos.BuildTag)
+	v.Set("generation.chaos.latency", c.Generation.Chaos.Latency)
+	v.Set("generation.chaos.error_rate", c.Generation.Chaos.ErrorRate)
+	v.Set("generation.server.enabled", c.Generation.Server.Enabled)
+	v.Set("generation.server.output_file", c.Generation.Server.OutputFile)
+	v.Set("generation.envelope.enabled", c.Generation.Envelope.Enabled)
+	v.Set("generation.envelope.package_dir", c.Generation.Envelope.PackageDir)
+	v.Set("generation.envelope.output_file", c.Generation.Envelope.OutputFile)
+	v.Set("generation.envelope.data_field", c.Generation.Envelope.DataField)
+	v.Set("generation.envelope.error_field", c.Generation.Envelope.ErrorField)
+	v.Set("generation.envelope.meta_field", c.Generation.Envelope.MetaField)
+	v.Set("generation.pii.enabled", c.Generation.PII.Enabled)
+	v.Set("generation.pii.output_file", c.Generation.PII.OutputFile)
+	v.Set("generation.redirects.output_file", c.Generation.Redirects.OutputFile)
+	v.Set("generation.redirects.migrations_file", c.Generation.Redirects.MigrationsFile)
+	v.Set("generation.swagger_ui.enabled", c.Generation.SwaggerUI.Enabled)
+	v.Set("generation.swagger_ui.output_file", c.Generation.SwaggerUI.OutputFile)
+	v.Set("generation.swagger_ui.path", c.Generation.SwaggerUI.Path)
+	v.Set("generation.swagger_ui.base_path", c.Generation.SwaggerUI.BasePath)
+	v.Set("generation.swagger_ui.file_path", c.Generation.SwaggerUI.FilePath)
+	v.Set("generation.swagger_ui.title", c.Generation.SwaggerUI.Title)
+	v.Set("generation.swagger_ui.deep_linking", c.Generation.SwaggerUI.DeepLinking)
+	v.Set("generation.swagger_ui.cache_age", c.Generation.SwaggerUI.CacheAge)
+	v.Set("generation.gateway.enabled", c.Generation.Gateway.Enabled)
+	v.Set("generation.gateway.output_file", c.Generation.Gateway.OutputFile)
+	v.Set("scanning.mode", c.Scanning.Mode)
+	v.Set("scanning.build_tags", c.Scanning.BuildTags)
+	v.Set("scanning.goos", c.Scanning.GOOS)
+	v.Set("scanning.goarch", c.Scanning.GOARCH)
+	v.Set("scanning.cache", c.Scanning.Cache)
+	v.Set("scanning.respect_gitignore", c.Scanning.RespectGitignore)
+	v.Set("scanning.follow_symlinks", c.Scanning.FollowSymlinks)
+	v.Set("scanning.malformed_annotations", c.Scanning.MalformedAnnotations)
+	v.Set("scanning.ignore", c.Scanning.Ignore)
+	v.Set("scanning.include", c.Scanning.Include)
+	v.Set("ownership.codeowners_file", c.Ownership.CodeownersFile)
+	v.Set("ownership.require_owners", c.Ownership.RequireOwners)
+	v.Set("conventions.provider_prefixes", c.Conventions.ProviderPrefixes)
+	v.Set("conventions.handler_suffixes", c.Conventions.HandlerSuffixes)
+	v.Set("validation.fail_on", c.FailOn())
+	v.Set("dev.main_package", c.Dev.MainPackage)
+	v.Set("dev.binary", c.Dev.Binary)
+	v.Set("dev.args", c.Dev.Args)
+	v.Set("dev.debounce", c.Dev.Debounce)
+	v.Set("score.min_score", c.Score.MinScore)
+	v.Set("score.min_coverage", c.Score.MinCoverage)
+	v.Set("score.max_errors", c.Score.MaxErrors)
+	v.Set("score.max_orphan_providers", c.Score.MaxOrphanProviders)
+	v.Set("score.max_route_conflicts", c.Score.MaxRouteConflicts)
+	v.Set("score.max_stale", c.Score.MaxStale)
+	v.Set("dev.exclude_dirs", c.Dev.ExcludeDirs)
+	v.Set("tools.timeout", c.Tools.Timeout)
+	if c.OpenAPI.IsSet() {
+		v.Set("openapi.title", c.OpenAPI.Title)
+		v.Set("openapi.version", c.OpenAPI.Version)
+		v.Set("openapi.description", c.OpenAPI.Description)
+		v.Set("openapi.terms_of_service", c.OpenAPI.TermsOfService)
+		v.Set("openapi.contact.name", c.OpenAPI.Contact.Name)
+		v.Set("openapi.contact.url", c.OpenAPI.Contact.URL)
+		v.Set("openapi.contact.email", c.OpenAPI.Contact.Email)
+		v.Set("openapi.license.name", c.OpenAPI.License.Name)
+		v.Set("openapi.license.url", c.OpenAPI.License.URL)
+		v.Set("openapi.servers", openAPIServerValues(c.OpenAPI.Servers))
+	}
+	if c.OpenAPI.SpecVersion != "" {
+		v.Set("openapi.spec_version", c.OpenAPI.SpecVersion)
+	}
 
 	// Write config file
 	if err := v.WriteConfig(); err != nil {
@@ -154,3 +947,43 @@ func (c *Config) Save(path string) error {
 
 	return nil
 }
+
+// routeMethodValues converts custom route methods to plain values so they are written with their YAML keys
+func routeMethodValues(methods map[string]RouteMethod) map[string]interface{} {
+	values := make(map[string]interface{}, len(methods))
+	for name, method := range methods {
+		value := map[string]interface{}{"register": method.Register}
+		if len(method.Imports) > 0 {
+			value["imports"] = method.Imports
+		}
+		values[strings.ToUpper(name)] = value
+	}
+	return values
+}
+
+// openAPIServerValues converts servers to plain values so they are written with their YAML keys
+func openAPIServerValues(servers []OpenAPIServer) []map[string]interface{} {
+	values := make([]map[string]interface{}, 0, len(servers))
+	for _, server := range servers {
+		value := map[string]interface{}{"url": server.URL}
+		if server.Description != "" {
+			value["description"] = server.Description
+		}
+		if len(server.Variables) > 0 {
+			variables := make(map[string]interface{}, len(server.Variables))
+			for name, variable := range server.Variables {
+				entry := map[string]interface{}{"default": variable.Default}
+				if len(variable.Enum) > 0 {
+					entry["enum"] = variable.Enum
+				}
+				if variable.Description != "" {
+					entry["description"] = variable.Description
+				}
+				variables[name] = entry
+			}
+			value["variables"] = variables
+		}
+		values = append(values, value)
+	}
+	return values
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a project with the given taskw.yaml into a temporary directory and returns its root
+func writeConfig(t *testing.T, taskwYAML string) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":     "module example.com/shop\n\ngo 1.22\n",
+		"taskw.yaml": taskwYAML,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestLoadRejectsUnknownValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string // Empty when the config is valid
+	}{
+		{name: "defaults", yaml: "generation:\n  routes:\n    enabled: true\n"},
+		{name: "known framework", yaml: "generation:\n  routes:\n    framework: Gin\n"},
+		{
+			name:    "unknown framework",
+			yaml:    "generation:\n  routes:\n    framework: gim\n",
+			wantErr: `unknown generation.routes.framework "gim"`,
+		},
+		{
+			name:    "unknown route style",
+			yaml:    "generation:\n  routes:\n    style: method\n",
+			wantErr: `unknown generation.routes.style "method"`,
+		},
+		{name: "known backend", yaml: "generation:\n  dependencies:\n    backend: FX\n"},
+		{
+			name:    "unknown dependency backend",
+			yaml:    "generation:\n  dependencies:\n    backend: fxx\n",
+			wantErr: `unknown generation.dependencies.backend "fxx"`,
+		},
+		{
+			name:    "unknown scanning mode",
+			yaml:    "scanning:\n  mode: typed\n",
+			wantErr: `unknown scanning.mode "typed"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.yaml))
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Load: %v, want no error", err)
+			case tt.wantErr != "" && err == nil:
+				t.Errorf("Load succeeded, want an error containing %q", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Errorf("Load error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+// ConfigFileName is the name of the taskw configuration file
+const ConfigFileName = "taskw.yaml"
+
+// projectLayout describes where a project's configuration lives relative to the working directory
+type projectLayout struct {
+	Root        string   // Directory all configured paths are relative to, holding the outermost taskw.yaml
+	ModuleDir   string   // Directory holding go.mod, empty outside a module
+	ConfigFiles []string // taskw.yaml files from the project root down to the working directory
+}
+
+// pathKeys are the settings holding paths, rebased onto the project root when set in a nested taskw.yaml.
+// Output file names are relative to output_dir and stay as written
+var pathKeys = []string{
+	"paths.scan_dirs",
+	"paths.output_dir",
+	"paths.routes_overlay",
+	"paths.templates_dir",
+	"generation.envelope.package_dir",
+	"generation.pii.output_file",
+	"generation.slo.output_file",
+	"generation.redirects.migrations_file",
+	"ownership.codeowners_file",
+	"dev.main_package",
+	"dev.binary",
+}
+
+// discoverProject walks up from dir like git does, collecting taskw.yaml files until the directory
+// holding go.mod. The outermost taskw.yaml marks the project root; without one, the module directory
+// (or dir itself outside a module) is the root and defaults apply
+func discoverProject(dir string) *projectLayout {
+	layout := &projectLayout{}
+	for current := dir; ; {
+		if fileExists(filepath.Join(current, ConfigFileName)) {
+			layout.ConfigFiles = append([]string{filepath.Join(current, ConfigFileName)}, layout.ConfigFiles...)
+		}
+		if fileExists(filepath.Join(current, "go.mod")) {
+			layout.ModuleDir = current
+			break
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+
+	switch {
+	case len(layout.ConfigFiles) > 0:
+		layout.Root = filepath.Dir(layout.ConfigFiles[0])
+	case layout.ModuleDir != "":
+		layout.Root = layout.ModuleDir
+	default:
+		layout.Root = dir
+	}
+	return layout
+}
+
+// mergeNestedConfig merges a taskw.yaml below the project root into v, its settings overriding
+// those of the files above it. Paths in the nested file are relative to its own directory
+func mergeNestedConfig(v *viper.Viper, root, path string) error {
+	nested := viper.New()
+	nested.SetConfigFile(path)
+	nested.SetConfigType("yaml")
+	if err := nested.ReadInConfig(); err != nil {
+		return fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	for _, key := range pathKeys {
+		if !nested.IsSet(key) {
+			continue
+		}
+		switch value := nested.Get(key).(type) {
+		case string:
+			nested.Set(key, rebasePath(root, dir, value))
+		case []interface{}:
+			rebased := make([]string, len(value))
+			for i, item := range value {
+				rebased[i] = rebasePath(root, dir, fmt.Sprint(item))
+			}
+			nested.Set(key, rebased)
+		}
+	}
+
+	return v.MergeConfigMap(nested.AllSettings())
+}
+
+// rebasePath makes a path relative to dir relative to the project root, e.g. "./internal" in
+// services/billing/taskw.yaml becomes "services/billing/internal"
+func rebasePath(root, dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	rel, err := filepath.Rel(root, filepath.Join(dir, path))
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// fileExists reports whether path exists and is a regular file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
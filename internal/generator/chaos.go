@@ -0,0 +1,521 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// ChaosGenerator generates failure injection wrappers around @ChaosWrap providers
+type ChaosGenerator struct {
+	config *config.Config
+	output *Output
+}
+
+// NewChaosGenerator creates a new chaos wrapper generator
+func NewChaosGenerator(cfg *config.Config, out *Output) *ChaosGenerator {
+	return &ChaosGenerator{
+		config: cfg,
+		output: out,
+	}
+}
+
+// chaosWrapper is a provider whose interface result is wrapped with latency and error injection
+type chaosWrapper struct {
+	Name         string // Wrapper provider, e.g., "provideChaosUserStore"
+	Provider     string // Wrapped provider reference, e.g., "user.ProvideStore"
+	Params       string // e.g., "p0 *store.DB, p1 *config.Config"
+	Args         string // e.g., "p0, p1"
+	Interface    string // e.g., "user.Store"
+	Struct       string // e.g., "chaosUserStore"
+	HasCleanup   bool
+	ReturnsError bool
+	Methods      []chaosMethod
+}
+
+// chaosMethod is an interface method implemented by a chaos wrapper
+type chaosMethod struct {
+	Name    string // e.g., "GetUser"
+	Call    string // Reported in injected errors, e.g., "user.Store.GetUser"
+	Params  string // e.g., "p0 context.Context, p1 string"
+	Args    string // e.g., "p0, p1"
+	Results string // e.g., "(r0 *user.User, err error)"
+	Returns bool   // true if the method has results
+	CanFail bool   // true if the last result is error
+}
+
+// ChaosFiles returns the paths of the wrapper file and its passthrough counterpart
+func ChaosFiles(cfg *config.Config) (string, string) {
+	wrapperPath := filepath.Join(cfg.Paths.OutputDir, cfg.Generation.Chaos.OutputFile)
+	return wrapperPath, strings.TrimSuffix(wrapperPath, ".go") + "_off.go"
+}
+
+// chaosProviderName returns the wrapper provider name for a @ChaosWrap provider
+// e.g., user.ProvideStore -> provideChaosUserStore
+// The name is unexported so the scanner never picks the wrapper up as a provider itself
+func chaosProviderName(provider scanner.ProviderFunction) string {
+	return "provideChaos" + upperFirst(provider.ImportName) + provider.BaseName
+}
+
+// GenerateChaos writes the wrappers of every @ChaosWrap provider and returns how many were wrapped
+// Wrappers are only compiled with the chaos build tag; without it the passthrough file calls the
+// original providers, so the dependency set can always reference the wrapper providers
+func (g *ChaosGenerator) GenerateChaos(providers []scanner.ProviderFunction) (int, error) {
+	if !g.config.Generation.Chaos.Enabled {
+		return 0, nil
+	}
+
+	chaosConfig := g.config.Generation.Chaos
+	if _, err := time.ParseDuration(chaosConfig.Latency); err != nil {
+		return 0, fmt.Errorf("generation.chaos.latency must be a duration such as 100ms, got %q", chaosConfig.Latency)
+	}
+	if chaosConfig.ErrorRate < 0 || chaosConfig.ErrorRate > 1 {
+		return 0, fmt.Errorf("generation.chaos.error_rate must be between 0 and 1, got %v", chaosConfig.ErrorRate)
+	}
+	if chaosConfig.BuildTag == "" {
+		return 0, fmt.Errorf("generation.chaos.build_tag must not be empty")
+	}
+
+	outputPackage, err := outputPackageName(g.config)
+	if err != nil {
+		return 0, err
+	}
+
+	// The passthrough file only needs the imports of the provider signatures,
+	// the wrapper file also needs those of the interface methods
+	providerImports := make(map[string]string)
+	methodImports := make(map[string]string)
+	packages := make(map[string][]*ast.File)
+	var wrappers []chaosWrapper
+	for _, provider := range providers {
+		if !provider.ChaosWrap {
+			continue
+		}
+
+		dir := filepath.Dir(provider.FilePath)
+		if _, parsed := packages[dir]; !parsed {
+			files, err := parsePackageDir(g.config.ResolvePath(dir))
+			if err != nil {
+				return 0, fmt.Errorf("error parsing package %s: %w", dir, err)
+			}
+			packages[dir] = files
+		}
+
+		wrapper, err := g.buildChaosWrapper(provider, packages[dir], outputPackage, providerImports, methodImports)
+		if err != nil {
+			return 0, fmt.Errorf("@ChaosWrap on %s.%s: %w", provider.Package, provider.FunctionName, err)
+		}
+		wrappers = append(wrappers, *wrapper)
+	}
+
+	sort.Slice(wrappers, func(i, j int) bool {
+		return wrappers[i].Name < wrappers[j].Name
+	})
+
+	wrapperImports := map[string]string{"fmt": "fmt", "math/rand": "rand", "os": "os", "strconv": "strconv", "time": "time"}
+	for _, imports := range []map[string]string{providerImports, methodImports} {
+		for importPath, name := range imports {
+			wrapperImports[importPath] = name
+		}
+	}
+
+	data := struct {
+		Package            string
+		BuildTag           string
+		Imports            []string
+		PassthroughImports []string
+		Latency            string
+		ErrorRate          float64
+		Wrappers           []chaosWrapper
+	}{
+		Package:            outputPackage,
+		BuildTag:           chaosConfig.BuildTag,
+		Imports:            importSpecs(wrapperImports),
+		PassthroughImports: importSpecs(providerImports),
+		Latency:            chaosConfig.Latency,
+		ErrorRate:          chaosConfig.ErrorRate,
+		Wrappers:           wrappers,
+	}
+
+	wrapperPath, passthroughPath := ChaosFiles(g.config)
+	for _, file := range []struct{ template, path string }{
+		{"templates/chaos.tmpl", wrapperPath},
+		{"templates/chaos_off.tmpl", passthroughPath},
+	} {
+		tmplContent, err := readTemplate(g.config, file.template)
+		if err != nil {
+			return 0, fmt.Errorf("error reading chaos template: %w", err)
+		}
+
+		tmpl, err := template.New("chaos").Parse(string(tmplContent))
+		if err != nil {
+			return 0, fmt.Errorf("error parsing chaos template: %w", err)
+		}
+
+		var buf strings.Builder
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return 0, fmt.Errorf("error executing chaos template: %w", err)
+		}
+
+		if err := g.output.writeGeneratedFile(g.config.Root, file.path, buf.String()); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(wrappers), nil
+}
+
+// importSpecs renders import paths as sorted import specs, naming those not matching their base name
+func importSpecs(imports map[string]string) []string {
+	var specs []string
+	for importPath, name := range imports {
+		specs = append(specs, importSpec(name, importPath))
+	}
+	sort.Strings(specs)
+	return specs
+}
+
+// buildChaosWrapper resolves the provider's parameters and the methods of the interface it returns
+func (g *ChaosGenerator) buildChaosWrapper(provider scanner.ProviderFunction, files []*ast.File, outputPackage string, providerImports, methodImports map[string]string) (*chaosWrapper, error) {
+	if strings.ContainsAny(provider.ReturnType, "*.[") {
+		return nil, fmt.Errorf("return type %s must be an interface declared in package %s", provider.ReturnType, provider.Package)
+	}
+
+	fn, fnFile := findProviderDecl(files, provider.FunctionName)
+	if fn == nil {
+		return nil, fmt.Errorf("declaration not found")
+	}
+	iface, ifaceFile := findInterfaceDecl(files, provider.ReturnType)
+	if iface == nil {
+		return nil, fmt.Errorf("return type %s must be an interface declared in package %s", provider.ReturnType, provider.Package)
+	}
+
+	importPath := NewDependencyGenerator(g.config, g.output).deriveImportPath(provider.FilePath)
+	qualify := func(file *ast.File, imports map[string]string) *typeQualifier {
+		return &typeQualifier{
+			pkg:         provider.ImportName,
+			pkgImport:   importPath,
+			local:       provider.ImportName == outputPackage,
+			fileImports: fileImports(file),
+			imports:     imports,
+		}
+	}
+
+	params, args, err := qualify(fnFile, providerImports).params(fn.Type.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	ifaceRef, err := qualify(ifaceFile, providerImports).expr(ast.NewIdent(provider.ReturnType))
+	if err != nil {
+		return nil, err
+	}
+
+	wrapper := &chaosWrapper{
+		Name:         chaosProviderName(provider),
+		Provider:     provider.FunctionName,
+		Params:       params,
+		Args:         args,
+		Interface:    ifaceRef,
+		Struct:       "chaos" + upperFirst(provider.ImportName) + provider.ReturnType,
+		HasCleanup:   provider.HasCleanup,
+		ReturnsError: provider.ReturnsError,
+	}
+	if provider.ImportName != outputPackage {
+		wrapper.Provider = provider.ImportName + "." + provider.FunctionName
+	}
+
+	for _, field := range iface.Methods.List {
+		if len(field.Names) == 0 {
+			return nil, fmt.Errorf("embedded interfaces are not supported in %s", provider.ReturnType)
+		}
+		funcType := field.Type.(*ast.FuncType)
+
+		for _, name := range field.Names {
+			method := chaosMethod{
+				Name: name.Name,
+				Call: provider.ImportName + "." + provider.ReturnType + "." + name.Name,
+			}
+
+			q := qualify(ifaceFile, methodImports)
+			if method.Params, method.Args, err = q.params(funcType.Params); err != nil {
+				return nil, err
+			}
+			if method.Results, method.CanFail, err = q.results(funcType.Results); err != nil {
+				return nil, err
+			}
+			method.Returns = method.Results != ""
+			wrapper.Methods = append(wrapper.Methods, method)
+		}
+	}
+
+	return wrapper, nil
+}
+
+// parsePackageDir parses the non-test Go files of a package directory
+func parsePackageDir(dir string) ([]*ast.File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// findProviderDecl finds a package level function declaration and the file declaring it
+func findProviderDecl(files []*ast.File, name string) (*ast.FuncDecl, *ast.File) {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name {
+				return fn, file
+			}
+		}
+	}
+	return nil, nil
+}
+
+// findInterfaceDecl finds a non-generic interface type declaration and the file declaring it
+func findInterfaceDecl(files []*ast.File, name string) (*ast.InterfaceType, *ast.File) {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if ts.Name.Name != name || ts.TypeParams != nil {
+					continue
+				}
+				if iface, ok := ts.Type.(*ast.InterfaceType); ok {
+					return iface, file
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// majorVersionPattern matches the major version suffix of module paths, e.g., "v2"
+var majorVersionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+// fileImports maps the names a file refers to its imports by to their paths
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := path.Base(importPath)
+		if majorVersionPattern.MatchString(name) {
+			name = path.Base(path.Dir(importPath))
+		}
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+	return imports
+}
+
+// typeQualifier renders type expressions from a scanned package so they compile in the output package
+type typeQualifier struct {
+	pkg         string            // Name of the declaring package, e.g., "user"
+	pkgImport   string            // Import path of the declaring package
+	local       bool              // true if the declaring package is the output package
+	fileImports map[string]string // Imports of the declaring file, by name
+	imports     map[string]string // Imports needed by the generated file, path -> name
+}
+
+// use records an import needed by the generated file
+func (q *typeQualifier) use(name, importPath string) error {
+	for existingPath, existingName := range q.imports {
+		if existingName == name && existingPath != importPath {
+			return fmt.Errorf("packages %s and %s are both imported as %s", existingPath, importPath, name)
+		}
+	}
+	q.imports[importPath] = name
+	return nil
+}
+
+// expr renders a type expression
+func (q *typeQualifier) expr(e ast.Expr) (string, error) {
+	switch t := e.(type) {
+	case *ast.Ident:
+		if types.Universe.Lookup(t.Name) != nil || q.local {
+			return t.Name, nil
+		}
+		if !ast.IsExported(t.Name) {
+			return "", fmt.Errorf("unexported type %s.%s cannot be referenced from the output package", q.pkg, t.Name)
+		}
+		if err := q.use(q.pkg, q.pkgImport); err != nil {
+			return "", err
+		}
+		return q.pkg + "." + t.Name, nil
+	case *ast.SelectorExpr:
+		pkgIdent, ok := t.X.(*ast.Ident)
+		if !ok {
+			return "", fmt.Errorf("unsupported type expression")
+		}
+		importPath, ok := q.fileImports[pkgIdent.Name]
+		if !ok {
+			return "", fmt.Errorf("unknown package %s", pkgIdent.Name)
+		}
+		if err := q.use(pkgIdent.Name, importPath); err != nil {
+			return "", err
+		}
+		return pkgIdent.Name + "." + t.Sel.Name, nil
+	case *ast.StarExpr:
+		inner, err := q.expr(t.X)
+		return "*" + inner, err
+	case *ast.Ellipsis:
+		inner, err := q.expr(t.Elt)
+		return "..." + inner, err
+	case *ast.ArrayType:
+		inner, err := q.expr(t.Elt)
+		if t.Len == nil {
+			return "[]" + inner, err
+		}
+		length, ok := t.Len.(*ast.BasicLit)
+		if !ok {
+			return "", fmt.Errorf("unsupported array length")
+		}
+		return "[" + length.Value + "]" + inner, err
+	case *ast.MapType:
+		key, err := q.expr(t.Key)
+		if err != nil {
+			return "", err
+		}
+		value, err := q.expr(t.Value)
+		return "map[" + key + "]" + value, err
+	case *ast.ChanType:
+		inner, err := q.expr(t.Value)
+		switch t.Dir {
+		case ast.SEND:
+			return "chan<- " + inner, err
+		case ast.RECV:
+			return "<-chan " + inner, err
+		}
+		return "chan " + inner, err
+	case *ast.FuncType:
+		params, _, err := q.params(t.Params)
+		if err != nil {
+			return "", err
+		}
+		results, err := q.typeList(t.Results)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case len(results) == 0:
+			return "func(" + params + ")", nil
+		case len(results) == 1:
+			return "func(" + params + ") " + results[0], nil
+		}
+		return "func(" + params + ") (" + strings.Join(results, ", ") + ")", nil
+	case *ast.InterfaceType:
+		if len(t.Methods.List) == 0 {
+			return "interface{}", nil
+		}
+	case *ast.StructType:
+		if len(t.Fields.List) == 0 {
+			return "struct{}", nil
+		}
+	}
+	return "", fmt.Errorf("unsupported type expression")
+}
+
+// typeList renders every type of a field list, repeating grouped types, e.g. (a, b int) -> [int, int]
+func (q *typeQualifier) typeList(fields *ast.FieldList) ([]string, error) {
+	if fields == nil {
+		return nil, nil
+	}
+
+	var list []string
+	for _, field := range fields.List {
+		typeName, err := q.expr(field.Type)
+		if err != nil {
+			return nil, err
+		}
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			list = append(list, typeName)
+		}
+	}
+	return list, nil
+}
+
+// params renders a parameter list with generated names and the matching call arguments
+// e.g., (ctx context.Context, ids ...string) -> "p0 context.Context, p1 ...string", "p0, p1..."
+func (q *typeQualifier) params(fields *ast.FieldList) (string, string, error) {
+	typeNames, err := q.typeList(fields)
+	if err != nil {
+		return "", "", err
+	}
+
+	params := make([]string, len(typeNames))
+	args := make([]string, len(typeNames))
+	for i, typeName := range typeNames {
+		params[i] = fmt.Sprintf("p%d %s", i, typeName)
+		args[i] = fmt.Sprintf("p%d", i)
+		if strings.HasPrefix(typeName, "...") {
+			args[i] += "..."
+		}
+	}
+	return strings.Join(params, ", "), strings.Join(args, ", "), nil
+}
+
+// results renders a method result list, naming the results when the last one is an error
+// so the wrapper can return early with zero values
+func (q *typeQualifier) results(fields *ast.FieldList) (string, bool, error) {
+	typeNames, err := q.typeList(fields)
+	if err != nil {
+		return "", false, err
+	}
+
+	switch {
+	case len(typeNames) == 0:
+		return "", false, nil
+	case typeNames[len(typeNames)-1] != "error":
+		if len(typeNames) == 1 {
+			return typeNames[0], false, nil
+		}
+		return "(" + strings.Join(typeNames, ", ") + ")", false, nil
+	}
+
+	named := make([]string, len(typeNames))
+	for i, typeName := range typeNames[:len(typeNames)-1] {
+		named[i] = fmt.Sprintf("r%d %s", i, typeName)
+	}
+	named[len(named)-1] = "err error"
+	return "(" + strings.Join(named, ", ") + ")", true, nil
+}
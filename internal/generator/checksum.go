@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// checksumLabel starts the header line recording the checksum of a generated file, the line after
+// the taskw header, e.g. "// taskw:checksum sha256:9f86d0..."
+const checksumLabel = "taskw:checksum sha256:"
+
+// ErrEdited reports a generated file whose content no longer matches its recorded checksum
+var ErrEdited = errors.New("generated file was edited by hand")
+
+// stampChecksum adds the checksum line after the taskw header of content, so hand edits can be told apart
+// later. Content without the header is returned as is
+func stampChecksum(content []byte) []byte {
+	first, rest := cutLine(content)
+	header := string(first)
+	if !isGeneratedHeader(header) {
+		return content
+	}
+	if _, ok := recordedChecksum(rest); ok {
+		return content // Already stamped, e.g. by a template copying a generated file
+	}
+	if !strings.HasSuffix(header, "\n") {
+		header += "\n"
+	}
+
+	sum := sha256.Sum256(append([]byte(header), rest...))
+	line := checksumComment(header, checksumLabel+hex.EncodeToString(sum[:]))
+	return append([]byte(header+line), rest...)
+}
+
+// editedByHand reports whether content carries a checksum line that no longer matches the rest of it.
+// Files without one, e.g. written by older taskw versions, count as unedited
+func editedByHand(content []byte) bool {
+	header, rest := cutLine(content)
+	if !isGeneratedHeader(string(header)) {
+		return false
+	}
+	recorded, ok := recordedChecksum(rest)
+	if !ok {
+		return false
+	}
+	_, body := cutLine(rest)
+	sum := sha256.Sum256(append(bytes.Clone(header), body...))
+	return recorded != hex.EncodeToString(sum[:])
+}
+
+// checkEdited fails with ErrEdited when the generated file at path was edited by hand since taskw wrote it,
+// unless overwriting is forced
+func checkEdited(path string, force bool) error {
+	content, err := os.ReadFile(path)
+	if err != nil || !editedByHand(content) || force {
+		return nil
+	}
+	return fmt.Errorf("%w since taskw wrote it, move the changes out of it or rerun with --force to overwrite them", ErrEdited)
+}
+
+// recordedChecksum returns the checksum of the first line of content when it is a checksum line
+func recordedChecksum(content []byte) (string, bool) {
+	line, _ := cutLine(content)
+	text := strings.TrimSpace(string(line))
+	for _, prefix := range []string{"//", "#", "<!--"} {
+		if rest, ok := strings.CutPrefix(text, prefix); ok {
+			rest = strings.TrimSpace(strings.TrimSuffix(rest, "-->"))
+			return strings.CutPrefix(rest, checksumLabel)
+		}
+	}
+	return "", false
+}
+
+// checksumComment writes text as a line comment in the syntax of the header line
+func checksumComment(header, text string) string {
+	header = strings.TrimSpace(header)
+	switch {
+	case strings.HasPrefix(header, "#"):
+		return "# " + text + "\n"
+	case strings.HasPrefix(header, "<!--"):
+		return "<!-- " + text + " -->\n"
+	default:
+		return "// " + text + "\n"
+	}
+}
+
+// cutLine splits content after its first line break
+func cutLine(content []byte) ([]byte, []byte) {
+	if i := bytes.IndexByte(content, '\n'); i >= 0 {
+		return content[:i+1], content[i+1:]
+	}
+	return content, nil
+}
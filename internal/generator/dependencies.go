@@ -2,7 +2,6 @@ package generator
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -12,24 +11,55 @@ import (
 	"github.com/nkaewam/taskw/internal/scanner"
 )
 
-// DependencyGenerator generates Wire provider sets
+// DependencyGenerator generates Wire provider sets, fx modules or plain constructor wiring
 type DependencyGenerator struct {
-	config *config.Config
+	config        *config.Config
+	output        *Output
+	outputPackage string // Resolved by GenerateDependencies
 }
 
 // NewDependencyGenerator creates a new dependency generator
-func NewDependencyGenerator(cfg *config.Config) *DependencyGenerator {
+func NewDependencyGenerator(cfg *config.Config, out *Output) *DependencyGenerator {
 	return &DependencyGenerator{
 		config: cfg,
+		output: out,
 	}
 }
 
 // GenerateDependencies generates the dependencies_gen.go file
-func (g *DependencyGenerator) GenerateDependencies(providers []scanner.ProviderFunction) error {
+// Handler implementations are used to bind their interfaces to the concrete types returned by providers
+func (g *DependencyGenerator) GenerateDependencies(providers []scanner.ProviderFunction, implementations []scanner.HandlerImplementation) error {
 	if !g.config.Generation.Dependencies.Enabled {
 		return nil
 	}
 
+	outputPackage, err := outputPackageName(g.config)
+	if err != nil {
+		return err
+	}
+	g.outputPackage = outputPackage
+
+	bindings := g.findInterfaceBindings(providers, implementations)
+
+	// @ChaosWrap providers are replaced by their wrappers, which call them without the chaos build tag
+	if g.config.Generation.Chaos.Enabled {
+		providers = g.applyChaosWrappers(providers)
+	}
+
+	// Plain constructor wiring only needs the providers the router depends on
+	if g.config.DependencyBackend() == config.BackendPlain {
+		return g.generatePlainDependencies(providers, bindings)
+	}
+
+	// fx constructors cannot return cleanup functions, cleanup belongs in fx.Lifecycle hooks
+	if g.config.DependencyBackend() == config.BackendFx {
+		for _, provider := range providers {
+			if provider.HasCleanup {
+				return fmt.Errorf("provider %s.%s returns a cleanup function, which the fx backend does not support (register an fx.Lifecycle OnStop hook instead)", provider.Package, provider.FunctionName)
+			}
+		}
+	}
+
 	// Organize providers by package for better structure
 	providersByPackage := g.organizeProvidersByPackage(providers)
 
@@ -40,13 +70,13 @@ func (g *DependencyGenerator) GenerateDependencies(providers []scanner.ProviderF
 	outputPath := filepath.Join(g.config.Paths.OutputDir, g.config.Generation.Dependencies.OutputFile)
 
 	// Generate the file content
-	content, err := g.generateDependencyFileContent(providersByPackage, imports)
+	content, err := g.generateDependencyFileContent(providersByPackage, imports, bindings)
 	if err != nil {
 		return fmt.Errorf("error generating dependency file content: %w", err)
 	}
 
 	// Write to file
-	return writeGeneratedFile(outputPath, content)
+	return g.output.writeGeneratedFile(g.config.Root, outputPath, content)
 }
 
 // organizeProvidersByPackage groups providers by their package
@@ -54,7 +84,7 @@ func (g *DependencyGenerator) organizeProvidersByPackage(providers []scanner.Pro
 	providersByPackage := make(map[string][]scanner.ProviderFunction)
 
 	for _, provider := range providers {
-		providersByPackage[provider.Package] = append(providersByPackage[provider.Package], provider)
+		providersByPackage[provider.ImportName] = append(providersByPackage[provider.ImportName], provider)
 	}
 
 	// Sort providers within each package by function name for consistent output
@@ -72,6 +102,22 @@ func (g *DependencyGenerator) generateImports(providers []scanner.ProviderFuncti
 	imports := []string{
 		`"github.com/google/wire"`,
 	}
+	if g.config.DependencyBackend() == config.BackendPlain {
+		imports = nil
+	}
+	if g.config.DependencyBackend() == config.BackendFx {
+		imports = []string{`"go.uber.org/fx"`}
+		if g.hasFiberLifecycle(providers) {
+			// The lifecycle hooks are only generated for Fiber, which is always supported
+			framework, _ := lookupRouteFramework(g.config)
+			imports = append(imports, `"context"`, framework.Imports[0])
+			if logger := findLifecycleLogger(providers); logger != nil {
+				imports = append(imports, logger.Import)
+			} else {
+				imports = append(imports, `"log"`)
+			}
+		}
+	}
 
 	// Determine the output package name from the output directory
 	outputPackage := g.getOutputPackageName()
@@ -79,11 +125,11 @@ func (g *DependencyGenerator) generateImports(providers []scanner.ProviderFuncti
 	// Collect unique packages that need to be imported
 	packageSet := make(map[string]bool)
 	for _, provider := range providers {
-		if provider.Package != "" && provider.Package != outputPackage {
+		if provider.ImportName != "" && provider.ImportName != outputPackage {
 			// Derive the import path from the file path instead of making assumptions
 			importPath := g.deriveImportPath(provider.FilePath)
 			if importPath != "" {
-				packageSet[fmt.Sprintf(`"%s"`, importPath)] = true
+				packageSet[importSpec(provider.ImportName, importPath)] = true
 			}
 		}
 	}
@@ -93,71 +139,107 @@ func (g *DependencyGenerator) generateImports(providers []scanner.ProviderFuncti
 		imports = append(imports, pkg)
 	}
 
-	sort.Strings(imports[1:]) // Sort everything except wire import
+	if g.config.DependencyBackend() == config.BackendPlain {
+		sort.Strings(imports)
+		return imports
+	}
+
+	sort.Strings(imports[1:]) // Sort everything except the DI framework import
 	return imports
 }
 
-// deriveImportPath derives the full import path from a file path without hardcoded assumptions
+// deriveImportPath derives the full import path of a provider package from the directory of its file
 func (g *DependencyGenerator) deriveImportPath(filePath string) string {
-	// Get the directory containing the Go file
-	dir := filepath.Dir(filePath)
+	return g.config.PackageImportPath(filepath.Dir(filePath))
+}
 
-	// Get current working directory to establish project root
-	cwd, err := os.Getwd()
-	if err != nil {
-		// Fallback: use the path as-is and clean it up
-		dir = filepath.Clean(dir)
-		dir = filepath.ToSlash(dir)
-		dir = strings.TrimPrefix(dir, "./")
-		return fmt.Sprintf("%s/%s", g.config.Project.Module, dir)
-	}
+// providerSet is a wire.NewSet holding the providers and interface bindings of one package
+type providerSet struct {
+	Name      string // e.g., "UserProviderSet"
+	Package   string // e.g., "user"
+	Providers []scanner.ProviderFunction
+	Bindings  []interfaceBinding
+}
 
-	// Convert to absolute path if relative
-	if !filepath.IsAbs(dir) {
-		dir = filepath.Join(cwd, dir)
+// buildPackageSets groups providers and bindings into one provider set per package
+func (g *DependencyGenerator) buildPackageSets(providersByPackage map[string][]scanner.ProviderFunction, bindings []interfaceBinding) []providerSet {
+	var packages []string
+	for pkg := range providersByPackage {
+		packages = append(packages, pkg)
 	}
-
-	// Make the directory path relative to the project root (cwd)
-	relDir, err := filepath.Rel(cwd, dir)
-	if err != nil {
-		// Fallback: clean up the original path
-		dir = filepath.Clean(filepath.Dir(filePath))
-		dir = filepath.ToSlash(dir)
-		dir = strings.TrimPrefix(dir, "./")
-		return fmt.Sprintf("%s/%s", g.config.Project.Module, dir)
+	sort.Strings(packages)
+
+	sets := make([]providerSet, 0, len(packages))
+	for _, pkg := range packages {
+		set := providerSet{
+			Name:      upperFirst(pkg) + "ProviderSet",
+			Package:   pkg,
+			Providers: providersByPackage[pkg],
+		}
+		// Bindings live with their implementation so each set can be used on its own
+		for _, binding := range bindings {
+			if binding.pkg == pkg {
+				set.Bindings = append(set.Bindings, binding)
+			}
+		}
+		sets = append(sets, set)
 	}
+	return sets
+}
 
-	// Normalize path separators and clean up
-	relDir = filepath.ToSlash(relDir)
-	relDir = filepath.Clean(relDir)
-
-	// Remove any current directory references
-	if relDir == "." {
-		relDir = ""
+// generateDependencyFileContent creates the actual file content
+func (g *DependencyGenerator) generateDependencyFileContent(providersByPackage map[string][]scanner.ProviderFunction, imports []string, bindings []interfaceBinding) (string, error) {
+	var allProviders []scanner.ProviderFunction
+	for _, providers := range providersByPackage {
+		allProviders = append(allProviders, providers...)
 	}
 
-	// Construct the full import path with the module
-	if relDir == "" {
-		return g.config.Project.Module
+	style, err := lookupRouteStyle(g.config)
+	if err != nil {
+		return "", err
+	}
+	routesParam := lowerFirst(strings.TrimPrefix(style.Deps, "*"))
+	if style.Name == config.RouteStyleFunction {
+		routesParam = "deps"
 	}
-	return fmt.Sprintf("%s/%s", g.config.Project.Module, relDir)
-}
 
-// generateDependencyFileContent creates the actual file content
-func (g *DependencyGenerator) generateDependencyFileContent(providersByPackage map[string][]scanner.ProviderFunction, imports []string) (string, error) {
 	data := struct {
 		Package            string
 		Imports            []string
 		ProvidersByPackage map[string][]scanner.ProviderFunction
+		Bindings           []interfaceBinding
+		PackageSets        []providerSet
+		FiberLifecycle     bool
+		RegisterRoutes     bool
+		RoutesParam        string // Lifecycle parameter holding the handlers, e.g. "router *Router"
+		RegisterCall       string // Call registering the scanned routes, e.g. "router.RegisterHandlers()"
+		ListenAddr         string // Address the Fiber app listens on, e.g. ":3000"
+		Logger             *lifecycleLogger
 		GetProviderRef     func(pkg, functionName string) string
 	}{
 		Package:            g.getOutputPackageName(),
 		Imports:            imports,
 		ProvidersByPackage: providersByPackage,
+		Bindings:           bindings,
+		FiberLifecycle:     g.hasFiberLifecycle(allProviders),
+		RegisterRoutes:     g.providesType(allProviders, style.Deps),
+		RoutesParam:        routesParam + " " + style.Deps,
+		RegisterCall:       style.Call(style.Func, true, routesParam, "app"),
+		ListenAddr:         g.config.Generation.Dependencies.ListenAddr,
+		Logger:             findLifecycleLogger(allProviders),
 		GetProviderRef:     g.getProviderRef,
 	}
 
-	tmplContent, err := templateFS.ReadFile("templates/dependencies.tmpl")
+	if g.config.Generation.Dependencies.PerPackageSets {
+		data.PackageSets = g.buildPackageSets(providersByPackage, bindings)
+	}
+
+	templatePath := "templates/dependencies.tmpl"
+	if g.config.DependencyBackend() == config.BackendFx {
+		templatePath = "templates/dependencies_fx.tmpl"
+	}
+
+	tmplContent, err := readTemplate(g.config, templatePath)
 	if err != nil {
 		return "", fmt.Errorf("error reading dependency template: %w", err)
 	}
@@ -175,6 +257,246 @@ func (g *DependencyGenerator) generateDependencyFileContent(providersByPackage m
 	return buf.String(), nil
 }
 
+// generatePlainDependencies writes a BuildServer function calling providers in dependency order
+func (g *DependencyGenerator) generatePlainDependencies(providers []scanner.ProviderFunction, bindings []interfaceBinding) error {
+	graph, err := newDependencyGraph(providers)
+	if err != nil {
+		return fmt.Errorf("error building dependency graph: %w", err)
+	}
+
+	// Interface parameters are satisfied by the provider of the bound concrete type
+	for _, binding := range bindings {
+		graph.bind(binding.interfaceType, binding.concreteType)
+	}
+
+	// The value holding the handlers (the generated Router by default) is the root of the graph, like InitializeRouter for Wire
+	style, err := lookupRouteStyle(g.config)
+	if err != nil {
+		return err
+	}
+	order, err := graph.buildOrder(g.outputTypeKey(style.Deps))
+	if err != nil {
+		return fmt.Errorf("error resolving dependencies: %w", err)
+	}
+
+	needed := make([]scanner.ProviderFunction, 0, len(order))
+	for _, index := range order {
+		needed = append(needed, providers[index])
+	}
+
+	names := buildVariableNames(providers, order, sortedPackages(needed))
+	steps := make([]buildStep, 0, len(order))
+	var cleanups []string // Cleanup functions acquired so far, most recent first
+	for _, index := range order {
+		provider := providers[index]
+		deps, err := graph.dependencies(index)
+		if err != nil {
+			return fmt.Errorf("error building dependency graph: %w", err)
+		}
+
+		args := make([]string, 0, len(deps))
+		for _, dep := range deps {
+			args = append(args, names[dep])
+		}
+
+		step := buildStep{
+			Var:          names[index],
+			Call:         g.getProviderRef(provider.ImportName, provider.FunctionName),
+			Args:         args,
+			ReturnsError: provider.ReturnsError,
+			Unwind:       cleanups,
+			Doc:          provider.DocLines(),
+		}
+		if provider.HasCleanup {
+			// Prefer the provider's own casing, e.g. ProvideDB -> cleanupDB
+			step.Cleanup = "cleanup" + upperFirst(step.Var)
+			if lowerFirst(provider.BaseName) == step.Var {
+				step.Cleanup = "cleanup" + provider.BaseName
+			}
+			cleanups = append([]string{step.Cleanup}, cleanups...)
+		}
+		steps = append(steps, step)
+	}
+
+	data := struct {
+		Package    string
+		Imports    []string
+		Steps      []buildStep
+		Root       string
+		RootType   string
+		HasCleanup bool
+		Cleanups   []string
+	}{
+		Package:    g.getOutputPackageName(),
+		RootType:   style.Deps,
+		Imports:    g.generateImports(needed),
+		Steps:      steps,
+		Root:       steps[len(steps)-1].Var,
+		HasCleanup: len(cleanups) > 0,
+		Cleanups:   cleanups,
+	}
+
+	tmplContent, err := readTemplate(g.config, "templates/dependencies_plain.tmpl")
+	if err != nil {
+		return fmt.Errorf("error reading dependency template: %w", err)
+	}
+
+	tmpl, err := template.New("dependencies_plain").Funcs(template.FuncMap{"join": strings.Join}).Parse(string(tmplContent))
+	if err != nil {
+		return fmt.Errorf("error parsing dependency template: %w", err)
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("error executing dependency template: %w", err)
+	}
+
+	outputPath := filepath.Join(g.config.Paths.OutputDir, g.config.Generation.Dependencies.OutputFile)
+	return g.output.writeGeneratedFile(g.config.Root, outputPath, buf.String())
+}
+
+// applyChaosWrappers replaces @ChaosWrap providers with the wrapper providers generated in the output package
+func (g *DependencyGenerator) applyChaosWrappers(providers []scanner.ProviderFunction) []scanner.ProviderFunction {
+	wrapperPath, _ := ChaosFiles(g.config)
+
+	wrapped := make([]scanner.ProviderFunction, len(providers))
+	for i, provider := range providers {
+		wrapped[i] = provider
+		if !provider.ChaosWrap {
+			continue
+		}
+
+		// Types stay qualified with the original package so the dependency graph still matches them
+		parameters := make([]string, len(provider.Parameters))
+		for j, param := range provider.Parameters {
+			parameters[j] = scanner.QualifyType(provider.ImportName, param)
+		}
+
+		wrapped[i].FunctionName = chaosProviderName(provider)
+		wrapped[i].BaseName = upperFirst(provider.ImportName) + provider.BaseName
+		wrapped[i].Package = g.outputPackage
+		wrapped[i].ImportName = g.outputPackage
+		wrapped[i].ReturnType = scanner.QualifyType(provider.ImportName, provider.ReturnType)
+		wrapped[i].Parameters = parameters
+		wrapped[i].FilePath = wrapperPath
+		wrapped[i].ChaosWrap = false
+	}
+	return wrapped
+}
+
+// interfaceBinding binds a handler interface to the concrete type returned by a provider
+type interfaceBinding struct {
+	Interface     string // Reference in the generated file, e.g., "user.Handler"
+	Concrete      string // Reference in the generated file, e.g., "*user.HandlerImpl"
+	interfaceType string // Type keyed by import path, e.g., "example.com/shop/internal/user.Handler"
+	concreteType  string // Type keyed by import path, e.g., "*example.com/shop/internal/user.HandlerImpl"
+	pkg           string // Package of the provider returning the concrete type, e.g., "user"
+}
+
+// findInterfaceBindings detects providers returning a handler implementation whose interface
+// is consumed elsewhere, so the interface can be bound without a hand-written wire.Bind
+func (g *DependencyGenerator) findInterfaceBindings(providers []scanner.ProviderFunction, implementations []scanner.HandlerImplementation) []interfaceBinding {
+	// Types already provided directly never need a binding
+	provided := make(map[string]bool)
+	for _, provider := range providers {
+		provided[provider.ReturnKey()] = true
+	}
+
+	var bindings []interfaceBinding
+	bound := make(map[string]bool)
+	for _, provider := range providers {
+		structName := strings.TrimPrefix(provider.ReturnType, "*")
+		providerDir := filepath.Dir(provider.FilePath)
+
+		for _, impl := range implementations {
+			if impl.StructName != structName || filepath.Dir(impl.FilePath) != providerDir {
+				continue
+			}
+
+			// Only interfaces the scanner associated the implementation with are bound, any other
+			// interface of the package would be a guess (see ValidateInterfaceBindings)
+			interfaceName := impl.InterfaceName
+			if interfaceName == "" {
+				continue
+			}
+
+			interfaceType := provider.PackageTypeKey(interfaceName)
+			if provided[interfaceType] || bound[interfaceType] {
+				continue
+			}
+			bound[interfaceType] = true
+
+			prefix := strings.TrimSuffix(provider.ReturnType, structName)
+			bindings = append(bindings, interfaceBinding{
+				Interface:     g.getProviderRef(provider.ImportName, interfaceName),
+				Concrete:      prefix + g.getProviderRef(provider.ImportName, structName),
+				interfaceType: interfaceType,
+				concreteType:  provider.ReturnKey(),
+				pkg:           provider.ImportName,
+			})
+		}
+	}
+
+	sort.Slice(bindings, func(i, j int) bool {
+		return bindings[i].Interface < bindings[j].Interface
+	})
+	return bindings
+}
+
+// lifecycleLogger is a logger the fx lifecycle hook reports a failed Listen with
+type lifecycleLogger struct {
+	Import string // e.g., `"log/slog"`
+	Type   string // e.g., "*slog.Logger"
+	Error  string // Statement logging err, e.g. `logger.Error("fiber app stopped", "error", err)`
+}
+
+// lifecycleLoggers are the loggers taskw init scaffolds providers of, by provided type
+var lifecycleLoggers = map[string]lifecycleLogger{
+	"*log/slog.Logger": {
+		Import: `"log/slog"`,
+		Type:   "*slog.Logger",
+		Error:  `logger.Error("fiber app stopped", "error", err)`,
+	},
+	"*go.uber.org/zap.Logger": {
+		Import: `"go.uber.org/zap"`,
+		Type:   "*zap.Logger",
+		Error:  `logger.Error("fiber app stopped", zap.Error(err))`,
+	},
+	"*github.com/rs/zerolog.Logger": {
+		Import: `"github.com/rs/zerolog"`,
+		Type:   "*zerolog.Logger",
+		Error:  `logger.Error().Err(err).Msg("fiber app stopped")`,
+	},
+}
+
+// findLifecycleLogger returns the logger a provider returns, nil when there is none
+func findLifecycleLogger(providers []scanner.ProviderFunction) *lifecycleLogger {
+	for _, provider := range providers {
+		if logger, ok := lifecycleLoggers[provider.ReturnKey()]; ok {
+			return &logger
+		}
+	}
+	return nil
+}
+
+// hasFiberLifecycle checks if the fx module should manage a Fiber app's lifecycle
+// This requires targeting Fiber and a provider returning *fiber.App
+func (g *DependencyGenerator) hasFiberLifecycle(providers []scanner.ProviderFunction) bool {
+	return g.config.DependencyBackend() == config.BackendFx &&
+		g.config.RouteFramework() == config.FrameworkFiber &&
+		g.providesType(providers, "*fiber.App")
+}
+
+// providesType checks if any provider returns the given type
+func (g *DependencyGenerator) providesType(providers []scanner.ProviderFunction, typeName string) bool {
+	for _, provider := range providers {
+		if provider.ReturnType == typeName {
+			return true
+		}
+	}
+	return false
+}
+
 // getProviderRef generates the provider reference for Wire
 func (g *DependencyGenerator) getProviderRef(pkg, functionName string) string {
 	outputPackage := g.getOutputPackageName()
@@ -188,10 +510,16 @@ func (g *DependencyGenerator) getProviderRef(pkg, functionName string) string {
 	return fmt.Sprintf("%s.%s", pkg, functionName)
 }
 
-// getOutputPackageName determines the package name of the output file
+// outputTypeKey identifies a type declared in the output package, like scanner.ProviderFunction.ReturnKey
+func (g *DependencyGenerator) outputTypeKey(typeName string) string {
+	importPath := g.config.PackageImportPath(g.config.Paths.OutputDir)
+	if importPath == "" {
+		importPath = g.outputPackage
+	}
+	return scanner.QualifyType(importPath, typeName)
+}
+
+// getOutputPackageName returns the package name of the output file
 func (g *DependencyGenerator) getOutputPackageName() string {
-	// Extract package name from output directory
-	// e.g., "./internal/api" -> "api"
-	outputDir := g.config.Paths.OutputDir
-	return filepath.Base(outputDir)
+	return g.outputPackage
 }
@@ -0,0 +1,202 @@
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// buildStep is a single constructor call in the generated BuildServer function
+type buildStep struct {
+	Var          string   // Variable holding the provided value, e.g. "userService"
+	Call         string   // Provider reference, e.g. "user.ProvideService"
+	Args         []string // Variables passed to the provider in parameter order
+	ReturnsError bool
+	Cleanup      string   // Variable holding the provider's cleanup function, if any
+	Unwind       []string // Cleanup functions to call if this step fails, most recent first
+	Doc          []string // Lines of the provider's doc comment
+}
+
+// dependencyGraph links provider parameters to the providers of their types
+type dependencyGraph struct {
+	providers []scanner.ProviderFunction
+	byType    map[string]int // Return type keyed by import paths -> provider index, see scanner.ProviderFunction.ReturnKey
+}
+
+// newDependencyGraph indexes providers by the type they return
+// Returns an error if two providers return the same type, since the graph would be ambiguous
+func newDependencyGraph(providers []scanner.ProviderFunction) (*dependencyGraph, error) {
+	graph := &dependencyGraph{
+		providers: providers,
+		byType:    make(map[string]int),
+	}
+
+	for i, provider := range providers {
+		if existing, exists := graph.byType[provider.ReturnKey()]; exists {
+			return nil, fmt.Errorf("type %s is provided by both %s.%s and %s.%s",
+				scanner.QualifyType(provider.ImportName, provider.ReturnType),
+				providers[existing].ImportName, providers[existing].FunctionName, provider.ImportName, provider.FunctionName)
+		}
+		graph.byType[provider.ReturnKey()] = i
+	}
+
+	return graph, nil
+}
+
+// bind makes interfaceType resolvable through the provider of concreteType
+func (g *dependencyGraph) bind(interfaceType, concreteType string) {
+	if index, ok := g.byType[concreteType]; ok {
+		if _, exists := g.byType[interfaceType]; !exists {
+			g.byType[interfaceType] = index
+		}
+	}
+}
+
+// dependencies returns the provider indexes for each parameter of a provider
+func (g *dependencyGraph) dependencies(index int) ([]int, error) {
+	provider := g.providers[index]
+
+	deps := make([]int, 0, len(provider.Parameters))
+	for i, param := range provider.Parameters {
+		dep, ok := g.byType[provider.ParameterKey(i)]
+		if !ok {
+			return nil, fmt.Errorf("no provider found for %s (parameter of %s.%s)",
+				scanner.QualifyType(provider.ImportName, param), provider.ImportName, provider.FunctionName)
+		}
+		deps = append(deps, dep)
+	}
+
+	return deps, nil
+}
+
+// buildOrder returns the providers needed to construct rootType, dependencies first
+func (g *dependencyGraph) buildOrder(rootType string) ([]int, error) {
+	root, ok := g.byType[rootType]
+	if !ok {
+		return nil, fmt.Errorf("no provider found for %s (is route generation enabled?)", rootType)
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[int]int)
+	var order []int
+	var path []string
+
+	var visit func(index int) error
+	visit = func(index int) error {
+		provider := g.providers[index]
+		name := provider.ImportName + "." + provider.FunctionName
+
+		switch state[index] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle detected: %s -> %s", strings.Join(path, " -> "), name)
+		}
+
+		state[index] = visiting
+		path = append(path, name)
+
+		deps, err := g.dependencies(index)
+		if err != nil {
+			return err
+		}
+		for _, dep := range deps {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[index] = visited
+		order = append(order, index)
+		return nil
+	}
+
+	if err := visit(root); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// buildVariableNames assigns a unique, readable variable name to every provider in order
+// e.g., ProvideUserService -> userService, with the package name prepended on conflicts
+func buildVariableNames(providers []scanner.ProviderFunction, order []int, reserved []string) map[int]string {
+	taken := map[string]bool{"err": true}
+	for _, name := range reserved {
+		taken[name] = true
+	}
+
+	names := make(map[int]string, len(order))
+	for _, index := range order {
+		provider := providers[index]
+		// Chaos wrappers are named after the provider they wrap, e.g. provideChaosUserStore -> userStore
+		base := lowerFirst(provider.BaseName)
+
+		candidates := []string{base, provider.ImportName + upperFirst(base)}
+		name := ""
+		for _, candidate := range candidates {
+			if candidate != "" && !taken[candidate] && !token.IsKeyword(candidate) {
+				name = candidate
+				break
+			}
+		}
+		for i := 2; name == ""; i++ {
+			if candidate := fmt.Sprintf("%s%d", candidates[1], i); !taken[candidate] {
+				name = candidate
+			}
+		}
+
+		taken[name] = true
+		names[index] = name
+	}
+
+	return names
+}
+
+// lowerFirst lowercases the first letter of an identifier, or its whole leading acronym
+// e.g., "UserService" -> "userService", "DB" -> "db", "HTTPClient" -> "httpClient"
+func lowerFirst(s string) string {
+	runes := []rune(s)
+	for i := range runes {
+		if !unicode.IsUpper(runes[i]) {
+			break
+		}
+		// Keep the last capital of an acronym followed by a lowercase word
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// upperFirst uppercases the first letter of an identifier
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// sortedPackages returns the distinct package names of the given providers
+func sortedPackages(providers []scanner.ProviderFunction) []string {
+	seen := make(map[string]bool)
+	var packages []string
+	for _, provider := range providers {
+		if !seen[provider.ImportName] {
+			seen[provider.ImportName] = true
+			packages = append(packages, provider.ImportName)
+		}
+	}
+	sort.Strings(packages)
+	return packages
+}
@@ -0,0 +1,181 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/nkaewam/taskw/internal/config"
+)
+
+// EnvelopeGenerator generates helpers wrapping JSON responses in a data/error/meta envelope
+type EnvelopeGenerator struct {
+	config *config.Config
+	output *Output
+}
+
+// NewEnvelopeGenerator creates a new response envelope generator
+func NewEnvelopeGenerator(cfg *config.Config, out *Output) *EnvelopeGenerator {
+	return &EnvelopeGenerator{
+		config: cfg,
+		output: out,
+	}
+}
+
+// EnvelopeFile returns the path of the generated envelope helpers
+func EnvelopeFile(cfg *config.Config) string {
+	return filepath.Join(cfg.Generation.Envelope.PackageDir, cfg.Generation.Envelope.OutputFile)
+}
+
+// GenerateEnvelope writes the envelope helpers into the configured package
+func (g *EnvelopeGenerator) GenerateEnvelope() error {
+	envelope := g.config.Generation.Envelope
+	if !envelope.Enabled {
+		return nil
+	}
+
+	fields := map[string]string{
+		"data_field":  envelope.DataField,
+		"error_field": envelope.ErrorField,
+		"meta_field":  envelope.MetaField,
+	}
+	seen := make(map[string]string)
+	for _, key := range []string{"data_field", "error_field", "meta_field"} {
+		field := fields[key]
+		if field == "" || strings.ContainsAny(field, `",`) {
+			return fmt.Errorf("generation.envelope.%s %q is not a valid JSON field name", key, field)
+		}
+		if other, exists := seen[field]; exists {
+			return fmt.Errorf("generation.envelope.%s and generation.envelope.%s are both %q", other, key, field)
+		}
+		seen[field] = key
+	}
+
+	packageName, err := packageNameForDir(g.config, envelope.PackageDir)
+	if err != nil {
+		return fmt.Errorf("error determining envelope package: %w", err)
+	}
+
+	tmplContent, err := readTemplate(g.config, "templates/envelope.tmpl")
+	if err != nil {
+		return fmt.Errorf("error reading envelope template: %w", err)
+	}
+
+	tmpl, err := template.New("envelope").Parse(string(tmplContent))
+	if err != nil {
+		return fmt.Errorf("error parsing envelope template: %w", err)
+	}
+
+	data := struct {
+		Package string
+		Config  config.EnvelopeConfig
+	}{
+		Package: packageName,
+		Config:  envelope,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("error executing envelope template: %w", err)
+	}
+
+	return g.output.writeGeneratedFile(g.config.Root, EnvelopeFile(g.config), buf.String())
+}
+
+// applyResponseEnvelope wraps the response schemas of every operation in the envelope:
+// successful schemas move under the data field, failed responses document the error object
+// Responses already documented with the envelope type are left untouched
+func applyResponseEnvelope(spec map[string]interface{}, envelope config.EnvelopeConfig, packageName string) {
+	responseRef := "#/definitions/" + packageName + ".Response"
+	errorName := packageName + ".Error"
+	errorRef := map[string]interface{}{"$ref": "#/definitions/" + errorName}
+
+	definitions, _ := spec["definitions"].(map[string]interface{})
+	if definitions == nil {
+		definitions = make(map[string]interface{})
+		spec["definitions"] = definitions
+	}
+	if _, exists := definitions[errorName]; !exists {
+		definitions[errorName] = map[string]interface{}{
+			"type":     "object",
+			"required": []interface{}{"code", "message"},
+			"properties": map[string]interface{}{
+				"code":    map[string]interface{}{"type": "string"},
+				"message": map[string]interface{}{"type": "string"},
+				"details": map[string]interface{}{},
+			},
+		}
+	}
+
+	paths, _ := spec["paths"].(map[string]interface{})
+	for _, pathItem := range paths {
+		operations, _ := pathItem.(map[string]interface{})
+		for method, operation := range operations {
+			if method == "parameters" || strings.HasPrefix(method, "x-") {
+				continue
+			}
+			op, _ := operation.(map[string]interface{})
+			responses, _ := op["responses"].(map[string]interface{})
+			for code, response := range responses {
+				resp, _ := response.(map[string]interface{})
+				if resp == nil {
+					continue
+				}
+
+				schema, hasSchema := resp["schema"]
+				if hasSchema && referencesSchema(schema, responseRef) {
+					continue
+				}
+
+				if isSuccessStatus(code) {
+					if !hasSchema {
+						continue // e.g. 204 No Content
+					}
+					resp["schema"] = map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							envelope.DataField: schema,
+							envelope.MetaField: map[string]interface{}{"type": "object"},
+						},
+					}
+					continue
+				}
+
+				resp["schema"] = map[string]interface{}{
+					"type":     "object",
+					"required": []interface{}{envelope.ErrorField},
+					"properties": map[string]interface{}{
+						envelope.ErrorField: errorRef,
+					},
+				}
+			}
+		}
+	}
+}
+
+// referencesSchema checks if a schema is, or is composed from, the referenced definition
+func referencesSchema(schema interface{}, ref string) bool {
+	object, _ := schema.(map[string]interface{})
+	if object == nil {
+		return false
+	}
+	if object["$ref"] == ref {
+		return true
+	}
+	allOf, _ := object["allOf"].([]interface{})
+	for _, part := range allOf {
+		if referencesSchema(part, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSuccessStatus checks if a response code is 1xx, 2xx or 3xx, "default" counts as a failure
+func isSuccessStatus(code string) bool {
+	status, err := strconv.Atoi(code)
+	return err == nil && status < 400
+}
@@ -0,0 +1,176 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
+)
+
+// routeFramework describes how routes are registered for a specific web framework
+type routeFramework struct {
+	Name         string                     // e.g., "fiber"
+	Template     string                     // Route template inside templates/
+	Imports      []string                   // Imports required by the route template
+	ConvertPath  func(path string) string   // Converts swagger paths to the router's syntax
+	RouterMethod func(method string) string // Maps HTTP methods to router method names
+	AppType      string                     // Application type the router registers on, e.g. "*fiber.App"
+	AppImport    string                     // Import declaring AppType
+	AppParam     string                     // Name of the application in generated code, e.g. "app"
+	RouterOnly   []string                   // Imports only the Router struct of the router style needs
+}
+
+// routeFrameworks contains all supported route generation backends
+var routeFrameworks = map[string]routeFramework{
+	config.FrameworkFiber: {
+		Name:         config.FrameworkFiber,
+		Template:     "templates/routes.tmpl",
+		Imports:      []string{`"github.com/gofiber/fiber/v2"`},
+		ConvertPath:  convertPathToColonParams,
+		RouterMethod: fiberRouterMethod,
+		AppType:      "*fiber.App",
+		AppImport:    `"github.com/gofiber/fiber/v2"`,
+		AppParam:     "app",
+	},
+	config.FrameworkGin: {
+		Name:         config.FrameworkGin,
+		Template:     "templates/routes_gin.tmpl",
+		Imports:      []string{`"github.com/gin-gonic/gin"`},
+		ConvertPath:  convertPathToColonParams,
+		RouterMethod: ginRouterMethod,
+		AppType:      "*gin.Engine",
+		AppImport:    `"github.com/gin-gonic/gin"`,
+		AppParam:     "engine",
+	},
+	config.FrameworkNetHTTP: {
+		Name:         config.FrameworkNetHTTP,
+		Template:     "templates/routes_nethttp.tmpl",
+		Imports:      []string{`"net/http"`},
+		ConvertPath:  convertPathToBraceParams,
+		RouterMethod: strings.ToUpper,
+		AppType:      "*http.ServeMux",
+		AppImport:    `"net/http"`,
+		AppParam:     "mux",
+	},
+	config.FrameworkChi: {
+		Name:         config.FrameworkChi,
+		Template:     "templates/routes_chi.tmpl",
+		Imports:      []string{`"github.com/go-chi/chi/v5"`},
+		ConvertPath:  convertPathForChi,
+		RouterMethod: chiRouterMethod,
+		AppType:      "*chi.Mux",
+		AppImport:    `"github.com/go-chi/chi/v5"`,
+		AppParam:     "router",
+		RouterOnly:   []string{`"fmt"`, `"net/http"`}, // Middleware registry of the Router
+	},
+}
+
+// lookupRouteFramework returns the configured route framework, an error for a framework taskw doesn't support
+func lookupRouteFramework(cfg *config.Config) (routeFramework, error) {
+	framework, ok := routeFrameworks[cfg.RouteFramework()]
+	if !ok {
+		return routeFramework{}, fmt.Errorf("unknown generation.routes.framework %q", cfg.Generation.Routes.Framework)
+	}
+
+	// Fiber v3 keeps the router API but moves to a new module path
+	if framework.Name == config.FrameworkFiber && cfg.FiberVersion() == 3 {
+		framework.Imports = []string{`"github.com/gofiber/fiber/v3"`}
+		framework.AppImport = `"github.com/gofiber/fiber/v3"`
+	}
+
+	return framework, nil
+}
+
+// convertPathToColonParams converts OpenAPI/Swagger path parameters to :param format
+// Used by both Fiber and Gin routers
+func convertPathToColonParams(path string) string {
+	converted := strings.ReplaceAll(path, "{", ":")
+	converted = strings.ReplaceAll(converted, "}", "")
+	return converted
+}
+
+// convertPathToBraceParams converts path parameters to the {param} format used by ServeMux and chi
+// e.g., /users/:id -> /users/{id} and /files/* -> /files/{path...}
+func convertPathToBraceParams(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+		case segment == "*":
+			segments[i] = "{path...}"
+		case strings.HasPrefix(segment, "*"):
+			segments[i] = "{" + strings.TrimPrefix(segment, "*") + "...}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// convertPathForChi converts path parameters to chi's {param} format, keeping chi's bare * wildcard
+func convertPathForChi(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+		} else if strings.HasPrefix(segment, "*") {
+			segments[i] = "*"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// FormatRoutePath converts a scanned route path to the syntax of the configured framework, the path is
+// returned as scanned for a framework taskw doesn't support
+func FormatRoutePath(cfg *config.Config, path string) string {
+	framework, err := lookupRouteFramework(cfg)
+	if err != nil {
+		return path
+	}
+	return framework.ConvertPath(path)
+}
+
+// fiberRouterMethod maps HTTP methods to Fiber router methods
+func fiberRouterMethod(method string) string {
+	switch strings.ToUpper(method) {
+	case "GET":
+		return "Get"
+	case "POST":
+		return "Post"
+	case "PUT":
+		return "Put"
+	case "DELETE":
+		return "Delete"
+	case "PATCH":
+		return "Patch"
+	case "HEAD":
+		return "Head"
+	case "OPTIONS":
+		return "Options"
+	default:
+		return "All" // Fallback for unsupported methods
+	}
+}
+
+// ginRouterMethod maps HTTP methods to Gin router methods
+func ginRouterMethod(method string) string {
+	switch strings.ToUpper(method) {
+	case "GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS":
+		return strings.ToUpper(method)
+	default:
+		return "Any" // Fallback for unsupported methods
+	}
+}
+
+// chiRouterMethod maps HTTP methods to chi router methods
+func chiRouterMethod(method string) string {
+	switch strings.ToUpper(method) {
+	case "CONNECT":
+		return "Connect"
+	case "TRACE":
+		return "Trace"
+	case "GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS":
+		return fiberRouterMethod(method) // Same Get/Post/... naming as Fiber
+	default:
+		return "HandleFunc" // Fallback matching all methods
+	}
+}
@@ -0,0 +1,296 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// gatewayImportNames are the names the gateway template imports packages under, message packages
+// get another name when they share one of them
+var gatewayImportNames = []string{"context", "fmt", "strconv", "fiber", "codes", "metadata", "status", "protojson", "proto", "protoreflect"}
+
+// GatewayGenerator generates the Fiber routes serving @RPC annotated gRPC methods over HTTP, from
+// generation.gateway
+type GatewayGenerator struct {
+	config    *config.Config
+	output    *Output
+	framework routeFramework
+}
+
+// NewGatewayGenerator creates a new gateway generator
+func NewGatewayGenerator(cfg *config.Config, out *Output) (*GatewayGenerator, error) {
+	framework, err := lookupRouteFramework(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &GatewayGenerator{
+		config:    cfg,
+		output:    out,
+		framework: framework,
+	}, nil
+}
+
+// GatewayFile returns the path of the generated gateway
+func GatewayFile(cfg *config.Config) string {
+	return filepath.Join(cfg.Paths.OutputDir, cfg.Generation.Gateway.OutputFile)
+}
+
+// GatewayServer is a gRPC service implementation the gateway is constructed with
+type GatewayServer struct {
+	FieldName string // e.g., "userServer"
+	TypeName  string // e.g., "*user.Server"
+}
+
+// GatewayRoute is the Fiber route serving a gRPC method
+type GatewayRoute struct {
+	Handler     string   // Gateway method serving the route, e.g. "userServerGetUser"
+	Field       string   // Field of the service implementation, e.g. "userServer"
+	MethodName  string   // e.g., "GetUser"
+	Ref         string   // e.g., "user.Server.GetUser"
+	Register    string   // Registration on the app, e.g. `Get("/users/:id"`
+	Path        string   // Path in Fiber's syntax, e.g. "/users/:id"
+	HTTPMethod  string   // e.g., "GET"
+	PathParams  []string // e.g., ["id"]
+	RequestType string   // Request message without the pointer, e.g. "userv1.GetUserRequest"
+	Summary     string
+}
+
+// GenerateGateway writes the Gateway struct, ProvideGateway and one route per gRPC method into the
+// output package. Routes are passed to report paths served by both a handler and the gateway
+func (g *GatewayGenerator) GenerateGateway(rpcs []scanner.RPCMethod, routes []scanner.RouteMapping) error {
+	if !g.config.Generation.Gateway.Enabled {
+		return nil
+	}
+
+	// The gateway converts requests with the Fiber v2 API
+	if g.framework.Name != config.FrameworkFiber {
+		return fmt.Errorf("gateway generation is only supported for the fiber framework, got %q", g.framework.Name)
+	}
+	if g.config.FiberVersion() != 2 {
+		return fmt.Errorf("gateway generation requires fiber v2, got fiber v%d", g.config.FiberVersion())
+	}
+
+	outputPackage, err := outputPackageName(g.config)
+	if err != nil {
+		return err
+	}
+
+	servers, gatewayRoutes, imports, err := g.gatewayRoutes(rpcs, routes)
+	if err != nil {
+		return err
+	}
+
+	tmplContent, err := readTemplate(g.config, "templates/gateway.tmpl")
+	if err != nil {
+		return fmt.Errorf("error reading gateway template: %w", err)
+	}
+
+	tmpl, err := template.New("gateway").Parse(string(tmplContent))
+	if err != nil {
+		return fmt.Errorf("error parsing gateway template: %w", err)
+	}
+
+	data := struct {
+		Package string
+		Imports []string
+		Servers []GatewayServer
+		Routes  []GatewayRoute
+	}{
+		Package: outputPackage,
+		Imports: imports,
+		Servers: servers,
+		Routes:  gatewayRoutes,
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("error executing gateway template: %w", err)
+	}
+
+	return g.output.writeGeneratedFile(g.config.Root, GatewayFile(g.config), buf.String())
+}
+
+// gatewayRoutes returns the service implementations the gateway is constructed with, its routes in
+// registration order, and the imports of the service and message packages
+func (g *GatewayGenerator) gatewayRoutes(rpcs []scanner.RPCMethod, routes []scanner.RouteMapping) ([]GatewayServer, []GatewayRoute, []string, error) {
+	outputDir := filepath.Clean(g.config.Paths.OutputDir)
+	outputImportPath := g.config.PackageImportPath(outputDir)
+
+	// Handlers keep their paths, the gateway can't serve them too
+	handled := make(map[string]string, len(routes))
+	for _, route := range routes {
+		handled[route.HTTPMethod+" "+g.framework.ConvertPath(route.Path)] = route.RouteID()
+	}
+
+	// Packages sharing a name are told apart in the order of their declarations, not of the scan
+	rpcs = append([]scanner.RPCMethod{}, rpcs...)
+	sort.Slice(rpcs, func(i, j int) bool {
+		if rpcs[i].FilePath != rpcs[j].FilePath {
+			return rpcs[i].FilePath < rpcs[j].FilePath
+		}
+		return rpcs[i].Line < rpcs[j].Line
+	})
+
+	aliases := newImportAliases(gatewayImportNames)
+	serverFields := make(map[string]GatewayServer)
+	served := make(map[string]string)
+	var gatewayRoutes []GatewayRoute
+
+	for _, rpc := range rpcs {
+		ref := rpc.ImportName + "." + rpc.ServerName + "." + rpc.MethodName
+		path := g.framework.ConvertPath(rpc.Path)
+		key := rpc.HTTPMethod + " " + path
+		if other, exists := handled[key]; exists {
+			return nil, nil, nil, fmt.Errorf("%s is served by both the handler %s and the gRPC method %s", key, other, ref)
+		}
+		if other, exists := served[key]; exists {
+			return nil, nil, nil, fmt.Errorf("%s is served by both the gRPC methods %s and %s", key, other, ref)
+		}
+		served[key] = ref
+
+		// The service implementation is referred to by its package, unless it is the output package
+		serverPackage := ""
+		if dir := filepath.Clean(filepath.Dir(rpc.FilePath)); dir != outputDir {
+			serverPackage = aliases.name(rpc.ImportName, g.config.PackageImportPath(dir))
+		}
+		field := lowerFirst(rpc.ImportName) + rpc.ServerName
+		if _, exists := serverFields[field]; !exists {
+			serverFields[field] = GatewayServer{FieldName: field, TypeName: "*" + qualify(serverPackage, rpc.ServerName)}
+		}
+
+		// Responses are written as any proto.Message, only the request type is referred to
+		requestType, err := g.messageType(rpc, rpc.RequestType, serverPackage, outputImportPath, aliases)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		register := fmt.Sprintf("%s(%s", fiberRouterMethod(rpc.HTTPMethod), strconv.Quote(path))
+		if !routeMethodNames[rpc.HTTPMethod] {
+			register = fmt.Sprintf("Add(%s, %s", strconv.Quote(rpc.HTTPMethod), strconv.Quote(path))
+		}
+
+		gatewayRoutes = append(gatewayRoutes, GatewayRoute{
+			Handler:     field + rpc.MethodName,
+			Field:       field,
+			MethodName:  rpc.MethodName,
+			Ref:         ref,
+			Register:    register,
+			Path:        path,
+			HTTPMethod:  rpc.HTTPMethod,
+			PathParams:  rpc.PathParams,
+			RequestType: strings.TrimPrefix(requestType, "*"),
+			Summary:     rpc.Summary,
+		})
+	}
+
+	// More specific routes first, like the generated router
+	routeGen, err := NewRouteGenerator(g.config, g.output)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sort.SliceStable(gatewayRoutes, func(i, j int) bool {
+		scoreA, scoreB := routeGen.calculateSpecificityScore(gatewayRoutes[i].Path), routeGen.calculateSpecificityScore(gatewayRoutes[j].Path)
+		if scoreA != scoreB {
+			return scoreA > scoreB
+		}
+		if gatewayRoutes[i].HTTPMethod != gatewayRoutes[j].HTTPMethod {
+			return gatewayRoutes[i].HTTPMethod < gatewayRoutes[j].HTTPMethod
+		}
+		return gatewayRoutes[i].Path < gatewayRoutes[j].Path
+	})
+
+	servers := make([]GatewayServer, 0, len(serverFields))
+	for _, server := range serverFields {
+		servers = append(servers, server)
+	}
+	sort.Slice(servers, func(i, j int) bool {
+		return servers[i].FieldName < servers[j].FieldName
+	})
+
+	return servers, gatewayRoutes, aliases.imports(), nil
+}
+
+// messageType requalifies the request type of a gRPC method with the name the gateway imports its
+// package under, e.g. "*userv1.GetUserRequest", or "*userv12.GetUserRequest" when another package
+// took the name
+func (g *GatewayGenerator) messageType(rpc scanner.RPCMethod, typeName, serverPackage, outputImportPath string, aliases *importAliases) (string, error) {
+	qualifier, name, qualified := strings.Cut(strings.TrimPrefix(typeName, "*"), ".")
+	if !qualified {
+		return "", fmt.Errorf("unsupported message type %s of %s.%s", typeName, rpc.ServerName, rpc.MethodName)
+	}
+
+	// Messages declared next to the service implementation, e.g. in a package generated by protoc
+	if qualifier == rpc.Package {
+		return "*" + qualify(serverPackage, name), nil
+	}
+
+	importPath := rpc.ImportPath(qualifier)
+	if importPath == "" {
+		return "", fmt.Errorf("can't find the package %s of %s in the imports of %s, import it by name, e.g. %s \"github.com/acme/api/gen/...\"", qualifier, typeName, rpc.FilePath, qualifier)
+	}
+	if importPath == outputImportPath {
+		return "*" + name, nil
+	}
+	return "*" + qualify(aliases.name(qualifier, importPath), name), nil
+}
+
+// routeMethodNames are the methods Fiber's router has a method for, e.g. app.Get
+var routeMethodNames = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "DELETE": true, "PATCH": true, "HEAD": true, "OPTIONS": true,
+}
+
+// qualify refers to a type by its package, unqualified for the package generated code is declared in
+func qualify(pkg, name string) string {
+	if pkg == "" {
+		return name
+	}
+	return pkg + "." + name
+}
+
+// importAliases names the packages generated code imports, one name per import path, preferring the
+// name the package is referred to by in the scanned sources
+type importAliases struct {
+	byPath map[string]string
+	taken  map[string]bool
+}
+
+// newImportAliases creates import aliases that never use the reserved names
+func newImportAliases(reserved []string) *importAliases {
+	aliases := &importAliases{byPath: make(map[string]string), taken: make(map[string]bool)}
+	for _, name := range reserved {
+		aliases.taken[name] = true
+	}
+	return aliases
+}
+
+// name returns the name an import path is imported under, e.g. "userv1", or "userv12" when another
+// import path already took "userv1"
+func (a *importAliases) name(preferred, importPath string) string {
+	if name, exists := a.byPath[importPath]; exists {
+		return name
+	}
+	name := preferred
+	for i := 2; a.taken[name]; i++ {
+		name = preferred + strconv.Itoa(i)
+	}
+	a.byPath[importPath] = name
+	a.taken[name] = true
+	return name
+}
+
+// imports returns the import statements of the named packages, sorted
+func (a *importAliases) imports() []string {
+	var imports []string
+	for importPath, name := range a.byPath {
+		imports = append(imports, importSpec(name, importPath))
+	}
+	sort.Strings(imports)
+	return imports
+}
@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
+)
+
+// GeneratedMarker starts the header of every file taskw generates, after the comment syntax of the
+// file type: "// Code generated by taskw. DO NOT EDIT." in Go files, "# ..." in YAML and "<!-- ... -->"
+// in Markdown. Removing the header hands the file over to its author, taskw clean leaves it alone
+const GeneratedMarker = "Code generated by taskw"
+
+// skippedDirs are never searched for generated files, besides hidden directories such as .git
+var skippedDirs = map[string]bool{
+	"vendor":       true,
+	"node_modules": true,
+	"testdata":     true,
+}
+
+// IsGeneratedFile reports whether the first line of a file is a taskw header
+func IsGeneratedFile(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	line, err := bufio.NewReader(file).ReadString('\n')
+	if line == "" && err != nil {
+		return false, nil // Empty
+	}
+	return isGeneratedHeader(line), nil
+}
+
+// handWritten reports whether a file taskw is about to write exists without the taskw header, either
+// written by hand or handed over by removing the header, so it must not be overwritten. Generated files
+// edited by hand are caught when they are written instead (see checkEdited)
+func handWritten(root, path string) bool {
+	generated, err := IsGeneratedFile(config.JoinRoot(root, path))
+	return err == nil && !generated
+}
+
+// isGeneratedHeader reports whether a line is a taskw header in any comment syntax
+func isGeneratedHeader(line string) bool {
+	line = strings.TrimSpace(line)
+	for _, prefix := range []string{"//", "#", "<!--"} {
+		if rest, ok := strings.CutPrefix(line, prefix); ok {
+			rest = strings.TrimSpace(rest)
+			return strings.HasPrefix(rest, GeneratedMarker) && strings.Contains(rest, "DO NOT EDIT")
+		}
+	}
+	return false
+}
+
+// FindGeneratedFiles returns the files under root bearing the taskw header, wherever the settings of
+// the run that wrote them put them. Hidden, vendor, node_modules and testdata directories are skipped
+func FindGeneratedFiles(root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			name := entry.Name()
+			if path != root && (strings.HasPrefix(name, ".") || skippedDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		generated, err := IsGeneratedFile(path)
+		if err != nil {
+			return err
+		}
+		if generated {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
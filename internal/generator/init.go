@@ -1,18 +1,59 @@
 package generator
 
 import (
+	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
+	"time"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/tools"
 )
 
 //go:embed templates/init
 var initTemplateFS embed.FS
 
+// InitOptions controls optional behavior of project initialization
+type InitOptions struct {
+	NoExec bool // Skip running external commands (go mod tidy, task generate)
+	Git    bool // Initialize a git repository with a .gitignore and an initial commit
+	// Batteries adds request validation, an error model, standard middleware and an example CRUD domain
+	Batteries bool
+	Logger    string // Logging library of the logger provider and request logging middleware, one of Loggers
+
+	GoProxy   string // GOPROXY for go mod tidy and task generate, e.g. a corporate proxy, empty keeps the environment's
+	GoPrivate string // GOPRIVATE for go mod tidy and task generate, patterns of modules fetched without the proxy
+	Offline   bool   // Resolve dependencies from the module cache only, deferring them when it lacks some
+
+	ToolTimeout time.Duration // How long each external command may run, tools.timeout, zero for no limit
+}
+
+// Retries of go mod tidy when it fails, e.g. on a proxy timing out, the delay doubling each time
+const (
+	tidyAttempts   = 3
+	tidyRetryDelay = 2 * time.Second
+)
+
+// Loggers are the logging libraries init can wire, the first is the default
+var Loggers = []string{"slog", "zap", "zerolog"}
+
+// loggerTypes are the types ProvideLogger returns, by logging library
+var loggerTypes = map[string]struct{ importPath, typeName string }{
+	"slog":    {"log/slog", "*slog.Logger"},
+	"zap":     {"go.uber.org/zap", "*zap.Logger"},
+	"zerolog": {"github.com/rs/zerolog", "*zerolog.Logger"},
+}
+
+// errDependenciesDeferred reports that an offline init left dependency resolution for later
+var errDependenciesDeferred = errors.New("dependencies are not in the module cache")
+
 // InitGenerator creates new projects from templates
 type InitGenerator struct{}
 
@@ -21,8 +62,17 @@ func NewInitGenerator() *InitGenerator {
 	return &InitGenerator{}
 }
 
-// InitProject scaffolds a new project with the specified configuration
-func (g *InitGenerator) InitProject(projectPath, module, projectName string) error {
+// InitProject scaffolds a new project with the specified configuration. Once ctx is done the external
+// commands still running are stopped and ctx's error is returned
+func (g *InitGenerator) InitProject(ctx context.Context, projectPath, module, projectName string, opts InitOptions) error {
+	if opts.Logger == "" {
+		opts.Logger = Loggers[0]
+	}
+	logger, ok := loggerTypes[opts.Logger]
+	if !ok {
+		return fmt.Errorf("unknown logger %q, expected one of: %s", opts.Logger, strings.Join(Loggers, ", "))
+	}
+
 	// Create project directory if it doesn't exist
 	if err := os.MkdirAll(projectPath, 0755); err != nil {
 		return fmt.Errorf("failed to create project directory: %w", err)
@@ -30,13 +80,35 @@ func (g *InitGenerator) InitProject(projectPath, module, projectName string) err
 
 	// Template data
 	data := struct {
-		Module      string
-		ProjectName string
-		BinaryName  string
+		Module       string
+		ProjectName  string
+		BinaryName   string
+		Batteries    bool
+		Logger       string
+		LoggerImport string
+		LoggerType   string
 	}{
-		Module:      module,
-		ProjectName: projectName,
-		BinaryName:  strings.ReplaceAll(strings.ToLower(projectName), " ", "-"),
+		Module:       module,
+		ProjectName:  projectName,
+		BinaryName:   strings.ReplaceAll(strings.ToLower(projectName), " ", "-"),
+		Batteries:    opts.Batteries,
+		Logger:       opts.Logger,
+		LoggerImport: logger.importPath,
+		LoggerType:   logger.typeName,
+	}
+
+	// In an existing repository, remember what was already changed so only the scaffold's files are staged
+	var gitBaseline map[string]bool
+	if _, err := os.Stat(filepath.Join(projectPath, ".git")); opts.Git && err == nil {
+		baseline, err := g.gitChanges(ctx, projectPath, opts)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			fmt.Printf("⚠️  Warning: Failed to read the git status, the scaffolded files won't be staged: %v\n", err)
+			opts.Git = false
+		}
+		gitBaseline = baseline
 	}
 
 	// Files to create with their templates
@@ -48,12 +120,26 @@ func (g *InitGenerator) InitProject(projectPath, module, projectName string) err
 		{"templates/init/internal/api/server.tmpl", "internal/api/server.go"},
 		{"templates/init/internal/api/wire.tmpl", "internal/api/wire.go"},
 		{"templates/init/internal/health/handler.tmpl", "internal/health/handler.go"},
+		{"templates/init/internal/logging/" + opts.Logger + ".tmpl", "internal/logging/logger.go"},
 		{"templates/init/docs/docs.tmpl", "docs/docs.go"},
-		{"templates/init/air.tmpl", ".air.toml"},
 		{"templates/init/Taskfile.tmpl", "Taskfile.yml"},
 		{"templates/init/taskw.tmpl", "taskw.yaml"},
 		{"templates/init/go_mod.tmpl", "go.mod"},
 	}
+	if opts.Batteries {
+		files = append(files, []struct {
+			template string
+			output   string
+		}{
+			{"templates/init/batteries/internal/apperror/apperror.tmpl", "internal/apperror/apperror.go"},
+			{"templates/init/batteries/internal/validation/validator.tmpl", "internal/validation/validator.go"},
+			{"templates/init/batteries/internal/middleware/middleware.tmpl", "internal/middleware/middleware.go"},
+			{"templates/init/batteries/internal/todo/model.tmpl", "internal/todo/model.go"},
+			{"templates/init/batteries/internal/todo/repository.tmpl", "internal/todo/repository.go"},
+			{"templates/init/batteries/internal/todo/service.tmpl", "internal/todo/service.go"},
+			{"templates/init/batteries/internal/todo/handler.tmpl", "internal/todo/handler.go"},
+		}...)
+	}
 
 	// Generate each file
 	for _, file := range files {
@@ -63,6 +149,13 @@ func (g *InitGenerator) InitProject(projectPath, module, projectName string) err
 		fmt.Printf("Created: %s\n", file.output)
 	}
 
+	// go mod tidy drops the swagger middleware unless a file imports it before task generate runs
+	swaggerUIFile, err := g.generateSwaggerUI(projectPath)
+	if err != nil {
+		return fmt.Errorf("failed to generate the swagger UI registration: %w", err)
+	}
+	fmt.Printf("Created: %s\n", swaggerUIFile)
+
 	// Create additional directories
 	directories := []string{
 		"bin",
@@ -82,15 +175,82 @@ func (g *InitGenerator) InitProject(projectPath, module, projectName string) err
 		fmt.Printf("Warning: Failed to create/update .taskwignore: %v\n", err)
 	}
 
-	// Automatically generate code after scaffolding
-	if err := g.runInitialGeneration(projectPath); err != nil {
+	if opts.NoExec {
+		fmt.Println("Skipped: go mod tidy and task generate (--no-exec)")
+	} else if err := g.runInitialGeneration(ctx, projectPath, opts); errors.Is(err, errDependenciesDeferred) {
+		fmt.Println("📴 Offline: some dependencies are not in the module cache, resolving them was deferred")
+		fmt.Println("   go.sum is empty until then. Once the module proxy is reachable, run:")
+		fmt.Printf("   %s\n", RecoveryCommand(projectPath, InitOptions{GoPrivate: opts.GoPrivate}))
+	} else if ctx.Err() != nil {
+		return ctx.Err()
+	} else if err != nil {
 		// Don't fail the entire init process, just warn the user
-		return fmt.Errorf("warning: Failed to run initial code generation: %v", err)
+		fmt.Printf("⚠️  Warning: Failed to run initial code generation: %v\n", err)
+		fmt.Println("   The project was scaffolded, but code was not generated. To recover, run:")
+		fmt.Printf("   %s\n", RecoveryCommand(projectPath, opts))
+	}
+
+	// Bootstrap the git repository last so the initial commit contains everything
+	if opts.Git {
+		if err := g.initGitRepository(ctx, projectPath, data, opts, gitBaseline); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			fmt.Printf("⚠️  Warning: Failed to bootstrap git repository: %v\n", err)
+		}
 	}
 
 	return nil
 }
 
+// generateSwaggerUI generates the swagger UI registration of the generation.swagger_ui section of the
+// scaffolded taskw.yaml, returning its path relative to the project
+func (g *InitGenerator) generateSwaggerUI(projectPath string) (string, error) {
+	cfg := &config.Config{}
+	cfg.Paths.OutputDir = filepath.Join(projectPath, "internal", "api")
+	cfg.Generation.Routes.Enabled = true
+	cfg.Generation.SwaggerUI = config.SwaggerUIConfig{
+		Enabled:     true,
+		OutputFile:  "swagger_ui_gen.go",
+		Path:        "docs",
+		Title:       "Swagger API Docs",
+		DeepLinking: true,
+	}
+	swaggerUIGen, err := NewSwaggerUIGenerator(cfg, nil)
+	if err != nil {
+		return "", err
+	}
+	if err := swaggerUIGen.GenerateSwaggerUI(); err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(filepath.Join("internal", "api", cfg.Generation.SwaggerUI.OutputFile)), nil
+}
+
+// RecoveryCommand returns the command that completes initialization when the initial generation was skipped or failed,
+// with the GOPROXY and GOPRIVATE init was given
+func RecoveryCommand(projectPath string, opts InitOptions) string {
+	env := ""
+	for _, variable := range goEnv(opts) {
+		env += variable + " "
+	}
+	return fmt.Sprintf("cd %s && %sgo mod tidy && %stask generate", projectPath, env, env)
+}
+
+// goEnv returns the Go environment variables init sets for go mod tidy and task generate
+func goEnv(opts InitOptions) []string {
+	var env []string
+	switch {
+	case opts.Offline:
+		env = append(env, "GOPROXY=off")
+	case opts.GoProxy != "":
+		env = append(env, "GOPROXY="+opts.GoProxy)
+	}
+	if opts.GoPrivate != "" {
+		env = append(env, "GOPRIVATE="+opts.GoPrivate)
+	}
+	return env
+}
+
 // generateFile generates a single file from a template
 func (g *InitGenerator) generateFile(projectPath, templatePath, outputPath string, data interface{}) error {
 	// Read template
@@ -127,46 +287,187 @@ func (g *InitGenerator) generateFile(projectPath, templatePath, outputPath strin
 }
 
 // runInitialGeneration runs go mod tidy and then task generate in the newly created project
-func (g *InitGenerator) runInitialGeneration(projectPath string) error {
-	// Check if go command is available
-	if !isCommandAvailable("go") {
-		return fmt.Errorf("go command not available in PATH, bro what?")
+func (g *InitGenerator) runInitialGeneration(ctx context.Context, projectPath string, opts InitOptions) error {
+	// taskw doctor reports every missing tool at once, init only needs these two
+	for _, tool := range []tools.Tool{tools.Go, tools.Task} {
+		if !tool.Available() {
+			return fmt.Errorf("%s command not available in PATH, install it with '%s' or run 'taskw doctor'", tool.Name, tool.InstallHint())
+		}
 	}
 
-	// Check if task command is available
-	if !isCommandAvailable("task") {
-		return fmt.Errorf("task command not available, please install Task runner or run 'go install github.com/go-task/task/v3/cmd/task@latest'")
-	}
+	env := append(os.Environ(), goEnv(opts)...)
 
 	// Step 1: Run go mod tidy to resolve dependencies
-	fmt.Println("📦 Running go mod tidy to resolve dependencies...")
-	tidyCmd := exec.Command("go", "mod", "tidy")
-	tidyCmd.Dir = projectPath
-
-	tidyOutput, err := tidyCmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to run 'go mod tidy': %w\nOutput: %s", err, string(tidyOutput))
+	if err := g.tidy(ctx, projectPath, env, opts); err != nil {
+		return err
 	}
 	fmt.Println("✅ Dependencies resolved successfully")
 
 	// Step 2: Run task generate directly to create initial code
 	fmt.Println("🔧 Running task generate to create initial code...")
-	generateCmd := exec.Command("task", "generate")
-	generateCmd.Dir = projectPath
-
 	// Capture output for better error reporting
-	generateOutput, err := generateCmd.CombinedOutput()
+	generateOutput, err := runCommand(ctx, projectPath, env, opts, "task", "generate")
 	if err != nil {
-		return fmt.Errorf("failed to run 'task generate': %w\nOutput: %s", err, string(generateOutput))
+		return commandError([]string{"task", "generate"}, generateOutput, err, opts)
 	}
 
 	return nil
 }
 
-// isCommandAvailable checks if a command is available in PATH
-func isCommandAvailable(name string) bool {
-	_, err := exec.LookPath(name)
-	return err == nil
+// runCommand runs an external command in the project and returns its combined output. The command
+// is killed once ctx is done or after opts.ToolTimeout, the context's error is returned then.
+// A nil env keeps the environment of taskw
+func runCommand(ctx context.Context, projectPath string, env []string, opts InitOptions, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	if opts.ToolTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, opts.ToolTimeout)
+	}
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = projectPath
+	cmd.Env = env
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return output, ctx.Err()
+	}
+	return output, err
+}
+
+// commandError reports a failed external command, an interrupted one is returned as is and one stopped
+// by tools.timeout says so instead of printing its partial output
+func commandError(args []string, output []byte, err error, opts InitOptions) error {
+	command := strings.Join(args, " ")
+	switch {
+	case errors.Is(err, context.Canceled):
+		return err
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("'%s' didn't finish within tools.timeout (%s)", command, opts.ToolTimeout)
+	}
+	return fmt.Errorf("failed to run '%s': %w\nOutput: %s", command, err, string(output))
+}
+
+// tidy runs go mod tidy, retrying with backoff since proxies fail transiently, until ctx is done.
+// Offline, it runs once against the module cache and writes an empty go.sum when dependencies are
+// missing from it
+func (g *InitGenerator) tidy(ctx context.Context, projectPath string, env []string, opts InitOptions) error {
+	attempts := tidyAttempts
+	if opts.Offline {
+		fmt.Println("📦 Running go mod tidy against the module cache (--offline)...")
+		attempts = 1
+	} else {
+		fmt.Println("📦 Running go mod tidy to resolve dependencies...")
+	}
+
+	delay := tidyRetryDelay
+	for attempt := 1; ; attempt++ {
+		tidyOutput, err := runCommand(ctx, projectPath, env, opts, "go", "mod", "tidy")
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if opts.Offline {
+			goSum := filepath.Join(projectPath, "go.sum")
+			if _, statErr := os.Stat(goSum); os.IsNotExist(statErr) {
+				if writeErr := os.WriteFile(goSum, nil, 0644); writeErr != nil {
+					return fmt.Errorf("failed to write go.sum: %w", writeErr)
+				}
+			}
+			return errDependenciesDeferred
+		}
+		if attempt == attempts {
+			return fmt.Errorf("%d attempts failed, the last one: %w\nBehind a proxy? Use --goproxy and --goprivate, or --offline to defer dependencies", attempts, commandError([]string{"go", "mod", "tidy"}, tidyOutput, err, opts))
+		}
+
+		fmt.Printf("⚠️  go mod tidy failed, retrying in %s (attempt %d of %d)...\n", delay, attempt+1, attempts)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// initGitRepository writes a .gitignore, then either runs git init and creates an initial commit or,
+// when baseline holds the changes of an existing repository before scaffolding, stages only the files
+// the scaffold changed and leaves committing them to the user
+func (g *InitGenerator) initGitRepository(ctx context.Context, projectPath string, data interface{}, opts InitOptions, baseline map[string]bool) error {
+	if !tools.Git.Available() {
+		return fmt.Errorf("git command not available in PATH")
+	}
+
+	// Write .gitignore unless the directory already has one
+	if _, err := os.Stat(filepath.Join(projectPath, ".gitignore")); os.IsNotExist(err) {
+		if err := g.generateFile(projectPath, "templates/init/gitignore.tmpl", ".gitignore", data); err != nil {
+			return fmt.Errorf("failed to generate .gitignore: %w", err)
+		}
+		fmt.Println("Created: .gitignore")
+	} else {
+		fmt.Println("Skipped: .gitignore (already exists)")
+	}
+
+	// Don't re-initialize a repository that already exists or sweep unrelated work into a commit
+	if baseline != nil {
+		changes, err := g.gitChanges(ctx, projectPath, opts)
+		if err != nil {
+			return err
+		}
+		var scaffolded []string
+		for path := range changes {
+			if !baseline[path] {
+				scaffolded = append(scaffolded, path)
+			}
+		}
+		if len(scaffolded) == 0 {
+			fmt.Println("Skipped: git add (no scaffolded files changed)")
+			return nil
+		}
+		sort.Strings(scaffolded)
+
+		args := append([]string{"git", "add", "--"}, scaffolded...)
+		if output, err := runCommand(ctx, projectPath, nil, opts, args[0], args[1:]...); err != nil {
+			return commandError(args[:3], output, err, opts)
+		}
+		fmt.Printf("✅ Staged %d scaffolded files in the existing git repository, commit them when ready\n", len(scaffolded))
+		return nil
+	}
+
+	commands := [][]string{
+		{"git", "init"},
+		{"git", "add", "-A"},
+		{"git", "commit", "-m", "Initial commit (scaffolded by taskw)"},
+	}
+	for _, args := range commands {
+		output, err := runCommand(ctx, projectPath, nil, opts, args[0], args[1:]...)
+		if err != nil {
+			return commandError(args, output, err, opts)
+		}
+	}
+
+	fmt.Println("✅ Git repository initialized with initial commit")
+	return nil
+}
+
+// gitChanges returns the paths git status reports as modified or untracked in the repository at
+// projectPath, relative to it
+func (g *InitGenerator) gitChanges(ctx context.Context, projectPath string, opts InitOptions) (map[string]bool, error) {
+	args := []string{"git", "status", "--porcelain", "-z", "--untracked-files=all", "--no-renames"}
+	output, err := runCommand(ctx, projectPath, nil, opts, args[0], args[1:]...)
+	if err != nil {
+		return nil, commandError(args, output, err, opts)
+	}
+
+	changes := make(map[string]bool)
+	for _, entry := range strings.Split(string(output), "\x00") {
+		// Each entry is the two-letter status, a space and the path
+		if len(entry) > 3 {
+			changes[entry[3:]] = true
+		}
+	}
+	return changes, nil
 }
 
 // createOrAppendTaskwIgnore creates or appends to .taskwignore file
@@ -263,6 +564,7 @@ target/
 **/*_gen.go
 !routes_gen.go
 !dependencies_gen.go
+!gateway_gen.go
 **/wire_gen.go
 
 # IDE and editor files
@@ -0,0 +1,259 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// ManifestFile records what the last generate runs wrote and from which sources, relative to the project root
+const ManifestFile = ".taskw/manifest.json"
+
+// manifestFormat changes whenever the manifest changes shape
+const manifestFormat = 1
+
+// Manifest describes the generated files of a project and the source files their routes and providers come from
+type Manifest struct {
+	Format       int              `json:"format"`
+	TaskwVersion string           `json:"taskw_version"` // Version of the taskw build of the last run
+	GeneratedAt  time.Time        `json:"generated_at"`  // End of the last run
+	Outputs      []ManifestOutput `json:"outputs"`       // Every file generated so far that still exists, by path
+	Sources      []ManifestSource `json:"sources"`       // Scanned files declaring routes or providers, by path
+}
+
+// ManifestOutput is a generated file, written by taskw or by wire or swag during a run
+type ManifestOutput struct {
+	Path         string    `json:"path"`
+	SHA256       string    `json:"sha256"`        // Content when it was last generated, differs once edited by hand
+	GeneratedAt  time.Time `json:"generated_at"`  // End of the run that last wrote it
+	TaskwVersion string    `json:"taskw_version"` // Version of the taskw build that last wrote it
+}
+
+// ManifestSource is a scanned file contributing routes or providers to the generated code
+type ManifestSource struct {
+	Path      string          `json:"path"`
+	Routes    []ManifestRoute `json:"routes,omitempty"`
+	Providers []string        `json:"providers,omitempty"` // e.g. "user.ProvideUserService"
+}
+
+// ManifestRoute is a route declared with @Router
+type ManifestRoute struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Handler string `json:"handler"` // e.g. "user.Handler.GetUser", the route ID without @ID
+	Line    int    `json:"line"`
+}
+
+// Recording collects the files written while it is active, so the manifest knows what a run generated
+type Recording struct {
+	mu    sync.Mutex
+	paths []string
+	seen  map[string]bool
+}
+
+// NewRecording creates a recording to note the files generators and external tools write in, set as
+// Output.Recording
+func NewRecording() *Recording {
+	return &Recording{seen: make(map[string]bool)}
+}
+
+// Paths returns the paths of the noted files in the order they were first written
+func (r *Recording) Paths() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.paths)
+}
+
+// note notes files about to be written, a nil recording notes nothing
+func (r *Recording) note(paths ...string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		if !r.seen[path] {
+			r.seen[path] = true
+			r.paths = append(r.paths, path)
+		}
+	}
+}
+
+// LoadManifest reads the manifest file, nil when it is missing or was written in another format
+func LoadManifest() (*Manifest, error) {
+	content, err := os.ReadFile(ManifestFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil || manifest.Format != manifestFormat {
+		return nil, nil
+	}
+	return &manifest, nil
+}
+
+// WriteManifest updates the manifest file with the files a run wrote and the sources of the scan result.
+// Outputs written by earlier runs are kept as long as they exist, e.g. dependencies_gen.go after
+// taskw generate routes
+func WriteManifest(written []string, result *scanner.ScanResult) error {
+	previous, err := LoadManifest()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", ManifestFile, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	version := TaskwVersion()
+	manifest := &Manifest{
+		Format:       manifestFormat,
+		TaskwVersion: version,
+		GeneratedAt:  now,
+		Outputs:      []ManifestOutput{},
+		Sources:      manifestSources(result),
+	}
+
+	outputs := make(map[string]ManifestOutput)
+	if previous != nil {
+		for _, output := range previous.Outputs {
+			outputs[output.Path] = output
+		}
+	}
+	for _, path := range written {
+		path = filepath.ToSlash(path)
+		content, err := os.ReadFile(filepath.FromSlash(path))
+		if err != nil {
+			continue // An external tool didn't write it after all
+		}
+		sum := sha256.Sum256(content)
+		outputs[path] = ManifestOutput{Path: path, SHA256: hex.EncodeToString(sum[:]), GeneratedAt: now, TaskwVersion: version}
+	}
+	for path, output := range outputs {
+		if _, err := os.Stat(filepath.FromSlash(path)); err != nil {
+			continue // Removed since
+		}
+		manifest.Outputs = append(manifest.Outputs, output)
+	}
+	sort.Slice(manifest.Outputs, func(i, j int) bool {
+		return manifest.Outputs[i].Path < manifest.Outputs[j].Path
+	})
+
+	encoded, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(ManifestFile), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := replaceFile(ManifestFile, append(encoded, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ManifestFile, err)
+	}
+	return nil
+}
+
+// Drift lists what changed since the manifest was written: routes and providers added to or removed
+// from the scanned sources, which the generated files don't reflect yet, and generated files edited
+// by hand or deleted, e.g. "route GET /users (user.Handler.GetUsers) added in internal/user/handler.go"
+func (m *Manifest) Drift(result *scanner.ScanResult) []string {
+	recorded := make(map[string]string)
+	for _, src := range m.Sources {
+		for _, key := range sourceKeys(src) {
+			recorded[key] = src.Path
+		}
+	}
+	current := make(map[string]string)
+	for _, src := range manifestSources(result) {
+		for _, key := range sourceKeys(src) {
+			current[key] = src.Path
+		}
+	}
+
+	var drift []string
+	for key, path := range current {
+		if _, ok := recorded[key]; !ok {
+			drift = append(drift, fmt.Sprintf("%s added in %s", key, path))
+		}
+	}
+	for key, path := range recorded {
+		if _, ok := current[key]; !ok {
+			drift = append(drift, fmt.Sprintf("%s removed from %s", key, path))
+		}
+	}
+	for _, output := range m.Outputs {
+		content, err := os.ReadFile(filepath.FromSlash(output.Path))
+		if err != nil {
+			drift = append(drift, fmt.Sprintf("generated file %s deleted", output.Path))
+			continue
+		}
+		if sum := sha256.Sum256(content); hex.EncodeToString(sum[:]) != output.SHA256 {
+			drift = append(drift, fmt.Sprintf("generated file %s edited since it was generated", output.Path))
+		}
+	}
+	sort.Strings(drift)
+	return drift
+}
+
+// sourceKeys identifies the routes and providers of a source file regardless of their line
+func sourceKeys(src ManifestSource) []string {
+	keys := make([]string, 0, len(src.Routes)+len(src.Providers))
+	for _, route := range src.Routes {
+		keys = append(keys, fmt.Sprintf("route %s %s (%s)", route.Method, route.Path, route.Handler))
+	}
+	for _, provider := range src.Providers {
+		keys = append(keys, "provider "+provider)
+	}
+	return keys
+}
+
+// manifestSources groups the routes and providers of a scan result by the file declaring them
+func manifestSources(result *scanner.ScanResult) []ManifestSource {
+	byPath := make(map[string]*ManifestSource)
+	source := func(path string) *ManifestSource {
+		path = filepath.ToSlash(path)
+		if byPath[path] == nil {
+			byPath[path] = &ManifestSource{Path: path}
+		}
+		return byPath[path]
+	}
+
+	for _, route := range result.Routes {
+		handler := route
+		handler.OperationID = ""
+		src := source(route.FilePath)
+		src.Routes = append(src.Routes, ManifestRoute{Method: route.HTTPMethod, Path: route.Path, Handler: handler.RouteID(), Line: route.Line})
+	}
+	for _, provider := range result.Providers {
+		src := source(provider.FilePath)
+		src.Providers = append(src.Providers, provider.Package+"."+provider.FunctionName)
+	}
+
+	sources := make([]ManifestSource, 0, len(byPath))
+	for _, src := range byPath {
+		sources = append(sources, *src)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Path < sources[j].Path
+	})
+	return sources
+}
+
+// TaskwVersion returns the module version of the running taskw build, "(devel)" for local builds
+func TaskwVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Version
+	}
+	return "unknown"
+}
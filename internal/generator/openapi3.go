@@ -0,0 +1,601 @@
+package generator
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
+)
+
+// jsonSchemaDialect is the dialect of OpenAPI 3.1 schemas, declared at the root of the spec
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// openAPIFiles are the names of the converted spec files, written next to swagger.json and swagger.yaml
+var openAPIFiles = struct{ JSON, YAML string }{JSON: "openapi.json", YAML: "openapi.yaml"}
+
+// convertToOpenAPI3 converts the rewritten Swagger 2.0 spec to OpenAPI 3.0 or 3.1
+// swag only generates Swagger 2.0, so request bodies, response content and servers are rebuilt from
+// the body and formData parameters, produces/consumes and host/basePath/schemes (or x-servers).
+// For 3.1 every schema is converted to the JSON Schema 2020-12 dialect
+func convertToOpenAPI3(swagger map[string]interface{}, version string) map[string]interface{} {
+	dialect := openAPI30Schema
+	spec := map[string]interface{}{"openapi": "3.0.3"}
+	if version == config.SpecVersionOpenAPI31 {
+		dialect = openAPI31Schema
+		spec["openapi"] = "3.1.0"
+		spec["jsonSchemaDialect"] = jsonSchemaDialect
+	}
+
+	for key, value := range swagger {
+		switch key {
+		case "swagger", "host", "basePath", "schemes", "x-servers", "consumes", "produces",
+			"definitions", "parameters", "responses", "securityDefinitions", "paths":
+		default:
+			// info, tags, security, externalDocs and extensions such as x-pii carry over as they are
+			spec[key] = value
+		}
+	}
+	if servers := openAPIServers(swagger); len(servers) > 0 {
+		spec["servers"] = servers
+	}
+
+	components := make(map[string]interface{})
+	if definitions, ok := swagger["definitions"].(map[string]interface{}); ok && len(definitions) > 0 {
+		schemas := make(map[string]interface{}, len(definitions))
+		for name, schema := range definitions {
+			schemas[name] = dialect(schema)
+		}
+		components["schemas"] = schemas
+	}
+	if definitions, ok := swagger["securityDefinitions"].(map[string]interface{}); ok && len(definitions) > 0 {
+		schemes := make(map[string]interface{}, len(definitions))
+		for name, definition := range definitions {
+			if object, ok := definition.(map[string]interface{}); ok {
+				schemes[name] = openAPISecurityScheme(object)
+			}
+		}
+		components["securitySchemes"] = schemes
+	}
+
+	consumes := stringValues(swagger["consumes"])
+	produces := stringValues(swagger["produces"])
+	if parameters, ok := swagger["parameters"].(map[string]interface{}); ok && len(parameters) > 0 {
+		converted := make(map[string]interface{}, len(parameters))
+		for name, parameter := range parameters {
+			if list := openAPIParameters([]interface{}{parameter}, dialect); len(list) == 1 {
+				converted[name] = list[0]
+			}
+		}
+		components["parameters"] = converted
+	}
+	if responses, ok := swagger["responses"].(map[string]interface{}); ok && len(responses) > 0 {
+		converted := make(map[string]interface{}, len(responses))
+		for name, value := range responses {
+			if response, ok := value.(map[string]interface{}); ok {
+				converted[name] = openAPIResponse(response, produces, dialect)
+			}
+		}
+		components["responses"] = converted
+	}
+	if paths, ok := swagger["paths"].(map[string]interface{}); ok {
+		converted := make(map[string]interface{}, len(paths))
+		for path, item := range paths {
+			pathItem, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			convertedItem := make(map[string]interface{}, len(pathItem))
+			for method, value := range pathItem {
+				operation, ok := value.(map[string]interface{})
+				if !ok || method == "parameters" {
+					convertedItem[method] = value
+					continue
+				}
+				convertedItem[method] = openAPIOperation(operation, consumes, produces, dialect)
+			}
+			if parameters, ok := pathItem["parameters"].([]interface{}); ok {
+				convertedItem["parameters"] = openAPIParameters(parameters, dialect)
+			}
+			converted[path] = convertedItem
+		}
+		spec["paths"] = converted
+	} else {
+		spec["paths"] = map[string]interface{}{}
+	}
+
+	if len(components) > 0 {
+		spec["components"] = components
+	}
+	return rewriteRefs(spec).(map[string]interface{})
+}
+
+// openAPIServers lists the servers of the spec, the x-servers written from openapi.servers or else
+// the single server described by host, basePath and schemes
+func openAPIServers(swagger map[string]interface{}) []interface{} {
+	if servers, ok := swagger["x-servers"].([]interface{}); ok && len(servers) > 0 {
+		return servers
+	}
+
+	host, _ := swagger["host"].(string)
+	basePath, _ := swagger["basePath"].(string)
+	if host == "" {
+		if basePath == "" || basePath == "/" {
+			return nil
+		}
+		return []interface{}{map[string]interface{}{"url": basePath}}
+	}
+
+	schemes := stringValues(swagger["schemes"])
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+	servers := make([]interface{}, 0, len(schemes))
+	for _, scheme := range schemes {
+		serverURL := url.URL{Scheme: scheme, Host: host, Path: strings.TrimSuffix(basePath, "/")}
+		servers = append(servers, map[string]interface{}{"url": serverURL.String()})
+	}
+	return servers
+}
+
+// openAPISecurityScheme converts a Swagger 2.0 security definition to an OpenAPI 3 security scheme
+func openAPISecurityScheme(definition map[string]interface{}) map[string]interface{} {
+	scheme := make(map[string]interface{})
+	for key, value := range definition {
+		if strings.HasPrefix(key, "x-") || key == "description" {
+			scheme[key] = value
+		}
+	}
+
+	switch definition["type"] {
+	case "basic":
+		scheme["type"] = "http"
+		scheme["scheme"] = "basic"
+	case "apiKey":
+		scheme["type"] = "apiKey"
+		scheme["name"] = definition["name"]
+		scheme["in"] = definition["in"]
+	case "oauth2":
+		scheme["type"] = "oauth2"
+		flow := make(map[string]interface{})
+		for _, key := range []string{"authorizationUrl", "tokenUrl"} {
+			if value, ok := definition[key]; ok {
+				flow[key] = value
+			}
+		}
+		flow["scopes"] = map[string]interface{}{}
+		if scopes, ok := definition["scopes"].(map[string]interface{}); ok {
+			flow["scopes"] = scopes
+		}
+		// Swagger 2.0 names the flows after the grant, OpenAPI 3 after the OAuth 2 specification
+		flowName := map[interface{}]string{
+			"implicit":    "implicit",
+			"password":    "password",
+			"application": "clientCredentials",
+			"accessCode":  "authorizationCode",
+		}[definition["flow"]]
+		if flowName == "" {
+			flowName = "implicit"
+		}
+		scheme["flows"] = map[string]interface{}{flowName: flow}
+	default:
+		scheme["type"] = definition["type"]
+	}
+	return scheme
+}
+
+// openAPIOperation converts a Swagger 2.0 operation, moving body and formData parameters into the
+// request body and response schemas into response content
+func openAPIOperation(operation map[string]interface{}, consumes, produces []string, dialect func(interface{}) interface{}) map[string]interface{} {
+	converted := make(map[string]interface{}, len(operation))
+	for key, value := range operation {
+		switch key {
+		case "consumes", "produces", "parameters", "responses", "schemes":
+		default:
+			converted[key] = value
+		}
+	}
+	if values := stringValues(operation["consumes"]); len(values) > 0 {
+		consumes = values
+	}
+	if values := stringValues(operation["produces"]); len(values) > 0 {
+		produces = values
+	}
+
+	parameters, _ := operation["parameters"].([]interface{})
+	var body map[string]interface{}
+	var formFields []map[string]interface{}
+	var others []interface{}
+	for _, value := range parameters {
+		parameter, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch parameter["in"] {
+		case "body":
+			body = parameter
+		case "formData":
+			formFields = append(formFields, parameter)
+		default:
+			others = append(others, parameter)
+		}
+	}
+	if len(others) > 0 {
+		converted["parameters"] = openAPIParameters(others, dialect)
+	}
+
+	switch {
+	case body != nil:
+		mimeTypes := consumes
+		if len(mimeTypes) == 0 {
+			mimeTypes = []string{"application/json"}
+		}
+		requestBody := map[string]interface{}{"content": mediaTypes(mimeTypes, dialect(body["schema"]))}
+		if description, ok := body["description"].(string); ok && description != "" {
+			requestBody["description"] = description
+		}
+		if required, ok := body["required"].(bool); ok && required {
+			requestBody["required"] = true
+		}
+		converted["requestBody"] = requestBody
+	case len(formFields) > 0:
+		converted["requestBody"] = formRequestBody(formFields, consumes, dialect)
+	}
+
+	if responses, ok := operation["responses"].(map[string]interface{}); ok {
+		convertedResponses := make(map[string]interface{}, len(responses))
+		for status, value := range responses {
+			response, ok := value.(map[string]interface{})
+			if !ok {
+				convertedResponses[status] = value
+				continue
+			}
+			convertedResponses[status] = openAPIResponse(response, produces, dialect)
+		}
+		converted["responses"] = convertedResponses
+	}
+	return converted
+}
+
+// formRequestBody gathers formData parameters into the object schema of a form request body,
+// multipart when a parameter is a file
+func formRequestBody(fields []map[string]interface{}, consumes []string, dialect func(interface{}) interface{}) map[string]interface{} {
+	properties := make(map[string]interface{}, len(fields))
+	var required []interface{}
+	multipart := false
+	for _, field := range fields {
+		name, _ := field["name"].(string)
+		schema := parameterSchema(field)
+		if schema["type"] == "file" {
+			multipart = true
+			schema["type"] = "string"
+			schema["format"] = "binary"
+		}
+		if description, ok := field["description"].(string); ok && description != "" {
+			schema["description"] = description
+		}
+		properties[name] = dialect(schema)
+		if isRequired, ok := field["required"].(bool); ok && isRequired {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	var mimeTypes []string
+	for _, mimeType := range consumes {
+		if mimeType == "multipart/form-data" || mimeType == "application/x-www-form-urlencoded" {
+			mimeTypes = append(mimeTypes, mimeType)
+		}
+	}
+	if len(mimeTypes) == 0 {
+		mimeTypes = []string{"application/x-www-form-urlencoded"}
+		if multipart {
+			mimeTypes = []string{"multipart/form-data"}
+		}
+	}
+	return map[string]interface{}{"content": mediaTypes(mimeTypes, schema)}
+}
+
+// openAPIParameters converts path, query, header and cookie parameters, whose type, format and
+// validations move into a schema
+func openAPIParameters(parameters []interface{}, dialect func(interface{}) interface{}) []interface{} {
+	converted := make([]interface{}, 0, len(parameters))
+	for _, value := range parameters {
+		parameter, ok := value.(map[string]interface{})
+		if !ok {
+			converted = append(converted, value)
+			continue
+		}
+		if _, isRef := parameter["$ref"]; isRef {
+			converted = append(converted, parameter)
+			continue
+		}
+
+		object := make(map[string]interface{})
+		for _, key := range []string{"name", "in", "description", "required", "deprecated", "allowEmptyValue"} {
+			if value, ok := parameter[key]; ok {
+				object[key] = value
+			}
+		}
+		for key, value := range parameter {
+			if strings.HasPrefix(key, "x-") {
+				object[key] = value
+			}
+		}
+		switch parameter["collectionFormat"] {
+		case "multi":
+			object["style"] = "form"
+			object["explode"] = true
+		case "ssv":
+			object["style"] = "spaceDelimited"
+			object["explode"] = false
+		case "pipes":
+			object["style"] = "pipeDelimited"
+			object["explode"] = false
+		case "csv":
+			if parameter["in"] == "query" {
+				object["style"] = "form"
+				object["explode"] = false
+			}
+		}
+		object["schema"] = dialect(parameterSchema(parameter))
+		converted = append(converted, object)
+	}
+	return converted
+}
+
+// parameterSchema collects the schema keywords Swagger 2.0 puts on a non-body parameter
+func parameterSchema(parameter map[string]interface{}) map[string]interface{} {
+	schema := make(map[string]interface{})
+	for _, key := range []string{
+		"type", "format", "items", "default", "enum", "maximum", "exclusiveMaximum", "minimum", "exclusiveMinimum",
+		"maxLength", "minLength", "pattern", "maxItems", "minItems", "uniqueItems", "multipleOf", "example", "x-nullable",
+	} {
+		if value, ok := parameter[key]; ok {
+			schema[key] = value
+		}
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		schema["items"] = parameterSchema(items)
+	}
+	return schema
+}
+
+// openAPIResponse converts a Swagger 2.0 response, its schema served with the content types the
+// response declares in x-content-types, else the ones the operation produces
+func openAPIResponse(response map[string]interface{}, produces []string, dialect func(interface{}) interface{}) map[string]interface{} {
+	converted := make(map[string]interface{}, len(response))
+	for key, value := range response {
+		switch key {
+		case "schema", "headers", "examples", "x-content-types":
+		default:
+			converted[key] = value
+		}
+	}
+	if _, ok := converted["description"]; !ok {
+		converted["description"] = ""
+	}
+
+	if headers, ok := response["headers"].(map[string]interface{}); ok && len(headers) > 0 {
+		convertedHeaders := make(map[string]interface{}, len(headers))
+		for name, value := range headers {
+			header, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			object := map[string]interface{}{"schema": dialect(parameterSchema(header))}
+			if description, ok := header["description"].(string); ok && description != "" {
+				object["description"] = description
+			}
+			convertedHeaders[name] = object
+		}
+		converted["headers"] = convertedHeaders
+	}
+
+	schema, ok := response["schema"]
+	if !ok {
+		return converted
+	}
+	mimeTypes := stringValues(response["x-content-types"])
+	if len(mimeTypes) == 0 {
+		mimeTypes = produces
+	}
+	if len(mimeTypes) == 0 {
+		mimeTypes = []string{"application/json"}
+	}
+	content := mediaTypes(mimeTypes, dialect(schema))
+	if examples, ok := response["examples"].(map[string]interface{}); ok {
+		for mimeType, example := range examples {
+			if mediaType, ok := content[mimeType].(map[string]interface{}); ok {
+				mediaType["example"] = example
+			}
+		}
+	}
+	converted["content"] = content
+	return converted
+}
+
+// mediaTypes maps every content type to the same schema
+func mediaTypes(mimeTypes []string, schema interface{}) map[string]interface{} {
+	content := make(map[string]interface{}, len(mimeTypes))
+	for _, mimeType := range mimeTypes {
+		content[mimeType] = map[string]interface{}{"schema": schema}
+	}
+	return content
+}
+
+// openAPI30Schema converts a Swagger 2.0 schema to an OpenAPI 3.0 schema, which only differ in
+// how nullable values and files are written
+func openAPI30Schema(value interface{}) interface{} {
+	return walkSchema(value, func(schema map[string]interface{}) {
+		if nullable, ok := schema["x-nullable"].(bool); ok {
+			delete(schema, "x-nullable")
+			if nullable {
+				schema["nullable"] = true
+			}
+		}
+		if schema["type"] == "file" {
+			schema["type"] = "string"
+			schema["format"] = "binary"
+		}
+	})
+}
+
+// openAPI31Schema converts a Swagger 2.0 schema to the JSON Schema 2020-12 dialect of OpenAPI 3.1:
+// nullable types become type arrays, example becomes examples and exclusive bounds become numbers
+func openAPI31Schema(value interface{}) interface{} {
+	return walkSchema(value, func(schema map[string]interface{}) {
+		if schema["type"] == "file" {
+			schema["type"] = "string"
+			schema["contentMediaType"] = "application/octet-stream"
+		}
+		if format, _ := schema["format"].(string); format == "binary" && schema["type"] == "string" {
+			delete(schema, "format")
+			schema["contentMediaType"] = "application/octet-stream"
+		}
+
+		if nullable, ok := schema["x-nullable"].(bool); ok {
+			delete(schema, "x-nullable")
+			if nullable {
+				makeNullable(schema)
+			}
+		}
+
+		if example, ok := schema["example"]; ok {
+			delete(schema, "example")
+			schema["examples"] = []interface{}{example}
+		}
+
+		for bound, exclusive := range map[string]string{"minimum": "exclusiveMinimum", "maximum": "exclusiveMaximum"} {
+			flag, ok := schema[exclusive].(bool)
+			if !ok {
+				continue
+			}
+			delete(schema, exclusive)
+			if limit, ok := schema[bound]; ok && flag {
+				delete(schema, bound)
+				schema[exclusive] = limit
+			}
+		}
+	})
+}
+
+// makeNullable lets a JSON Schema 2020-12 schema accept null
+func makeNullable(schema map[string]interface{}) {
+	switch schemaType := schema["type"].(type) {
+	case string:
+		schema["type"] = []interface{}{schemaType, "null"}
+		if enum, ok := schema["enum"].([]interface{}); ok {
+			schema["enum"] = append(enum, nil)
+		}
+	case []interface{}:
+		for _, value := range schemaType {
+			if value == "null" {
+				return
+			}
+		}
+		schema["type"] = append(schemaType, "null")
+	default:
+		// References and compositions have no type of their own
+		alternatives := make(map[string]interface{}, len(schema))
+		for key, value := range schema {
+			if key != "description" && key != "title" && !strings.HasPrefix(key, "x-") {
+				alternatives[key] = value
+				delete(schema, key)
+			}
+		}
+		schema["anyOf"] = []interface{}{alternatives, map[string]interface{}{"type": "null"}}
+	}
+}
+
+// walkSchema applies convert to a schema and every schema nested in it, returning a converted copy
+func walkSchema(value interface{}, convert func(map[string]interface{})) interface{} {
+	schema, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+
+	converted := make(map[string]interface{}, len(schema))
+	for key, item := range schema {
+		switch key {
+		case "properties", "patternProperties", "definitions", "$defs":
+			if object, ok := item.(map[string]interface{}); ok {
+				nested := make(map[string]interface{}, len(object))
+				for name, property := range object {
+					nested[name] = walkSchema(property, convert)
+				}
+				converted[key] = nested
+				continue
+			}
+		case "items", "additionalProperties", "not":
+			converted[key] = walkSchema(item, convert)
+			continue
+		case "allOf", "anyOf", "oneOf":
+			if list, ok := item.([]interface{}); ok {
+				nested := make([]interface{}, len(list))
+				for i, element := range list {
+					nested[i] = walkSchema(element, convert)
+				}
+				converted[key] = nested
+				continue
+			}
+		}
+		converted[key] = item
+	}
+	convert(converted)
+	return converted
+}
+
+// rewriteRefs points references to Swagger 2.0 definitions at the OpenAPI 3 components
+func rewriteRefs(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if ref, ok := item.(string); ok && key == "$ref" {
+				v[key] = openAPIRef(ref)
+				continue
+			}
+			v[key] = rewriteRefs(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = rewriteRefs(item)
+		}
+		return v
+	}
+	return value
+}
+
+// openAPIRef maps a Swagger 2.0 reference to its OpenAPI 3 component
+func openAPIRef(ref string) string {
+	for prefix, replacement := range map[string]string{
+		"#/definitions/":         "#/components/schemas/",
+		"#/parameters/":          "#/components/parameters/",
+		"#/responses/":           "#/components/responses/",
+		"#/securityDefinitions/": "#/components/securitySchemes/",
+	} {
+		if strings.HasPrefix(ref, prefix) {
+			return replacement + strings.TrimPrefix(ref, prefix)
+		}
+	}
+	return ref
+}
+
+// stringValues reads a list of strings, decoded from JSON or set while rewriting the spec
+func stringValues(value interface{}) []string {
+	if values, ok := value.([]string); ok {
+		return values
+	}
+	list, _ := value.([]interface{})
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
@@ -0,0 +1,57 @@
+package generator
+
+import "sync"
+
+// Output is the state of one generation run, shared by the generators it runs: whether files edited by
+// hand are overwritten, the preview capturing the generated files instead of writing them, the recording
+// noting them for the manifest and the transaction restoring them when the run fails. Runs with their
+// own Output run side by side. A nil Output writes straight to disk and notes nothing
+type Output struct {
+	Force     bool       // Overwrite generated files edited by hand instead of failing with ErrEdited
+	Preview   *Preview   // Captures the generated files instead of writing them, nil to write them
+	Recording *Recording // Notes the written files for the manifest, nil to note nothing
+
+	mu sync.Mutex
+	tx *Transaction // Transaction written files are backed up in, nil when none is running
+}
+
+// Previewing reports whether generated files are captured instead of written
+func (o *Output) Previewing() bool {
+	return o.preview() != nil
+}
+
+// Track records the current content of files an external tool is about to rewrite, e.g. wire_gen.go,
+// so they are restored as well, and notes them in the recording for the manifest. paths are
+// relative to the project root. Does nothing without a running transaction or recording
+func (o *Output) Track(root string, paths ...string) error {
+	if o == nil {
+		return nil
+	}
+	o.Recording.note(paths...)
+
+	o.mu.Lock()
+	tx := o.tx
+	o.mu.Unlock()
+	if tx == nil {
+		return nil
+	}
+	for _, path := range paths {
+		if err := tx.record(root, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// preview returns the preview generated files are captured in, nil when they are written
+func (o *Output) preview() *Preview {
+	if o == nil {
+		return nil
+	}
+	return o.Preview
+}
+
+// forcing reports whether generated files edited by hand are overwritten
+func (o *Output) forcing() bool {
+	return o != nil && o.Force
+}
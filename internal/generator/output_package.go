@@ -0,0 +1,102 @@
+package generator
+
+import (
+	"fmt"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
+)
+
+// outputPackageName determines the package name of the generated files
+// The package of the existing Go files in output_dir wins over the directory name, so an
+// output_dir of "." holding package main generates into package main instead of clashing with it
+func outputPackageName(cfg *config.Config) (string, error) {
+	return packageNameForDir(cfg, cfg.Paths.OutputDir)
+}
+
+// packageNameForDir determines the package name of files generated into a directory relative to the project root
+func packageNameForDir(cfg *config.Config, outputDir string) (string, error) {
+	packages, err := existingPackages(cfg.ResolvePath(outputDir))
+	if err != nil {
+		return "", fmt.Errorf("error reading output_dir %s: %w", outputDir, err)
+	}
+
+	switch len(packages) {
+	case 0:
+		// No Go files yet, e.g., "./internal/api" -> "api"
+		absDir, err := filepath.Abs(cfg.ResolvePath(outputDir))
+		if err != nil {
+			return "", fmt.Errorf("error resolving output_dir %s: %w", outputDir, err)
+		}
+		name := filepath.Base(absDir)
+		if !token.IsIdentifier(name) {
+			return "", fmt.Errorf("output_dir %s has no Go files and its directory name %q is not a valid package name (rename the directory or point paths.output_dir at a package directory such as \"./internal/api\")", outputDir, name)
+		}
+		return name, nil
+	case 1:
+		for name := range packages {
+			return name, nil
+		}
+	}
+
+	var names []string
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var details []string
+	for _, name := range names {
+		details = append(details, fmt.Sprintf("package %s (%s)", name, strings.Join(packages[name], ", ")))
+	}
+	return "", fmt.Errorf("output_dir %s contains more than one package: %s; generated files must belong to a single package (move the extra files or set paths.output_dir to a dedicated package directory, e.g. paths.output_dir: \"./internal/api\")", outputDir, strings.Join(details, "; "))
+}
+
+// existingPackages maps the package names declared by Go files in a directory to those files
+// Test files, files excluded by build constraints and files previously generated by taskw are skipped
+func existingPackages(dir string) (map[string][]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make(map[string][]string)
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if match, err := build.Default.MatchFile(dir, name); err != nil || !match {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		// Files written by taskw are overwritten on every run
+		if header, _ := cutLine(content); isGeneratedHeader(string(header)) {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, path, content, parser.PackageClauseOnly)
+		if err != nil {
+			// Files that don't parse can't tell us anything about the package
+			continue
+		}
+		packages[file.Name.Name] = append(packages[file.Name.Name], name)
+	}
+
+	return packages, nil
+}
@@ -0,0 +1,156 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// PackageDocGenerator generates per-package doc.go files summarizing the scanned API surface
+type PackageDocGenerator struct {
+	config *config.Config
+	output *Output
+}
+
+// NewPackageDocGenerator creates a new package documentation generator
+func NewPackageDocGenerator(cfg *config.Config, out *Output) *PackageDocGenerator {
+	return &PackageDocGenerator{
+		config: cfg,
+		output: out,
+	}
+}
+
+// packageDoc holds the documented API surface of a single package directory
+type packageDoc struct {
+	Package   string
+	Dir       string
+	Routes    []packageDocRoute
+	Handlers  []string
+	Providers []scanner.ProviderFunction
+}
+
+// packageDocRoute is a route entry in a package doc
+type packageDocRoute struct {
+	HTTPMethod string
+	Path       string
+	Handler    string   // e.g., "Handler.GetUser"
+	DocLines   []string // From @Summary and @Description
+}
+
+// GeneratePackageDocs writes a doc file into every scanned package directory
+// Returns the written files and the files skipped because they were not generated by taskw
+func (g *PackageDocGenerator) GeneratePackageDocs(result *scanner.ScanResult) ([]string, []string, error) {
+	if !g.config.Generation.PackageDocs.Enabled {
+		return nil, nil, nil
+	}
+
+	tmplContent, err := readTemplate(g.config, "templates/package_doc.tmpl")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading package doc template: %w", err)
+	}
+
+	tmpl, err := template.New("package_doc").Funcs(template.FuncMap{"join": strings.Join}).Parse(string(tmplContent))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing package doc template: %w", err)
+	}
+
+	var written, skipped []string
+	for _, doc := range g.collectPackageDocs(result) {
+		outputPath := filepath.Join(doc.Dir, g.config.Generation.PackageDocs.OutputFile)
+
+		// Never overwrite hand-written package documentation
+		if handWritten(g.config.Root, outputPath) {
+			skipped = append(skipped, outputPath)
+			continue
+		}
+
+		var buf strings.Builder
+		if err := tmpl.Execute(&buf, doc); err != nil {
+			return written, skipped, fmt.Errorf("error executing package doc template for %s: %w", doc.Dir, err)
+		}
+
+		if err := g.output.writeGeneratedFile(g.config.Root, outputPath, buf.String()); err != nil {
+			return written, skipped, err
+		}
+		written = append(written, outputPath)
+	}
+
+	return written, skipped, nil
+}
+
+// collectPackageDocs groups handlers, routes, and providers by package directory
+func (g *PackageDocGenerator) collectPackageDocs(result *scanner.ScanResult) []*packageDoc {
+	docs := make(map[string]*packageDoc)
+	docFor := func(filePath, pkg string) *packageDoc {
+		dir := filepath.Dir(filePath)
+		if _, exists := docs[dir]; !exists {
+			docs[dir] = &packageDoc{Package: pkg, Dir: dir}
+		}
+		return docs[dir]
+	}
+
+	handlerSeen := make(map[string]bool)
+	handlerNames := make(map[string]string) // dir + function name -> "Receiver.Function"
+	for _, handler := range result.Handlers {
+		doc := docFor(handler.FilePath, handler.Package)
+		name := handler.HandlerName + "." + handler.FunctionName
+		if handler.IsFunction {
+			name = handler.FunctionName
+		}
+		if !handlerSeen[doc.Dir+name] {
+			handlerSeen[doc.Dir+name] = true
+			doc.Handlers = append(doc.Handlers, name)
+		}
+		if _, exists := handlerNames[doc.Dir+handler.FunctionName]; !exists || !handler.IsInterfaceBased {
+			handlerNames[doc.Dir+handler.FunctionName] = name
+		}
+	}
+
+	for _, route := range result.Routes {
+		if route.FilePath == "" {
+			continue
+		}
+		doc := docFor(route.FilePath, route.Package)
+		handlerName, exists := handlerNames[doc.Dir+route.MethodName]
+		if !exists {
+			handlerName = route.MethodName
+		}
+		doc.Routes = append(doc.Routes, packageDocRoute{
+			HTTPMethod: route.HTTPMethod,
+			Path:       route.Path,
+			Handler:    handlerName,
+			DocLines:   route.DocLines(),
+		})
+	}
+
+	for _, provider := range result.Providers {
+		doc := docFor(provider.FilePath, provider.Package)
+		doc.Providers = append(doc.Providers, provider)
+	}
+
+	// Sort everything for deterministic output
+	var sorted []*packageDoc
+	for _, doc := range docs {
+		sort.Strings(doc.Handlers)
+		sort.Slice(doc.Routes, func(i, j int) bool {
+			if doc.Routes[i].Path != doc.Routes[j].Path {
+				return doc.Routes[i].Path < doc.Routes[j].Path
+			}
+			return doc.Routes[i].HTTPMethod < doc.Routes[j].HTTPMethod
+		})
+		sort.Slice(doc.Providers, func(i, j int) bool {
+			return doc.Providers[i].FunctionName < doc.Providers[j].FunctionName
+		})
+		sorted = append(sorted, doc)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Dir < sorted[j].Dir
+	})
+
+	return sorted
+}
@@ -0,0 +1,246 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// ParamGenerator generates per-route helpers parsing the UUID path parameters documented with @Param
+type ParamGenerator struct {
+	config    *config.Config
+	output    *Output
+	framework routeFramework
+}
+
+// NewParamGenerator creates a new path parameter helper generator
+func NewParamGenerator(cfg *config.Config, out *Output) (*ParamGenerator, error) {
+	framework, err := lookupRouteFramework(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &ParamGenerator{
+		config:    cfg,
+		output:    out,
+		framework: framework,
+	}, nil
+}
+
+// paramFile holds the parameter helpers of a single package directory
+type paramFile struct {
+	Package string
+	Dir     string
+	Funcs   []paramFunc
+}
+
+// paramFunc is the helper parsing the UUID path parameters of one route
+type paramFunc struct {
+	Name   string // e.g., "ParseGetUserParams"
+	Struct string // Struct returned for several parameters, e.g. "GetMemberParams", empty for one
+	Route  string // e.g., "GET /users/{id}"
+	Params []paramField
+}
+
+// paramField is a UUID path parameter of a route
+type paramField struct {
+	Name  string // Parameter name in the path, e.g. "orgId"
+	Field string // Struct field, e.g. "OrgID"
+}
+
+// GenerateParams writes the parameter helpers into every package declaring routes with UUID path parameters
+// Returns the written files and the files skipped because they were not generated by taskw
+func (g *ParamGenerator) GenerateParams(routes []scanner.RouteMapping) ([]string, []string, error) {
+	if !g.config.Generation.Params.Enabled {
+		return nil, nil, nil
+	}
+
+	tmplContent, err := readTemplate(g.config, "templates/params.tmpl")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading params template: %w", err)
+	}
+
+	tmpl, err := template.New("params").Parse(string(tmplContent))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing params template: %w", err)
+	}
+
+	ctxParams, ctxArgs, paramValue, imports := g.frameworkBindings()
+	failureDoc := "On failure it answers 400 Bad Request, return the error from the handler"
+	switch g.framework.Name {
+	case config.FrameworkGin:
+		failureDoc = "On failure the request is aborted with 400 Bad Request, return from the handler"
+	case config.FrameworkChi, config.FrameworkNetHTTP:
+		failureDoc = "On failure 400 Bad Request has been written, return from the handler"
+	}
+
+	var written, skipped []string
+	for _, file := range g.collectParamFiles(routes) {
+		outputPath := filepath.Join(file.Dir, g.config.Generation.Params.OutputFile)
+
+		// Never overwrite a hand-written file of the same name
+		if handWritten(g.config.Root, outputPath) {
+			skipped = append(skipped, outputPath)
+			continue
+		}
+
+		data := struct {
+			paramFile
+			Framework  string
+			Imports    []string
+			CtxParams  string
+			CtxArgs    string
+			ParamValue string
+			FailureDoc string
+		}{
+			paramFile:  file,
+			Framework:  g.framework.Name,
+			Imports:    imports,
+			CtxParams:  ctxParams,
+			CtxArgs:    ctxArgs,
+			ParamValue: paramValue,
+			FailureDoc: failureDoc,
+		}
+
+		var buf strings.Builder
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return written, skipped, fmt.Errorf("error executing params template for %s: %w", file.Dir, err)
+		}
+
+		if err := g.output.writeGeneratedFile(g.config.Root, outputPath, buf.String()); err != nil {
+			return written, skipped, err
+		}
+		written = append(written, outputPath)
+	}
+
+	return written, skipped, nil
+}
+
+// frameworkBindings returns how the helpers receive the request and read a path parameter
+// with the configured framework, along with the imports they need
+func (g *ParamGenerator) frameworkBindings() (ctxParams, ctxArgs, paramValue string, imports []string) {
+	imports = []string{`"fmt"`}
+	switch g.framework.Name {
+	case config.FrameworkGin:
+		imports = append(imports, `"net/http"`)
+		ctxParams, ctxArgs, paramValue = "c *gin.Context", "c", "c.Param(name)"
+	case config.FrameworkChi:
+		imports = append(imports, `"net/http"`)
+		ctxParams, ctxArgs, paramValue = "w http.ResponseWriter, r *http.Request", "w, r", "chi.URLParam(r, name)"
+	case config.FrameworkNetHTTP:
+		ctxParams, ctxArgs, paramValue = "w http.ResponseWriter, r *http.Request", "w, r", "r.PathValue(name)"
+	default:
+		ctxParams, ctxArgs, paramValue = "c *fiber.Ctx", "c", "c.Params(name)"
+		if g.config.FiberVersion() == 3 {
+			ctxParams = "c fiber.Ctx"
+		}
+	}
+
+	for _, frameworkImport := range g.framework.Imports {
+		if !containsString(imports, frameworkImport) {
+			imports = append(imports, frameworkImport)
+		}
+	}
+	return ctxParams, ctxArgs, paramValue, append(imports, `"github.com/google/uuid"`)
+}
+
+// collectParamFiles groups the routes with UUID path parameters by package directory
+func (g *ParamGenerator) collectParamFiles(routes []scanner.RouteMapping) []paramFile {
+	files := make(map[string]*paramFile)
+	names := make(map[string]int) // dir + method name -> number of routes with UUID parameters
+
+	type routeParams struct {
+		route  scanner.RouteMapping
+		params []paramField
+	}
+	var collected []routeParams
+	for _, route := range routes {
+		if route.FilePath == "" {
+			continue
+		}
+		var params []paramField
+		for _, param := range route.PathParams {
+			if param.IsUUID() && pathDeclaresParam(route.Path, param.Name) {
+				params = append(params, paramField{Name: param.Name, Field: paramFieldName(param.Name)})
+			}
+		}
+		if len(params) == 0 {
+			continue
+		}
+		collected = append(collected, routeParams{route: route, params: params})
+		names[filepath.Dir(route.FilePath)+route.MethodName]++
+	}
+
+	for _, entry := range collected {
+		route := entry.route
+		dir := filepath.Dir(route.FilePath)
+		if _, exists := files[dir]; !exists {
+			files[dir] = &paramFile{Package: route.Package, Dir: dir}
+		}
+
+		// Methods of the same name on different handlers of a package are told apart by their receiver
+		base := route.MethodName
+		if names[dir+route.MethodName] > 1 && route.HandlerName != "" {
+			base = upperFirst(route.HandlerName) + route.MethodName
+		}
+
+		fn := paramFunc{
+			Name:   "Parse" + base + "Params",
+			Route:  route.HTTPMethod + " " + route.Path,
+			Params: entry.params,
+		}
+		if len(entry.params) > 1 {
+			fn.Struct = base + "Params"
+		}
+		files[dir].Funcs = append(files[dir].Funcs, fn)
+	}
+
+	sorted := make([]paramFile, 0, len(files))
+	for _, file := range files {
+		sort.Slice(file.Funcs, func(i, j int) bool {
+			return file.Funcs[i].Name < file.Funcs[j].Name
+		})
+		sorted = append(sorted, *file)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Dir < sorted[j].Dir
+	})
+	return sorted
+}
+
+// pathDeclaresParam checks if a route path has a parameter of the given name, e.g. /users/{id} or /users/:id
+func pathDeclaresParam(path, name string) bool {
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "{"+name+"}" || segment == ":"+name {
+			return true
+		}
+	}
+	return false
+}
+
+// paramWordPattern matches the words of a parameter name, e.g. "org", "Id" in "orgId"
+var paramWordPattern = regexp.MustCompile(`[A-Za-z][a-z0-9]*|[0-9]+`)
+
+// paramInitialisms are the words written in upper case in field names, e.g. "ID"
+var paramInitialisms = map[string]bool{"id": true, "uuid": true, "url": true, "api": true}
+
+// paramFieldName turns a path parameter name into an exported field name, e.g. "orgId" and "org_id" -> "OrgID"
+func paramFieldName(name string) string {
+	var field strings.Builder
+	for _, word := range paramWordPattern.FindAllString(name, -1) {
+		if paramInitialisms[strings.ToLower(word)] {
+			field.WriteString(strings.ToUpper(word))
+			continue
+		}
+		field.WriteString(upperFirst(word))
+	}
+	if field.Len() == 0 {
+		return "Param"
+	}
+	return field.String()
+}
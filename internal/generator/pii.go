@@ -0,0 +1,191 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// PIIReportGenerator generates a report of the endpoints handling personal data from @PII annotations
+type PIIReportGenerator struct {
+	config *config.Config
+	output *Output
+}
+
+// NewPIIReportGenerator creates a new PII report generator
+func NewPIIReportGenerator(cfg *config.Config, out *Output) *PIIReportGenerator {
+	return &PIIReportGenerator{
+		config: cfg,
+		output: out,
+	}
+}
+
+// piiRoute is a route handling personal data
+type piiRoute struct {
+	Route      scanner.RouteMapping
+	Handler    string   // e.g., "user.Handler.GetUser"
+	Categories []string // e.g., ["email", "name"]
+	Sources    []string // Where the categories come from, e.g., ["@PII", "user.User.email"]
+}
+
+// GenerateReport writes the PII report and returns the number of routes handling personal data
+func (g *PIIReportGenerator) GenerateReport(result *scanner.ScanResult) (int, error) {
+	if !g.config.Generation.PII.Enabled {
+		return 0, nil
+	}
+
+	routes := collectPIIRoutes(result)
+
+	fields := append([]scanner.PIIField{}, result.PIIFields...)
+	sort.SliceStable(fields, func(i, j int) bool {
+		if fields[i].Struct != fields[j].Struct {
+			return fields[i].Struct < fields[j].Struct
+		}
+		return fields[i].Line < fields[j].Line
+	})
+
+	tmplContent, err := readTemplate(g.config, "templates/pii_report.tmpl")
+	if err != nil {
+		return 0, fmt.Errorf("error reading PII report template: %w", err)
+	}
+
+	tmpl, err := template.New("pii_report").Funcs(template.FuncMap{
+		"join": strings.Join,
+	}).Parse(string(tmplContent))
+	if err != nil {
+		return 0, fmt.Errorf("error parsing PII report template: %w", err)
+	}
+
+	data := struct {
+		Routes []piiRoute
+		Fields []scanner.PIIField
+	}{
+		Routes: routes,
+		Fields: fields,
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return 0, fmt.Errorf("error executing PII report template: %w", err)
+	}
+
+	outputPath := g.config.Generation.PII.OutputFile
+	if err := g.output.writeFileAtomic(g.config.Root, outputPath, []byte(buf.String())); err != nil {
+		return 0, fmt.Errorf("failed to write file %s: %w", outputPath, err)
+	}
+
+	return len(routes), nil
+}
+
+// collectPIIRoutes returns the routes annotated with @PII or whose request/response types,
+// directly or through nested struct fields, contain @PII fields
+func collectPIIRoutes(result *scanner.ScanResult) []piiRoute {
+	fieldsByStruct := make(map[string][]scanner.PIIField)
+	for _, field := range result.PIIFields {
+		fieldsByStruct[field.Struct] = append(fieldsByStruct[field.Struct], field)
+	}
+	nested := make(map[string][]string)
+	for _, st := range result.Structs {
+		nested[st.Struct] = append(nested[st.Struct], st.FieldTypes...)
+	}
+
+	var routes []piiRoute
+	for _, route := range result.Routes {
+		var categories, sources []string
+		if len(route.PII) > 0 {
+			categories = appendMissing(categories, route.PII...)
+			sources = append(sources, "@PII")
+		}
+
+		// Follow the documented schemas through nested struct fields
+		visited := make(map[string]bool)
+		pending := append([]string{}, route.Schemas...)
+		for len(pending) > 0 {
+			structName := strings.TrimLeft(pending[0], "*[]")
+			pending = pending[1:]
+			if visited[structName] {
+				continue
+			}
+			visited[structName] = true
+
+			for _, field := range fieldsByStruct[structName] {
+				if field.JSONName == "" {
+					continue // Excluded from request/response bodies
+				}
+				categories = appendMissing(categories, field.Categories...)
+				sources = appendMissing(sources, field.Struct+"."+field.Field)
+			}
+			pending = append(pending, nested[structName]...)
+		}
+
+		if len(categories) == 0 {
+			continue
+		}
+
+		handler := route.Package + "." + route.MethodName
+		if !route.IsFunction {
+			handler = route.Package + "." + route.HandlerName + "." + route.MethodName
+		}
+		routes = append(routes, piiRoute{
+			Route:      route,
+			Handler:    handler,
+			Categories: categories,
+			Sources:    sources,
+		})
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Route.Path != routes[j].Route.Path {
+			return routes[i].Route.Path < routes[j].Route.Path
+		}
+		return routes[i].Route.HTTPMethod < routes[j].Route.HTTPMethod
+	})
+
+	return routes
+}
+
+// applyPIIExtensions marks the operations and schema properties handling personal data with x-pii,
+// listing the categories of the @PII annotations
+func applyPIIExtensions(spec map[string]interface{}, result *scanner.ScanResult) {
+	definitions, _ := spec["definitions"].(map[string]interface{})
+	for _, field := range result.PIIFields {
+		if field.JSONName == "" {
+			continue // Not part of request/response bodies
+		}
+		definition, _ := definitions[field.Struct].(map[string]interface{})
+		properties, _ := definition["properties"].(map[string]interface{})
+		property, _ := properties[field.JSONName].(map[string]interface{})
+		if property == nil {
+			continue
+		}
+		property["x-pii"] = field.Categories
+	}
+
+	paths, _ := spec["paths"].(map[string]interface{})
+	for _, route := range collectPIIRoutes(result) {
+		for path, pathItem := range paths {
+			if specPathKey(path) != specPathKey(route.Route.Path) {
+				continue
+			}
+			operations, _ := pathItem.(map[string]interface{})
+			if operation, ok := operations[strings.ToLower(route.Route.HTTPMethod)].(map[string]interface{}); ok {
+				operation["x-pii"] = route.Categories
+			}
+			break
+		}
+	}
+}
+
+// appendMissing appends the values not already present
+func appendMissing(values []string, additions ...string) []string {
+	for _, value := range additions {
+		if !containsString(values, value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,485 @@
+package generator
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"maps"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// PortGenerator generates service interfaces (ports) from the methods handlers call on their service fields,
+// so handlers of codebases built on concrete services can depend on interfaces instead
+type PortGenerator struct {
+	config   *config.Config
+	output   *Output
+	packages map[string][]*ast.File // Parsed package directories
+	warnings []string               // Ports left out because their calls can't be listed in an interface
+}
+
+// NewPortGenerator creates a new port generator
+func NewPortGenerator(cfg *config.Config, out *Output) *PortGenerator {
+	return &PortGenerator{
+		config:   cfg,
+		output:   out,
+		packages: make(map[string][]*ast.File),
+	}
+}
+
+// portFile holds the ports of a single handler package directory
+type portFile struct {
+	Package string
+	Dir     string
+	Imports []string
+	Ports   []port
+}
+
+// port is the interface of a concrete service handlers depend on, listing the methods they call
+type port struct {
+	Name    string // e.g., "ServicePort"
+	Service string // Concrete service type as referred to from the handler package, e.g. "Service" or "order.Service"
+	UsedBy  string // Handler types calling the service, e.g. "Handler" or "Handler and AdminHandler"
+	Methods []portMethod
+
+	handlers []string
+	called   map[string]bool
+	ref      serviceRef
+}
+
+// portMethod is a service method called by a handler
+type portMethod struct {
+	Name      string // e.g., "GetUser"
+	Signature string // Parameters and results, e.g. "(ctx context.Context, id string) (*User, error)"
+}
+
+// serviceRef is a concrete service type a handler field refers to
+type serviceRef struct {
+	Name       string // Type name, e.g. "Service"
+	Package    string // Name the handler file refers to the declaring package by, empty when it is the handler's
+	ImportPath string // Import path of the declaring package
+	Dir        string // Directory of the declaring package
+}
+
+// GeneratePorts writes the ports of every handler package whose handlers call methods on a service field
+// Returns the written files and the files skipped because they were not generated by taskw
+func (g *PortGenerator) GeneratePorts(handlers []scanner.HandlerFunction) ([]string, []string, error) {
+	if !g.config.Generation.Ports.Enabled {
+		return nil, nil, nil
+	}
+
+	tmplContent, err := readTemplate(g.config, "templates/ports.tmpl")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading ports template: %w", err)
+	}
+
+	tmpl, err := template.New("ports").Parse(string(tmplContent))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing ports template: %w", err)
+	}
+
+	files, err := g.collectPortFiles(handlers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var written, skipped []string
+	for _, file := range files {
+		outputPath := filepath.Join(file.Dir, g.config.Generation.Ports.OutputFile)
+
+		// Never overwrite a hand-written file of the same name
+		if handWritten(g.config.Root, outputPath) {
+			skipped = append(skipped, outputPath)
+			continue
+		}
+
+		var buf strings.Builder
+		if err := tmpl.Execute(&buf, file); err != nil {
+			return written, skipped, fmt.Errorf("error executing ports template for %s: %w", file.Dir, err)
+		}
+
+		if err := g.output.writeGeneratedFile(g.config.Root, outputPath, buf.String()); err != nil {
+			return written, skipped, err
+		}
+		written = append(written, outputPath)
+	}
+
+	return written, skipped, nil
+}
+
+// collectPortFiles derives the ports of every handler package, in directory order
+func (g *PortGenerator) collectPortFiles(handlers []scanner.HandlerFunction) ([]portFile, error) {
+	// Handler types by package directory, with the name of their package
+	receivers := make(map[string]map[string]bool)
+	packageNames := make(map[string]string)
+	for _, handler := range handlers {
+		if handler.IsFunction {
+			continue
+		}
+		receiver := handler.HandlerName
+		if handler.IsInterfaceBased {
+			receiver = handler.ImplementerName
+		}
+		dir := filepath.Dir(handler.FilePath)
+		if receivers[dir] == nil {
+			receivers[dir] = make(map[string]bool)
+		}
+		receivers[dir][receiver] = true
+		packageNames[dir] = handler.Package
+	}
+
+	dirs := make([]string, 0, len(receivers))
+	for dir := range receivers {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	var files []portFile
+	for _, dir := range dirs {
+		file, err := g.buildPortFile(dir, packageNames[dir], sortedKeys(receivers[dir]))
+		if err != nil {
+			return nil, err
+		}
+		if len(file.Ports) > 0 {
+			files = append(files, *file)
+		}
+	}
+	return files, nil
+}
+
+// buildPortFile finds the calls of the handler types of a package on their service fields
+// and resolves the signatures of the called methods
+func (g *PortGenerator) buildPortFile(dir, pkg string, receivers []string) (*portFile, error) {
+	files, err := g.parse(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing package %s: %w", dir, err)
+	}
+
+	// Ports by the service they abstract, e.g. "example.com/api/internal/order.Service"
+	ports := make(map[string]*port)
+	for _, receiver := range receivers {
+		st, stFile := findStructDecl(files, receiver)
+		if st == nil {
+			continue
+		}
+
+		// Service fields of the handler by name
+		fields := make(map[string]serviceRef)
+		for _, field := range st.Fields.List {
+			ref, ok := g.serviceField(dir, field.Type, stFile)
+			if !ok {
+				continue
+			}
+			for _, name := range field.Names {
+				fields[name.Name] = ref
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		for _, call := range fieldCalls(files, receiver) {
+			ref, ok := fields[call.field]
+			if !ok {
+				continue
+			}
+			key := ref.ImportPath + "." + ref.Name
+			p := ports[key]
+			if p == nil {
+				p = &port{ref: ref, called: make(map[string]bool)}
+				ports[key] = p
+			}
+			p.called[call.method] = true
+			if !containsString(p.handlers, receiver) {
+				p.handlers = append(p.handlers, receiver)
+			}
+		}
+	}
+
+	file := &portFile{Package: pkg, Dir: dir}
+	imports := make(map[string]string)
+	names := make(map[string]int)
+	for _, p := range ports {
+		names[p.ref.Name]++
+	}
+
+	for _, key := range sortedKeys(ports) {
+		p := ports[key]
+		skip, err := g.resolveMethods(p, imports)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
+		}
+		if skip != "" {
+			g.warnings = append(g.warnings, fmt.Sprintf("%s: no port generated for %s, %s", dir, p.ref.Name, skip))
+			continue
+		}
+		if len(p.Methods) == 0 {
+			continue
+		}
+
+		p.Name = p.ref.Name + "Port"
+		p.Service = p.ref.Name
+		if p.ref.Package != "" {
+			p.Service = p.ref.Package + "." + p.ref.Name
+			imports[p.ref.ImportPath] = p.ref.Package
+			// Services of other packages sharing a type name, e.g. order.Service and user.Service
+			if names[p.ref.Name] > 1 {
+				p.Name = upperFirst(p.ref.Package) + p.Name
+			}
+		}
+		p.UsedBy = strings.Join(p.handlers, " and ")
+		file.Ports = append(file.Ports, *p)
+	}
+
+	sort.Slice(file.Ports, func(i, j int) bool {
+		return file.Ports[i].Name < file.Ports[j].Name
+	})
+	file.Imports = importSpecs(imports)
+	return file, nil
+}
+
+// serviceField resolves a handler field type naming a service, e.g. *Service or *order.Service
+func (g *PortGenerator) serviceField(dir string, expr ast.Expr, file *ast.File) (serviceRef, bool) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if !g.config.Generation.Ports.ServiceSuffix(t.Name) {
+			return serviceRef{}, false
+		}
+		return serviceRef{Name: t.Name, ImportPath: g.config.PackageImportPath(dir), Dir: dir}, true
+	case *ast.SelectorExpr:
+		pkgIdent, ok := t.X.(*ast.Ident)
+		if !ok || !g.config.Generation.Ports.ServiceSuffix(t.Sel.Name) {
+			return serviceRef{}, false
+		}
+		importPath, ok := fileImports(file)[pkgIdent.Name]
+		if !ok {
+			return serviceRef{}, false
+		}
+		// Services of other modules can't be read, their packages aren't scanned
+		serviceDir, ok := g.config.PackageDir(importPath)
+		if !ok {
+			return serviceRef{}, false
+		}
+		return serviceRef{Name: t.Sel.Name, Package: pkgIdent.Name, ImportPath: importPath, Dir: serviceDir}, true
+	}
+	return serviceRef{}, false
+}
+
+// resolveMethods renders the signatures of the methods handlers call on a service, in declaration order
+// Services that already are interfaces need no port and get no methods. Calls an interface can't list,
+// e.g. of promoted methods, return why the port is skipped instead
+func (g *PortGenerator) resolveMethods(p *port, imports map[string]string) (string, error) {
+	files, err := g.parse(p.ref.Dir)
+	if err != nil {
+		return "", fmt.Errorf("error parsing package %s: %w", p.ref.Dir, err)
+	}
+	if iface, _ := findInterfaceDecl(files, p.ref.Name); iface != nil {
+		return "", nil
+	}
+
+	// Imports are only kept once every method resolved, a skipped port mustn't leave unused ones
+	portImports := maps.Clone(imports)
+	var methods []portMethod
+	declared := make(map[string]bool)
+	for _, file := range files {
+		q := &typeQualifier{
+			pkg:         p.ref.Package,
+			pkgImport:   p.ref.ImportPath,
+			local:       p.ref.Package == "",
+			fileImports: fileImports(file),
+			imports:     portImports,
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 || receiverTypeName(fn.Recv.List[0].Type) != p.ref.Name || !p.called[fn.Name.Name] {
+				continue
+			}
+
+			signature, err := portSignature(q, fn.Type)
+			if err != nil {
+				return fmt.Sprintf("the signature of %s.%s can't be rendered: %v", p.ref.Name, fn.Name.Name, err), nil
+			}
+			methods = append(methods, portMethod{Name: fn.Name.Name, Signature: signature})
+			declared[fn.Name.Name] = true
+		}
+	}
+
+	// Func-typed fields and methods promoted from embedded fields aren't declared on the service itself
+	st, _ := findStructDecl(files, p.ref.Name)
+	for _, method := range sortedKeys(p.called) {
+		if declared[method] {
+			continue
+		}
+		if st != nil && hasFieldNamed(st, method) {
+			return fmt.Sprintf("%s calls the %s.%s field, which is not a method", strings.Join(p.handlers, ", "), p.ref.Name, method), nil
+		}
+		return fmt.Sprintf("%s calls %s.%s, which %s doesn't declare (promoted methods are not supported)",
+			strings.Join(p.handlers, ", "), p.ref.Name, method, p.ref.Name), nil
+	}
+
+	p.Methods = methods
+	maps.Copy(imports, portImports)
+	return "", nil
+}
+
+// hasFieldNamed checks if a struct declares a field of the given name
+func hasFieldNamed(st *ast.StructType, name string) bool {
+	for _, field := range st.Fields.List {
+		for _, fieldName := range field.Names {
+			if fieldName.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Warnings returns why ports were left out, e.g. a handler calling a promoted method
+func (g *PortGenerator) Warnings() []string {
+	return g.warnings
+}
+
+// parse parses the non-test Go files of a package directory once
+func (g *PortGenerator) parse(dir string) ([]*ast.File, error) {
+	if files, ok := g.packages[dir]; ok {
+		return files, nil
+	}
+	files, err := parsePackageDir(g.config.ResolvePath(dir))
+	if err != nil {
+		return nil, err
+	}
+	g.packages[dir] = files
+	return files, nil
+}
+
+// fieldCall is a call of a method on a field of a handler's receiver, e.g. h.service.GetUser(ctx, id)
+type fieldCall struct {
+	field  string // e.g., "service"
+	method string // e.g., "GetUser"
+}
+
+// fieldCalls returns the calls every method of a type makes on the fields of its receiver
+func fieldCalls(files []*ast.File, typeName string) []fieldCall {
+	var calls []fieldCall
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || fn.Recv == nil || len(fn.Recv.List) != 1 || receiverTypeName(fn.Recv.List[0].Type) != typeName {
+				continue
+			}
+			names := fn.Recv.List[0].Names
+			if len(names) == 0 || names[0].Name == "_" {
+				continue
+			}
+			recv := names[0].Name
+
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				method, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				field, ok := method.X.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				if ident, ok := field.X.(*ast.Ident); ok && ident.Name == recv {
+					calls = append(calls, fieldCall{field: field.Sel.Name, method: method.Sel.Name})
+				}
+				return true
+			})
+		}
+	}
+	return calls
+}
+
+// findStructDecl finds a struct type declaration and the file declaring it
+func findStructDecl(files []*ast.File, name string) (*ast.StructType, *ast.File) {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if ts.Name.Name != name {
+					continue
+				}
+				if st, ok := ts.Type.(*ast.StructType); ok {
+					return st, file
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// portSignature renders the parameters and results of a method as written, with types qualified for the
+// handler package, e.g. "(ctx context.Context, id string) (*order.Order, error)"
+func portSignature(q *typeQualifier, fn *ast.FuncType) (string, error) {
+	params, err := portFields(q, fn.Params)
+	if err != nil {
+		return "", err
+	}
+	signature := "(" + params + ")"
+
+	if fn.Results == nil || len(fn.Results.List) == 0 {
+		return signature, nil
+	}
+	results, err := portFields(q, fn.Results)
+	if err != nil {
+		return "", err
+	}
+	if len(fn.Results.List) == 1 && len(fn.Results.List[0].Names) == 0 {
+		return signature + " " + results, nil
+	}
+	return signature + " (" + results + ")", nil
+}
+
+// portFields renders a field list keeping its names, e.g. "ctx context.Context, a, b int"
+func portFields(q *typeQualifier, fields *ast.FieldList) (string, error) {
+	if fields == nil {
+		return "", nil
+	}
+
+	var list []string
+	for _, field := range fields.List {
+		typeName, err := q.expr(field.Type)
+		if err != nil {
+			return "", err
+		}
+		if len(field.Names) == 0 {
+			list = append(list, typeName)
+			continue
+		}
+		names := make([]string, len(field.Names))
+		for i, name := range field.Names {
+			names[i] = name.Name
+		}
+		list = append(list, strings.Join(names, ", ")+" "+typeName)
+	}
+	return strings.Join(list, ", "), nil
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
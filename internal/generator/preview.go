@@ -0,0 +1,246 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Preview keeps the files generators write in memory instead of writing them, so generated code
+// can be compared with the files on disk without touching them
+type Preview struct {
+	mu    sync.Mutex
+	files map[string]previewFile
+	order []string // Paths in the order they were first written
+}
+
+// previewFile is the latest content generated for a file
+type previewFile struct {
+	abs     string // Where the file is, its path resolved against the project root
+	content []byte
+}
+
+// FileChange is a generated file whose content differs from the file on disk
+type FileChange struct {
+	Path    string
+	Existed bool // False when the file doesn't exist on disk yet
+	Old     []byte
+	New     []byte
+}
+
+// NewPreview creates a preview to capture the files of a run in, set as Output.Preview. Nothing is
+// written meanwhile. External tools such as wire and swag write their files themselves and must not be run
+func NewPreview() *Preview {
+	return &Preview{files: make(map[string]previewFile)}
+}
+
+// Changes compares the captured files with the files on disk and returns the ones that differ,
+// in the order they were generated
+func (p *Preview) Changes() ([]FileChange, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var changes []FileChange
+	for _, path := range p.order {
+		file := p.files[path]
+		generated := file.content
+		current, err := os.ReadFile(file.abs)
+		switch {
+		case os.IsNotExist(err):
+			changes = append(changes, FileChange{Path: path, New: generated})
+		case err != nil:
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		case !bytes.Equal(current, generated):
+			changes = append(changes, FileChange{Path: path, Existed: true, Old: current, New: generated})
+		}
+	}
+	return changes, nil
+}
+
+// capture keeps the latest content written to path, found at abs
+func (p *Preview) capture(path, abs string, content []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	path = filepath.Clean(path)
+	if _, seen := p.files[path]; !seen {
+		p.order = append(p.order, path)
+	}
+	p.files[path] = previewFile{abs: abs, content: bytes.Clone(content)}
+}
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// maxDiffCells bounds the table comparing the changed region of a file line by line, larger
+// regions are shown as removed and added as a whole
+const maxDiffCells = 1 << 22
+
+// Diff returns the change as a unified diff, with a/ and b/ prefixed paths as git writes them
+// so it can be applied with git apply or patch -p1
+func (c FileChange) Diff() string {
+	name := filepath.ToSlash(c.Path)
+	oldName := "a/" + name
+	if !c.Existed {
+		oldName = "/dev/null"
+	}
+
+	ops := diffLines(splitLines(c.Old), splitLines(c.New))
+
+	// Line numbers in the old and new file before each operation
+	oldAt := make([]int, len(ops)+1)
+	newAt := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldAt[i+1], newAt[i+1] = oldAt[i], newAt[i]
+		if op.kind != '+' {
+			oldAt[i+1]++
+		}
+		if op.kind != '-' {
+			newAt[i+1]++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ b/%s\n", oldName, name)
+	for i := 0; i < len(ops); {
+		for i < len(ops) && ops[i].kind == ' ' {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+
+		// A hunk runs until the unchanged lines between two changes are too many to show all of them
+		start := max(i-diffContext, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContext {
+				end = min(end+diffContext, len(ops))
+				break
+			}
+			end = next
+		}
+
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(oldAt[start], oldAt[end]), hunkRange(newAt[start], newAt[end]))
+		for _, op := range ops[start:end] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return b.String()
+}
+
+// hunkRange formats the lines from and to of a file in a hunk header, starting at 1
+func hunkRange(from, to int) string {
+	count := to - from
+	if count == 0 {
+		return fmt.Sprintf("%d,0", from)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", from+1)
+	}
+	return fmt.Sprintf("%d,%d", from+1, count)
+}
+
+// diffOp is a line kept (' '), removed ('-') or added ('+')
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// splitLines splits content into lines, keeping their line breaks
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the operations turning old into new, keeping the longest common subsequence of lines
+func diffLines(old, new []string) []diffOp {
+	// Generated files mostly change in one place, leave the unchanged start and end out of the table
+	prefix := 0
+	for prefix < len(old) && prefix < len(new) && old[prefix] == new[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(new)-prefix && old[len(old)-1-suffix] == new[len(new)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, line := range old[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+
+	a, b := old[prefix:len(old)-suffix], new[prefix:len(new)-suffix]
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for _, line := range a {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{'+', line})
+		}
+	} else {
+		// common[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+		common := make([][]int32, len(a)+1)
+		for i := range common {
+			common[i] = make([]int32, len(b)+1)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			for j := len(b) - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					common[i][j] = common[i+1][j+1] + 1
+				} else {
+					common[i][j] = max(common[i+1][j], common[i][j+1])
+				}
+			}
+		}
+
+		i, j := 0, 0
+		for i < len(a) && j < len(b) {
+			switch {
+			case a[i] == b[j]:
+				ops = append(ops, diffOp{' ', a[i]})
+				i++
+				j++
+			case common[i+1][j] >= common[i][j+1]:
+				ops = append(ops, diffOp{'-', a[i]})
+				i++
+			default:
+				ops = append(ops, diffOp{'+', b[j]})
+				j++
+			}
+		}
+		for ; i < len(a); i++ {
+			ops = append(ops, diffOp{'-', a[i]})
+		}
+		for ; j < len(b); j++ {
+			ops = append(ops, diffOp{'+', b[j]})
+		}
+	}
+
+	for _, line := range old[len(old)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
@@ -0,0 +1,155 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// formatOps writes diff operations one per line, prefixed by their kind
+func formatOps(ops []diffOp) string {
+	var b strings.Builder
+	for _, op := range ops {
+		b.WriteByte(op.kind)
+		b.WriteString(op.line)
+	}
+	return b.String()
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want string
+	}{
+		{name: "identical", old: "a\nb\n", new: "a\nb\n", want: " a\n b\n"},
+		{name: "both empty", old: "", new: "", want: ""},
+		{name: "new file", old: "", new: "a\nb\n", want: "+a\n+b\n"},
+		{name: "removed file", old: "a\nb\n", new: "", want: "-a\n-b\n"},
+		{name: "line added", old: "a\nc\n", new: "a\nb\nc\n", want: " a\n+b\n c\n"},
+		{name: "line removed", old: "a\nb\nc\n", new: "a\nc\n", want: " a\n-b\n c\n"},
+		{name: "line replaced", old: "a\nx\nc\n", new: "a\ny\nc\n", want: " a\n-x\n+y\n c\n"},
+		{name: "line moved", old: "a\nb\nc\n", new: "c\na\nb\n", want: "+c\n a\n b\n-c\n"},
+		{name: "changes at both ends", old: "x\na\nb\ny\n", new: "a\nb\n", want: "-x\n a\n b\n-y\n"},
+		{name: "last line break added", old: "a\nb", new: "a\nb\n", want: " a\n-b+b\n"},
+	}
+
+	for _, tt := range tests {
+		got := formatOps(diffLines(splitLines([]byte(tt.old)), splitLines([]byte(tt.new))))
+		if got != tt.want {
+			t.Errorf("%s: diffLines(%q, %q) =\n%s\nwant\n%s", tt.name, tt.old, tt.new, got, tt.want)
+		}
+	}
+}
+
+func TestDiffLinesLargeRegion(t *testing.T) {
+	// A changed region too large to compare line by line is removed and added as a whole
+	var old, new []string
+	for i := 0; i < 2100; i++ {
+		old = append(old, fmt.Sprintf("old %d\n", i))
+		new = append(new, fmt.Sprintf("new %d\n", i))
+	}
+	old = append([]string{"package api\n"}, old...)
+	new = append([]string{"package api\n"}, new...)
+
+	ops := diffLines(old, new)
+	if len(ops) != 1+2*2100 {
+		t.Fatalf("diffLines returned %d operations, want %d", len(ops), 1+2*2100)
+	}
+	if ops[0] != (diffOp{' ', "package api\n"}) {
+		t.Errorf("first operation = %+v, want the common line kept", ops[0])
+	}
+	for i, op := range ops[1:] {
+		want := byte('-')
+		if i >= 2100 {
+			want = '+'
+		}
+		if op.kind != want {
+			t.Fatalf("operation %d = %+v, want %q", i+1, op, want)
+		}
+	}
+}
+
+// numberedLines returns the lines 1 to n, replacing the ones in changed
+func numberedLines(n int, changed map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if line, ok := changed[i]; ok {
+			b.WriteString(line + "\n")
+			continue
+		}
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	return b.String()
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		change FileChange
+		want   string
+	}{
+		{
+			name:   "new file",
+			change: FileChange{Path: "internal/api/routes_gen.go", New: []byte("a\nb\n")},
+			want:   "--- /dev/null\n+++ b/internal/api/routes_gen.go\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:   "emptied file",
+			change: FileChange{Path: "routes_gen.go", Existed: true, Old: []byte("a\n")},
+			want:   "--- a/routes_gen.go\n+++ b/routes_gen.go\n@@ -1 +0,0 @@\n-a\n",
+		},
+		{
+			name: "one change with context",
+			change: FileChange{
+				Path:    "routes_gen.go",
+				Existed: true,
+				Old:     []byte(numberedLines(8, nil)),
+				New:     []byte(numberedLines(8, map[int]string{4: "changed"})),
+			},
+			want: "--- a/routes_gen.go\n+++ b/routes_gen.go\n@@ -1,7 +1,7 @@\n" +
+				" line 1\n line 2\n line 3\n-line 4\n+changed\n line 5\n line 6\n line 7\n",
+		},
+		{
+			name: "changes far apart in separate hunks",
+			change: FileChange{
+				Path:    "routes_gen.go",
+				Existed: true,
+				Old:     []byte(numberedLines(20, nil)),
+				New:     []byte(numberedLines(20, map[int]string{2: "first", 19: "second"})),
+			},
+			want: "--- a/routes_gen.go\n+++ b/routes_gen.go\n" +
+				"@@ -1,5 +1,5 @@\n line 1\n-line 2\n+first\n line 3\n line 4\n line 5\n" +
+				"@@ -16,5 +16,5 @@\n line 16\n line 17\n line 18\n-line 19\n+second\n line 20\n",
+		},
+		{
+			name: "changes close together in one hunk",
+			change: FileChange{
+				Path:    "routes_gen.go",
+				Existed: true,
+				Old:     []byte(numberedLines(10, nil)),
+				New:     []byte(numberedLines(10, map[int]string{2: "first", 8: "second"})),
+			},
+			want: "--- a/routes_gen.go\n+++ b/routes_gen.go\n@@ -1,10 +1,10 @@\n" +
+				" line 1\n-line 2\n+first\n line 3\n line 4\n line 5\n line 6\n line 7\n-line 8\n+second\n line 9\n line 10\n",
+		},
+		{
+			name:   "no newline at end of file",
+			change: FileChange{Path: "routes_gen.go", Existed: true, Old: []byte("a\nb"), New: []byte("a\nc")},
+			want: "--- a/routes_gen.go\n+++ b/routes_gen.go\n@@ -1,2 +1,2 @@\n" +
+				" a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+		{
+			name:   "unchanged",
+			change: FileChange{Path: "routes_gen.go", Existed: true, Old: []byte("a\n"), New: []byte("a\n")},
+			want:   "--- a/routes_gen.go\n+++ b/routes_gen.go\n",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.change.Diff(); got != tt.want {
+			t.Errorf("%s: Diff() =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// RecordingGenerator generates middleware that captures request/response pairs into JSON fixtures
+type RecordingGenerator struct {
+	config    *config.Config
+	output    *Output
+	framework routeFramework
+}
+
+// NewRecordingGenerator creates a new recording middleware generator
+func NewRecordingGenerator(cfg *config.Config, out *Output) (*RecordingGenerator, error) {
+	framework, err := lookupRouteFramework(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &RecordingGenerator{
+		config:    cfg,
+		output:    out,
+		framework: framework,
+	}, nil
+}
+
+// recordedRoute is a route captured by the recording middleware
+type recordedRoute struct {
+	Key     string   // e.g., "GET /users/:id", matching the router's registered pattern
+	Fixture string   // Fixture subdirectory, e.g., "user_GetUser"
+	Scrub   []string // JSON fields redacted from captured bodies
+}
+
+// GenerateRecording writes the recording middleware into the output directory
+func (g *RecordingGenerator) GenerateRecording(routes []scanner.RouteMapping) error {
+	if !g.config.Generation.Recording.Enabled {
+		return nil
+	}
+
+	// Recording relies on the router exposing the matched route pattern after the handler runs
+	if g.framework.Name != config.FrameworkFiber {
+		return fmt.Errorf("recording middleware is only supported for the fiber framework, got %q", g.framework.Name)
+	}
+
+	sampleRate := g.config.Generation.Recording.SampleRate
+	if sampleRate < 0 || sampleRate > 1 {
+		return fmt.Errorf("generation.recording.sample_rate must be between 0 and 1, got %v", sampleRate)
+	}
+
+	outputPackage, err := outputPackageName(g.config)
+	if err != nil {
+		return err
+	}
+
+	tmplContent, err := readTemplate(g.config, "templates/recording.tmpl")
+	if err != nil {
+		return fmt.Errorf("error reading recording template: %w", err)
+	}
+
+	tmpl, err := template.New("recording").Parse(string(tmplContent))
+	if err != nil {
+		return fmt.Errorf("error parsing recording template: %w", err)
+	}
+
+	ctxType := "*fiber.Ctx"
+	if g.config.FiberVersion() == 3 {
+		ctxType = "fiber.Ctx"
+	}
+
+	data := struct {
+		Package     string
+		Imports     []string
+		CtxType     string
+		FixturesDir string
+		SampleRate  float64
+		Routes      []recordedRoute
+	}{
+		Package:     outputPackage,
+		Imports:     append([]string{`"encoding/json"`, `"math/rand"`, `"os"`, `"path/filepath"`, `"strings"`, `"time"`}, g.framework.Imports...),
+		CtxType:     ctxType,
+		FixturesDir: g.config.Generation.Recording.FixturesDir,
+		SampleRate:  sampleRate,
+		Routes:      g.collectRecordedRoutes(routes),
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("error executing recording template: %w", err)
+	}
+
+	outputPath := filepath.Join(g.config.Paths.OutputDir, g.config.Generation.Recording.OutputFile)
+	return g.output.writeGeneratedFile(g.config.Root, outputPath, buf.String())
+}
+
+// collectRecordedRoutes builds the route table of the recording middleware, sorted by key
+func (g *RecordingGenerator) collectRecordedRoutes(routes []scanner.RouteMapping) []recordedRoute {
+	recorded := make([]recordedRoute, 0, len(routes))
+	for _, route := range routes {
+		scrub := make([]string, 0, len(route.Scrub))
+		for _, field := range route.Scrub {
+			scrub = append(scrub, strings.ToLower(field))
+		}
+
+		recorded = append(recorded, recordedRoute{
+			Key:     strings.ToUpper(route.HTTPMethod) + " " + g.framework.ConvertPath(route.Path),
+			Fixture: route.ImportName + "_" + route.MethodName,
+			Scrub:   scrub,
+		})
+	}
+
+	sort.Slice(recorded, func(i, j int) bool {
+		return recorded[i].Key < recorded[j].Key
+	})
+	return recorded
+}
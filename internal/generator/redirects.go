@@ -0,0 +1,310 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/scanner"
+	"gopkg.in/yaml.v3"
+)
+
+// Route migration modes
+const (
+	MigrationRedirect = "redirect" // Answer old paths with a redirect to the new path
+	MigrationProxy    = "proxy"    // Serve old paths with the handler of the new path
+)
+
+// migrationDateLayout is the layout of removal dates, e.g. "2026-12-31"
+const migrationDateLayout = "2006-01-02"
+
+// httpDateLayout is the date format of HTTP headers such as Sunset
+const httpDateLayout = "Mon, 02 Jan 2006 15:04:05 GMT"
+
+// RouteMigrations is the file recording the path migrations created by taskw migrate path
+type RouteMigrations struct {
+	Migrations []RouteMigration `yaml:"migrations"`
+}
+
+// RouteMigration moves every route under an old path prefix to a new one
+type RouteMigration struct {
+	From        string `yaml:"from"`             // Old path prefix, e.g. "/old/users"
+	To          string `yaml:"to"`               // New path prefix, e.g. "/api/v1/users"
+	Mode        string `yaml:"mode"`             // "redirect" (default) or "proxy"
+	Status      int    `yaml:"status,omitempty"` // Redirect status, 308 (default) or 301
+	RemoveAfter string `yaml:"remove_after"`     // Last day the old paths are served, e.g. "2026-12-31"
+}
+
+// LoadRouteMigrations reads the route migrations file at a path relative to the project root,
+// a missing file holds no migrations
+func LoadRouteMigrations(root, path string) (*RouteMigrations, error) {
+	content, err := os.ReadFile(config.JoinRoot(root, path))
+	if os.IsNotExist(err) {
+		return &RouteMigrations{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading route migrations: %w", err)
+	}
+
+	migrations := &RouteMigrations{}
+	if err := yaml.Unmarshal(content, migrations); err != nil {
+		return nil, fmt.Errorf("error parsing route migrations %s: %w", path, err)
+	}
+	for i := range migrations.Migrations {
+		if err := migrations.Migrations[i].normalize(); err != nil {
+			return nil, fmt.Errorf("route migrations %s: %w", path, err)
+		}
+	}
+	return migrations, nil
+}
+
+// Save writes the route migrations file at a path relative to the project root
+func (m *RouteMigrations) Save(root, path string) error {
+	content, err := yaml.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("error encoding route migrations: %w", err)
+	}
+
+	// Saved by taskw migrate path, outside of any generation run
+	header := "# Path migrations created by taskw migrate path, registered by RegisterRedirects\n"
+	if err := new(Output).writeFileAtomic(root, path, append([]byte(header), content...)); err != nil {
+		return fmt.Errorf("error writing route migrations: %w", err)
+	}
+	return nil
+}
+
+// Add records a migration, replacing any previous migration of the same old prefix
+func (m *RouteMigrations) Add(migration RouteMigration) error {
+	if err := migration.normalize(); err != nil {
+		return err
+	}
+
+	for i, existing := range m.Migrations {
+		if existing.From == migration.From {
+			m.Migrations[i] = migration
+			return nil
+		}
+	}
+	m.Migrations = append(m.Migrations, migration)
+	return nil
+}
+
+// ParsePathMigration parses an "/old:/new" argument
+func ParsePathMigration(arg string) (string, string, error) {
+	from, to, found := strings.Cut(arg, ":/")
+	if !found || from == "" {
+		return "", "", fmt.Errorf("invalid path migration %q (expected /old/path:/new/path)", arg)
+	}
+	return from, "/" + to, nil
+}
+
+// normalize validates a migration and fills in its defaults
+func (m *RouteMigration) normalize() error {
+	m.From = "/" + strings.Trim(m.From, "/")
+	m.To = "/" + strings.Trim(m.To, "/")
+	if m.From == m.To {
+		return fmt.Errorf("migration %s:%s moves a path onto itself", m.From, m.To)
+	}
+	if strings.ContainsAny(m.From, "{}:*") {
+		return fmt.Errorf("migration %s:%s: the old prefix can't contain path parameters", m.From, m.To)
+	}
+
+	if m.Mode == "" {
+		m.Mode = MigrationRedirect
+	}
+	if m.Mode != MigrationRedirect && m.Mode != MigrationProxy {
+		return fmt.Errorf("migration %s:%s: unknown mode %q (expected redirect or proxy)", m.From, m.To, m.Mode)
+	}
+
+	if m.Mode == MigrationRedirect && m.Status == 0 {
+		m.Status = 308
+	}
+	if m.Mode == MigrationRedirect && m.Status != 301 && m.Status != 308 {
+		return fmt.Errorf("migration %s:%s: redirect status must be 301 or 308, got %d", m.From, m.To, m.Status)
+	}
+	if m.Mode == MigrationProxy {
+		m.Status = 0
+	}
+
+	if _, err := time.Parse(migrationDateLayout, m.RemoveAfter); err != nil {
+		return fmt.Errorf("migration %s:%s: remove_after %q is not a date like 2026-12-31", m.From, m.To, m.RemoveAfter)
+	}
+	return nil
+}
+
+// Expired checks if the removal date of a migration has passed
+func (m RouteMigration) Expired(now time.Time) bool {
+	removeAfter, err := time.Parse(migrationDateLayout, m.RemoveAfter)
+	if err != nil {
+		return false
+	}
+	return now.After(removeAfter.AddDate(0, 0, 1))
+}
+
+// RedirectGenerator generates the registrations serving the old paths of migrated routes
+type RedirectGenerator struct {
+	config    *config.Config
+	output    *Output
+	framework routeFramework
+	style     routeStyle
+}
+
+// NewRedirectGenerator creates a new redirect generator
+func NewRedirectGenerator(cfg *config.Config, out *Output) (*RedirectGenerator, error) {
+	style, err := lookupRouteStyle(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &RedirectGenerator{
+		config:    cfg,
+		output:    out,
+		framework: style.framework,
+		style:     style,
+	}, nil
+}
+
+// redirectRoute is an old path registered for a migrated route
+type redirectRoute struct {
+	Method      string // e.g., "Get"
+	OldPath     string // e.g., "/old/users/:id"
+	NewPath     string // e.g., "/api/v1/users/:id"
+	Proxy       bool
+	Status      int
+	Sunset      string // HTTP date of the removal, e.g. "Thu, 31 Dec 2026 23:59:59 GMT"
+	RemoveAfter string // e.g. "2026-12-31"
+}
+
+// RedirectsFile returns the path of the generated redirect registrations
+func RedirectsFile(cfg *config.Config) string {
+	return filepath.Join(cfg.Paths.OutputDir, cfg.Generation.Redirects.OutputFile)
+}
+
+// GenerateRedirects writes the redirect registrations of the active migrations
+// Returns the migrations past their removal date, which are no longer registered
+func (g *RedirectGenerator) GenerateRedirects(migrations *RouteMigrations, routes []scanner.RouteMapping, now time.Time) ([]RouteMigration, error) {
+	// Proxying restarts routing on the new path, which relies on Fiber
+	if g.framework.Name != config.FrameworkFiber {
+		return nil, fmt.Errorf("path migrations are only supported for the fiber framework, got %q", g.framework.Name)
+	}
+	// Old paths are registered on the generated Router
+	if !g.config.Generation.Routes.Enabled {
+		return nil, fmt.Errorf("path migrations require route generation (set generation.routes.enabled: true)")
+	}
+
+	existing := make(map[string]bool)
+	for _, route := range routes {
+		existing[route.HTTPMethod+" "+specPathKey(route.Path)] = true
+	}
+
+	var expired []RouteMigration
+	var redirects []redirectRoute
+	for _, migration := range migrations.Migrations {
+		if migration.Expired(now) {
+			expired = append(expired, migration)
+			continue
+		}
+
+		removeAfter, _ := time.Parse(migrationDateLayout, migration.RemoveAfter)
+		sunset := removeAfter.Add(24*time.Hour - time.Second).UTC().Format(httpDateLayout)
+
+		matched := false
+		for _, route := range routes {
+			rest, ok := cutPathPrefix(route.Path, migration.To)
+			if !ok {
+				continue
+			}
+			matched = true
+
+			oldPath := migration.From + rest
+			if existing[route.HTTPMethod+" "+specPathKey(oldPath)] {
+				return nil, fmt.Errorf("migration %s:%s: %s %s is still served by a handler", migration.From, migration.To, route.HTTPMethod, oldPath)
+			}
+
+			redirects = append(redirects, redirectRoute{
+				Method:      g.framework.RouterMethod(route.HTTPMethod),
+				OldPath:     g.framework.ConvertPath(oldPath),
+				NewPath:     g.framework.ConvertPath(route.Path),
+				Proxy:       migration.Mode == MigrationProxy,
+				Status:      migration.Status,
+				Sunset:      sunset,
+				RemoveAfter: migration.RemoveAfter,
+			})
+		}
+
+		if !matched {
+			return nil, fmt.Errorf("migration %s:%s matches no scanned route under %s", migration.From, migration.To, migration.To)
+		}
+	}
+
+	sort.SliceStable(redirects, func(i, j int) bool {
+		if redirects[i].OldPath != redirects[j].OldPath {
+			return redirects[i].OldPath < redirects[j].OldPath
+		}
+		return redirects[i].Method < redirects[j].Method
+	})
+
+	outputPackage, err := outputPackageName(g.config)
+	if err != nil {
+		return nil, err
+	}
+
+	tmplContent, err := readTemplate(g.config, "templates/redirects.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("error reading redirects template: %w", err)
+	}
+
+	tmpl, err := template.New("redirects").Parse(string(tmplContent))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing redirects template: %w", err)
+	}
+
+	ctxType := "*fiber.Ctx"
+	if g.config.FiberVersion() == 3 {
+		ctxType = "fiber.Ctx"
+	}
+
+	data := struct {
+		Package   string
+		Imports   []string
+		CtxType   string
+		Redirects []redirectRoute
+		Decl      string // Declaration of RegisterRedirects in the configured style
+		App       string // e.g., "ar.app" or "app"
+		After     string // Function registering the scanned routes, e.g. "RegisterHandlers"
+	}{
+		Package:   outputPackage,
+		Imports:   append([]string{`"strings"`}, g.framework.Imports...),
+		CtxType:   ctxType,
+		Redirects: redirects,
+		Decl:      g.style.Declare("RegisterRedirects", false),
+		App:       g.style.App(),
+		After:     g.style.Func,
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("error executing redirects template: %w", err)
+	}
+
+	if err := g.output.writeGeneratedFile(g.config.Root, RedirectsFile(g.config), buf.String()); err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// cutPathPrefix returns the remainder of a path under a prefix, matching whole segments only
+// e.g., ("/api/v1/users/{id}", "/api/v1/users") -> "/{id}"
+func cutPathPrefix(path, prefix string) (string, bool) {
+	if path == prefix {
+		return "", true
+	}
+	if strings.HasPrefix(path, prefix+"/") {
+		return strings.TrimPrefix(path, prefix), true
+	}
+	return "", false
+}
@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
+)
+
+// routeStyle describes the shape of the generated registration functions, from generation.routes.style
+type routeStyle struct {
+	Name     string // e.g., "router"
+	Func     string // Name of the function registering the scanned routes, e.g. "RegisterHandlers"
+	Receiver string // Struct the registration functions are methods of, empty for the function style
+	Deps     string // Type holding the handlers the routes are registered with, e.g. "*Router", "*RouteDeps" or "*Server"
+
+	framework routeFramework
+}
+
+// lookupRouteStyle returns the configured registration style for the configured framework
+func lookupRouteStyle(cfg *config.Config) (routeStyle, error) {
+	framework, err := lookupRouteFramework(cfg)
+	if err != nil {
+		return routeStyle{}, err
+	}
+	style := routeStyle{
+		Name:      cfg.RouteStyle(),
+		Func:      cfg.RegisterFunc(),
+		framework: framework,
+	}
+	switch style.Name {
+	case config.RouteStyleFunction:
+		style.Deps = "*RouteDeps"
+	case config.RouteStyleServer:
+		style.Receiver = cfg.RouteReceiver()
+		style.Deps = "*" + style.Receiver
+	default:
+		style.Receiver = "Router"
+		style.Deps = "*Router"
+	}
+	return style, nil
+}
+
+// IsRouter reports whether the routes are registered from a generated Router struct
+func (s routeStyle) IsRouter() bool {
+	return s.Name == config.RouteStyleRouter
+}
+
+// App returns the expression of the application inside registration functions, e.g. "ar.app" or "app"
+func (s routeStyle) App() string {
+	if s.IsRouter() {
+		return "ar." + s.framework.AppParam
+	}
+	return s.framework.AppParam
+}
+
+// Declare returns the declaration of a registration function after the func keyword, taking the
+// application unless the Router holds it and, for the scanned routes of the function style, the handlers
+// e.g. "(ar *Router) RegisterSwaggerUI()", "RegisterRoutes(app *fiber.App, deps *RouteDeps)" or
+// "(s *Server) RegisterHandlers(app *fiber.App)"
+func (s routeStyle) Declare(name string, handlers bool) string {
+	var params []string
+	if !s.IsRouter() {
+		params = append(params, s.framework.AppParam+" "+s.framework.AppType)
+	}
+	if handlers && s.Name == config.RouteStyleFunction {
+		params = append(params, "deps "+s.Deps)
+	}
+
+	declaration := name + "(" + strings.Join(params, ", ") + ")"
+	switch s.Name {
+	case config.RouteStyleRouter:
+		return "(ar *Router) " + declaration
+	case config.RouteStyleServer:
+		return "(s *" + s.Receiver + ") " + declaration
+	}
+	return declaration
+}
+
+// Call returns the call of a registration function from outside, given the expression of the value
+// of the Deps type and of the application, e.g. "router.RegisterHandlers()" or "RegisterRoutes(app, deps)"
+func (s routeStyle) Call(name string, handlers bool, deps, app string) string {
+	switch {
+	case s.IsRouter():
+		return deps + "." + name + "()"
+	case s.Name == config.RouteStyleServer:
+		return deps + "." + name + "(" + app + ")"
+	case handlers:
+		return name + "(" + app + ", " + deps + ")"
+	}
+	return name + "(" + app + ")"
+}
+
+// handlerField returns the expression of a handler field inside the function registering the scanned
+// routes, e.g. "ar.userHandler", "deps.UserHandler" or "s.userHandler"
+func (s routeStyle) handlerField(field string) string {
+	switch s.Name {
+	case config.RouteStyleFunction:
+		return "deps." + upperFirst(field)
+	case config.RouteStyleServer:
+		return "s." + field
+	}
+	return "ar." + field
+}
@@ -1,12 +1,14 @@
 package generator
 
 import (
-	"embed"
 	"fmt"
 	"go/format"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -14,27 +16,35 @@ import (
 	"github.com/nkaewam/taskw/internal/scanner"
 )
 
-//go:embed templates/*.tmpl
-var templateFS embed.FS
-
-// RouteGenerator generates Fiber route registration code
+// RouteGenerator generates route registration code for the configured framework
 type RouteGenerator struct {
-	config *config.Config
+	config    *config.Config
+	output    *Output
+	framework routeFramework
+	style     routeStyle
 }
 
 // NewRouteGenerator creates a new route generator
-func NewRouteGenerator(cfg *config.Config) *RouteGenerator {
-	return &RouteGenerator{
-		config: cfg,
+func NewRouteGenerator(cfg *config.Config, out *Output) (*RouteGenerator, error) {
+	style, err := lookupRouteStyle(cfg)
+	if err != nil {
+		return nil, err
 	}
+	return &RouteGenerator{
+		config:    cfg,
+		output:    out,
+		framework: style.framework,
+		style:     style,
+	}, nil
 }
 
 // HandlerInfo represents information about a handler for dependency injection
 type HandlerInfo struct {
-	FieldName string // e.g., "userHandler"
-	ParamName string // e.g., "userHandler"
-	TypeName  string // e.g., "user.Handler"
-	Package   string // e.g., "user"
+	FieldName  string // e.g., "userHandler"
+	ParamName  string // e.g., "userHandler"
+	TypeName   string // e.g., "user.Handler"
+	Package    string // e.g., "user"
+	ImportPath string // e.g., "github.com/acme/api/internal/domain/user/v2"
 }
 
 // GenerateRoutes generates the routes_gen.go file
@@ -43,6 +53,33 @@ func (g *RouteGenerator) GenerateRoutes(handlers []scanner.HandlerFunction, rout
 		return nil
 	}
 
+	if g.config.Generation.Routes.Hooks && g.framework.Name != config.FrameworkFiber {
+		return fmt.Errorf("route hooks are only supported for the %s framework, got %s", config.FrameworkFiber, g.framework.Name)
+	}
+	// Hooks are registered on the Router, and so are the middlewares of chi routes
+	if g.config.Generation.Routes.Hooks && !g.style.IsRouter() {
+		return fmt.Errorf("route hooks require generation.routes.style %s, got %s", config.RouteStyleRouter, g.style.Name)
+	}
+	if g.framework.Name == config.FrameworkChi && !g.style.IsRouter() {
+		for _, route := range routes {
+			if len(route.Middlewares) > 0 {
+				return fmt.Errorf("@Middleware on %s.%s requires generation.routes.style %s with chi, the Router holds the middleware registry", route.Package, route.MethodName, config.RouteStyleRouter)
+			}
+		}
+	}
+
+	outputPackage, err := outputPackageName(g.config)
+	if err != nil {
+		return err
+	}
+
+	// Report broken snippets up front rather than from the middle of the route template
+	for method, routeMethod := range g.config.Generation.Routes.Methods {
+		if _, err := template.New(method).Parse(routeMethod.Register); err != nil {
+			return fmt.Errorf("invalid registration snippet for %s in generation.routes.methods: %w", strings.ToUpper(method), err)
+		}
+	}
+
 	// Organize routes by package for better structure
 	routesByPackage := g.organizeRoutesByPackage(routes)
 
@@ -56,13 +93,13 @@ func (g *RouteGenerator) GenerateRoutes(handlers []scanner.HandlerFunction, rout
 	outputPath := filepath.Join(g.config.Paths.OutputDir, g.config.Generation.Routes.OutputFile)
 
 	// Generate the file content
-	content, err := g.generateRouteFileContent(routesByPackage, imports, handlerInfo)
+	content, err := g.generateRouteFileContent(outputPackage, routesByPackage, imports, handlerInfo)
 	if err != nil {
 		return fmt.Errorf("error generating route file content: %w", err)
 	}
 
 	// Write to file (assuming a file writer utility will be available)
-	return writeGeneratedFile(outputPath, content)
+	return g.output.writeGeneratedFile(g.config.Root, outputPath, content)
 }
 
 // organizeRoutesByPackage groups routes by their package for better organization
@@ -71,8 +108,8 @@ func (g *RouteGenerator) organizeRoutesByPackage(routes []scanner.RouteMapping)
 
 	for _, route := range routes {
 		// Convert path format early for consistent sorting
-		route.Path = g.convertPathForFiber(route.Path)
-		routesByPackage[route.Package] = append(routesByPackage[route.Package], route)
+		route.Path = g.framework.ConvertPath(route.Path)
+		routesByPackage[route.ImportName] = append(routesByPackage[route.ImportName], route)
 	}
 
 	// Routes will be sorted globally later
@@ -82,17 +119,40 @@ func (g *RouteGenerator) organizeRoutesByPackage(routes []scanner.RouteMapping)
 
 // generateImports creates the import statements needed for the generated file
 func (g *RouteGenerator) generateImports(handlers []scanner.HandlerFunction, routes []scanner.RouteMapping, handlerInfo []HandlerInfo) []string {
-	imports := []string{
-		`"github.com/gofiber/fiber/v2"`,
+	imports := append([]string{}, g.framework.Imports...)
+	if g.style.IsRouter() {
+		imports = append(imports, g.framework.RouterOnly...)
+	} else if g.framework.Name == config.FrameworkChi && g.config.Generation.Routes.RouteIDHeader != "" {
+		// withRouteID wraps the http.HandlerFunc of chi routes
+		imports = append(imports, `"net/http"`)
 	}
 
-	// Add imports for handler packages
+	// Add imports for handler packages, the server style refers to the handlers through the fields of the server
 	packageSet := make(map[string]bool)
 	for _, handler := range handlerInfo {
-		// Derive the import path from the handler package
-		importPath := g.deriveHandlerImportPath(handler.Package)
-		if importPath != "" {
-			packageSet[fmt.Sprintf(`"%s"`, importPath)] = true
+		if handler.ImportPath != "" && g.style.Name != config.RouteStyleServer {
+			packageSet[importSpec(handler.Package, handler.ImportPath)] = true
+		}
+	}
+
+	// Package-level handler functions are referenced through their package directly
+	for _, route := range routes {
+		if !route.IsFunction {
+			continue
+		}
+		if importPath := g.config.PackageImportPath(filepath.Dir(route.FilePath)); importPath != "" {
+			packageSet[importSpec(route.ImportName, importPath)] = true
+		}
+	}
+
+	// Custom registrations bring their own imports, only needed when a route uses them
+	for _, route := range routes {
+		routeMethod, ok := g.config.Generation.Routes.Method(route.HTTPMethod)
+		if !ok || routeMethod.Register == "" {
+			continue
+		}
+		for _, spec := range routeMethod.Imports {
+			packageSet[methodImportSpec(spec)] = true
 		}
 	}
 
@@ -107,8 +167,24 @@ func (g *RouteGenerator) generateImports(handlers []scanner.HandlerFunction, rou
 	return imports
 }
 
-// generateRouteFileContent creates the actual file content
-func (g *RouteGenerator) generateRouteFileContent(routesByPackage map[string][]scanner.RouteMapping, imports []string, handlerInfo []HandlerInfo) (string, error) {
+// RegistrationOrder returns the routes in the order the generated router registers them, with their
+// paths in the router's syntax
+func (g *RouteGenerator) RegistrationOrder(routes []scanner.RouteMapping) []scanner.RouteMapping {
+	ordered := g.orderRoutes(g.organizeRoutesByPackage(routes))
+	if g.framework.Name != config.FrameworkChi {
+		return ordered
+	}
+
+	// chi registers routes sharing a middleware chain together in one group
+	var grouped []scanner.RouteMapping
+	for _, group := range g.groupRoutesByMiddleware(ordered) {
+		grouped = append(grouped, group.Routes...)
+	}
+	return grouped
+}
+
+// orderRoutes flattens the routes of every package, more specific routes first to avoid conflicts
+func (g *RouteGenerator) orderRoutes(routesByPackage map[string][]scanner.RouteMapping) []scanner.RouteMapping {
 	// Flatten routes from all packages into a single slice
 	// Process packages in deterministic order
 	var packageNames []string
@@ -141,23 +217,65 @@ func (g *RouteGenerator) generateRouteFileContent(routesByPackage map[string][]s
 		return allRoutes[i].Path < allRoutes[j].Path
 	})
 
+	return allRoutes
+}
+
+// generateRouteFileContent creates the actual file content
+func (g *RouteGenerator) generateRouteFileContent(outputPackage string, routesByPackage map[string][]scanner.RouteMapping, imports []string, handlerInfo []HandlerInfo) (string, error) {
+	allRoutes := g.orderRoutes(routesByPackage)
+
+	ctxType := "*fiber.Ctx"
+	if g.config.FiberVersion() == 3 {
+		ctxType = "fiber.Ctx"
+	}
+
+	// The function style passes the handlers in a struct with exported fields, e.g. deps.UserHandler
+	if g.style.Name == config.RouteStyleFunction {
+		exported := make([]HandlerInfo, len(handlerInfo))
+		for i, handler := range handlerInfo {
+			handler.FieldName = upperFirst(handler.FieldName)
+			exported[i] = handler
+		}
+		handlerInfo = exported
+	}
+
 	data := struct {
 		Package         string
 		Imports         []string
 		Routes          []scanner.RouteMapping
+		RouteGroups     []RouteGroup
 		Handlers        []HandlerInfo
+		Style           string // "router", "function" or "server"
+		RegisterFunc    string // e.g., "RegisterHandlers"
+		RegisterDecl    string // Declaration of the registration function, e.g. "(ar *Router) RegisterHandlers()"
+		App             string // Application routes are registered on, e.g. "ar.app" or "app"
+		Hooks           bool
+		RouteIDHeader   string // Response header set to the route ID, empty for none
+		CtxType         string // Fiber context type, "*fiber.Ctx" or "fiber.Ctx" for Fiber v3
 		GetRouterMethod func(method string) string
-		GetHandlerRef   func(pkg, handlerRef string) string
+		GetHandlerRef   func(route scanner.RouteMapping) string
+		// Renders the generation.routes.methods snippet of a route on a router expression, "" without one
+		CustomRegistration func(router string, route scanner.RouteMapping) (string, error)
 	}{
-		Package:         "api",
+		Package:         outputPackage,
 		Imports:         imports,
 		Routes:          allRoutes,
+		RouteGroups:     g.groupRoutesByMiddleware(allRoutes),
 		Handlers:        handlerInfo,
+		Style:           g.style.Name,
+		RegisterFunc:    g.style.Func,
+		RegisterDecl:    g.style.Declare(g.style.Func, true),
+		App:             g.style.App(),
+		Hooks:           g.config.Generation.Routes.Hooks,
+		RouteIDHeader:   g.config.Generation.Routes.RouteIDHeader,
+		CtxType:         ctxType,
 		GetRouterMethod: g.getRouterMethod,
 		GetHandlerRef:   g.getHandlerRef,
+
+		CustomRegistration: g.customRegistration,
 	}
 
-	tmplContent, err := templateFS.ReadFile("templates/routes.tmpl")
+	tmplContent, err := readTemplate(g.config, g.framework.Template)
 	if err != nil {
 		return "", fmt.Errorf("error reading route template: %w", err)
 	}
@@ -175,6 +293,32 @@ func (g *RouteGenerator) generateRouteFileContent(routesByPackage map[string][]s
 	return buf.String(), nil
 }
 
+// RouteGroup is a set of routes sharing the same middleware chain
+type RouteGroup struct {
+	Middlewares []string // Empty for routes without @Middleware annotations
+	Routes      []scanner.RouteMapping
+}
+
+// groupRoutesByMiddleware groups routes by their middleware chain, keeping the registration order
+// Routes without middleware come first, followed by one group per distinct middleware chain
+func (g *RouteGenerator) groupRoutesByMiddleware(routes []scanner.RouteMapping) []RouteGroup {
+	groups := []RouteGroup{{}}
+	groupIndex := map[string]int{"": 0}
+
+	for _, route := range routes {
+		key := strings.Join(route.Middlewares, ",")
+		index, exists := groupIndex[key]
+		if !exists {
+			index = len(groups)
+			groupIndex[key] = index
+			groups = append(groups, RouteGroup{Middlewares: route.Middlewares})
+		}
+		groups[index].Routes = append(groups[index].Routes, route)
+	}
+
+	return groups
+}
+
 // organizeRoutesByAPIGroups groups routes by their API prefix
 // Unused for now, but can be used in the future
 func (g *RouteGenerator) organizeRoutesByAPIGroups(routesByPackage map[string][]scanner.RouteMapping) map[string][]scanner.RouteMapping {
@@ -227,48 +371,73 @@ func (g *RouteGenerator) getAPIPrefix(path string) string {
 	return "/" // Default fallback
 }
 
-// getRouterMethod maps HTTP methods to Fiber router methods
+// getRouterMethod maps HTTP methods to the framework's router methods
 func (g *RouteGenerator) getRouterMethod(method string) string {
-	switch strings.ToUpper(method) {
-	case "GET":
-		return "Get"
-	case "POST":
-		return "Post"
-	case "PUT":
-		return "Put"
-	case "DELETE":
-		return "Delete"
-	case "PATCH":
-		return "Patch"
-	case "HEAD":
-		return "Head"
-	case "OPTIONS":
-		return "Options"
-	default:
-		return "All" // Fallback for unsupported methods
+	return g.framework.RouterMethod(method)
+}
+
+// getHandlerRef generates the handler reference for route registration, wrapped to set the route ID
+// header when generation.routes.route_id_header is set
+func (g *RouteGenerator) getHandlerRef(route scanner.RouteMapping) string {
+	handlerRef := g.handlerExpr(route)
+	if g.config.Generation.Routes.RouteIDHeader != "" {
+		return fmt.Sprintf("withRouteID(%s, %s)", strconv.Quote(route.RouteID()), handlerRef)
 	}
+	return handlerRef
 }
 
-// getHandlerRef generates the handler reference for route registration
-func (g *RouteGenerator) getHandlerRef(pkg, handlerRef string) string {
+// handlerExpr returns the expression referring to the handler of a route
+func (g *RouteGenerator) handlerExpr(route scanner.RouteMapping) string {
+	// Package-level functions are registered as is, e.g., "health.GetHealth"
+	if route.IsFunction {
+		return route.HandlerRef
+	}
+
 	// handlerRef comes from scanner as "userHandler.GetUsers"
-	// We need to convert it to "ar.userHandler.GetUsers" for Router pattern
+	// We need to convert it to "ar.userHandler.GetUsers" for Router pattern, or the field of the configured style
+	handlerRef := route.HandlerRef
 	parts := strings.Split(handlerRef, ".")
 	if len(parts) == 2 {
 		handlerName := parts[0] // e.g., "userHandler"
 		methodName := parts[1]  // e.g., "GetUsers"
-		return fmt.Sprintf("ar.%s.%s", handlerName, methodName)
+		return fmt.Sprintf("%s.%s", g.style.handlerField(handlerName), methodName)
 	}
 	return handlerRef
 }
 
-// convertPathForFiber converts OpenAPI/Swagger path parameters to Fiber format
-// Converts {param} to :param for Fiber router
-func (g *RouteGenerator) convertPathForFiber(path string) string {
-	// Convert {param} to :param for Fiber
-	converted := strings.ReplaceAll(path, "{", ":")
-	converted = strings.ReplaceAll(converted, "}", "")
-	return converted
+// MethodSnippetData is the data the registration snippets of generation.routes.methods are rendered with
+type MethodSnippetData struct {
+	Router  string // Router the route is registered on, e.g. "ar.app", or "r" inside a chi middleware group
+	Method  string // e.g., "PURGE"
+	Path    string // Path in the framework's syntax, e.g. "/cache/:key"
+	Handler string // Handler expression, e.g. "ar.cacheHandler.Purge"
+	Route   scanner.RouteMapping
+}
+
+// customRegistration renders the registration snippet configured for the method of a route,
+// returning "" for routes registered with the framework's own router method
+func (g *RouteGenerator) customRegistration(router string, route scanner.RouteMapping) (string, error) {
+	routeMethod, ok := g.config.Generation.Routes.Method(route.HTTPMethod)
+	if !ok || routeMethod.Register == "" {
+		return "", nil
+	}
+
+	tmpl, err := template.New(route.HTTPMethod).Parse(routeMethod.Register)
+	if err != nil {
+		return "", fmt.Errorf("invalid registration snippet for %s in generation.routes.methods: %w", route.HTTPMethod, err)
+	}
+	var buf strings.Builder
+	err = tmpl.Execute(&buf, MethodSnippetData{
+		Router:  router,
+		Method:  route.HTTPMethod,
+		Path:    route.Path,
+		Handler: g.getHandlerRef(route),
+		Route:   route,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error rendering registration snippet for %s %s: %w", route.HTTPMethod, route.Path, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
 }
 
 // isMoreSpecificRoute determines if pathA is more specific than pathB
@@ -298,7 +467,7 @@ func (g *RouteGenerator) calculateSpecificityScore(path string) int {
 
 	// Bonus for static segments, penalty for parameters
 	for _, segment := range segments {
-		if strings.HasPrefix(segment, ":") {
+		if isPathParameter(segment) {
 			score -= 100 // Parameter penalty
 		} else {
 			score += 100 // Static segment bonus
@@ -313,32 +482,52 @@ func (g *RouteGenerator) countPathParameters(path string) int {
 	count := 0
 	segments := strings.Split(path, "/")
 	for _, segment := range segments {
-		if strings.HasPrefix(segment, ":") {
+		if isPathParameter(segment) {
 			count++
 		}
 	}
 	return count
 }
 
+// isPathParameter checks if a path segment is a parameter in either :param or {param} syntax
+func isPathParameter(segment string) bool {
+	return strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "{")
+}
+
+// Handlers returns the handlers the generated router is constructed with, sorted by field name
+func (g *RouteGenerator) Handlers(handlers []scanner.HandlerFunction, routes []scanner.RouteMapping) []HandlerInfo {
+	return g.extractHandlerInfo(handlers, routes)
+}
+
+// AppType returns the application type the generated router registers on, e.g. "*fiber.App"
+func (g *RouteGenerator) AppType() string {
+	return g.framework.AppType
+}
+
 // extractHandlerInfo extracts unique handler information from routes for dependency injection
 func (g *RouteGenerator) extractHandlerInfo(handlers []scanner.HandlerFunction, routes []scanner.RouteMapping) []HandlerInfo {
 	handlerMap := make(map[string]HandlerInfo)
 
-	// Extract handler info from routes
+	// Extract handler info from routes, package-level functions need no handler struct
 	for _, route := range routes {
+		if route.IsFunction {
+			continue
+		}
+
 		// route.HandlerRef is like "userHandler.GetUsers"
 		parts := strings.Split(route.HandlerRef, ".")
 		if len(parts) == 2 {
 			handlerName := parts[0] // e.g., "userHandler"
-			pkg := route.Package    // e.g., "user"
+			pkg := route.ImportName // e.g., "user"
 
 			// Create handler info if not already present
 			if _, exists := handlerMap[handlerName]; !exists {
 				handlerMap[handlerName] = HandlerInfo{
-					FieldName: handlerName, // e.g., "userHandler"
-					ParamName: handlerName, // e.g., "userHandler"
-					TypeName:  g.getHandlerTypeName(pkg),
-					Package:   pkg,
+					FieldName:  handlerName, // e.g., "userHandler"
+					ParamName:  handlerName, // e.g., "userHandler"
+					TypeName:   g.getHandlerTypeName(route),
+					Package:    pkg,
+					ImportPath: g.config.PackageImportPath(filepath.Dir(route.FilePath)),
 				}
 			}
 		}
@@ -359,42 +548,60 @@ func (g *RouteGenerator) extractHandlerInfo(handlers []scanner.HandlerFunction,
 }
 
 // getHandlerTypeName generates the handler type name for dependency injection
-func (g *RouteGenerator) getHandlerTypeName(pkg string) string {
+func (g *RouteGenerator) getHandlerTypeName(route scanner.RouteMapping) string {
+	pkg, handlerName := route.ImportName, route.HandlerName
+
+	// Type-checked scanning knows the exact type, e.g. user.Handler for an interface implementation
+	if route.HandlerType != "" {
+		return route.HandlerType
+	}
+
+	// Receivers following the handler naming convention are used as is, e.g., *order.OrderController
+	if _, ok := g.config.Conventions.HandlerSuffix(handlerName); ok {
+		return fmt.Sprintf("*%s.%s", pkg, handlerName)
+	}
+
 	// For interface-based handlers, use pkg.Handler (e.g., user.Handler)
 	// For concrete handlers, use *pkg.Handler (e.g., *user.Handler)
 	// Default to pointer pattern for concrete struct handlers
 	return fmt.Sprintf("*%s.Handler", pkg)
 }
 
-// deriveHandlerImportPath derives the import path for a handler package
-func (g *RouteGenerator) deriveHandlerImportPath(pkg string) string {
-	// Use the project module from config and construct the path
-	// Assuming handlers are in internal/<pkg> relative to project root
-	if g.config != nil && g.config.Project.Module != "" {
-		return fmt.Sprintf("%s/internal/%s", g.config.Project.Module, pkg)
+// importSpec creates an import statement, named when the package is referred to by another name than
+// its directory, e.g. adminuser "github.com/acme/api/internal/admin/user"
+func importSpec(name, importPath string) string {
+	if name == "" || name == path.Base(importPath) {
+		return strconv.Quote(importPath)
 	}
-	// Fallback - this should be improved based on actual project structure
-	return fmt.Sprintf("internal/%s", pkg)
+	return name + " " + strconv.Quote(importPath)
 }
 
-// writeGeneratedFile writes content to a file with proper Go formatting
-func writeGeneratedFile(path, content string) error {
-	// Ensure the directory exists
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+// methodImportSpec creates an import statement from an import of generation.routes.methods, either
+// an import path or a name followed by the path, e.g. "cache github.com/acme/api/pkg/cache"
+func methodImportSpec(spec string) string {
+	name, importPath, named := strings.Cut(strings.TrimSpace(spec), " ")
+	if !named {
+		name, importPath = "", name
 	}
+	return importSpec(name, strings.Trim(strings.TrimSpace(importPath), `"`))
+}
+
+// formatWarnings receives the warnings of generated code failing to format
+var formatWarnings io.Writer = os.Stdout
 
+// writeGeneratedFile writes content to a file with proper Go formatting, at a path relative to the project root
+func (o *Output) writeGeneratedFile(root, path, content string) error {
 	// Format the generated Go code
 	formatted, err := format.Source([]byte(content))
 	if err != nil {
 		// If formatting fails, still write the unformatted content
 		// This helps with debugging template issues
-		fmt.Printf("Warning: Failed to format generated code: %v\n", err)
+		fmt.Fprintf(formatWarnings, "Warning: Failed to format generated code: %v\n", err)
 		formatted = []byte(content)
 	}
 
 	// Write the file
-	if err := os.WriteFile(path, formatted, 0644); err != nil {
+	if err := o.writeFileAtomic(root, path, formatted); err != nil {
 		return fmt.Errorf("failed to write file %s: %w", path, err)
 	}
 
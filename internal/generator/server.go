@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/nkaewam/taskw/internal/config"
+)
+
+// ServerGenerator generates the Server struct wiring the application to the generated router
+type ServerGenerator struct {
+	config    *config.Config
+	output    *Output
+	framework routeFramework
+}
+
+// NewServerGenerator creates a new server generator
+func NewServerGenerator(cfg *config.Config, out *Output) (*ServerGenerator, error) {
+	framework, err := lookupRouteFramework(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &ServerGenerator{
+		config:    cfg,
+		output:    out,
+		framework: framework,
+	}, nil
+}
+
+// ServerFile returns the path of the generated server wiring
+func ServerFile(cfg *config.Config) string {
+	return filepath.Join(cfg.Paths.OutputDir, cfg.Generation.Server.OutputFile)
+}
+
+// GenerateServer writes the Server struct, ProvideServer and RegisterRoutes into the output package
+func (g *ServerGenerator) GenerateServer() error {
+	if !g.config.Generation.Server.Enabled {
+		return nil
+	}
+
+	// The server registers routes through the generated Router
+	if !g.config.Generation.Routes.Enabled {
+		return fmt.Errorf("server generation requires route generation (set generation.routes.enabled: true)")
+	}
+	if style := g.config.RouteStyle(); style != config.RouteStyleRouter {
+		return fmt.Errorf("server generation requires generation.routes.style %s, got %s", config.RouteStyleRouter, style)
+	}
+
+	outputPackage, err := outputPackageName(g.config)
+	if err != nil {
+		return err
+	}
+
+	tmplContent, err := readTemplate(g.config, "templates/server.tmpl")
+	if err != nil {
+		return fmt.Errorf("error reading server template: %w", err)
+	}
+
+	tmpl, err := template.New("server").Parse(string(tmplContent))
+	if err != nil {
+		return fmt.Errorf("error parsing server template: %w", err)
+	}
+
+	// Old paths of migrated routes are registered along with the routes once generated
+	_, err = os.Stat(g.config.ResolvePath(RedirectsFile(g.config)))
+	redirects := err == nil
+	// So is the swagger UI, before them
+	_, err = os.Stat(g.config.ResolvePath(SwaggerUIFile(g.config)))
+	swaggerUI := err == nil && g.config.Generation.SwaggerUI.Enabled
+	// And the routes of the gateway, after them
+	_, err = os.Stat(g.config.ResolvePath(GatewayFile(g.config)))
+	gateway := err == nil && g.config.Generation.Gateway.Enabled
+
+	data := struct {
+		Package   string
+		AppType   string
+		AppImport string
+		Register  string // Router method registering the scanned routes, e.g. "RegisterHandlers"
+		Redirects bool
+		SwaggerUI bool
+		Gateway   bool
+	}{
+		Package:   outputPackage,
+		AppType:   g.framework.AppType,
+		AppImport: g.framework.AppImport,
+		Register:  g.config.RegisterFunc(),
+		Redirects: redirects,
+		SwaggerUI: swaggerUI,
+		Gateway:   gateway,
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("error executing server template: %w", err)
+	}
+
+	return g.output.writeGeneratedFile(g.config.Root, ServerFile(g.config), buf.String())
+}
@@ -0,0 +1,358 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// StaleField is a handler field of a hand-written server struct no scanned route uses anymore
+type StaleField struct {
+	FieldName string // e.g., "orderHandler"
+	TypeName  string // e.g., "*order.Handler"
+}
+
+// pruneFields removes the handler fields of the server struct whose handler no scanned route uses
+// anymore, with their constructor parameters, composite literal values and imports. Fields still
+// referenced elsewhere in the file are kept and reported, removing them would break the build
+func (s *ServerSyncer) pruneFields(filePath string, src []byte, structName string, handlers []HandlerInfo, result *ServerSyncResult) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", filePath, err)
+	}
+	serverStruct := findStruct(file, structName)
+	if serverStruct == nil {
+		return src, nil
+	}
+
+	importPaths := make(map[string]string) // Local name -> import path
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		importPaths[importName(spec, importPath)] = importPath
+	}
+
+	// Scanned handlers, by import path and type name and by field name
+	current := make(map[string]bool)
+	for _, handler := range handlers {
+		current[handler.ImportPath+"."+typeBase(handler.TypeName)] = true
+		current[handler.FieldName] = true
+	}
+
+	constructor := findConstructor(fset, file, structName)
+	var literal *ast.CompositeLit
+	if constructor != nil {
+		literal = findCompositeLiteral(constructor, structName)
+	}
+
+	fields := 0
+	for _, field := range serverStruct.Fields.List {
+		fields += max(len(field.Names), 1)
+	}
+
+	var edits []sourceEdit
+	removedParams := make(map[int]bool)
+	removedValues := make(map[int]bool)
+	stalePaths := make(map[string]bool)
+	index := -1 // Position of the field in an unkeyed composite literal
+	for _, field := range serverStruct.Fields.List {
+		index += max(len(field.Names), 1)
+		if len(field.Names) != 1 {
+			continue
+		}
+		name := field.Names[0].Name
+		typeName := exprString(fset, field.Type)
+		importPath, ok := s.handlerImport(typeName, importPaths)
+		if !ok || current[name] || current[importPath+"."+typeBase(typeName)] {
+			continue
+		}
+
+		stale := StaleField{FieldName: name, TypeName: typeName}
+		if selects(file, name) {
+			result.Referenced = append(result.Referenced, stale)
+			continue
+		}
+
+		// The constructor parameter must only feed the field, other uses would be left dangling
+		param, value := -1, -1
+		if constructor != nil {
+			param = findParam(fset, constructor, typeName)
+			value = findValue(literal, name, index, fields)
+			if param >= 0 {
+				paramName := constructor.Type.Params.List[param].Names[0].Name
+				uses := countIdent(constructor.Body, paramName)
+				if value >= 0 {
+					uses -= countIdent(literal.Elts[value], paramName)
+				}
+				if uses > 0 {
+					result.Referenced = append(result.Referenced, stale)
+					continue
+				}
+			}
+		}
+
+		result.Removed = append(result.Removed, stale)
+		stalePaths[importPath] = true
+		edits = append(edits, removeField(fset, src, field))
+		if param >= 0 {
+			removedParams[param] = true
+		}
+		if value >= 0 {
+			removedValues[value] = true
+		}
+	}
+	if len(result.Removed) == 0 {
+		return src, nil
+	}
+
+	if len(removedParams) > 0 {
+		params := make([]ast.Node, len(constructor.Type.Params.List))
+		for i, param := range constructor.Type.Params.List {
+			params[i] = param
+		}
+		edits = append(edits, removeItems(fset, src, params, removedParams)...)
+	}
+	if len(removedValues) > 0 {
+		values := make([]ast.Node, len(literal.Elts))
+		for i, value := range literal.Elts {
+			values[i] = value
+		}
+		edits = append(edits, removeItems(fset, src, values, removedValues)...)
+	}
+
+	pruned, err := format.Source(applyEdits(src, edits))
+	if err != nil {
+		return nil, fmt.Errorf("error formatting %s: %w", filePath, err)
+	}
+	return removeUnusedImports(filePath, pruned, stalePaths)
+}
+
+// handlerImport returns the import path of a field type declared by a project package following the
+// handler naming convention, e.g. "*order.Handler", false for other fields such as *fiber.App
+func (s *ServerSyncer) handlerImport(typeName string, importPaths map[string]string) (string, bool) {
+	pkg, name, ok := strings.Cut(strings.TrimPrefix(typeName, "*"), ".")
+	if !ok {
+		return "", false
+	}
+	importPath := importPaths[pkg]
+	module := s.config.Project.Module
+	if importPath == "" || module == "" || (importPath != module && !strings.HasPrefix(importPath, module+"/")) {
+		return "", false
+	}
+	if _, ok := s.config.Conventions.HandlerSuffix(name); !ok && name != "Handler" {
+		return "", false
+	}
+	return importPath, true
+}
+
+// removeUnusedImports removes the imports of paths the file no longer refers to
+func removeUnusedImports(filePath string, src []byte, paths map[string]bool) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", filePath, err)
+	}
+
+	var edits []sourceEdit
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		unused := make(map[int]bool)
+		for i, spec := range gen.Specs {
+			importSpec := spec.(*ast.ImportSpec)
+			importPath, _ := strconv.Unquote(importSpec.Path.Value)
+			if paths[importPath] && !selectsFrom(file, importName(importSpec, importPath)) {
+				unused[i] = true
+			}
+		}
+		switch {
+		case len(unused) == 0:
+		case len(unused) == len(gen.Specs):
+			start, end := lineRange(src, fset.Position(gen.Pos()).Offset, fset.Position(gen.End()).Offset)
+			edits = append(edits, sourceEdit{offset: start, end: end})
+		default:
+			for i := range unused {
+				edits = append(edits, removeLine(fset, src, gen.Specs[i]))
+			}
+		}
+	}
+	if len(edits) == 0 {
+		return src, nil
+	}
+
+	formatted, err := format.Source(applyEdits(src, edits))
+	if err != nil {
+		return nil, fmt.Errorf("error formatting %s: %w", filePath, err)
+	}
+	return formatted, nil
+}
+
+// removeField removes a struct field with its doc and line comments
+func removeField(fset *token.FileSet, src []byte, field *ast.Field) sourceEdit {
+	from, to := field.Pos(), field.End()
+	if field.Doc != nil {
+		from = field.Doc.Pos()
+	}
+	if field.Comment != nil {
+		to = field.Comment.End()
+	}
+	return removeSpan(src, fset.Position(from).Offset, fset.Position(to).Offset)
+}
+
+// removeLine removes a node, with the whole line when nothing else is written on it
+func removeLine(fset *token.FileSet, src []byte, node ast.Node) sourceEdit {
+	return removeSpan(src, fset.Position(node.Pos()).Offset, fset.Position(node.End()).Offset)
+}
+
+// removeSpan removes the source from start to end, with the whole lines when nothing else is written on them
+func removeSpan(src []byte, start, end int) sourceEdit {
+	lineStart, lineEnd := lineRange(src, start, end)
+	if isBlank(src[lineStart:start]) && isBlank(src[end:lineEnd]) {
+		return sourceEdit{offset: lineStart, end: lineEnd}
+	}
+	return sourceEdit{offset: start, end: end}
+}
+
+// removeItems removes the items of a parameter list or composite literal at the given indexes with
+// their separators, following the list's layout like listEdit
+func removeItems(fset *token.FileSet, src []byte, items []ast.Node, remove map[int]bool) []sourceEdit {
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+
+	var edits []sourceEdit
+	for i := 0; i < len(items); i++ {
+		if !remove[i] {
+			continue
+		}
+		// Consecutive items are removed at once, their separators would overlap
+		last := i
+		for last+1 < len(items) && remove[last+1] {
+			last++
+		}
+
+		start, end := offset(items[i].Pos()), offset(items[last].End())
+		lineStart, lineEnd := lineRange(src, start, end)
+		switch {
+		case isBlank(src[lineStart:start]) && string(bytes.TrimSpace(src[end:lineEnd])) == ",":
+			// One item per line with a trailing comma
+			edits = append(edits, sourceEdit{offset: lineStart, end: lineEnd})
+		case last+1 < len(items):
+			edits = append(edits, sourceEdit{offset: start, end: offset(items[last+1].Pos())})
+		case i > 0:
+			edits = append(edits, sourceEdit{offset: offset(items[i-1].End()), end: end})
+		default:
+			edits = append(edits, sourceEdit{offset: start, end: end})
+		}
+		i = last
+	}
+	return edits
+}
+
+// findParam returns the index of the constructor parameter of a type, -1 if there is none
+func findParam(fset *token.FileSet, fn *ast.FuncDecl, typeName string) int {
+	for i, param := range fn.Type.Params.List {
+		if len(param.Names) == 1 && exprString(fset, param.Type) == typeName {
+			return i
+		}
+	}
+	return -1
+}
+
+// findValue returns the index of the value a composite literal sets a field to, by key or by
+// position in an unkeyed literal, -1 if there is none
+func findValue(literal *ast.CompositeLit, fieldName string, index, fields int) int {
+	if literal == nil {
+		return -1
+	}
+	if !isKeyed(literal) {
+		if len(literal.Elts) == fields && index < len(literal.Elts) {
+			return index
+		}
+		return -1
+	}
+	for i, elt := range literal.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok && isIdent(kv.Key, fieldName) {
+			return i
+		}
+	}
+	return -1
+}
+
+// selects reports whether the file selects a field or method of the name, e.g. s.orderHandler
+func selects(file *ast.File, name string) bool {
+	found := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && sel.Sel.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// selectsFrom reports whether the file refers to a declaration of an imported package, e.g. order.Handler
+func selectsFrom(file *ast.File, pkg string) bool {
+	found := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok && isIdent(sel.X, pkg) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// countIdent counts the identifiers of a name in a node
+func countIdent(node ast.Node, name string) int {
+	count := 0
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == name {
+			count++
+		}
+		return true
+	})
+	return count
+}
+
+// isIdent reports whether an expression is the identifier of a name, or a key-value pair set to it
+func isIdent(expr ast.Expr, name string) bool {
+	if kv, ok := expr.(*ast.KeyValueExpr); ok {
+		expr = kv.Value
+	}
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+// importName returns the name a file refers to an imported package by
+func importName(spec *ast.ImportSpec, importPath string) string {
+	if spec.Name != nil {
+		return spec.Name.Name
+	}
+	return importPath[strings.LastIndex(importPath, "/")+1:]
+}
+
+// typeBase returns the name of a type without its package and pointer, e.g. "Handler" for "*order.Handler"
+func typeBase(typeName string) string {
+	return typeName[strings.LastIndex(typeName, ".")+1:]
+}
+
+// lineRange returns the offsets of the lines spanning start to end, the line break included
+func lineRange(src []byte, start, end int) (int, int) {
+	lineStart := bytes.LastIndexByte(src[:start], '\n') + 1
+	lineEnd := len(src)
+	if i := bytes.IndexByte(src[end:], '\n'); i >= 0 {
+		lineEnd = end + i + 1
+	}
+	return lineStart, lineEnd
+}
+
+// isBlank reports whether the source holds only white space
+func isBlank(src []byte) bool {
+	return len(bytes.TrimSpace(src)) == 0
+}
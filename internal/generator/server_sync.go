@@ -0,0 +1,323 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// ServerSyncer patches a hand-written Server struct with the handlers of newly scanned packages,
+// and removes the handlers no scanned route uses anymore
+type ServerSyncer struct {
+	config *config.Config
+	output *Output
+	routes *RouteGenerator
+}
+
+// ServerSyncResult describes the changes made to a hand-written server file
+type ServerSyncResult struct {
+	Added       []HandlerInfo // Handler fields added to the server struct
+	Removed     []StaleField  // Handler fields removed with their constructor parameters, no scanned route uses them anymore
+	Referenced  []StaleField  // Handler fields no scanned route uses anymore, kept since other code in the file refers to them
+	Constructor string        // Constructor given the new parameters, empty if none was found
+	Unassigned  bool          // true if the constructor builds the server without a composite literal to extend
+}
+
+// NewServerSyncer creates a new server syncer
+func NewServerSyncer(cfg *config.Config, out *Output) (*ServerSyncer, error) {
+	routes, err := NewRouteGenerator(cfg, out)
+	if err != nil {
+		return nil, err
+	}
+	return &ServerSyncer{
+		config: cfg,
+		output: out,
+		routes: routes,
+	}, nil
+}
+
+// sourceEdit inserts text at a byte offset of the source, replacing the source up to end when it is set
+type sourceEdit struct {
+	offset int
+	end    int
+	text   string
+}
+
+// SyncServer rewrites the server file at path so its struct holds a field for every scanned handler,
+// adding the missing fields, constructor parameters, composite literal keys and imports, and removing
+// those of handlers no scanned route uses anymore. New fields are added in the order of their names.
+// Other code and comments are left untouched
+func (s *ServerSyncer) SyncServer(filePath, structName string, handlers []scanner.HandlerFunction, routes []scanner.RouteMapping) (*ServerSyncResult, error) {
+	src, err := os.ReadFile(s.config.ResolvePath(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", filePath, err)
+	}
+	if header, _ := cutLine(src); isGeneratedHeader(string(header)) {
+		return nil, fmt.Errorf("%s is generated by taskw, run taskw generate server instead", filePath)
+	}
+
+	handlerInfo := s.routes.extractHandlerInfo(handlers, routes)
+	result := &ServerSyncResult{}
+	original := src
+	if src, err = s.pruneFields(filePath, src, structName, handlerInfo, result); err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", filePath, err)
+	}
+
+	serverStruct := findStruct(file, structName)
+	if serverStruct == nil {
+		return nil, fmt.Errorf("no %s struct found in %s", structName, filePath)
+	}
+
+	// Local names of the imported packages, e.g. "github.com/acme/api/internal/user" -> "user"
+	importNames := make(map[string]string)
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		importNames[importPath] = importName(spec, importPath)
+	}
+
+	// Fields already declared, by name and by type, e.g. "userHandler" and "*user.Handler"
+	fieldNames := make(map[string]bool)
+	fieldTypes := make(map[string]bool)
+	for _, field := range serverStruct.Fields.List {
+		for _, name := range field.Names {
+			fieldNames[name.Name] = true
+		}
+		fieldTypes[strings.TrimPrefix(exprString(fset, field.Type), "*")] = true
+	}
+
+	var missingImports []string
+	for _, handler := range handlerInfo {
+		importPath := handler.ImportPath
+		localName, imported := importNames[importPath]
+		if !imported {
+			localName = handler.Package
+		}
+
+		typeName := handler.TypeName
+		if localName != handler.Package {
+			typeName = strings.Replace(typeName, handler.Package+".", localName+".", 1)
+		}
+		if fieldNames[handler.FieldName] || fieldTypes[strings.TrimPrefix(typeName, "*")] {
+			continue
+		}
+
+		handler.TypeName = typeName
+		result.Added = append(result.Added, handler)
+		if !imported {
+			importNames[importPath] = localName
+			missingImports = append(missingImports, importSpec(localName, importPath))
+		}
+	}
+	if len(result.Added) == 0 {
+		if bytes.Equal(src, original) {
+			return result, nil
+		}
+		if err := s.output.writeFileAtomic(s.config.Root, filePath, src); err != nil {
+			return nil, fmt.Errorf("error writing %s: %w", filePath, err)
+		}
+		return result, nil
+	}
+
+	edits := []sourceEdit{s.fieldsEdit(fset, src, serverStruct, result.Added)}
+
+	if constructor := findConstructor(fset, file, structName); constructor != nil {
+		result.Constructor = constructor.Name.Name
+
+		params := make([]string, len(result.Added))
+		for i, handler := range result.Added {
+			params[i] = handler.ParamName + " " + handler.TypeName
+		}
+		var lastParam ast.Node
+		if fields := constructor.Type.Params.List; len(fields) > 0 {
+			lastParam = fields[len(fields)-1]
+		}
+		edits = append(edits, listEdit(fset, src, lastParam, constructor.Type.Params.Closing, params))
+
+		if literal := findCompositeLiteral(constructor, structName); literal != nil {
+			values := make([]string, len(result.Added))
+			for i, handler := range result.Added {
+				values[i] = handler.ParamName
+				if len(literal.Elts) == 0 || isKeyed(literal) {
+					values[i] = handler.FieldName + ": " + handler.ParamName
+				}
+			}
+			var lastValue ast.Node
+			if len(literal.Elts) > 0 {
+				lastValue = literal.Elts[len(literal.Elts)-1]
+			}
+			edits = append(edits, listEdit(fset, src, lastValue, literal.Rbrace, values))
+		} else {
+			result.Unassigned = true
+		}
+	}
+
+	if len(missingImports) > 0 {
+		sort.Strings(missingImports)
+		edits = append(edits, importsEdits(fset, src, file, missingImports)...)
+	}
+
+	formatted, err := format.Source(applyEdits(src, edits))
+	if err != nil {
+		return nil, fmt.Errorf("error formatting %s: %w", filePath, err)
+	}
+	if err := s.output.writeFileAtomic(s.config.Root, filePath, formatted); err != nil {
+		return nil, fmt.Errorf("error writing %s: %w", filePath, err)
+	}
+	return result, nil
+}
+
+// applyEdits applies edits from the end of the file so earlier offsets stay valid
+func applyEdits(src []byte, edits []sourceEdit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].offset > edits[j].offset
+	})
+	patched := append([]byte{}, src...)
+	for _, edit := range edits {
+		end := max(edit.end, edit.offset)
+		patched = append(patched[:edit.offset], append([]byte(edit.text), patched[end:]...)...)
+	}
+	return patched
+}
+
+// fieldsEdit appends the handler fields before the closing brace of the struct
+func (s *ServerSyncer) fieldsEdit(fset *token.FileSet, src []byte, serverStruct *ast.StructType, added []HandlerInfo) sourceEdit {
+	var buf strings.Builder
+	closing := fset.Position(serverStruct.Fields.Closing).Offset
+	if !endsWithNewline(src[:closing]) {
+		buf.WriteString("\n")
+	}
+	for _, handler := range added {
+		fmt.Fprintf(&buf, "\t%s %s\n", handler.FieldName, handler.TypeName)
+	}
+	return sourceEdit{offset: closing, text: buf.String()}
+}
+
+// listEdit appends items to a parameter list or composite literal before its closing token,
+// following the list's layout: one item per line if the list spans lines, inline otherwise
+func listEdit(fset *token.FileSet, src []byte, lastItem ast.Node, closingPos token.Pos, items []string) sourceEdit {
+	closing := fset.Position(closingPos).Offset
+	if lastItem == nil {
+		return sourceEdit{offset: closing, text: strings.Join(items, ", ")}
+	}
+
+	before := bytes.TrimRight(src[:closing], " \t\r\n")
+	if bytes.HasSuffix(before, []byte(",")) {
+		// Multi-line list with a trailing comma
+		return sourceEdit{offset: closing, text: strings.Join(items, ",\n") + ",\n"}
+	}
+
+	last := fset.Position(lastItem.End()).Offset
+	return sourceEdit{offset: last, text: ", " + strings.Join(items, ", ")}
+}
+
+// importsEdits adds import specs to the file's first import declaration, or a new one after the package clause
+func importsEdits(fset *token.FileSet, src []byte, file *ast.File, specs []string) []sourceEdit {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if gen.Rparen.IsValid() {
+			rparen := fset.Position(gen.Rparen).Offset
+			text := "\t" + strings.Join(specs, "\n\t") + "\n"
+			if !endsWithNewline(src[:rparen]) {
+				text = "\n" + text
+			}
+			return []sourceEdit{{offset: rparen, text: text}}
+		}
+		// A single import without parentheses becomes a block, e.g. import "fmt" -> import ("fmt" ...)
+		return []sourceEdit{
+			{offset: fset.Position(gen.Specs[0].Pos()).Offset, text: "(\n\t"},
+			{offset: fset.Position(gen.End()).Offset, text: "\n\t" + strings.Join(specs, "\n\t") + "\n)"},
+		}
+	}
+
+	return []sourceEdit{{offset: fset.Position(file.Name.End()).Offset, text: "\n\nimport (\n\t" + strings.Join(specs, "\n\t") + "\n)"}}
+}
+
+// findStruct returns the struct type declared under name
+func findStruct(file *ast.File, name string) *ast.StructType {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if structType, ok := typeSpec.Type.(*ast.StructType); ok && typeSpec.Name.Name == name {
+				return structType
+			}
+		}
+	}
+	return nil
+}
+
+// findConstructor returns the function building the struct, e.g. func ProvideServer(...) *Server
+func findConstructor(fset *token.FileSet, file *ast.File, structName string) *ast.FuncDecl {
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Type.Results == nil || len(fn.Type.Results.List) == 0 {
+			continue
+		}
+		if strings.TrimPrefix(exprString(fset, fn.Type.Results.List[0].Type), "*") == structName {
+			return fn
+		}
+	}
+	return nil
+}
+
+// findCompositeLiteral returns the first composite literal of the struct in a function body, e.g. &Server{...}
+func findCompositeLiteral(fn *ast.FuncDecl, structName string) *ast.CompositeLit {
+	var literal *ast.CompositeLit
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if literal != nil {
+			return false
+		}
+		if lit, ok := n.(*ast.CompositeLit); ok {
+			if ident, ok := lit.Type.(*ast.Ident); ok && ident.Name == structName {
+				literal = lit
+				return false
+			}
+		}
+		return true
+	})
+	return literal
+}
+
+// isKeyed reports whether a composite literal uses field names, e.g. Server{app: app}
+func isKeyed(lit *ast.CompositeLit) bool {
+	for _, elt := range lit.Elts {
+		if _, ok := elt.(*ast.KeyValueExpr); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// exprString renders an expression as written in the source, e.g. "*user.Handler"
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	format.Node(&buf, fset, expr)
+	return buf.String()
+}
+
+// endsWithNewline reports whether the source before an offset ends a line, ignoring indentation
+func endsWithNewline(src []byte) bool {
+	return bytes.HasSuffix(bytes.TrimRight(src, " \t"), []byte("\n"))
+}
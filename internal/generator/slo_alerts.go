@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// SLOAlertGenerator generates Prometheus alerting rules from @SLO annotations
+type SLOAlertGenerator struct {
+	config *config.Config
+	output *Output
+}
+
+// NewSLOAlertGenerator creates a new SLO alert generator
+func NewSLOAlertGenerator(cfg *config.Config, out *Output) *SLOAlertGenerator {
+	return &SLOAlertGenerator{
+		config: cfg,
+		output: out,
+	}
+}
+
+// sloAlert is a single Prometheus alerting rule
+type sloAlert struct {
+	Name      string // e.g., "UserGetUserLatencyP99"
+	RouteName string // e.g., "user.GetUser"
+	Method    string
+	Path      string // Route pattern as registered with the router
+	Target    scanner.SLOTarget
+	Quantile  string // e.g., "0.99"
+	Seconds   string // Threshold in seconds, e.g., "0.2"
+}
+
+// GenerateAlerts writes the alerting rules file and returns the number of rules written
+func (g *SLOAlertGenerator) GenerateAlerts(routes []scanner.RouteMapping) (int, error) {
+	if !g.config.Generation.SLO.Enabled {
+		return 0, nil
+	}
+
+	alerts := g.collectAlerts(routes)
+
+	tmplContent, err := readTemplate(g.config, "templates/slo_alerts.tmpl")
+	if err != nil {
+		return 0, fmt.Errorf("error reading SLO alerts template: %w", err)
+	}
+
+	tmpl, err := template.New("slo_alerts").Parse(string(tmplContent))
+	if err != nil {
+		return 0, fmt.Errorf("error parsing SLO alerts template: %w", err)
+	}
+
+	data := struct {
+		Config config.SLOConfig
+		Alerts []sloAlert
+	}{
+		Config: g.config.Generation.SLO,
+		Alerts: alerts,
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return 0, fmt.Errorf("error executing SLO alerts template: %w", err)
+	}
+
+	outputPath := g.config.Generation.SLO.OutputFile
+	if err := g.output.writeFileAtomic(g.config.Root, outputPath, []byte(buf.String())); err != nil {
+		return 0, fmt.Errorf("failed to write file %s: %w", outputPath, err)
+	}
+
+	return len(alerts), nil
+}
+
+// collectAlerts creates one rule per route and SLO target, sorted by alert name
+func (g *SLOAlertGenerator) collectAlerts(routes []scanner.RouteMapping) []sloAlert {
+	var alerts []sloAlert
+	for _, route := range routes {
+		for _, target := range route.SLOs {
+			percentile := strings.ReplaceAll(strings.ToUpper(target.Percentile), ".", "")
+			alerts = append(alerts, sloAlert{
+				Name:      upperFirst(route.ImportName) + route.MethodName + "Latency" + percentile,
+				RouteName: route.ImportName + "." + route.MethodName,
+				Method:    route.HTTPMethod,
+				Path:      FormatRoutePath(g.config, route.Path),
+				Target:    target,
+				Quantile:  strconv.FormatFloat(target.Quantile, 'f', -1, 64),
+				Seconds:   strconv.FormatFloat(target.Threshold.Seconds(), 'f', -1, 64),
+			})
+		}
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Name < alerts[j].Name
+	})
+	return alerts
+}
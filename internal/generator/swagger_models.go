@@ -0,0 +1,269 @@
+package generator
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// definitionRefPrefix starts references to the definitions of a Swagger 2.0 spec
+const definitionRefPrefix = "#/definitions/"
+
+// modelSchemas completes the definitions of a spec from the scanned models
+type modelSchemas struct {
+	models      map[string]scanner.Model
+	definitions map[string]interface{}
+	defined     map[string]bool // Definitions written from a model, or being written
+	changed     bool
+}
+
+// applyModelSchemas completes the schemas swag leaves opaque from the types found while scanning:
+// references to missing definitions get one, and object stubs, whole definitions or properties typed
+// with a struct of another package, get the properties of the struct, adding its nested definitions
+// Returns whether the spec changed
+func applyModelSchemas(spec map[string]interface{}, models []scanner.Model) bool {
+	if len(models) == 0 {
+		return false
+	}
+
+	definitions, _ := spec["definitions"].(map[string]interface{})
+	if definitions == nil {
+		definitions = make(map[string]interface{})
+	}
+	schemas := &modelSchemas{
+		models:      make(map[string]scanner.Model, len(models)),
+		definitions: definitions,
+		defined:     make(map[string]bool),
+	}
+	for _, model := range models {
+		schemas.models[model.Name] = model
+	}
+
+	// Complete existing definitions first, then define the ones only referenced
+	names := make([]string, 0, len(definitions))
+	for name := range definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		schemas.complete(name)
+	}
+	for _, name := range collectDefinitionRefs(spec) {
+		if _, exists := definitions[name]; !exists {
+			schemas.define(name)
+		}
+	}
+
+	if schemas.changed && spec["definitions"] == nil {
+		spec["definitions"] = definitions
+	}
+	return schemas.changed
+}
+
+// complete replaces an opaque definition, or its opaque properties, with the schema of its model
+func (m *modelSchemas) complete(name string) {
+	model, ok := m.models[name]
+	if !ok || model.Underlying != "" {
+		return
+	}
+	definition, _ := m.definitions[name].(map[string]interface{})
+	if definition == nil || isOpaqueSchema(definition) {
+		m.define(name)
+		return
+	}
+
+	properties, _ := definition["properties"].(map[string]interface{})
+	fields := m.fieldsByName(model, map[string]bool{})
+	for property, schema := range properties {
+		field, ok := fields[property]
+		schemaMap, isMap := schema.(map[string]interface{})
+		if !ok || !isMap || !isOpaqueSchema(schemaMap) {
+			continue
+		}
+		if completed := m.typeSchema(field.Type); !isOpaqueSchema(completed) {
+			keepAnnotations(schemaMap, completed)
+			properties[property] = completed
+			m.changed = true
+		}
+	}
+}
+
+// define writes the definition of a struct model, if it is missing or opaque
+func (m *modelSchemas) define(name string) {
+	model, ok := m.models[name]
+	if !ok || model.Underlying != "" || m.defined[name] {
+		return
+	}
+	if existing, ok := m.definitions[name].(map[string]interface{}); ok && !isOpaqueSchema(existing) {
+		return
+	}
+
+	// Mark the name first, models may refer to themselves, e.g. a tree of categories
+	m.defined[name] = true
+	definition := map[string]interface{}{"type": "object"}
+	if existing, ok := m.definitions[name].(map[string]interface{}); ok {
+		keepAnnotations(existing, definition)
+	}
+	m.definitions[name] = definition
+	m.changed = true
+
+	properties := make(map[string]interface{})
+	var required []string
+	fields := m.fieldsByName(model, map[string]bool{})
+	for property, field := range fields {
+		properties[property] = m.typeSchema(field.Type)
+		if field.Required {
+			required = append(required, property)
+		}
+	}
+	if len(properties) > 0 {
+		definition["properties"] = properties
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		requiredList := make([]interface{}, len(required))
+		for i, property := range required {
+			requiredList[i] = property
+		}
+		definition["required"] = requiredList
+	}
+}
+
+// fieldsByName returns the fields of a struct model by JSON name, with the fields of embedded structs promoted
+// Fields declared by the struct itself take precedence over promoted ones, like encoding/json
+func (m *modelSchemas) fieldsByName(model scanner.Model, visiting map[string]bool) map[string]scanner.ModelField {
+	fields := make(map[string]scanner.ModelField)
+	if visiting[model.Name] {
+		return fields
+	}
+	visiting[model.Name] = true
+
+	for _, field := range model.Fields {
+		if !field.Embedded {
+			fields[field.Name] = field
+		}
+	}
+	for _, field := range model.Fields {
+		embedded, ok := m.models[strings.TrimPrefix(field.Type, "*")]
+		if !field.Embedded || !ok {
+			continue
+		}
+		for name, promoted := range m.fieldsByName(embedded, visiting) {
+			if _, exists := fields[name]; !exists {
+				fields[name] = promoted
+			}
+		}
+	}
+	return fields
+}
+
+// typeSchema returns the schema of a qualified Go type, defining the struct models it references
+// e.g., "[]*user.Address" -> {"type": "array", "items": {"$ref": "#/definitions/user.Address"}}
+func (m *modelSchemas) typeSchema(goType string) map[string]interface{} {
+	goType = strings.TrimLeft(goType, "*")
+
+	switch {
+	case goType == "[]byte" || goType == "[]uint8":
+		return map[string]interface{}{"type": "string", "format": "byte"}
+	case strings.HasPrefix(goType, "[]"):
+		return map[string]interface{}{"type": "array", "items": m.typeSchema(goType[2:])}
+	case strings.HasPrefix(goType, "map["):
+		if end := matchingBracket(goType, len("map")); end > 0 {
+			return map[string]interface{}{"type": "object", "additionalProperties": m.typeSchema(goType[end+1:])}
+		}
+	}
+
+	switch goType {
+	case "string":
+		return map[string]interface{}{"type": "string"}
+	case "bool":
+		return map[string]interface{}{"type": "boolean"}
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "byte", "rune":
+		return map[string]interface{}{"type": "integer"}
+	case "float32", "float64":
+		return map[string]interface{}{"type": "number"}
+	case "time.Time":
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	model, ok := m.models[goType]
+	if !ok {
+		return map[string]interface{}{"type": "object"}
+	}
+	if model.Underlying != "" {
+		return m.typeSchema(model.Underlying)
+	}
+	m.define(goType)
+	return map[string]interface{}{"$ref": definitionRefPrefix + goType}
+}
+
+// keepAnnotations copies the description, example and extensions swag wrote on a replaced schema
+func keepAnnotations(from, to map[string]interface{}) {
+	for key, value := range from {
+		if key == "description" || key == "example" || strings.HasPrefix(key, "x-") {
+			to[key] = value
+		}
+	}
+}
+
+// isOpaqueSchema reports whether a schema is an object stub telling nothing about its content,
+// e.g. {"type": "object"}, or an array of them
+func isOpaqueSchema(schema map[string]interface{}) bool {
+	if items, ok := schema["items"].(map[string]interface{}); ok && schema["type"] == "array" {
+		return isOpaqueSchema(items)
+	}
+	for _, key := range []string{"$ref", "properties", "additionalProperties", "allOf", "oneOf", "anyOf", "items", "enum"} {
+		if _, ok := schema[key]; ok {
+			return false
+		}
+	}
+	typeName, _ := schema["type"].(string)
+	return typeName == "" || typeName == "object"
+}
+
+// collectDefinitionRefs returns the definitions referenced anywhere in the spec, sorted
+func collectDefinitionRefs(value interface{}) []string {
+	seen := make(map[string]bool)
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			if ref, ok := value["$ref"].(string); ok && strings.HasPrefix(ref, definitionRefPrefix) {
+				seen[strings.TrimPrefix(ref, definitionRefPrefix)] = true
+			}
+			for _, nested := range value {
+				walk(nested)
+			}
+		case []interface{}:
+			for _, nested := range value {
+				walk(nested)
+			}
+		}
+	}
+	walk(value)
+
+	refs := make([]string, 0, len(seen))
+	for ref := range seen {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// matchingBracket returns the index of the bracket closing the one at open, or -1
+func matchingBracket(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
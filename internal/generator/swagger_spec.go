@@ -0,0 +1,492 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/scanner"
+	"gopkg.in/yaml.v3"
+)
+
+// SwaggerSpecGenerator rewrites the spec generated by swag with the settings of taskw.yaml:
+// the openapi section, the response envelope and the x-pii extensions, and completes the
+// schemas swag leaves opaque from the scanned types
+type SwaggerSpecGenerator struct {
+	config *config.Config
+	output *Output
+}
+
+// NewSwaggerSpecGenerator creates a new Swagger spec generator
+func NewSwaggerSpecGenerator(cfg *config.Config, out *Output) *SwaggerSpecGenerator {
+	return &SwaggerSpecGenerator{
+		config: cfg,
+		output: out,
+	}
+}
+
+// openAPIInfoFile holds the runtime override of the spec served from the swag docs package
+const openAPIInfoFile = "openapi_info_gen.go"
+
+// RewriteSpec rewrites swagger.json and swagger.yaml in docsDir with the configured API information,
+// the schemas of scanned types swag left opaque, the response content types of the routes,
+// the response envelope and the personal data handled,
+// converts them to openapi.json and openapi.yaml when openapi.spec_version is 3.0 or 3.1,
+// and writes a Go file making the docs package serve the same spec
+// Returns the paths of the written files, none when there is nothing to rewrite
+func (g *SwaggerSpecGenerator) RewriteSpec(docsDir string, result *scanner.ScanResult) ([]string, error) {
+	info := g.config.OpenAPI
+	envelope := g.config.Generation.Envelope
+	pii := g.config.Generation.PII.Enabled
+	contentRoutes := routesWithResponseContent(result.Routes)
+	prefixedRoutes := routesWithPrefix(result.Routes)
+	version := g.config.SpecVersion()
+	rewrite := info.IsSet() || envelope.Enabled || pii || len(contentRoutes) > 0 || len(prefixedRoutes) > 0 ||
+		version != config.SpecVersionSwagger2
+	if !rewrite && len(result.Models) == 0 {
+		return nil, nil
+	}
+
+	jsonPath := filepath.Join(docsDir, "swagger.json")
+	content, err := os.ReadFile(g.config.ResolvePath(jsonPath))
+	if err != nil {
+		return nil, fmt.Errorf("error reading swagger spec: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var spec map[string]interface{}
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("error parsing swagger spec %s: %w", jsonPath, err)
+	}
+
+	// Nested definitions come first, the envelope and x-pii apply to them as well
+	if completed := applyModelSchemas(spec, result.Models); !completed && !rewrite {
+		return nil, nil
+	}
+
+	if info.IsSet() {
+		if err := applyOpenAPIInfo(spec, info); err != nil {
+			return nil, err
+		}
+	}
+	// Later steps match operations on the registered path
+	applyRouterPrefixes(spec, prefixedRoutes)
+	applyResponseContentTypes(spec, contentRoutes)
+	if envelope.Enabled {
+		packageName, err := packageNameForDir(g.config, envelope.PackageDir)
+		if err != nil {
+			return nil, fmt.Errorf("error determining envelope package: %w", err)
+		}
+		applyResponseEnvelope(spec, envelope, packageName)
+	}
+	if pii {
+		applyPIIExtensions(spec, result)
+	}
+
+	// The YAML spec is only rewritten when swag wrote one
+	yamlPath := filepath.Join(docsDir, "swagger.yaml")
+	if _, err := os.Stat(g.config.ResolvePath(yamlPath)); err != nil {
+		yamlPath = ""
+	}
+	specJSON, err := g.writeSpec(spec, jsonPath, yamlPath)
+	if err != nil {
+		return nil, err
+	}
+	written := []string{jsonPath}
+	if yamlPath != "" {
+		written = append(written, yamlPath)
+	}
+
+	// Converted last, the converted spec shares objects with the Swagger 2.0 spec
+	if version != config.SpecVersionSwagger2 {
+		openAPIPath := filepath.Join(docsDir, openAPIFiles.JSON)
+		openAPIYAMLPath := ""
+		if yamlPath != "" {
+			openAPIYAMLPath = filepath.Join(docsDir, openAPIFiles.YAML)
+		}
+		specJSON, err = g.writeSpec(convertToOpenAPI3(spec, version), openAPIPath, openAPIYAMLPath)
+		if err != nil {
+			return nil, err
+		}
+		written = append(written, openAPIPath)
+		if openAPIYAMLPath != "" {
+			written = append(written, openAPIYAMLPath)
+		}
+	}
+
+	if _, err := os.Stat(g.config.ResolvePath(filepath.Join(docsDir, "docs.go"))); err == nil {
+		goPath, err := g.writeDocsOverride(docsDir, specJSON)
+		if err != nil {
+			return nil, err
+		}
+		written = append(written, goPath)
+	}
+
+	return written, nil
+}
+
+// writeSpec writes a spec as indented JSON to jsonPath and as YAML to yamlPath, unless yamlPath is
+// empty, both relative to the project root. Returns the JSON written
+func (g *SwaggerSpecGenerator) writeSpec(spec map[string]interface{}, jsonPath, yamlPath string) (string, error) {
+	var specJSON bytes.Buffer
+	encoder := json.NewEncoder(&specJSON)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(spec); err != nil {
+		return "", fmt.Errorf("error encoding swagger spec: %w", err)
+	}
+	if err := g.output.writeFileAtomic(g.config.Root, jsonPath, specJSON.Bytes()); err != nil {
+		return "", fmt.Errorf("error writing swagger spec: %w", err)
+	}
+
+	if yamlPath == "" {
+		return strings.TrimSpace(specJSON.String()), nil
+	}
+	var specYAML bytes.Buffer
+	yamlEncoder := yaml.NewEncoder(&specYAML)
+	yamlEncoder.SetIndent(2)
+	if err := yamlEncoder.Encode(yamlValue(spec)); err != nil {
+		return "", fmt.Errorf("error encoding swagger spec: %w", err)
+	}
+	if err := g.output.writeFileAtomic(g.config.Root, yamlPath, specYAML.Bytes()); err != nil {
+		return "", fmt.Errorf("error writing swagger spec: %w", err)
+	}
+	return strings.TrimSpace(specJSON.String()), nil
+}
+
+// writeDocsOverride replaces the template of the swag docs package with the rewritten spec,
+// swag only knows about main.go and handler annotations
+func (g *SwaggerSpecGenerator) writeDocsOverride(docsDir, specJSON string) (string, error) {
+	packages, err := existingPackages(g.config.ResolvePath(docsDir))
+	if err != nil {
+		return "", fmt.Errorf("error reading docs package: %w", err)
+	}
+	packageName := "docs"
+	for name := range packages {
+		packageName = name
+	}
+
+	tmplContent, err := readTemplate(g.config, "templates/openapi_info.tmpl")
+	if err != nil {
+		return "", fmt.Errorf("error reading OpenAPI info template: %w", err)
+	}
+
+	tmpl, err := template.New("openapi_info").Parse(string(tmplContent))
+	if err != nil {
+		return "", fmt.Errorf("error parsing OpenAPI info template: %w", err)
+	}
+
+	// The swag template is executed at runtime, so literal delimiters must be escaped
+	escaped := strings.ReplaceAll(specJSON, "{{", `{{"{{"}}`)
+	literal := "`" + escaped + "`"
+	if strings.Contains(escaped, "`") {
+		literal = strconv.Quote(escaped)
+	}
+
+	data := struct {
+		Package  string
+		Template string
+	}{
+		Package:  packageName,
+		Template: literal,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("error executing OpenAPI info template: %w", err)
+	}
+
+	path := filepath.Join(docsDir, openAPIInfoFile)
+	if err := g.output.writeGeneratedFile(g.config.Root, path, buf.String()); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// applyOpenAPIInfo sets the info object and servers of a Swagger 2.0 spec
+// Swagger 2.0 has a single host, so the first server sets host, basePath and schemes and the full
+// list is kept in the x-servers extension using the OpenAPI 3 server object layout
+func applyOpenAPIInfo(spec map[string]interface{}, info config.OpenAPI) error {
+	infoObject, _ := spec["info"].(map[string]interface{})
+	if infoObject == nil {
+		infoObject = make(map[string]interface{})
+		spec["info"] = infoObject
+	}
+
+	setString := func(object map[string]interface{}, key, value string) {
+		if value != "" {
+			object[key] = value
+		}
+	}
+	setString(infoObject, "title", info.Title)
+	setString(infoObject, "version", info.Version)
+	setString(infoObject, "description", info.Description)
+	setString(infoObject, "termsOfService", info.TermsOfService)
+
+	if info.Contact != (config.OpenAPIContact{}) {
+		contact := make(map[string]interface{})
+		setString(contact, "name", info.Contact.Name)
+		setString(contact, "url", info.Contact.URL)
+		setString(contact, "email", info.Contact.Email)
+		infoObject["contact"] = contact
+	}
+	if info.License != (config.OpenAPILicense{}) {
+		license := make(map[string]interface{})
+		setString(license, "name", info.License.Name)
+		setString(license, "url", info.License.URL)
+		infoObject["license"] = license
+	}
+
+	if len(info.Servers) == 0 {
+		return nil
+	}
+
+	servers := make([]interface{}, 0, len(info.Servers))
+	for i, server := range info.Servers {
+		if server.URL == "" {
+			return fmt.Errorf("openapi.servers[%d] is missing a url", i)
+		}
+
+		object := map[string]interface{}{"url": server.URL}
+		setString(object, "description", server.Description)
+		if len(server.Variables) > 0 {
+			variables := make(map[string]interface{}, len(server.Variables))
+			for name, variable := range server.Variables {
+				if len(variable.Enum) > 0 && !containsString(variable.Enum, variable.Default) {
+					return fmt.Errorf("openapi.servers[%d].variables.%s: default %q is not one of %v", i, name, variable.Default, variable.Enum)
+				}
+				entry := map[string]interface{}{"default": variable.Default}
+				if len(variable.Enum) > 0 {
+					entry["enum"] = variable.Enum
+				}
+				setString(entry, "description", variable.Description)
+				variables[name] = entry
+			}
+			object["variables"] = variables
+		}
+		servers = append(servers, object)
+	}
+	spec["x-servers"] = servers
+
+	primary := info.Servers[0]
+	resolved := expandServerVariables(primary.URL, primary.Variables)
+	parsed, err := url.Parse(resolved)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("openapi.servers[0].url %q is not an absolute URL", primary.URL)
+	}
+	spec["host"] = parsed.Host
+	basePath := strings.TrimSuffix(parsed.Path, "/")
+	if basePath == "" {
+		basePath = "/"
+	}
+	spec["basePath"] = basePath
+
+	// Every server sharing the primary host contributes its scheme, e.g. http and https
+	schemes := []string{parsed.Scheme}
+	for _, server := range info.Servers[1:] {
+		other, err := url.Parse(expandServerVariables(server.URL, server.Variables))
+		if err != nil || other.Host != parsed.Host || containsString(schemes, other.Scheme) {
+			continue
+		}
+		schemes = append(schemes, other.Scheme)
+	}
+	spec["schemes"] = schemes
+
+	return nil
+}
+
+// routesWithResponseContent returns the routes whose responses declare content types
+func routesWithResponseContent(routes []scanner.RouteMapping) []scanner.RouteMapping {
+	var filtered []scanner.RouteMapping
+	for _, route := range routes {
+		if len(route.Responses) > 0 {
+			filtered = append(filtered, route)
+		}
+	}
+	return filtered
+}
+
+// routesWithPrefix returns the routes registered under a @RouterPrefix
+func routesWithPrefix(routes []scanner.RouteMapping) []scanner.RouteMapping {
+	var filtered []scanner.RouteMapping
+	for _, route := range routes {
+		if specPathKey(route.Path) != specPathKey(route.RouterPath) {
+			filtered = append(filtered, route)
+		}
+	}
+	return filtered
+}
+
+// applyRouterPrefixes moves operations from the @Router path swag documents them at to the path
+// they are registered at, swag doesn't know about @RouterPrefix
+func applyRouterPrefixes(spec map[string]interface{}, routes []scanner.RouteMapping) {
+	paths, _ := spec["paths"].(map[string]interface{})
+	if paths == nil {
+		return
+	}
+
+	// Every operation is taken out before any is put back, a prefixed path may be
+	// another route's @Router path
+	type move struct {
+		path      string
+		method    string
+		operation interface{}
+	}
+	var moves []move
+	for _, route := range routes {
+		method := strings.ToLower(route.HTTPMethod)
+		for path, pathItem := range paths {
+			if specPathKey(path) != specPathKey(route.RouterPath) {
+				continue
+			}
+			operations, _ := pathItem.(map[string]interface{})
+			if operation, ok := operations[method]; ok {
+				delete(operations, method)
+				if len(operations) == 0 {
+					delete(paths, path)
+				}
+				moves = append(moves, move{path: route.Path, method: method, operation: operation})
+			}
+			break
+		}
+	}
+
+	for _, m := range moves {
+		var operations map[string]interface{}
+		for path, pathItem := range paths {
+			if specPathKey(path) == specPathKey(m.path) {
+				operations, _ = pathItem.(map[string]interface{})
+				break
+			}
+		}
+		if operations == nil {
+			operations = make(map[string]interface{})
+			paths[m.path] = operations
+		}
+		operations[m.method] = m.operation
+	}
+}
+
+// applyResponseContentTypes documents the content types declared on @Success and @Failure responses
+// Swagger 2.0 only has operation level produces, so it becomes the union of @Produce and every
+// response content type, and each response lists its own types in the x-content-types extension
+func applyResponseContentTypes(spec map[string]interface{}, routes []scanner.RouteMapping) {
+	paths, _ := spec["paths"].(map[string]interface{})
+	for _, route := range routes {
+		var operation map[string]interface{}
+		for path, pathItem := range paths {
+			if specPathKey(path) != specPathKey(route.Path) {
+				continue
+			}
+			operations, _ := pathItem.(map[string]interface{})
+			operation, _ = operations[strings.ToLower(route.HTTPMethod)].(map[string]interface{})
+			break
+		}
+		if operation == nil {
+			continue
+		}
+
+		var produces []string
+		existing, _ := operation["produces"].([]interface{})
+		for _, value := range existing {
+			if mimeType, ok := value.(string); ok && !containsString(produces, mimeType) {
+				produces = append(produces, mimeType)
+			}
+		}
+		for _, mimeType := range route.Produces {
+			if !containsString(produces, mimeType) {
+				produces = append(produces, mimeType)
+			}
+		}
+
+		responses, _ := operation["responses"].(map[string]interface{})
+		for _, content := range route.Responses {
+			for _, mimeType := range content.ContentTypes {
+				if !containsString(produces, mimeType) {
+					produces = append(produces, mimeType)
+				}
+			}
+
+			response, _ := responses[content.Status].(map[string]interface{})
+			if response == nil {
+				continue
+			}
+			// swag reads the content type list as part of the description
+			response["description"] = content.Description
+			response["x-content-types"] = content.ContentTypes
+		}
+
+		operation["produces"] = produces
+	}
+}
+
+// specPathKey makes {param} and :param path segments comparable
+func specPathKey(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if isPathParameter(segment) {
+			segments[i] = "{}"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// expandServerVariables substitutes {variables} in a server URL with their defaults
+func expandServerVariables(serverURL string, variables map[string]config.OpenAPIServerVariable) string {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		serverURL = strings.ReplaceAll(serverURL, "{"+name+"}", variables[name].Default)
+	}
+	return serverURL
+}
+
+// yamlValue converts decoded JSON numbers so they are written as YAML numbers instead of strings
+func yamlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[key] = yamlValue(item)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, item := range v {
+			converted[i] = yamlValue(item)
+		}
+		return converted
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	}
+	return value
+}
+
+// containsString checks if a slice contains a value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/nkaewam/taskw/internal/config"
+)
+
+// SwaggerUIGenerator generates the registration of the gofiber/contrib/swagger middleware serving
+// the API documentation, from generation.swagger_ui
+type SwaggerUIGenerator struct {
+	config    *config.Config
+	output    *Output
+	framework routeFramework
+	style     routeStyle
+}
+
+// NewSwaggerUIGenerator creates a new swagger UI generator
+func NewSwaggerUIGenerator(cfg *config.Config, out *Output) (*SwaggerUIGenerator, error) {
+	style, err := lookupRouteStyle(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &SwaggerUIGenerator{
+		config:    cfg,
+		output:    out,
+		framework: style.framework,
+		style:     style,
+	}, nil
+}
+
+// SwaggerUIFile returns the path of the generated swagger UI registration
+func SwaggerUIFile(cfg *config.Config) string {
+	return filepath.Join(cfg.Paths.OutputDir, cfg.Generation.SwaggerUI.OutputFile)
+}
+
+// SwaggerUISpecFile returns the spec the swagger UI serves: generation.swagger_ui.file_path, or the
+// spec written for openapi.spec_version
+func SwaggerUISpecFile(cfg *config.Config) string {
+	if cfg.Generation.SwaggerUI.FilePath != "" {
+		return cfg.Generation.SwaggerUI.FilePath
+	}
+	if cfg.SpecVersion() != config.SpecVersionSwagger2 {
+		return "./docs/openapi.json"
+	}
+	return "./docs/swagger.json"
+}
+
+// SwaggerUIURL returns the path the documentation is served at, e.g. "/docs"
+func SwaggerUIURL(cfg *config.Config) string {
+	return path.Join("/", cfg.Generation.SwaggerUI.BasePath, cfg.Generation.SwaggerUI.Path)
+}
+
+// GenerateSwaggerUI writes RegisterSwaggerUI into the output package
+func (g *SwaggerUIGenerator) GenerateSwaggerUI() error {
+	swaggerUI := g.config.Generation.SwaggerUI
+	if !swaggerUI.Enabled {
+		return nil
+	}
+
+	// gofiber/contrib/swagger is a Fiber v2 middleware
+	if g.framework.Name != config.FrameworkFiber {
+		return fmt.Errorf("swagger UI generation is only supported for the fiber framework, got %q", g.framework.Name)
+	}
+	if g.config.FiberVersion() != 2 {
+		return fmt.Errorf("swagger UI generation requires fiber v2, the gofiber/contrib/swagger middleware doesn't support fiber v%d", g.config.FiberVersion())
+	}
+	// The middleware is registered on the generated Router
+	if !g.config.Generation.Routes.Enabled {
+		return fmt.Errorf("swagger UI generation requires route generation (set generation.routes.enabled: true)")
+	}
+	if strings.Trim(swaggerUI.Path, "/") == "" {
+		return fmt.Errorf("generation.swagger_ui.path must not be empty, it would shadow every route")
+	}
+	if swaggerUI.CacheAge < 0 {
+		return fmt.Errorf("generation.swagger_ui.cache_age must not be negative, got %d", swaggerUI.CacheAge)
+	}
+
+	title := swaggerUI.Title
+	if title == "" {
+		title = g.config.OpenAPI.Title
+	}
+
+	outputPackage, err := outputPackageName(g.config)
+	if err != nil {
+		return err
+	}
+
+	tmplContent, err := readTemplate(g.config, "templates/swagger_ui.tmpl")
+	if err != nil {
+		return fmt.Errorf("error reading swagger UI template: %w", err)
+	}
+
+	tmpl, err := template.New("swagger_ui").Parse(string(tmplContent))
+	if err != nil {
+		return fmt.Errorf("error parsing swagger UI template: %w", err)
+	}
+
+	data := struct {
+		Package     string
+		URL         string
+		BasePath    string
+		FilePath    string
+		Path        string
+		Title       string
+		DeepLinking bool
+		CacheAge    int
+		Style       string
+		Decl        string // Declaration of RegisterSwaggerUI in the configured style
+		App         string // e.g., "ar.app" or "app"
+		Before      string // Function registering the scanned routes, e.g. "RegisterHandlers"
+	}{
+		Package:     outputPackage,
+		URL:         SwaggerUIURL(g.config),
+		BasePath:    swaggerUI.BasePath,
+		FilePath:    SwaggerUISpecFile(g.config),
+		Path:        swaggerUI.Path,
+		Title:       title,
+		DeepLinking: swaggerUI.DeepLinking,
+		CacheAge:    swaggerUI.CacheAge,
+		Style:       g.style.Name,
+		Decl:        g.style.Declare("RegisterSwaggerUI", false),
+		App:         g.style.App(),
+		Before:      g.style.Func,
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("error executing swagger UI template: %w", err)
+	}
+
+	return g.output.writeGeneratedFile(g.config.Root, SwaggerUIFile(g.config), buf.String())
+}
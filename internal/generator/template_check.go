@@ -0,0 +1,741 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/scanner"
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateProblem is an issue found while rendering a template or checking its output
+type TemplateProblem struct {
+	Template string // e.g., "routes.tmpl"
+	Variant  string // Configuration the template was rendered with, e.g. "gin, plain"
+	File     string // Generated file relative to the check project, empty when rendering failed
+	Message  string
+}
+
+// String formats the problem for display, e.g. "routes.tmpl [gin, plain] internal/api/routes_gen.go: unused import "fmt""
+func (p TemplateProblem) String() string {
+	location := p.Template + " [" + p.Variant + "]"
+	if p.File != "" {
+		location += " " + p.File
+	}
+	return location + ": " + p.Message
+}
+
+// TemplateCheckResult holds the outcome of rendering every template against the synthetic project
+type TemplateCheckResult struct {
+	Templates []string // Checked templates, e.g. "routes.tmpl"
+	Variants  []string // Configurations the templates were rendered with
+	Problems  []TemplateProblem
+}
+
+// templateVariant is a configuration the synthetic project is generated with
+type templateVariant struct {
+	Framework      string
+	FiberVersion   int
+	Backend        string
+	PerPackageSets bool
+	Style          string // generation.routes.style, router when empty
+}
+
+// Name describes the variant, e.g. "fiber v3, fx, function style"
+func (v templateVariant) Name() string {
+	framework := v.Framework
+	if v.Framework == config.FrameworkFiber {
+		framework += " v" + strconv.Itoa(v.FiberVersion)
+	}
+	backend := v.Backend
+	if v.PerPackageSets {
+		backend += " per-package sets"
+	}
+	name := framework + ", " + backend
+	if v.Style != "" {
+		name += ", " + v.Style + " style"
+	}
+	return name
+}
+
+// templateVariants cover every route framework, dependency backend and route style at least once
+var templateVariants = []templateVariant{
+	{Framework: config.FrameworkFiber, FiberVersion: 2, Backend: config.BackendWire, PerPackageSets: true},
+	{Framework: config.FrameworkFiber, FiberVersion: 3, Backend: config.BackendFx, Style: config.RouteStyleFunction},
+	{Framework: config.FrameworkGin, Backend: config.BackendPlain, Style: config.RouteStyleFunction},
+	{Framework: config.FrameworkChi, Backend: config.BackendWire},
+	{Framework: config.FrameworkNetHTTP, Backend: config.BackendFx, Style: config.RouteStyleServer},
+}
+
+// router reports whether the variant registers routes from the generated Router
+func (v templateVariant) router() bool {
+	return v.Style == "" || v.Style == config.RouteStyleRouter
+}
+
+// checkHandler is the handler signature of a framework in the synthetic project
+type checkHandler struct {
+	Import    string // e.g., `"github.com/gofiber/fiber/v2"`
+	Signature string // Parameters and results, e.g. "(c *fiber.Ctx) error"
+	Body      string
+}
+
+// handler returns the handler signature the scanner expects for the variant's framework
+func (v templateVariant) handler() checkHandler {
+	switch v.Framework {
+	case config.FrameworkGin:
+		return checkHandler{Import: `"github.com/gin-gonic/gin"`, Signature: "(c *gin.Context)"}
+	case config.FrameworkChi, config.FrameworkNetHTTP:
+		return checkHandler{Import: `"net/http"`, Signature: "(w http.ResponseWriter, r *http.Request)"}
+	}
+	if v.FiberVersion == 3 {
+		return checkHandler{Import: `"github.com/gofiber/fiber/v3"`, Signature: "(c fiber.Ctx) error", Body: "return nil"}
+	}
+	return checkHandler{Import: `"github.com/gofiber/fiber/v2"`, Signature: "(c *fiber.Ctx) error", Body: "return nil"}
+}
+
+// checkSources are the files of the synthetic project, exercising every annotation the templates render
+// HANDLER_IMPORT, HANDLER_SIGNATURE and HANDLER_BODY are replaced with the variant's handler signature,
+// APP_IMPORT and APP_TYPE with the application type of its framework
+var checkSources = map[string]string{
+	"go.mod": "module example.com/check\n\ngo 1.22\n",
+	"internal/user/user.go": `package user
+
+import (
+	"context"
+	"errors"
+)
+
+// User is a registered user
+type User struct {
+	ID    string ` + "`json:\"id\"`" + `
+	Email string ` + "`json:\"email\"`" + ` // @PII email
+	// @PII name
+	Name string ` + "`json:\"name\"`" + `
+}
+
+// Store loads users
+type Store interface {
+	Find(ctx context.Context, id string) (*User, error)
+	Count() int
+}
+
+type memoryStore struct{}
+
+// ProvideStore creates the user store
+// @ChaosWrap
+func ProvideStore() (Store, error) {
+	return &memoryStore{}, nil
+}
+
+func (s *memoryStore) Find(ctx context.Context, id string) (*User, error) {
+	return nil, errors.New("not found")
+}
+
+func (s *memoryStore) Count() int {
+	return 0
+}
+
+// Service implements the user use cases
+type Service struct {
+	store Store
+}
+
+// Find returns the user with the given ID
+func (s *Service) Find(ctx context.Context, id string) (*User, error) {
+	return s.store.Find(ctx, id)
+}
+`,
+	"internal/user/handler.go": `package user
+
+import HANDLER_IMPORT
+
+// Handler serves the user endpoints
+type Handler struct {
+	store   Store
+	service *Service
+}
+
+// ProvideHandler creates the user handler
+func ProvideHandler(store Store) *Handler {
+	return &Handler{store: store}
+}
+
+// GetUser returns a user
+// @Summary Get a user
+// @Description Returns the user with the given ID
+// @ID getUser
+// @Tags users
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} User
+// @SLO p99=200ms, p99.9=1s
+// @Router /users/{id} [get]
+func (h *Handler) GetUser HANDLER_SIGNATURE {
+	HANDLER_BODY
+}
+
+// CreateUser registers a user
+// @Summary Create a user
+// @Param user body User true "User"
+// @Success 201 {object} User
+// @Scrub email
+// @Router /users [post]
+func (h *Handler) CreateUser HANDLER_SIGNATURE {
+	HANDLER_BODY
+}
+
+// GetMember returns a user of an organization
+// @Summary Get an organization member
+// @Param orgId path string true "Organization ID" format(uuid)
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} User
+// @Router /orgs/{orgId}/members/{id} [get]
+func (h *Handler) GetMember HANDLER_SIGNATURE {
+	HANDLER_BODY
+}
+
+// find loads a user through the service, making up its port
+func (h *Handler) find(id string) (*User, error) {
+	return h.service.Find(nil, id)
+}
+`,
+	"internal/app/app.go": `package app
+
+import APP_IMPORT
+
+// ProvideApp creates the application the router registers on
+func ProvideApp() APP_TYPE {
+	return nil
+}
+`,
+	"internal/health/health.go": `package health
+
+import HANDLER_IMPORT
+
+// GetHealth reports whether the service is up
+// @Summary Health check
+// @Success 200 {string} string
+// @Router /health [get]
+func GetHealth HANDLER_SIGNATURE {
+	HANDLER_BODY
+}
+`,
+}
+
+// checkGatewaySource is a gRPC service method served over HTTP by the gateway, for Fiber v2
+const checkGatewaySource = `package user
+
+import (
+	"context"
+
+	userv1 "example.com/check/gen/user/v1"
+)
+
+// Server implements the gRPC user service
+type Server struct{}
+
+// GetProfile returns the profile of a user
+// @RPC
+// @Summary Get a user profile
+// @Router /profiles/{id} [get]
+func (s *Server) GetProfile(ctx context.Context, req *userv1.GetProfileRequest) (*userv1.Profile, error) {
+	return nil, nil
+}
+`
+
+// checkCleanupSource is a provider returning a cleanup function, which the fx backend does not support
+const checkCleanupSource = `package db
+
+// DB is a database connection
+type DB struct{}
+
+// ProvideDB opens the database connection
+func ProvideDB() (*DB, func(), error) {
+	return &DB{}, func() {}, nil
+}
+`
+
+// checkStep renders one template into the synthetic project
+type checkStep struct {
+	template string            // Template rendering the step's files, e.g. "routes.tmpl"
+	others   map[string]string // Files rendered by another template of the step, e.g. the chaos passthrough file
+	run      func() error
+}
+
+// CheckTemplates renders every embedded or overridden template against a synthetic project for each
+// route framework and dependency backend, then checks the output is valid Go (gofmt, unused imports,
+// duplicate declarations, generated header) or valid YAML, so broken customizations surface before
+// they break real generation
+func CheckTemplates(ctx context.Context, cfg *config.Config) (*TemplateCheckResult, error) {
+	if _, err := UnknownTemplateOverrides(cfg); err != nil {
+		return nil, err
+	}
+
+	templatesDir := cfg.Paths.TemplatesDir
+	if templatesDir != "" {
+		abs, err := filepath.Abs(templatesDir)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving templates directory: %w", err)
+		}
+		templatesDir = abs
+	}
+
+	tmpDir, err := os.MkdirTemp("", "taskw-templates-*")
+	if err != nil {
+		return nil, fmt.Errorf("error creating check project: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Formatting failures are reported as problems of the template instead
+	formatWarnings = io.Discard
+	defer func() { formatWarnings = os.Stdout }()
+
+	result := &TemplateCheckResult{Templates: TemplateNames()}
+	for i, variant := range templateVariants {
+		dir := filepath.Join(tmpDir, strconv.Itoa(i))
+		problems, err := checkVariant(ctx, cfg, templatesDir, dir, variant)
+		if err != nil {
+			return nil, fmt.Errorf("error checking templates with %s: %w", variant.Name(), err)
+		}
+		result.Variants = append(result.Variants, variant.Name())
+		result.Problems = append(result.Problems, problems...)
+	}
+
+	return result, nil
+}
+
+// checkVariant generates the synthetic project with one variant and checks every generated file
+func checkVariant(ctx context.Context, cfg *config.Config, templatesDir, dir string, variant templateVariant) ([]TemplateProblem, error) {
+	handler := variant.handler()
+	framework, err := lookupRouteFramework(&config.Config{Generation: config.Generation{Routes: config.RouteConfig{Framework: variant.Framework, FiberVersion: variant.FiberVersion}}})
+	if err != nil {
+		return nil, err
+	}
+	replacer := strings.NewReplacer(
+		"HANDLER_IMPORT", handler.Import, "HANDLER_SIGNATURE", handler.Signature, "HANDLER_BODY", handler.Body,
+		"APP_IMPORT", framework.AppImport, "APP_TYPE", framework.AppType,
+	)
+	sources := make(map[string]string, len(checkSources)+1)
+	for name, source := range checkSources {
+		sources[name] = replacer.Replace(source)
+	}
+	if variant.Backend != config.BackendFx {
+		sources["internal/db/db.go"] = checkCleanupSource
+	}
+	if variant.Framework == config.FrameworkFiber && variant.FiberVersion != 3 {
+		sources["internal/user/grpc.go"] = checkGatewaySource
+	}
+	for name, source := range sources {
+		filePath := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filePath, []byte(source), 0644); err != nil {
+			return nil, err
+		}
+	}
+	docsDir := filepath.Join(dir, "docs")
+	if err := os.MkdirAll(docsDir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(docsDir, "docs.go"), []byte("package docs\n"), 0644); err != nil {
+		return nil, err
+	}
+
+	checkCfg := checkConfig(cfg, templatesDir, dir, variant)
+	scan := func() (*scanner.ScanResult, error) {
+		result, err := scanner.NewScanner(checkCfg).ScanAll(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning check project: %w", err)
+		}
+		return result, nil
+	}
+	result, err := scan()
+	if err != nil {
+		return nil, err
+	}
+
+	// The check project is written straight to disk, outside of any generation run
+	_, passthroughPath := ChaosFiles(checkCfg)
+	steps := []checkStep{
+		{template: path.Base(framework.Template), run: func() error {
+			routeGen, err := NewRouteGenerator(checkCfg, nil)
+			if err != nil {
+				return err
+			}
+			return routeGen.GenerateRoutes(result.Handlers, result.Routes)
+		}},
+		{template: dependencyTemplate(checkCfg), run: func() error {
+			// The generated router is a provider of the dependency graph
+			rescanned, err := scan()
+			if err != nil {
+				return err
+			}
+			return NewDependencyGenerator(checkCfg, nil).GenerateDependencies(rescanned.Providers, rescanned.Implementations)
+		}},
+		{template: "chaos.tmpl", others: map[string]string{passthroughPath: "chaos_off.tmpl"}, run: func() error {
+			_, err := NewChaosGenerator(checkCfg, nil).GenerateChaos(result.Providers)
+			return err
+		}},
+		{template: "params.tmpl", run: func() error {
+			paramGen, err := NewParamGenerator(checkCfg, nil)
+			if err != nil {
+				return err
+			}
+			_, _, err = paramGen.GenerateParams(result.Routes)
+			return err
+		}},
+		{template: "ports.tmpl", run: func() error {
+			_, _, err := NewPortGenerator(checkCfg, nil).GeneratePorts(result.Handlers)
+			return err
+		}},
+		{template: "package_doc.tmpl", run: func() error {
+			_, _, err := NewPackageDocGenerator(checkCfg, nil).GeneratePackageDocs(result)
+			return err
+		}},
+		{template: "envelope.tmpl", run: func() error {
+			return NewEnvelopeGenerator(checkCfg, nil).GenerateEnvelope()
+		}},
+		{template: "slo_alerts.tmpl", run: func() error {
+			_, err := NewSLOAlertGenerator(checkCfg, nil).GenerateAlerts(result.Routes)
+			return err
+		}},
+		{template: "pii_report.tmpl", run: func() error {
+			_, err := NewPIIReportGenerator(checkCfg, nil).GenerateReport(result)
+			return err
+		}},
+		{template: "openapi_info.tmpl", run: func() error {
+			_, err := NewSwaggerSpecGenerator(checkCfg, nil).writeDocsOverride(docsDir, `{"swagger": "2.0", "info": {"title": "{{.Title}}"}}`)
+			return err
+		}},
+	}
+	// Recording middleware and path migrations rely on Fiber
+	if variant.Framework == config.FrameworkFiber {
+		migrations := &RouteMigrations{Migrations: []RouteMigration{
+			{From: "/old/users", To: "/users", Mode: MigrationRedirect, Status: 308, RemoveAfter: "2999-12-31"},
+			{From: "/legacy/health", To: "/health", Mode: MigrationProxy, RemoveAfter: "2999-12-31"},
+		}}
+		steps = append(steps,
+			checkStep{template: "recording.tmpl", run: func() error {
+				recordingGen, err := NewRecordingGenerator(checkCfg, nil)
+				if err != nil {
+					return err
+				}
+				return recordingGen.GenerateRecording(result.Routes)
+			}},
+			checkStep{template: "redirects.tmpl", run: func() error {
+				redirectGen, err := NewRedirectGenerator(checkCfg, nil)
+				if err != nil {
+					return err
+				}
+				_, err = redirectGen.GenerateRedirects(migrations, result.Routes, time.Now())
+				return err
+			}},
+		)
+	}
+	// The generated Server holds the Router
+	if checkCfg.Generation.Server.Enabled {
+		steps = append(steps, checkStep{template: "server.tmpl", run: func() error {
+			serverGen, err := NewServerGenerator(checkCfg, nil)
+			if err != nil {
+				return err
+			}
+			return serverGen.GenerateServer()
+		}})
+	}
+	// The swagger UI middleware is a Fiber v2 one
+	if checkCfg.Generation.SwaggerUI.Enabled {
+		steps = append(steps, checkStep{template: "swagger_ui.tmpl", run: func() error {
+			swaggerUIGen, err := NewSwaggerUIGenerator(checkCfg, nil)
+			if err != nil {
+				return err
+			}
+			return swaggerUIGen.GenerateSwaggerUI()
+		}})
+	}
+
+	// So is the gateway
+	if checkCfg.Generation.Gateway.Enabled {
+		steps = append(steps, checkStep{template: "gateway.tmpl", run: func() error {
+			gatewayGen, err := NewGatewayGenerator(checkCfg, nil)
+			if err != nil {
+				return err
+			}
+			return gatewayGen.GenerateGateway(result.RPCs, result.Routes)
+		}})
+	}
+
+	var problems []TemplateProblem
+	for _, step := range steps {
+		before, err := snapshotFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		if err := step.run(); err != nil {
+			problems = append(problems, TemplateProblem{Template: step.template, Variant: variant.Name(), Message: err.Error()})
+			continue
+		}
+		after, err := snapshotFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, filePath := range changedFiles(before, after) {
+			template := step.template
+			if other, ok := step.others[filePath]; ok {
+				template = other
+			}
+			rel, err := filepath.Rel(dir, filePath)
+			if err != nil {
+				rel = filePath
+			}
+			for _, message := range checkGeneratedFile(filePath, after[filePath]) {
+				problems = append(problems, TemplateProblem{Template: template, Variant: variant.Name(), File: filepath.ToSlash(rel), Message: message})
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+// checkConfig returns the configuration generating the synthetic project in dir with every feature enabled
+// Settings shaping the output, such as output file names and the OpenAPI information, are kept
+func checkConfig(cfg *config.Config, templatesDir, dir string, variant templateVariant) *config.Config {
+	checkCfg := *cfg
+	checkCfg.Root = ""
+	checkCfg.WorkDir = ""
+	checkCfg.ModuleDir = dir
+	checkCfg.Project.Module = "example.com/check"
+	checkCfg.Scanning = config.Scanning{Mode: config.ScanModeAST}
+	checkCfg.Conventions = config.Conventions{}
+	checkCfg.Paths = config.Paths{
+		ScanDirs:     []string{dir},
+		OutputDir:    filepath.Join(dir, "internal", "api"),
+		TemplatesDir: templatesDir,
+	}
+
+	generation := &checkCfg.Generation
+	generation.Routes.Enabled = true
+	generation.Routes.Framework = variant.Framework
+	generation.Routes.FiberVersion = variant.FiberVersion
+	generation.Routes.Hooks = variant.Framework == config.FrameworkFiber && variant.router()
+	generation.Routes.RouteIDHeader = "X-Route-Id"
+	generation.Routes.Style = variant.Style
+	generation.Routes.RegisterFunc = ""
+	generation.Server.Enabled = variant.router()
+	generation.Dependencies.Enabled = true
+	generation.Dependencies.Backend = variant.Backend
+	generation.Dependencies.PerPackageSets = variant.PerPackageSets
+	generation.Dependencies.RunWire = false
+	generation.PackageDocs.Enabled = true
+	generation.Params.Enabled = true
+	generation.Ports.Enabled = true
+	generation.Recording.Enabled = variant.Framework == config.FrameworkFiber
+	generation.SLO.Enabled = true
+	generation.SLO.OutputFile = filepath.Join(dir, filepath.Base(generation.SLO.OutputFile))
+	generation.Chaos.Enabled = true
+	generation.Envelope.Enabled = true
+	generation.Envelope.PackageDir = filepath.Join(dir, "internal", "envelope")
+	generation.PII.Enabled = true
+	generation.PII.OutputFile = filepath.Join(dir, filepath.Base(generation.PII.OutputFile))
+	generation.SwaggerUI.Enabled = variant.Framework == config.FrameworkFiber && variant.FiberVersion != 3
+	generation.Gateway.Enabled = variant.Framework == config.FrameworkFiber && variant.FiberVersion != 3
+
+	return &checkCfg
+}
+
+// dependencyTemplate returns the dependency template of the configured backend
+func dependencyTemplate(cfg *config.Config) string {
+	switch cfg.DependencyBackend() {
+	case config.BackendFx:
+		return "dependencies_fx.tmpl"
+	case config.BackendPlain:
+		return "dependencies_plain.tmpl"
+	}
+	return "dependencies.tmpl"
+}
+
+// snapshotFiles returns the content of every file under dir
+func snapshotFiles(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		files[filePath] = content
+		return nil
+	})
+	return files, err
+}
+
+// changedFiles returns the files written or modified between two snapshots, sorted
+func changedFiles(before, after map[string][]byte) []string {
+	var changed []string
+	for filePath, content := range after {
+		if previous, ok := before[filePath]; !ok || !bytes.Equal(previous, content) {
+			changed = append(changed, filePath)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// checkGeneratedFile returns the problems of a generated file
+func checkGeneratedFile(filePath string, content []byte) []string {
+	switch filepath.Ext(filePath) {
+	case ".go":
+		return checkGeneratedGo(content)
+	case ".yaml", ".yml":
+		var document interface{}
+		if err := yaml.Unmarshal(content, &document); err != nil {
+			return []string{fmt.Sprintf("invalid YAML: %v", err)}
+		}
+	}
+	return nil
+}
+
+// checkGeneratedGo runs the checks of go vet that need no type information:
+// the file must parse and format, carry the generated header, and declare and use its imports once
+func checkGeneratedGo(content []byte) []string {
+	// writeGeneratedFile keeps unformatted content when gofmt fails, the syntax error is the problem
+	if _, err := format.Source(content); err != nil {
+		return []string{fmt.Sprintf("invalid Go: %v", err)}
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, parser.ParseComments)
+	if err != nil {
+		return []string{fmt.Sprintf("invalid Go: %v", err)}
+	}
+
+	var problems []string
+	if header, _ := cutLine(content); !isGeneratedHeader(string(header)) {
+		problems = append(problems, fmt.Sprintf("missing the \"// %s. DO NOT EDIT.\" header, taskw will not overwrite the file on the next run", GeneratedMarker))
+	}
+
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if selector, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := selector.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	imported := make(map[string]string)
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := defaultImportName(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if name == "_" || name == "." {
+			continue
+		}
+		line := fset.Position(spec.Pos()).Line
+		if other, exists := imported[name]; exists {
+			problems = append(problems, fmt.Sprintf("line %d: %s redeclared in this block (imported from %q and %q)", line, name, other, importPath))
+			continue
+		}
+		imported[name] = importPath
+		if !used[name] {
+			problems = append(problems, fmt.Sprintf("line %d: %q imported and not used", line, importPath))
+		}
+	}
+
+	declared := make(map[string]int)
+	declare := func(name string, pos token.Pos) {
+		if name == "_" || name == "init" {
+			return
+		}
+		line := fset.Position(pos).Line
+		if first, exists := declared[name]; exists {
+			problems = append(problems, fmt.Sprintf("line %d: %s redeclared in this block (first declared on line %d)", line, name, first))
+			return
+		}
+		declared[name] = line
+	}
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			name := decl.Name.Name
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				name = receiverTypeName(decl.Recv.List[0].Type) + "." + name
+			}
+			declare(name, decl.Name.Pos())
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					declare(spec.Name.Name, spec.Name.Pos())
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						declare(name.Name, name.Pos())
+					}
+				}
+			}
+		}
+	}
+
+	return problems
+}
+
+// defaultImportName returns the name a package is imported under without an explicit name,
+// assuming the package is named after its path, e.g. "github.com/gofiber/fiber/v2" -> "fiber", "gopkg.in/yaml.v3" -> "yaml"
+func defaultImportName(importPath string) string {
+	segments := strings.Split(importPath, "/")
+	name := segments[len(segments)-1]
+	if len(segments) > 1 && isMajorVersion(name) {
+		name = segments[len(segments)-2]
+	}
+	if dot := strings.Index(name, ".v"); dot > 0 && isMajorVersion(name[dot+1:]) {
+		name = name[:dot]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	return strings.ReplaceAll(name, "-", "")
+}
+
+// isMajorVersion reports whether an import path segment is a major version suffix, e.g. "v2"
+func isMajorVersion(segment string) bool {
+	if len(segment) < 2 || segment[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(segment[1:])
+	return err == nil
+}
+
+// receiverTypeName returns the type name of a method receiver, e.g. "*Router" -> "Router"
+func receiverTypeName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(expr.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(expr.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	}
+	return ""
+}
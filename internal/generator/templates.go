@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// readTemplate returns the content of an embedded template, e.g. "templates/routes.tmpl"
+// A file with the same name in paths.templates_dir replaces the embedded template
+func readTemplate(cfg *config.Config, name string) ([]byte, error) {
+	if override := TemplateOverride(cfg, path.Base(name)); override != "" {
+		content, err := os.ReadFile(cfg.ResolvePath(override))
+		if err != nil {
+			return nil, fmt.Errorf("error reading template override %s: %w", override, err)
+		}
+		return content, nil
+	}
+	return templateFS.ReadFile(name)
+}
+
+// TemplateOverride returns the file overriding the named template, e.g. "routes.tmpl",
+// or "" when the embedded template is used
+func TemplateOverride(cfg *config.Config, name string) string {
+	if cfg.Paths.TemplatesDir == "" {
+		return ""
+	}
+	override := filepath.Join(cfg.Paths.TemplatesDir, name)
+	if _, err := os.Stat(cfg.ResolvePath(override)); err != nil {
+		return ""
+	}
+	return override
+}
+
+// TemplateNames returns the names of the embedded generation templates, e.g. "routes.tmpl"
+func TemplateNames() []string {
+	entries, err := fs.ReadDir(templateFS, "templates")
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".tmpl") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UnknownTemplateOverrides returns the files in paths.templates_dir not matching any embedded template,
+// which would otherwise be silently ignored
+func UnknownTemplateOverrides(cfg *config.Config) ([]string, error) {
+	if cfg.Paths.TemplatesDir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(cfg.ResolvePath(cfg.Paths.TemplatesDir))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("templates directory %s does not exist", cfg.Paths.TemplatesDir)
+		}
+		return nil, fmt.Errorf("error reading templates directory %s: %w", cfg.Paths.TemplatesDir, err)
+	}
+
+	known := make(map[string]bool)
+	for _, name := range TemplateNames() {
+		known[name] = true
+	}
+	var unknown []string
+	for _, entry := range entries {
+		if !entry.IsDir() && !known[entry.Name()] {
+			unknown = append(unknown, filepath.Join(cfg.Paths.TemplatesDir, entry.Name()))
+		}
+	}
+	return unknown, nil
+}
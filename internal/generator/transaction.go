@@ -0,0 +1,177 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/nkaewam/taskw/internal/config"
+)
+
+// Transaction keeps the previous content of every file written while it is active, so a run failing
+// halfway can restore them instead of leaving a new routes_gen.go next to a stale dependencies_gen.go
+type Transaction struct {
+	output  *Output // Output the transaction runs in, nil for a nested transaction
+	mu      sync.Mutex
+	backups map[string]fileBackup
+	order   []string // Paths in the order they were first written
+}
+
+// fileBackup is the content of a file before the transaction first wrote it
+type fileBackup struct {
+	abs     string // Where the file is, its path resolved against the project root
+	existed bool
+	content []byte
+	mode    os.FileMode
+}
+
+// BeginTransaction starts recording the files generators write through o, until Commit or Rollback.
+// A transaction already running keeps recording, the outer one decides
+func (o *Output) BeginTransaction() *Transaction {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.tx != nil {
+		return &Transaction{}
+	}
+	o.tx = &Transaction{output: o, backups: make(map[string]fileBackup)}
+	return o.tx
+}
+
+// Commit keeps the written files and stops recording
+func (t *Transaction) Commit() {
+	t.end()
+}
+
+// Rollback restores every file written during the transaction to its previous content, removing the
+// files that didn't exist before, and returns the restored paths
+func (t *Transaction) Rollback() ([]string, error) {
+	if !t.end() {
+		return nil, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var restored []string
+	var failed []string
+	for i := len(t.order) - 1; i >= 0; i-- {
+		path := t.order[i]
+		backup := t.backups[path]
+
+		var err error
+		if backup.existed {
+			err = replaceFile(backup.abs, backup.content, backup.mode)
+		} else if err = os.Remove(backup.abs); os.IsNotExist(err) {
+			err = nil
+		}
+		if err != nil {
+			failed = append(failed, path)
+			continue
+		}
+		restored = append(restored, path)
+	}
+
+	if len(failed) > 0 {
+		return restored, fmt.Errorf("failed to restore %v", failed)
+	}
+	return restored, nil
+}
+
+// end stops recording, false for a nested transaction that leaves it to the outer one
+func (t *Transaction) end() bool {
+	if t.output == nil {
+		return false
+	}
+	t.output.mu.Lock()
+	defer t.output.mu.Unlock()
+	if t.output.tx != t {
+		return false
+	}
+	t.output.tx = nil
+	return true
+}
+
+// record keeps the content of a file before its first write in the transaction
+func (t *Transaction) record(root, path string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	path = filepath.Clean(path)
+	if _, seen := t.backups[path]; seen {
+		return nil
+	}
+
+	backup := fileBackup{abs: config.JoinRoot(root, path)}
+	info, err := os.Stat(backup.abs)
+	switch {
+	case err == nil:
+		content, err := os.ReadFile(backup.abs)
+		if err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+		backup.existed, backup.content, backup.mode = true, content, info.Mode().Perm()
+	case !os.IsNotExist(err):
+		return fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+
+	t.backups[path] = backup
+	t.order = append(t.order, path)
+	return nil
+}
+
+// writeFileAtomic writes a generated file, recording its previous content in the running transaction of o.
+// The content goes to a temporary file renamed over the target, so a failed write never leaves it half written.
+// Files with the taskw header get a checksum line, a file edited by hand since is not overwritten unless forced.
+// With a preview the content is kept in memory instead, and nothing is written.
+// path is relative to the project root, root
+func (o *Output) writeFileAtomic(root, path string, content []byte) error {
+	content = stampChecksum(content)
+	abs := config.JoinRoot(root, path)
+	if preview := o.preview(); preview != nil {
+		preview.capture(path, abs, content)
+		return nil
+	}
+	if err := checkEdited(abs, o.forcing()); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(abs), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := o.Track(root, path); err != nil {
+		return err
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(abs); err == nil {
+		mode = info.Mode().Perm()
+	}
+	return replaceFile(abs, content, mode)
+}
+
+// replaceFile writes content to a temporary file in the directory of path and renames it over path
+func replaceFile(path string, content []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	_, err = tmp.Write(content)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, mode)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
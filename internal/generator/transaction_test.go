@@ -0,0 +1,188 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles writes files below root, by path relative to it
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// assertFiles checks the content of files below root, a file missing from want must not exist
+func assertFiles(t *testing.T, root string, paths []string, want map[string]string) {
+	t.Helper()
+	for _, name := range paths {
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		wantContent, exists := want[name]
+		switch {
+		case !exists && !os.IsNotExist(err):
+			t.Errorf("%s exists after the rollback, want it removed", name)
+		case exists && err != nil:
+			t.Errorf("%s: %v", name, err)
+		case exists && string(content) != wantContent:
+			t.Errorf("%s = %q, want %q", name, content, wantContent)
+		}
+	}
+}
+
+func TestTransactionRollback(t *testing.T) {
+	tests := []struct {
+		name         string
+		existing     map[string]string // Files before the transaction
+		writes       []string          // Files written during the transaction, in order
+		tracked      []string          // Files an external tool rewrites, recorded with Track
+		wantRestored []string
+	}{
+		{
+			name:         "new file removed",
+			writes:       []string{"internal/api/routes_gen.go"},
+			wantRestored: []string{"internal/api/routes_gen.go"},
+		},
+		{
+			name:         "existing file restored",
+			existing:     map[string]string{"internal/api/routes_gen.go": "package api // before\n"},
+			writes:       []string{"internal/api/routes_gen.go"},
+			wantRestored: []string{"internal/api/routes_gen.go"},
+		},
+		{
+			name:         "file written twice restored to its content before the first write",
+			existing:     map[string]string{"internal/api/routes_gen.go": "package api // before\n"},
+			writes:       []string{"internal/api/routes_gen.go", "internal/api/routes_gen.go"},
+			wantRestored: []string{"internal/api/routes_gen.go"},
+		},
+		{
+			name:     "files restored in reverse order of their first write",
+			existing: map[string]string{"internal/api/dependencies_gen.go": "package api // before\n"},
+			writes: []string{
+				"internal/api/routes_gen.go",
+				"internal/api/dependencies_gen.go",
+				"internal/api/routes_gen.go",
+			},
+			wantRestored: []string{"internal/api/dependencies_gen.go", "internal/api/routes_gen.go"},
+		},
+		{
+			name:         "tracked file of an external tool",
+			existing:     map[string]string{"internal/api/wire_gen.go": "package api // before\n"},
+			tracked:      []string{"internal/api/wire_gen.go", "docs/swagger.json"},
+			wantRestored: []string{"docs/swagger.json", "internal/api/wire_gen.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.existing)
+
+			out := &Output{}
+			tx := out.BeginTransaction()
+			for i, path := range tt.writes {
+				if err := out.writeFileAtomic(root, path, []byte("package api // written\n")); err != nil {
+					tx.Rollback()
+					t.Fatalf("write %d of %s: %v", i, path, err)
+				}
+			}
+			if err := out.Track(root, tt.tracked...); err != nil {
+				tx.Rollback()
+				t.Fatalf("Track: %v", err)
+			}
+			for _, path := range tt.tracked {
+				writeFiles(t, root, map[string]string{path: "written by the tool\n"})
+			}
+
+			restored, err := tx.Rollback()
+			if err != nil {
+				t.Fatalf("Rollback: %v", err)
+			}
+			if !reflect.DeepEqual(restored, tt.wantRestored) {
+				t.Errorf("Rollback restored %v, want %v", restored, tt.wantRestored)
+			}
+			assertFiles(t, root, append(tt.writes, tt.tracked...), tt.existing)
+		})
+	}
+}
+
+func TestTransactionRollbackKeepsMode(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "run.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &Output{}
+	tx := out.BeginTransaction()
+	if err := out.writeFileAtomic(root, "run.sh", []byte("#!/bin/sh\nexit 1\n")); err != nil {
+		tx.Rollback()
+		t.Fatal(err)
+	}
+	if _, err := tx.Rollback(); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("mode after the rollback = %v, want %v", info.Mode().Perm(), os.FileMode(0755))
+	}
+}
+
+func TestTransactionCommit(t *testing.T) {
+	root := t.TempDir()
+
+	out := &Output{}
+	tx := out.BeginTransaction()
+	if err := out.writeFileAtomic(root, "routes_gen.go", []byte("package api\n")); err != nil {
+		tx.Rollback()
+		t.Fatal(err)
+	}
+	tx.Commit()
+
+	// Once committed there is nothing left to roll back
+	restored, err := tx.Rollback()
+	if err != nil || restored != nil {
+		t.Errorf("Rollback after Commit = %v, %v, want nothing restored", restored, err)
+	}
+	assertFiles(t, root, []string{"routes_gen.go"}, map[string]string{"routes_gen.go": "package api\n"})
+}
+
+func TestNestedTransaction(t *testing.T) {
+	root := t.TempDir()
+
+	out := &Output{}
+	outer := out.BeginTransaction()
+	inner := out.BeginTransaction()
+	if err := out.writeFileAtomic(root, "routes_gen.go", []byte("package api\n")); err != nil {
+		outer.Rollback()
+		t.Fatal(err)
+	}
+
+	// The outer transaction decides, rolling back the inner one leaves the file
+	restored, err := inner.Rollback()
+	if err != nil || restored != nil {
+		t.Errorf("inner Rollback = %v, %v, want nothing restored", restored, err)
+	}
+	assertFiles(t, root, []string{"routes_gen.go"}, map[string]string{"routes_gen.go": "package api\n"})
+
+	restored, err = outer.Rollback()
+	if err != nil {
+		t.Fatalf("outer Rollback: %v", err)
+	}
+	if want := []string{"routes_gen.go"}; !reflect.DeepEqual(restored, want) {
+		t.Errorf("outer Rollback restored %v, want %v", restored, want)
+	}
+	assertFiles(t, root, []string{"routes_gen.go"}, nil)
+}
@@ -0,0 +1,211 @@
+// Package pipeline runs the generation steps of a project in the order taskw generate all runs them,
+// shared by the taskw generate commands and the public pkg/gen package
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/generator"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// Steps of taskw generate all --only selects, named after their subcommands
+const (
+	StepRoutes    = "routes"
+	StepSwaggerUI = "swaggerui"
+	StepGateway   = "gateway"
+	StepServer    = "server"
+	StepDeps      = "deps"
+	StepPkgDocs   = "pkgdocs"
+	StepParams    = "params"
+	StepPorts     = "ports"
+	StepRecording = "recording"
+	StepAlerts    = "alerts"
+	StepEnvelope  = "envelope"
+	StepPII       = "pii"
+	StepRedirects = "redirects"
+	StepSwagger   = "swagger"
+)
+
+// Steps lists the steps of taskw generate all in the order they run
+var Steps = []string{StepRoutes, StepSwaggerUI, StepGateway, StepServer, StepDeps, StepPkgDocs, StepParams, StepPorts, StepRecording, StepAlerts, StepEnvelope, StepPII, StepRedirects, StepSwagger}
+
+// CheckSteps returns an error for a step --only doesn't know
+func CheckSteps(steps []string) error {
+	for _, step := range steps {
+		if !slices.Contains(Steps, step) {
+			return exitcode.New(exitcode.Config, fmt.Errorf("unknown step %q, use one of %s", step, strings.Join(Steps, ", ")))
+		}
+	}
+	return nil
+}
+
+// Reporter shows the progress of the steps, the CLI prints it and pkg/gen keeps the warnings and errors
+type Reporter interface {
+	// ShowSpinner shows a step running and returns the function reporting its outcome
+	ShowSpinner(message string) func(completedMessage string)
+	Infof(format string, args ...interface{})
+	Detailf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// Tools finds and installs the external commands of the wire and swagger steps
+type Tools interface {
+	// IsCommandAvailable checks if a command is available in PATH
+	IsCommandAvailable(name string) bool
+	// InstallSwag installs the swag command, stopping once ctx is done
+	InstallSwag(ctx context.Context) error
+	// InstallWire installs the wire command, stopping once ctx is done
+	InstallWire(ctx context.Context) error
+}
+
+// Runner runs the generation steps enabled in taskw.yaml, generation.only selecting some of them
+type Runner struct {
+	config  *config.Config
+	scanner *scanner.Scanner
+	report  Reporter
+	tools   Tools
+	output  *generator.Output
+}
+
+// NewRunner creates a runner scanning the project with scan, writing the generated files straight to disk
+func NewRunner(cfg *config.Config, scan *scanner.Scanner, report Reporter, tools Tools) *Runner {
+	return &Runner{
+		config:  cfg,
+		scanner: scan,
+		report:  report,
+		tools:   tools,
+		output:  &generator.Output{},
+	}
+}
+
+// SetOutput sets the state of the next runs: whether files edited by hand are overwritten, the preview
+// capturing the generated files and the recording noting them for the manifest
+func (r *Runner) SetOutput(out *generator.Output) {
+	r.output = out
+}
+
+// GenerateAll generates routes, dependencies, and swagger documentation
+func (r *Runner) GenerateAll(ctx context.Context) error {
+	return r.transaction(func() error {
+		if err := r.generateCode(ctx); err != nil {
+			return err
+		}
+
+		// Generate Swagger documentation
+		if !r.selected(StepSwagger) {
+			return nil
+		}
+		return r.GenerateSwagger(ctx)
+	})
+}
+
+// GenerateCode generates every enabled Go artifact, leaving out the swagger documentation
+func (r *Runner) GenerateCode(ctx context.Context) error {
+	return r.transaction(func() error { return r.generateCode(ctx) })
+}
+
+// transaction runs generate and restores every file it wrote when it fails, so a failed run
+// never leaves the outputs of some generators updated and the others stale
+func (r *Runner) transaction(generate func() error) error {
+	tx := r.output.BeginTransaction()
+	err := generate()
+	if err == nil {
+		tx.Commit()
+		return nil
+	}
+
+	restored, rollbackErr := tx.Rollback()
+	if len(restored) > 0 {
+		r.report.Detailf("Rolled back %d generated file(s) to their previous content", len(restored))
+	}
+	if rollbackErr != nil {
+		return fmt.Errorf("%w (rolling back generated files: %v)", err, rollbackErr)
+	}
+	return err
+}
+
+// selected reports whether --only leaves a step of taskw generate all to run
+func (r *Runner) selected(step string) bool {
+	return len(r.config.Generation.Only) == 0 || slices.Contains(r.config.Generation.Only, step)
+}
+
+// generateCode runs the generator of every enabled Go artifact
+func (r *Runner) generateCode(ctx context.Context) error {
+	if r.config.Generation.Routes.Enabled && r.selected(StepRoutes) {
+		if err := r.GenerateRoutes(ctx); err != nil {
+			return err
+		}
+	}
+	// The server registers the swagger UI once generated
+	if r.config.Generation.SwaggerUI.Enabled && r.selected(StepSwaggerUI) {
+		if err := r.GenerateSwaggerUI(ctx); err != nil {
+			return err
+		}
+	}
+	// So are the gateway routes
+	if r.config.Generation.Gateway.Enabled && r.selected(StepGateway) {
+		if err := r.GenerateGateway(ctx); err != nil {
+			return err
+		}
+	}
+	if r.config.Generation.Server.Enabled && r.selected(StepServer) {
+		if err := r.GenerateServer(ctx); err != nil {
+			return err
+		}
+	}
+	if r.config.Generation.Dependencies.Enabled && r.selected(StepDeps) {
+		if err := r.GenerateDependencies(ctx); err != nil {
+			return err
+		}
+	}
+	if r.config.Generation.PackageDocs.Enabled && r.selected(StepPkgDocs) {
+		if err := r.GeneratePackageDocs(ctx); err != nil {
+			return err
+		}
+	}
+	if r.config.Generation.Params.Enabled && r.selected(StepParams) {
+		if err := r.GenerateParams(ctx); err != nil {
+			return err
+		}
+	}
+	if r.config.Generation.Ports.Enabled && r.selected(StepPorts) {
+		if err := r.GeneratePorts(ctx); err != nil {
+			return err
+		}
+	}
+	if r.config.Generation.Recording.Enabled && r.selected(StepRecording) {
+		if err := r.GenerateRecording(ctx); err != nil {
+			return err
+		}
+	}
+	if r.config.Generation.SLO.Enabled && r.selected(StepAlerts) {
+		if err := r.GenerateSLOAlerts(ctx); err != nil {
+			return err
+		}
+	}
+	if r.config.Generation.Envelope.Enabled && r.selected(StepEnvelope) {
+		if err := r.GenerateEnvelope(ctx); err != nil {
+			return err
+		}
+	}
+	if r.config.Generation.PII.Enabled && r.selected(StepPII) {
+		if err := r.GeneratePIIReport(ctx); err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(r.config.ResolvePath(r.config.Generation.Redirects.MigrationsFile)); err == nil && r.selected(StepRedirects) {
+		if err := r.GenerateRedirects(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,795 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/nkaewam/taskw/internal/cli/exitcode"
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/generator"
+	"github.com/nkaewam/taskw/internal/scanner"
+	"github.com/nkaewam/taskw/internal/tools"
+)
+
+// GenerateRoutes generates only route registration code
+func (r *Runner) GenerateRoutes(ctx context.Context) error {
+	if !r.config.Generation.Routes.Enabled {
+		return nil
+	}
+
+	stopSpinner := r.report.ShowSpinner("Generating routes...")
+
+	handlers, routes, err := r.scanner.ScanRoutes(ctx, r.config.Paths.ScanDirs)
+	if err != nil {
+		stopSpinner("Error scanning routes")
+		return exitcode.New(exitcode.Scan, fmt.Errorf("error scanning routes: %w", err))
+	}
+
+	handlers, routes, left, unmatched := r.routePackages(handlers, routes)
+
+	if len(handlers) == 0 {
+		stopSpinner("No handlers found")
+		return nil
+	}
+
+	if len(routes) == 0 {
+		stopSpinner("No @Router annotations found")
+		return nil
+	}
+
+	// Handler types sharing a field would be shadowed silently, only the first one is injected
+	validation := &scanner.ValidationResult{}
+	scanner.NewValidator(r.config.Conventions).ValidateHandlerFields(routes, validation)
+	scanner.RecordValidation(validation)
+	if validation.HasErrors() {
+		stopSpinner("Conflicting handler fields")
+		for _, fieldErr := range validation.Errors {
+			r.report.Errorf("  • %s", fieldErr)
+		}
+		return exitcode.New(exitcode.Validation, fmt.Errorf("error generating routes: %d handler field conflict(s) found", len(validation.Errors)))
+	}
+
+	// Generate routes using the RouteGenerator
+	routeGen, err := generator.NewRouteGenerator(r.config, r.output)
+	if err != nil {
+		stopSpinner("Error generating routes")
+		return exitcode.New(exitcode.Config, err)
+	}
+	if err := routeGen.GenerateRoutes(handlers, routes); err != nil {
+		stopSpinner("Error generating routes")
+		return exitcode.New(exitcode.Generation, fmt.Errorf("error generating routes: %w", err))
+	}
+
+	outputPath := filepath.Join(r.config.Paths.OutputDir, r.config.Generation.Routes.OutputFile)
+	stopSpinner("Routes generated successfully")
+	r.report.Detailf("Found %d handlers and %d routes", len(handlers), len(routes))
+	if left > 0 {
+		r.report.Detailf("Left out %d routes of packages excluded by generation.routes", left)
+	}
+	for _, setting := range unmatched {
+		r.report.Detailf("%s matches no handler package", setting)
+	}
+	r.report.Detailf("Generated: %s", outputPath)
+
+	// Without dependency generation the providers of the routed handlers are left for hand-written wiring
+	if !r.config.Generation.Dependencies.Enabled {
+		result, err := r.scanner.ScanAll(ctx)
+		if err != nil {
+			return exitcode.New(exitcode.Scan, fmt.Errorf("error scanning providers: %w", err))
+		}
+		validation := &scanner.ValidationResult{}
+		scanner.NewValidator(r.config.Conventions).ValidatePartialGeneration(result, r.config.Generation, validation)
+		scanner.RecordValidation(validation)
+		for _, warning := range validation.Warnings {
+			r.report.Warnf("%s", warning)
+		}
+	}
+
+	return nil
+}
+
+// routePackages keeps the handlers and routes of the packages generation.routes selects, see
+// scanner.SelectRoutePackages
+func (r *Runner) routePackages(handlers []scanner.HandlerFunction, routes []scanner.RouteMapping) ([]scanner.HandlerFunction, []scanner.RouteMapping, int, []string) {
+	return scanner.SelectRoutePackages(r.config.Generation.Routes, handlers, routes)
+}
+
+// GenerateServer generates the Server struct wiring the application to the generated router
+func (r *Runner) GenerateServer(ctx context.Context) error {
+	if !r.config.Generation.Server.Enabled {
+		r.report.Infof("• Server generation is disabled (set generation.server.enabled: true)")
+		return nil
+	}
+
+	routesPath := filepath.Join(r.config.Paths.OutputDir, r.config.Generation.Routes.OutputFile)
+	if _, err := os.Stat(r.config.ResolvePath(routesPath)); err != nil {
+		r.report.Infof("• Skipping server generation, %s has not been generated yet", routesPath)
+		return nil
+	}
+
+	stopSpinner := r.report.ShowSpinner("Generating server...")
+
+	serverGen, err := generator.NewServerGenerator(r.config, r.output)
+	if err != nil {
+		stopSpinner("Error generating server")
+		return exitcode.New(exitcode.Config, err)
+	}
+	if err := serverGen.GenerateServer(); err != nil {
+		stopSpinner("Error generating server")
+		return exitcode.New(exitcode.Generation, fmt.Errorf("error generating server: %w", err))
+	}
+
+	stopSpinner("Server generated successfully")
+	r.report.Detailf("Generated: %s", generator.ServerFile(r.config))
+
+	return nil
+}
+
+// GenerateDependencies generates only dependency injection code
+func (r *Runner) GenerateDependencies(ctx context.Context) error {
+	if !r.config.Generation.Dependencies.Enabled {
+		return nil
+	}
+
+	stopSpinner := r.report.ShowSpinner("Generating dependencies...")
+
+	result, err := r.scanner.ScanAll(ctx)
+	if err != nil {
+		stopSpinner("Error scanning providers")
+		return exitcode.New(exitcode.Scan, fmt.Errorf("error scanning providers: %w", err))
+	}
+	if len(result.Providers) == 0 {
+		stopSpinner("No provider functions found")
+		return nil
+	}
+
+	// Providers tagged for other environments are left out, e.g. in-memory repositories of dev builds
+	env := r.config.Generation.Dependencies.Env
+	providers, left := scanner.ProvidersForEnv(result.Providers, env)
+	envResult := *result
+	envResult.Providers = providers
+	result = &envResult
+
+	// Report duplicates, cycles and ambiguous packages before the DI framework does, its errors don't name the providers involved
+	validator := scanner.NewValidator(r.config.Conventions)
+	validation := &scanner.ValidationResult{}
+	validator.ValidateDuplicateProviders(providers, validation)
+	validator.ValidateProviderCycles(providers, validation)
+	validator.ValidatePackageConflicts(result.Conflicts, validation)
+	scanner.RecordValidation(validation)
+	if validation.HasErrors() {
+		stopSpinner("Invalid provider graph")
+		for _, graphErr := range validation.Errors {
+			r.report.Errorf("  • %s", graphErr)
+		}
+		return exitcode.New(exitcode.Validation, fmt.Errorf("error generating dependencies: %d provider graph error(s) found", len(validation.Errors)))
+	}
+
+	// Generate dependencies using the DependencyGenerator
+	depGen := generator.NewDependencyGenerator(r.config, r.output)
+	if err := depGen.GenerateDependencies(providers, result.Implementations); err != nil {
+		stopSpinner("Error generating dependencies")
+		return exitcode.New(exitcode.Generation, fmt.Errorf("error generating dependencies: %w", err))
+	}
+
+	outputPath := filepath.Join(r.config.Paths.OutputDir, r.config.Generation.Dependencies.OutputFile)
+	stopSpinner("Dependencies generated successfully")
+	r.report.Detailf("Found %d providers", len(providers))
+	switch {
+	case left > 0 && env == "":
+		r.report.Detailf("Left out %d providers tagged with an environment, pass --env to generate them", left)
+	case left > 0:
+		r.report.Detailf("Left out %d providers tagged for other environments than %q", left, env)
+	}
+	r.report.Detailf("Generated: %s", outputPath)
+
+	// Unused providers still end up in the generated set, point them out so they can be removed,
+	// and so do handler providers whose routes aren't generated
+	validator.ValidateInterfaceBindings(result, validation)
+	validator.ValidateUnusedProviders(result, r.config.Root, r.config.Paths.OutputDir, validation)
+	validator.ValidatePartialGeneration(result, r.config.Generation, validation)
+	scanner.RecordValidation(validation)
+	for _, warning := range validation.Warnings {
+		r.report.Warnf("%s", warning)
+	}
+
+	// The dependency set references the chaos wrappers, so they must be up to date
+	if r.config.Generation.Chaos.Enabled {
+		if err := r.generateChaosWrappers(providers); err != nil {
+			return err
+		}
+	}
+
+	// wire writes wire_gen.go itself, a preview can't capture it
+	if r.config.Generation.Dependencies.RunWire && r.config.DependencyBackend() == config.BackendWire && !r.output.Previewing() {
+		return r.runWire(ctx)
+	}
+
+	return nil
+}
+
+// runWire runs wire on the output directory to regenerate wire_gen.go
+func (r *Runner) runWire(ctx context.Context) error {
+	stopSpinner := r.report.ShowSpinner("Running wire...")
+
+	// Check if wire command is available
+	if !r.tools.IsCommandAvailable("wire") {
+		stopSpinner("Installing wire command...")
+		installSpinner := r.report.ShowSpinner("Installing wire...")
+
+		if err := r.installTool(ctx, r.tools.InstallWire); err != nil {
+			installSpinner("Failed to install wire")
+			if errors.Is(err, context.Canceled) {
+				return err
+			}
+			r.report.Warnf("Please install manually: %s (taskw doctor lists every missing tool)", tools.Wire.InstallHint())
+			return nil
+		}
+		installSpinner("wire installed successfully")
+		stopSpinner = r.report.ShowSpinner("Running wire...")
+	}
+
+	pkg := filepath.ToSlash(filepath.Clean(r.config.Paths.OutputDir))
+	if !filepath.IsAbs(pkg) && pkg != "." {
+		pkg = "./" + pkg
+	}
+	// wire rewrites wire_gen.go itself, keep its content in case the run is rolled back
+	if err := r.output.Track(r.config.Root, filepath.Join(r.config.Paths.OutputDir, "wire_gen.go")); err != nil {
+		stopSpinner("Error running wire")
+		return exitcode.New(exitcode.Generation, err)
+	}
+	output, err := r.runTool(ctx, tools.Wire, "gen", pkg)
+	if err != nil {
+		stopSpinner("Error running wire")
+		return r.toolError(tools.Wire, "error running wire", output, err)
+	}
+
+	stopSpinner(fmt.Sprintf("wire completed successfully for %s", pkg))
+	return nil
+}
+
+// runTool runs an external tool in the project root, stopping it once ctx is done or after tools.timeout
+func (r *Runner) runTool(ctx context.Context, tool tools.Tool, args ...string) ([]byte, error) {
+	ctx, cancel := r.toolContext(ctx)
+	defer cancel()
+	return tool.RunIn(ctx, r.config.Root, args...)
+}
+
+// installTool installs a missing external tool, stopping once ctx is done or after tools.timeout
+func (r *Runner) installTool(ctx context.Context, install func(ctx context.Context) error) error {
+	ctx, cancel := r.toolContext(ctx)
+	defer cancel()
+	return install(ctx)
+}
+
+// toolContext limits ctx to tools.timeout, if set
+func (r *Runner) toolContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if timeout := r.config.ToolTimeout(); timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// toolError reports a failed run of an external tool. An interrupted run is returned as is, a run
+// stopped by tools.timeout says so instead of printing the partial output
+func (r *Runner) toolError(tool tools.Tool, message string, output []byte, err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return err
+	case errors.Is(err, context.DeadlineExceeded):
+		return exitcode.New(exitcode.ExternalTool, fmt.Errorf("%s: %s didn't finish within tools.timeout (%s)", message, tool.Name, r.config.Tools.Timeout))
+	}
+	r.report.Errorf("Output: %s", output)
+	return exitcode.New(exitcode.ExternalTool, fmt.Errorf("%s: %w", message, err))
+}
+
+// GeneratePackageDocs generates per-package doc files summarizing handlers, routes, and providers
+func (r *Runner) GeneratePackageDocs(ctx context.Context) error {
+	if !r.config.Generation.PackageDocs.Enabled {
+		r.report.Infof("• Package docs generation is disabled (set generation.package_docs.enabled: true)")
+		return nil
+	}
+
+	stopSpinner := r.report.ShowSpinner("Generating package docs...")
+
+	result, err := r.scanner.ScanAll(ctx)
+	if err != nil {
+		stopSpinner("Error scanning packages")
+		return exitcode.New(exitcode.Scan, fmt.Errorf("error scanning packages: %w", err))
+	}
+
+	docGen := generator.NewPackageDocGenerator(r.config, r.output)
+	written, skipped, err := docGen.GeneratePackageDocs(scanner.PackageFilter(r.config.Generation.Packages).Apply(result))
+	if err != nil {
+		stopSpinner("Error generating package docs")
+		return exitcode.New(exitcode.Generation, fmt.Errorf("error generating package docs: %w", err))
+	}
+
+	stopSpinner("Package docs generated successfully")
+	for _, path := range written {
+		r.report.Detailf("Generated: %s", path)
+	}
+	for _, path := range skipped {
+		r.report.Detailf("Skipped: %s (not generated by taskw)", path)
+	}
+
+	return nil
+}
+
+// GenerateParams generates per-route helpers parsing the UUID path parameters documented with @Param
+func (r *Runner) GenerateParams(ctx context.Context) error {
+	if !r.config.Generation.Params.Enabled {
+		r.report.Infof("• Path parameter helpers generation is disabled (set generation.params.enabled: true)")
+		return nil
+	}
+
+	stopSpinner := r.report.ShowSpinner("Generating path parameter helpers...")
+
+	_, routes, err := r.scanner.ScanRoutes(ctx, r.config.Paths.ScanDirs)
+	if err != nil {
+		stopSpinner("Error scanning routes")
+		return exitcode.New(exitcode.Scan, fmt.Errorf("error scanning routes: %w", err))
+	}
+
+	// --package limits the helpers to the selected packages
+	filter := scanner.PackageFilter(r.config.Generation.Packages)
+	routes = slices.DeleteFunc(routes, func(r scanner.RouteMapping) bool {
+		return !filter.Matches(r.Package, r.ImportName, r.FilePath)
+	})
+
+	paramGen, err := generator.NewParamGenerator(r.config, r.output)
+	if err != nil {
+		stopSpinner("Error generating path parameter helpers")
+		return exitcode.New(exitcode.Config, err)
+	}
+	written, skipped, err := paramGen.GenerateParams(routes)
+	if err != nil {
+		stopSpinner("Error generating path parameter helpers")
+		return exitcode.New(exitcode.Generation, fmt.Errorf("error generating path parameter helpers: %w", err))
+	}
+
+	if len(written) == 0 && len(skipped) == 0 {
+		stopSpinner("No @Param path parameters of type uuid found")
+		return nil
+	}
+
+	stopSpinner("Path parameter helpers generated successfully")
+	for _, path := range written {
+		r.report.Detailf("Generated: %s", path)
+	}
+	for _, path := range skipped {
+		r.report.Detailf("Skipped: %s (not generated by taskw)", path)
+	}
+
+	return nil
+}
+
+// GeneratePorts generates service interfaces from the methods handlers call on their service fields
+func (r *Runner) GeneratePorts(ctx context.Context) error {
+	if !r.config.Generation.Ports.Enabled {
+		r.report.Infof("• Ports generation is disabled (set generation.ports.enabled: true)")
+		return nil
+	}
+
+	stopSpinner := r.report.ShowSpinner("Generating ports...")
+
+	handlers, _, err := r.scanner.ScanRoutes(ctx, r.config.Paths.ScanDirs)
+	if err != nil {
+		stopSpinner("Error scanning handlers")
+		return exitcode.New(exitcode.Scan, fmt.Errorf("error scanning handlers: %w", err))
+	}
+
+	// --package limits the ports to the selected packages
+	filter := scanner.PackageFilter(r.config.Generation.Packages)
+	handlers = slices.DeleteFunc(handlers, func(h scanner.HandlerFunction) bool {
+		return !filter.Matches(h.Package, h.ImportName, h.FilePath)
+	})
+
+	portGen := generator.NewPortGenerator(r.config, r.output)
+	written, skipped, err := portGen.GeneratePorts(handlers)
+	if err != nil {
+		stopSpinner("Error generating ports")
+		return exitcode.New(exitcode.Generation, fmt.Errorf("error generating ports: %w", err))
+	}
+
+	// Ports left out are reported whether or not others were generated
+	defer func() {
+		for _, warning := range portGen.Warnings() {
+			r.report.Warnf("%s", warning)
+		}
+	}()
+
+	if len(written) == 0 && len(skipped) == 0 {
+		stopSpinner("No handler calls on concrete services found")
+		return nil
+	}
+
+	stopSpinner("Ports generated successfully")
+	for _, path := range written {
+		r.report.Detailf("Generated: %s", path)
+	}
+	for _, path := range skipped {
+		r.report.Detailf("Skipped: %s (not generated by taskw)", path)
+	}
+
+	return nil
+}
+
+// GenerateRecording generates middleware that captures request/response fixtures
+func (r *Runner) GenerateRecording(ctx context.Context) error {
+	if !r.config.Generation.Recording.Enabled {
+		r.report.Infof("• Recording middleware generation is disabled (set generation.recording.enabled: true)")
+		return nil
+	}
+
+	stopSpinner := r.report.ShowSpinner("Generating recording middleware...")
+
+	_, routes, err := r.scanner.ScanRoutes(ctx, r.config.Paths.ScanDirs)
+	if err != nil {
+		stopSpinner("Error scanning routes")
+		return exitcode.New(exitcode.Scan, fmt.Errorf("error scanning routes: %w", err))
+	}
+
+	recordingGen, err := generator.NewRecordingGenerator(r.config, r.output)
+	if err != nil {
+		stopSpinner("Error generating recording middleware")
+		return exitcode.New(exitcode.Config, err)
+	}
+	if err := recordingGen.GenerateRecording(routes); err != nil {
+		stopSpinner("Error generating recording middleware")
+		return exitcode.New(exitcode.Generation, fmt.Errorf("error generating recording middleware: %w", err))
+	}
+
+	scrubbed := 0
+	for _, route := range routes {
+		if len(route.Scrub) > 0 {
+			scrubbed++
+		}
+	}
+
+	outputPath := filepath.Join(r.config.Paths.OutputDir, r.config.Generation.Recording.OutputFile)
+	stopSpinner("Recording middleware generated successfully")
+	r.report.Detailf("Recording %d routes (%d with scrubbed fields)", len(routes), scrubbed)
+	r.report.Detailf("Fixtures directory: %s", r.config.Generation.Recording.FixturesDir)
+	r.report.Detailf("Generated: %s", outputPath)
+
+	return nil
+}
+
+// GenerateSLOAlerts generates Prometheus alerting rules from @SLO annotations
+func (r *Runner) GenerateSLOAlerts(ctx context.Context) error {
+	if !r.config.Generation.SLO.Enabled {
+		r.report.Infof("• SLO alerts generation is disabled (set generation.slo.enabled: true)")
+		return nil
+	}
+
+	stopSpinner := r.report.ShowSpinner("Generating SLO alerts...")
+
+	result, err := r.scanner.ScanAll(ctx)
+	if err != nil {
+		stopSpinner("Error scanning routes")
+		return exitcode.New(exitcode.Scan, fmt.Errorf("error scanning routes: %w", err))
+	}
+
+	alertGen := generator.NewSLOAlertGenerator(r.config, r.output)
+	count, err := alertGen.GenerateAlerts(result.Routes)
+	if err != nil {
+		stopSpinner("Error generating SLO alerts")
+		return exitcode.New(exitcode.Generation, fmt.Errorf("error generating SLO alerts: %w", err))
+	}
+
+	stopSpinner("SLO alerts generated successfully")
+	r.report.Detailf("Generated %d alerting rules", count)
+	r.report.Detailf("Generated: %s", r.config.Generation.SLO.OutputFile)
+	for _, e := range result.Errors {
+		if e.Type == "annotation" {
+			r.report.Detailf("Skipped: %s: %s", e.Position(), e.Message)
+		}
+	}
+
+	return nil
+}
+
+// GenerateChaos generates failure injection wrappers around @ChaosWrap providers
+func (r *Runner) GenerateChaos(ctx context.Context) error {
+	if !r.config.Generation.Chaos.Enabled {
+		r.report.Infof("• Chaos wrapper generation is disabled (set generation.chaos.enabled: true)")
+		return nil
+	}
+
+	stopSpinner := r.report.ShowSpinner("Generating chaos wrappers...")
+
+	providers, err := r.scanner.ScanProviders(ctx, r.config.Paths.ScanDirs)
+	if err != nil {
+		stopSpinner("Error scanning providers")
+		return exitcode.New(exitcode.Scan, fmt.Errorf("error scanning providers: %w", err))
+	}
+
+	if err := r.generateChaosWrappers(providers); err != nil {
+		stopSpinner("Error generating chaos wrappers")
+		return err
+	}
+
+	stopSpinner("Chaos wrappers generated successfully")
+	r.report.Detailf("Run taskw generate deps to use the wrappers in the dependency set")
+	return nil
+}
+
+// generateChaosWrappers writes the chaos wrapper files and reports what was wrapped
+func (r *Runner) generateChaosWrappers(providers []scanner.ProviderFunction) error {
+	chaosGen := generator.NewChaosGenerator(r.config, r.output)
+	count, err := chaosGen.GenerateChaos(providers)
+	if err != nil {
+		return exitcode.New(exitcode.Generation, fmt.Errorf("error generating chaos wrappers: %w", err))
+	}
+
+	wrapperPath, passthroughPath := generator.ChaosFiles(r.config)
+	r.report.Detailf("Wrapped %d providers (build with -tags %s to inject failures)", count, r.config.Generation.Chaos.BuildTag)
+	r.report.Detailf("Generated: %s, %s", wrapperPath, passthroughPath)
+	return nil
+}
+
+// GenerateEnvelope generates helpers wrapping JSON responses in the response envelope
+func (r *Runner) GenerateEnvelope(ctx context.Context) error {
+	if !r.config.Generation.Envelope.Enabled {
+		r.report.Infof("• Response envelope generation is disabled (set generation.envelope.enabled: true)")
+		return nil
+	}
+
+	stopSpinner := r.report.ShowSpinner("Generating response envelope...")
+
+	envelopeGen := generator.NewEnvelopeGenerator(r.config, r.output)
+	if err := envelopeGen.GenerateEnvelope(); err != nil {
+		stopSpinner("Error generating response envelope")
+		return exitcode.New(exitcode.Generation, fmt.Errorf("error generating response envelope: %w", err))
+	}
+
+	envelope := r.config.Generation.Envelope
+	stopSpinner("Response envelope generated successfully")
+	r.report.Detailf("Envelope fields: %s, %s, %s", envelope.DataField, envelope.ErrorField, envelope.MetaField)
+	r.report.Detailf("Generated: %s", generator.EnvelopeFile(r.config))
+
+	return nil
+}
+
+// GenerateSwaggerUI generates the registration of the swagger middleware serving the documentation
+func (r *Runner) GenerateSwaggerUI(ctx context.Context) error {
+	if !r.config.Generation.SwaggerUI.Enabled {
+		r.report.Infof("• Swagger UI generation is disabled (set generation.swagger_ui.enabled: true)")
+		return nil
+	}
+
+	stopSpinner := r.report.ShowSpinner("Generating swagger UI...")
+
+	swaggerUIGen, err := generator.NewSwaggerUIGenerator(r.config, r.output)
+	if err != nil {
+		stopSpinner("Error generating swagger UI")
+		return exitcode.New(exitcode.Config, err)
+	}
+	if err := swaggerUIGen.GenerateSwaggerUI(); err != nil {
+		stopSpinner("Error generating swagger UI")
+		return exitcode.New(exitcode.Generation, fmt.Errorf("error generating swagger UI: %w", err))
+	}
+
+	stopSpinner("Swagger UI generated successfully")
+	r.report.Detailf("Serving %s at %s", generator.SwaggerUISpecFile(r.config), generator.SwaggerUIURL(r.config))
+	r.report.Detailf("Generated: %s", generator.SwaggerUIFile(r.config))
+	if !r.config.Generation.Server.Enabled {
+		r.report.Detailf("Call RegisterSwaggerUI before %s, or enable generation.server to call it from RegisterRoutes", r.config.RegisterFunc())
+	}
+
+	return nil
+}
+
+// GenerateGateway generates the Fiber routes serving @RPC annotated gRPC methods over HTTP
+func (r *Runner) GenerateGateway(ctx context.Context) error {
+	if !r.config.Generation.Gateway.Enabled {
+		r.report.Infof("• Gateway generation is disabled (set generation.gateway.enabled: true)")
+		return nil
+	}
+
+	stopSpinner := r.report.ShowSpinner("Generating gateway...")
+
+	result, err := r.scanner.ScanAll(ctx)
+	if err != nil {
+		stopSpinner("Error scanning gRPC methods")
+		return exitcode.New(exitcode.Scan, fmt.Errorf("error scanning gRPC methods: %w", err))
+	}
+
+	if len(result.RPCs) == 0 {
+		stopSpinner("No @RPC annotations found")
+		return nil
+	}
+
+	gatewayGen, err := generator.NewGatewayGenerator(r.config, r.output)
+	if err != nil {
+		stopSpinner("Error generating gateway")
+		return exitcode.New(exitcode.Config, err)
+	}
+	if err := gatewayGen.GenerateGateway(result.RPCs, result.Routes); err != nil {
+		stopSpinner("Error generating gateway")
+		return exitcode.New(exitcode.Generation, fmt.Errorf("error generating gateway: %w", err))
+	}
+
+	stopSpinner("Gateway generated successfully")
+	r.report.Detailf("Serving %d gRPC methods over HTTP", len(result.RPCs))
+	r.report.Detailf("Generated: %s", generator.GatewayFile(r.config))
+	if !r.config.Generation.Server.Enabled {
+		r.report.Detailf("Call gateway.RegisterRoutes() after %s, or enable generation.server to call it from RegisterRoutes", r.config.RegisterFunc())
+	}
+
+	return nil
+}
+
+// GeneratePIIReport generates a report of the endpoints handling personal data from @PII annotations
+func (r *Runner) GeneratePIIReport(ctx context.Context) error {
+	if !r.config.Generation.PII.Enabled {
+		r.report.Infof("• PII report generation is disabled (set generation.pii.enabled: true)")
+		return nil
+	}
+
+	stopSpinner := r.report.ShowSpinner("Generating PII report...")
+
+	result, err := r.scanner.ScanAll(ctx)
+	if err != nil {
+		stopSpinner("Error scanning")
+		return exitcode.New(exitcode.Scan, fmt.Errorf("error scanning: %w", err))
+	}
+
+	reportGen := generator.NewPIIReportGenerator(r.config, r.output)
+	count, err := reportGen.GenerateReport(result)
+	if err != nil {
+		stopSpinner("Error generating PII report")
+		return exitcode.New(exitcode.Generation, fmt.Errorf("error generating PII report: %w", err))
+	}
+
+	stopSpinner("PII report generated successfully")
+	r.report.Detailf("%d @PII fields, %d endpoints handling personal data", len(result.PIIFields), count)
+	r.report.Detailf("Generated: %s", r.config.Generation.PII.OutputFile)
+
+	return nil
+}
+
+// GenerateRedirects generates the registrations serving the old paths of migrated routes
+func (r *Runner) GenerateRedirects(ctx context.Context) error {
+	migrationsFile := r.config.Generation.Redirects.MigrationsFile
+	migrations, err := generator.LoadRouteMigrations(r.config.Root, migrationsFile)
+	if err != nil {
+		return exitcode.New(exitcode.Config, err)
+	}
+	if len(migrations.Migrations) == 0 {
+		r.report.Infof("• No path migrations in %s (add one with taskw migrate path /old:/new)", migrationsFile)
+		return nil
+	}
+
+	stopSpinner := r.report.ShowSpinner("Generating path migration redirects...")
+
+	_, routes, err := r.scanner.ScanRoutes(ctx, r.config.Paths.ScanDirs)
+	if err != nil {
+		stopSpinner("Error scanning routes")
+		return exitcode.New(exitcode.Scan, fmt.Errorf("error scanning routes: %w", err))
+	}
+
+	now := time.Now()
+	redirectGen, err := generator.NewRedirectGenerator(r.config, r.output)
+	if err != nil {
+		stopSpinner("Error generating redirects")
+		return exitcode.New(exitcode.Config, err)
+	}
+	expired, err := redirectGen.GenerateRedirects(migrations, routes, now)
+	if err != nil {
+		stopSpinner("Error generating redirects")
+		return exitcode.New(exitcode.Generation, fmt.Errorf("error generating redirects: %w", err))
+	}
+
+	stopSpinner("Path migration redirects generated successfully")
+	for _, migration := range migrations.Migrations {
+		if migration.Expired(now) {
+			continue
+		}
+		r.report.Detailf("%s -> %s (%s, remove after %s)", migration.From, migration.To, migration.Mode, migration.RemoveAfter)
+	}
+	for _, migration := range expired {
+		r.report.Detailf("%s is past its removal date and no longer registered, delete it from %s", migration.From, migrationsFile)
+	}
+	r.report.Detailf("Generated: %s", generator.RedirectsFile(r.config))
+
+	// The generated server registers the redirects along with the routes
+	if r.config.Generation.Server.Enabled {
+		return r.GenerateServer(ctx)
+	}
+	return nil
+}
+
+// GenerateSwagger generates swagger documentation
+func (r *Runner) GenerateSwagger(ctx context.Context) error {
+	if r.output.Previewing() {
+		r.report.Infof("• Skipping swagger documentation, swag writes it itself and it can't be previewed")
+		return nil
+	}
+
+	stopSpinner := r.report.ShowSpinner("Generating Swagger documentation...")
+
+	// Check if swag command is available
+	if !r.tools.IsCommandAvailable("swag") {
+		stopSpinner("Installing swag command...")
+		installSpinner := r.report.ShowSpinner("Installing swag...")
+
+		if err := r.installTool(ctx, r.tools.InstallSwag); err != nil {
+			installSpinner("Failed to install swag")
+			if errors.Is(err, context.Canceled) {
+				return err
+			}
+			r.report.Warnf("Please install manually: %s (taskw doctor lists every missing tool)", tools.Swag.InstallHint())
+			return nil
+		}
+		installSpinner("swag installed successfully")
+	}
+
+	// Generate swagger docs
+	mainFile := r.findMainFile()
+	if mainFile == "" {
+		stopSpinner("Could not find main.go file for swagger generation")
+		return nil
+	}
+
+	docsDir := "docs"
+	if err := r.output.Track(r.config.Root, filepath.Join(docsDir, "docs.go"), filepath.Join(docsDir, "swagger.json"), filepath.Join(docsDir, "swagger.yaml")); err != nil {
+		stopSpinner("Error generating swagger docs")
+		return exitcode.New(exitcode.Generation, err)
+	}
+	output, err := r.runTool(ctx, tools.Swag, "init", "-g", mainFile, "-o", docsDir)
+	if err != nil {
+		stopSpinner("Error generating swagger docs")
+		return r.toolError(tools.Swag, "error generating swagger docs", output, err)
+	}
+
+	// Inject API information, servers, response content types, the response envelope and x-pii
+	result, err := r.scanner.ScanAll(ctx)
+	if err != nil {
+		stopSpinner("Error scanning")
+		return exitcode.New(exitcode.Scan, fmt.Errorf("error scanning: %w", err))
+	}
+
+	specGen := generator.NewSwaggerSpecGenerator(r.config, r.output)
+	written, err := specGen.RewriteSpec(docsDir, result)
+	if err != nil {
+		stopSpinner("Error rewriting swagger docs")
+		return exitcode.New(exitcode.Generation, fmt.Errorf("error applying taskw.yaml to swagger docs: %w", err))
+	}
+
+	stopSpinner(fmt.Sprintf("Swagger documentation generated successfully at %s/", docsDir))
+	if len(written) > 0 && r.config.OpenAPI.IsSet() {
+		r.report.Detailf("Applied openapi info from taskw.yaml (%d servers)", len(r.config.OpenAPI.Servers))
+	}
+	if len(written) > 0 && r.config.Generation.Envelope.Enabled {
+		r.report.Detailf("Wrapped response schemas in the response envelope")
+	}
+	if len(written) > 0 && r.config.Generation.PII.Enabled {
+		r.report.Detailf("Marked operations and fields handling personal data with x-pii")
+	}
+	if version := r.config.SpecVersion(); len(written) > 0 && version != config.SpecVersionSwagger2 {
+		r.report.Detailf("Converted the spec to OpenAPI %s at %s", version, filepath.Join(docsDir, "openapi.json"))
+	}
+	return nil
+}
+
+// findMainFile returns the main.go swag documents the API from, in its common locations
+func (r *Runner) findMainFile() string {
+	candidates := []string{
+		"./cmd/server/main.go",
+		"./cmd/main.go",
+		"./main.go",
+	}
+	for _, candidate := range candidates {
+		if _, err := os.Stat(r.config.ResolvePath(candidate)); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
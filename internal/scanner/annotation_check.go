@@ -0,0 +1,172 @@
+package scanner
+
+import (
+	"fmt"
+	"go/ast"
+	"sort"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/pkg/annotations"
+)
+
+// httpMethods are the methods @Router accepts besides the custom ones of generation.routes.methods
+var httpMethods = []string{"GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS", "TRACE", "CONNECT"}
+
+// checkAnnotations reports the annotations of a function that would otherwise be skipped without a
+// trace, e.g. a @Router with a misspelled method, as warnings suggesting a fix. isHandler tells
+// whether the function was recognized as a handler, hasRoute whether one of its @Router lines was used
+func (s *ASTScanner) checkAnnotations(fn *ast.FuncDecl, isHandler, hasRoute bool, filePath string, result *ScanResult) {
+	for _, annotation := range annotations.All(fn.Doc) {
+		warn := func(message, suggestion string) {
+			position := s.fset.Position(annotation.Pos)
+			result.Errors = append(result.Errors, ScanError{
+				FilePath:   filePath,
+				Line:       position.Line,
+				Column:     position.Column,
+				Message:    message,
+				Type:       "warning",
+				Suggestion: suggestion,
+			})
+		}
+
+		if !annotation.Is("Router") {
+			if name := closestAnnotation(annotation.Name); name != "" {
+				warn(fmt.Sprintf("unknown annotation @%s on %s is ignored", annotation.Name, fn.Name.Name), replaceName(annotation, name))
+			}
+			continue
+		}
+
+		switch {
+		case !isHandler:
+			warn(s.notHandlerMessage(fn), "")
+		case hasRoute:
+			// Only the first valid @Router of a handler is used, the others are swag documentation
+		default:
+			router, ok := annotations.ParseRouter(annotation.Args)
+			if !ok {
+				suggestion := ""
+				if fields := strings.Fields(annotation.Args); len(fields) == 1 && strings.HasPrefix(fields[0], "/") {
+					suggestion = fmt.Sprintf("@%s %s [get]", annotation.Name, fields[0])
+				}
+				warn(fmt.Sprintf("malformed @Router on %s is ignored, expected a path and a method, e.g. @Router /users/{id} [get]", fn.Name.Name), suggestion)
+				continue
+			}
+
+			suggestion := ""
+			if method := closest(router.Method, s.routerMethods()); method != "" {
+				suggestion = fmt.Sprintf("@%s %s [%s]", annotation.Name, router.Path, strings.ToLower(method))
+			}
+			warn(fmt.Sprintf("@Router on %s is ignored, %s is not an HTTP method (register custom methods in generation.routes.methods)", fn.Name.Name, router.Method), suggestion)
+		}
+	}
+}
+
+// notHandlerMessage explains why a function with a @Router annotation isn't a handler
+func (s *ASTScanner) notHandlerMessage(fn *ast.FuncDecl) string {
+	if fn.Recv != nil && len(fn.Recv.List) == 1 {
+		name := s.getReceiverTypeName(fn.Recv.List[0])
+		if _, ok := s.config.Conventions.HandlerSuffix(name); !ok && !s.isHandlerImplementation(name) {
+			return fmt.Sprintf("@Router on %s.%s is ignored, the receiver type doesn't end in a handler suffix (conventions.handler_suffixes)", name, fn.Name.Name)
+		}
+	}
+	return fmt.Sprintf("@Router on %s is ignored, handlers have the signature %s", fn.Name.Name, s.handlerSignature())
+}
+
+// handlerSignature describes the signature isHandlerSignature accepts
+func (s *ASTScanner) handlerSignature() string {
+	switch s.config.RouteFramework() {
+	case config.FrameworkGin:
+		return "func(c *gin.Context)"
+	case config.FrameworkNetHTTP, config.FrameworkChi:
+		return "func(w http.ResponseWriter, r *http.Request)"
+	default:
+		if s.config.FiberVersion() == 3 {
+			return "func(c fiber.Ctx) error"
+		}
+		return "func(c *fiber.Ctx) error"
+	}
+}
+
+// routerMethods lists the methods @Router accepts, custom ones included
+func (s *ASTScanner) routerMethods() []string {
+	methods := append([]string{}, httpMethods...)
+	for method := range s.config.Generation.Routes.Methods {
+		methods = append(methods, strings.ToUpper(method))
+	}
+	sort.Strings(methods[len(httpMethods):])
+	return methods
+}
+
+// closestAnnotation returns the known annotation a name is likely a misspelling of, or an empty
+// string for known names, extensions such as @x-order, and names too far from any known one
+func closestAnnotation(name string) string {
+	lower := strings.ToLower(name)
+	if _, known := canonicalAnnotations[lower]; known || strings.HasPrefix(lower, "x-") || len(name) < 4 {
+		return ""
+	}
+
+	names := make([]string, 0, len(canonicalAnnotations))
+	for _, canonical := range canonicalAnnotations {
+		names = append(names, canonical)
+	}
+	sort.Strings(names)
+	return closest(name, names)
+}
+
+// closest returns the candidate closest to value ignoring case, within one edit for short values and
+// two for longer ones. Returns an empty string when none is close enough
+func closest(value string, candidates []string) string {
+	limit := 1
+	if len(value) > 5 {
+		limit = 2
+	}
+
+	best, bestDistance := "", limit+1
+	for _, candidate := range candidates {
+		if d := editDistance(strings.ToLower(value), strings.ToLower(candidate)); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// replaceName returns an annotation line with another name and the same arguments
+func replaceName(annotation annotations.Annotation, name string) string {
+	if annotation.Args == "" {
+		return "@" + name
+	}
+	return "@" + name + " " + annotation.Args
+}
+
+// strictAnnotations turns the annotation warnings of a scan into errors failing it, for
+// scanning.malformed_annotations: error
+func strictAnnotations(result *ScanResult) {
+	for i := range result.Errors {
+		if result.Errors[i].Warning() {
+			result.Errors[i].Type = "annotation"
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package scanner
+
+import (
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// canonicalAnnotations maps lowercased taskw and swag annotation names to their canonical spelling
+var canonicalAnnotations = map[string]string{}
+
+func init() {
+	for _, name := range []string{
+		// taskw
+		"Router", "RouterPrefix", "Middleware", "Tags", "TagsDefault", "Scrub", "SLO", "Provider",
+		"PII", "ChaosWrap", "RPC",
+		// swag
+		"Summary", "Description", "ID", "Accept", "Produce", "Param", "Success", "Failure",
+		"Response", "Header", "Security", "Deprecated", "Schemes", "x-codeSamples",
+	} {
+		canonicalAnnotations[strings.ToLower(name)] = name
+	}
+}
+
+// listAnnotations hold comma separated values and are normalized to "a, b, c"
+var listAnnotations = map[string]bool{
+	"Middleware": true, "Tags": true, "TagsDefault": true, "Scrub": true, "SLO": true,
+	"Accept": true, "Produce": true, "Schemes": true,
+}
+
+// freeTextAnnotations keep their text untouched apart from surrounding whitespace
+var freeTextAnnotations = map[string]bool{
+	"Summary": true, "Description": true, "Deprecated": true, "x-codeSamples": true,
+}
+
+var (
+	annotationCommentPattern = regexp.MustCompile(`^//\s*@([A-Za-z][\w-]*)(.*)$`)
+	routerArgsPattern        = regexp.MustCompile(`^("[^"]*"|\S+)\s+\[([^\]]+)\]$`)
+)
+
+// FormatAnnotations normalizes the taskw and swag annotations in a Go source file and gofmts it:
+// - "//@router" becomes "// @Router"
+// - @Router methods are lowercased: "[GET]" becomes "[get]"
+// - list annotations are comma separated: "@Middleware auth   audit" becomes "@Middleware auth, audit"
+// - runs of whitespace between annotation arguments are collapsed, except inside quotes
+func FormatAnnotations(src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing source: %w", err)
+	}
+
+	var out strings.Builder
+	last := 0
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			formatted, ok := formatAnnotationComment(comment.Text)
+			if !ok || formatted == comment.Text {
+				continue
+			}
+			start := fset.Position(comment.Pos()).Offset
+			out.Write(src[last:start])
+			out.WriteString(formatted)
+			last = start + len(comment.Text)
+		}
+	}
+	out.Write(src[last:])
+
+	formatted, err := format.Source([]byte(out.String()))
+	if err != nil {
+		return nil, fmt.Errorf("error formatting source: %w", err)
+	}
+	return formatted, nil
+}
+
+// formatAnnotationComment normalizes a single "// @Name args" line comment
+func formatAnnotationComment(text string) (string, bool) {
+	matches := annotationCommentPattern.FindStringSubmatch(text)
+	if matches == nil {
+		return "", false
+	}
+
+	name := matches[1]
+	if canonical, known := canonicalAnnotations[strings.ToLower(name)]; known {
+		name = canonical
+	}
+
+	args := strings.TrimSpace(matches[2])
+	switch {
+	case args == "" || freeTextAnnotations[name]:
+	case listAnnotations[name]:
+		args = strings.Join(strings.FieldsFunc(args, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		}), ", ")
+	case name == "Router":
+		args = collapseWhitespace(args)
+		if route := routerArgsPattern.FindStringSubmatch(args); route != nil {
+			args = route[1] + " [" + strings.ToLower(strings.TrimSpace(route[2])) + "]"
+		}
+	default:
+		args = collapseWhitespace(args)
+	}
+
+	if args == "" {
+		return "// @" + name, true
+	}
+	return "// @" + name + " " + args, true
+}
+
+// collapseWhitespace replaces runs of spaces and tabs with a single space outside double quotes
+func collapseWhitespace(s string) string {
+	var b strings.Builder
+	inQuotes := false
+	pendingSpace := false
+	for _, r := range s {
+		if !inQuotes && (r == ' ' || r == '\t') {
+			pendingSpace = true
+			continue
+		}
+		if pendingSpace {
+			b.WriteByte(' ')
+			pendingSpace = false
+		}
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
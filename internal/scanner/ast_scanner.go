@@ -5,26 +5,38 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
+	"os"
 	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/pkg/annotations"
 )
 
 // ASTScanner uses Go's AST parser for accurate code analysis
 type ASTScanner struct {
-	fset *token.FileSet
+	fset   *token.FileSet
+	config *config.Config
 }
 
 // NewASTScanner creates a new AST-based scanner
-func NewASTScanner() *ASTScanner {
+func NewASTScanner(cfg *config.Config) *ASTScanner {
 	return &ASTScanner{
-		fset: token.NewFileSet(),
+		fset:   token.NewFileSet(),
+		config: cfg,
 	}
 }
 
 // ScanFile parses a Go file and extracts handlers, routes, and providers
 func (s *ASTScanner) ScanFile(filePath string) (*ScanResult, error) {
-	// Parse the Go file into AST
-	node, err := parser.ParseFile(s.fset, filePath, nil, parser.ParseComments)
+	// Parse the Go file into AST, positions keep the path relative to the project root
+	src, err := os.ReadFile(s.config.ResolvePath(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+	node, err := parser.ParseFile(s.fset, filePath, src, parser.ParseComments)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse file %s: %w", filePath, err)
 	}
@@ -35,21 +47,43 @@ func (s *ASTScanner) ScanFile(filePath string) (*ScanResult, error) {
 		Providers:       []ProviderFunction{},
 		Interfaces:      []HandlerInterface{},
 		Implementations: []HandlerImplementation{},
+		HandlerDefaults: []HandlerDefaults{},
 		Errors:          []ScanError{},
 	}
 
+	// Files opting out with a taskw:ignore directive above the package clause contribute nothing
+	if s.isFileIgnored(node) {
+		return result, nil
+	}
+
 	packageName := node.Name.Name
 
 	// Walk the AST to find functions and type declarations
 	ast.Inspect(node, func(n ast.Node) bool {
 		switch x := n.(type) {
 		case *ast.FuncDecl:
-			s.processFuncDecl(x, packageName, filePath, result)
+			s.processFuncDecl(x, packageName, filePath, node.Scope, result)
+		case *ast.GenDecl:
+			s.processHandlerDefaults(x, packageName, filePath, result)
 		case *ast.TypeSpec:
 			s.processTypeSpec(x, packageName, filePath, result)
 		}
 		return true
 	})
+	s.extractModels(node, packageName, filePath, result)
+	s.extractPackageVars(node, packageName, filePath, result)
+
+	// Provider types are qualified by the names the file imports packages under
+	if len(result.Providers) > 0 || len(result.RPCs) > 0 {
+		imports := fileImports(node)
+		for i := range result.Providers {
+			result.Providers[i].Imports = imports
+		}
+		// So are the messages of gRPC methods
+		for i := range result.RPCs {
+			result.RPCs[i].Imports = imports
+		}
+	}
 
 	// After scanning all types and functions, associate interfaces with implementations
 	s.associateInterfacesWithImplementations(result)
@@ -58,16 +92,34 @@ func (s *ASTScanner) ScanFile(filePath string) (*ScanResult, error) {
 }
 
 // processFuncDecl analyzes a function declaration for handlers and providers
-func (s *ASTScanner) processFuncDecl(fn *ast.FuncDecl, pkg, filePath string, result *ScanResult) {
+func (s *ASTScanner) processFuncDecl(fn *ast.FuncDecl, pkg, filePath string, fileScope *ast.Scope, result *ScanResult) {
+	// Functions opting out with a taskw:ignore directive are neither handlers nor providers
+	if s.isIgnored(fn.Doc) {
+		return
+	}
+
 	// Check if this is a handler function
-	if handler := s.extractHandler(fn, pkg, filePath); handler != nil {
+	handler := s.extractHandler(fn, pkg, filePath)
+	hasRoute := false
+	if handler == nil {
+		// gRPC methods served over HTTP carry their route instead of a handler
+		if rpc := s.extractRPC(fn, pkg, filePath, result); rpc != nil {
+			result.RPCs = append(result.RPCs, *rpc)
+			hasRoute = true
+		}
+	} else {
+		handler.SharedWrites = s.sharedWrites(fn, fileScope)
 		result.Handlers = append(result.Handlers, *handler)
 
 		// Look for @Router annotation
 		if route := s.extractRoute(fn, *handler); route != nil {
+			route.SLOs = s.extractSLOs(fn, filePath, result)
+			route.Responses = s.extractResponseContents(fn, filePath, result)
 			result.Routes = append(result.Routes, *route)
+			hasRoute = true
 		}
 	}
+	s.checkAnnotations(fn, handler != nil || hasRoute, hasRoute, filePath, result)
 
 	// Check if this is a provider function
 	if provider := s.extractProvider(fn, pkg, filePath); provider != nil {
@@ -77,8 +129,13 @@ func (s *ASTScanner) processFuncDecl(fn *ast.FuncDecl, pkg, filePath string, res
 
 // extractHandler checks if a function is a Fiber handler and extracts its information
 func (s *ASTScanner) extractHandler(fn *ast.FuncDecl, pkg, filePath string) *HandlerFunction {
-	// Must have a receiver
-	if fn.Recv == nil || len(fn.Recv.List) != 1 {
+	// Package-level functions, e.g. func GetHealth(c *fiber.Ctx) error
+	if fn.Recv == nil {
+		return s.extractFunctionHandler(fn, pkg, filePath)
+	}
+
+	// Methods must have a single receiver
+	if len(fn.Recv.List) != 1 {
 		return nil
 	}
 
@@ -89,27 +146,54 @@ func (s *ASTScanner) extractHandler(fn *ast.FuncDecl, pkg, filePath string) *Han
 		return nil
 	}
 
-	// Accept both traditional pattern (*Handler) and interface pattern (*Impl)
-	if !strings.HasSuffix(handlerName, "Handler") && !s.isHandlerImplementation(handlerName) {
+	// Accept both traditional pattern (*Handler, or a configured suffix) and interface pattern (*Impl)
+	if _, ok := s.config.Conventions.HandlerSuffix(handlerName); !ok && !s.isHandlerImplementation(handlerName) {
 		return nil
 	}
 
-	// Check function parameters: should have (c *fiber.Ctx)
-	if !s.hasFiberCtxParam(fn) {
+	// Check the signature matches the configured framework, e.g. (c *fiber.Ctx) error
+	if !s.isHandlerSignature(fn.Type) {
 		return nil
 	}
 
-	// Check return type: should return error
-	if !s.returnsError(fn) {
-		return nil
+	returnType := ""
+	if s.returnsError(fn.Type) {
+		returnType = "error"
 	}
 
+	position := s.fset.Position(fn.Name.Pos())
 	return &HandlerFunction{
 		FunctionName: fn.Name.Name,
 		Package:      pkg,
 		HandlerName:  handlerName,
-		ReturnType:   "error",
+		ReturnType:   returnType,
+		FilePath:     filePath,
+		Line:         position.Line,
+		Column:       position.Column,
+	}
+}
+
+// extractFunctionHandler checks if a package-level function is a handler
+// Only functions with a @Router annotation qualify, so middleware sharing the handler signature is left alone
+func (s *ASTScanner) extractFunctionHandler(fn *ast.FuncDecl, pkg, filePath string) *HandlerFunction {
+	if !annotations.Has(fn.Doc, "Router") || !s.isHandlerSignature(fn.Type) {
+		return nil
+	}
+
+	returnType := ""
+	if s.returnsError(fn.Type) {
+		returnType = "error"
+	}
+
+	position := s.fset.Position(fn.Name.Pos())
+	return &HandlerFunction{
+		FunctionName: fn.Name.Name,
+		Package:      pkg,
+		ReturnType:   returnType,
 		FilePath:     filePath,
+		Line:         position.Line,
+		Column:       position.Column,
+		IsFunction:   true,
 	}
 }
 
@@ -123,62 +207,190 @@ func (s *ASTScanner) extractRoute(fn *ast.FuncDecl, handler HandlerFunction) *Ro
 		return nil
 	}
 
-	// Enhanced regex patterns for standard Swagger formats
-	routerPatterns := []*regexp.Regexp{
-		// Standard format: @Router /path [method]
-		regexp.MustCompile(`(?i)@Router\s+([^\s\[\]]+)\s+\[([^\]]+)\]`),
-		// Quoted path format: @Router "/path" [method]
-		regexp.MustCompile(`(?i)@Router\s+"([^"]+)"\s+\[([^\]]+)\]`),
-		// Alternative format: @Router /path method
-		regexp.MustCompile(`(?i)@Router\s+([^\s]+)\s+([A-Za-z]+)(?:\s|$)`),
-		// Gin-style format: @router /path [method]
-		regexp.MustCompile(`(?i)@router\s+([^\s\[\]]+)\s+\[([^\]]+)\]`),
+	for _, annotation := range annotations.Find(fn.Doc, "Router") {
+		router, ok := annotations.ParseRouter(annotation.Args)
+		if !ok || !s.isValidHTTPMethod(router.Method) {
+			continue
+		}
+
+		position := s.fset.Position(annotation.Pos)
+		return &RouteMapping{
+			MethodName:  fn.Name.Name,
+			Path:        router.Path,
+			RouterPath:  router.Path,
+			HTTPMethod:  router.Method,
+			HandlerRef:  handlerRef(handler.Package, handler.FunctionName, handler.IsFunction),
+			HandlerName: handler.HandlerName,
+			IsFunction:  handler.IsFunction,
+			Package:     handler.Package,
+			Middlewares: s.extractMiddlewares(fn),
+			Tags:        annotations.List(fn.Doc, "Tags"),
+			Scrub:       annotations.List(fn.Doc, "Scrub"),
+			Produces:    s.extractProduces(fn),
+			PII:         annotations.List(fn.Doc, "PII"),
+			Schemas:     s.extractSchemaTypes(fn, handler.Package),
+			PathParams:  s.extractPathParams(fn),
+			Params:      s.extractParams(fn),
+			Summary:     annotations.Text(fn.Doc, "Summary"),
+			Description: s.extractDescription(fn),
+			OperationID: annotations.Text(fn.Doc, "ID"),
+			Deprecated:  annotations.Has(fn.Doc, "Deprecated"),
+			FilePath:    handler.FilePath,
+			Line:        position.Line,
+			Column:      position.Column,
+		}
 	}
 
-	for _, comment := range fn.Doc.List {
-		text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
-		text = strings.TrimSpace(strings.TrimPrefix(text, "*")) // Support /** comments
+	return nil
+}
 
-		for _, pattern := range routerPatterns {
-			if matches := pattern.FindStringSubmatch(text); matches != nil {
-				path := strings.Trim(matches[1], `"'`) // Remove quotes if present
-				method := strings.ToUpper(strings.TrimSpace(matches[2]))
+// extractDescription joins the text of every @Description annotation, one line each, the way swag
+// concatenates repeated annotations
+func (s *ASTScanner) extractDescription(fn *ast.FuncDecl) string {
+	var lines []string
+	for _, annotation := range annotations.Find(fn.Doc, "Description") {
+		if annotation.Args != "" {
+			lines = append(lines, annotation.Args)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
 
-				// Validate HTTP method
-				if !s.isValidHTTPMethod(method) {
-					continue
-				}
+// extractMiddlewares parses @Middleware comments into a list of middleware names
+// Supports both comma and space separated lists, and multiple annotations:
+// - @Middleware auth, audit
+// - @Middleware ratelimit
+func (s *ASTScanner) extractMiddlewares(fn *ast.FuncDecl) []string {
+	return annotations.List(fn.Doc, "Middleware")
+}
 
-				return &RouteMapping{
-					MethodName: fn.Name.Name,
-					Path:       path,
-					HTTPMethod: method,
-					HandlerRef: s.generateHandlerRef(handler),
-					Package:    handler.Package,
-				}
-			}
+// extractSLOs parses @SLO annotations into latency budgets
+// Supports multiple targets per annotation and repeated annotations:
+// - @SLO p99=200ms
+// - @SLO p95=100ms, p99.9=1s
+// Invalid targets are reported as scan errors
+func (s *ASTScanner) extractSLOs(fn *ast.FuncDecl, filePath string, result *ScanResult) []SLOTarget {
+	var targets []SLOTarget
+	for _, value := range annotations.List(fn.Doc, "SLO") {
+		slo, err := annotations.ParseSLO(value)
+		if err != nil {
+			position := s.fset.Position(fn.Name.Pos())
+			result.Errors = append(result.Errors, ScanError{
+				FilePath: filePath,
+				Line:     position.Line,
+				Column:   position.Column,
+				Message:  fmt.Sprintf("invalid @SLO target %q on %s (expected e.g. p99=200ms)", value, fn.Name.Name),
+				Type:     "annotation",
+			})
+			continue
 		}
+
+		targets = append(targets, SLOTarget(slo))
 	}
 
-	return nil
+	return targets
+}
+
+// ignoreDirectivePattern matches the taskw:ignore directive, with or without the @ prefix
+var ignoreDirectivePattern = regexp.MustCompile(`^//\s*@?taskw:ignore\b`)
+
+// isIgnored checks if a doc comment contains a taskw:ignore directive
+func (s *ASTScanner) isIgnored(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+
+	for _, comment := range doc.List {
+		if ignoreDirectivePattern.MatchString(comment.Text) {
+			return true
+		}
+	}
+	return false
+}
+
+// isFileIgnored checks if a taskw:ignore directive appears before the package clause
+func (s *ASTScanner) isFileIgnored(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() > file.Package {
+			break
+		}
+		if s.isIgnored(group) {
+			return true
+		}
+	}
+	return false
 }
 
-// generateHandlerRef creates a proper handler reference
-func (s *ASTScanner) generateHandlerRef(handler HandlerFunction) string {
-	// Use package name as the base for handler reference
-	// e.g., "user" package becomes "userHandler"
-	handlerName := handler.Package + "Handler"
+// docParagraph returns the first paragraph of a doc comment, leaving out @annotations and directives
+func docParagraph(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+
+	var lines []string
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "@") {
+			continue
+		}
+		if line == "" {
+			if len(lines) > 0 {
+				break
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
 
-	// Convert first letter to lowercase for field reference
-	if len(handlerName) > 0 {
-		handlerName = strings.ToLower(handlerName[:1]) + handlerName[1:]
+// processHandlerDefaults extracts struct-level route defaults from type declarations
+// Supported annotations on handler types:
+// - @RouterPrefix /api/v1/users
+// - @TagsDefault users
+func (s *ASTScanner) processHandlerDefaults(decl *ast.GenDecl, pkg, filePath string, result *ScanResult) {
+	if decl.Tok != token.TYPE {
+		return
 	}
 
-	return fmt.Sprintf("%s.%s", handlerName, handler.FunctionName)
+	for _, spec := range decl.Specs {
+		ts, ok := spec.(*ast.TypeSpec)
+		if !ok {
+			continue
+		}
+
+		// The doc comment of an ungrouped type declaration is attached to the GenDecl
+		doc := ts.Doc
+		if doc == nil && len(decl.Specs) == 1 {
+			doc = decl.Doc
+		}
+
+		prefixes := annotations.List(doc, "RouterPrefix")
+		tags := annotations.List(doc, "TagsDefault")
+		if len(prefixes) == 0 && len(tags) == 0 {
+			continue
+		}
+
+		defaults := HandlerDefaults{
+			StructName: ts.Name.Name,
+			Package:    pkg,
+			Tags:       tags,
+			FilePath:   filePath,
+		}
+		if len(prefixes) > 0 {
+			defaults.RouterPrefix = prefixes[0]
+		}
+		result.HandlerDefaults = append(result.HandlerDefaults, defaults)
+	}
 }
 
-// isValidHTTPMethod checks if the method is a valid HTTP method
+// isValidHTTPMethod checks if the method is a valid HTTP method, or a custom one registered through
+// generation.routes.methods
 func (s *ASTScanner) isValidHTTPMethod(method string) bool {
+	if _, ok := s.config.Generation.Routes.Method(method); ok {
+		return true
+	}
+
 	validMethods := map[string]bool{
 		"GET":     true,
 		"POST":    true,
@@ -195,8 +407,14 @@ func (s *ASTScanner) isValidHTTPMethod(method string) bool {
 
 // extractProvider checks if a function is a Wire provider function
 func (s *ASTScanner) extractProvider(fn *ast.FuncDecl, pkg, filePath string) *ProviderFunction {
-	// Must start with "Provide"
-	if !strings.HasPrefix(fn.Name.Name, "Provide") {
+	// Methods can't be referenced as providers, e.g. Conventions.ProviderPrefix
+	if fn.Recv != nil {
+		return nil
+	}
+
+	// Must start with a provider prefix, "Provide" unless configured otherwise
+	prefix, ok := s.config.Conventions.ProviderPrefix(fn.Name.Name)
+	if !ok {
 		return nil
 	}
 
@@ -218,40 +436,99 @@ func (s *ASTScanner) extractProvider(fn *ast.FuncDecl, pkg, filePath string) *Pr
 		returnType = pkg + "." + returnType
 	}
 
-	// Extract parameters
+	// Extract parameters, one entry per parameter even when grouped (a, b *Service)
 	var parameters []string
 	if fn.Type.Params != nil {
 		for _, param := range fn.Type.Params.List {
 			paramType := s.getTypeString(param.Type)
-			if paramType != "" {
+			if paramType == "" {
+				continue
+			}
+			count := len(param.Names)
+			if count == 0 {
+				count = 1
+			}
+			for i := 0; i < count; i++ {
 				parameters = append(parameters, paramType)
 			}
 		}
 	}
 
+	// Record the full return tuple, e.g. (T, func(), error) for providers with cleanup
+	var results []string
+	for _, result := range fn.Type.Results.List {
+		resultType := s.getTypeString(result.Type)
+		if s.isCleanupFunc(result.Type) {
+			resultType = "func()"
+		}
+		count := len(result.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			results = append(results, resultType)
+		}
+	}
+
+	position := s.fset.Position(fn.Name.Pos())
 	return &ProviderFunction{
 		FunctionName: fn.Name.Name,
+		BaseName:     strings.TrimPrefix(fn.Name.Name, prefix),
 		Package:      pkg,
 		ReturnType:   returnType,
+		Results:      results,
+		ReturnsError: len(results) > 1 && results[len(results)-1] == "error",
+		HasCleanup:   len(results) > 1 && results[1] == "func()",
 		Parameters:   parameters,
 		FilePath:     filePath,
+		Line:         position.Line,
+		Column:       position.Column,
+		ChaosWrap:    annotations.Has(fn.Doc, "ChaosWrap"),
+		Envs:         providerEnvs(fn.Doc),
+		Doc:          docParagraph(fn.Doc),
+	}
+}
+
+// providerEnvs returns the environments of "@Provider env=dev,test" annotations, nil for none
+func providerEnvs(doc *ast.CommentGroup) []string {
+	var envs []string
+	for _, annotation := range annotations.Find(doc, "Provider") {
+		for _, field := range strings.Fields(annotation.Args) {
+			if values, ok := strings.CutPrefix(field, "env="); ok {
+				for _, env := range strings.Split(values, ",") {
+					if env != "" && !slices.Contains(envs, env) {
+						envs = append(envs, env)
+					}
+				}
+			}
+		}
 	}
+	return envs
 }
 
-// hasErrorReturnType checks if a function returns error as the second return type
+// hasErrorReturnType checks if a function returns error as its last return type
 func (s *ASTScanner) hasErrorReturnType(fn *ast.FuncDecl) bool {
-	if fn.Type.Results == nil || len(fn.Type.Results.List) != 2 {
+	if fn.Type.Results == nil || len(fn.Type.Results.List) < 2 {
 		return false
 	}
 
-	// Check if second return type is error
-	secondResult := fn.Type.Results.List[1]
-	if ident, ok := secondResult.Type.(*ast.Ident); ok {
+	// Check if the last return type is error
+	lastResult := fn.Type.Results.List[len(fn.Type.Results.List)-1]
+	if ident, ok := lastResult.Type.(*ast.Ident); ok {
 		return ident.Name == "error"
 	}
 	return false
 }
 
+// isCleanupFunc checks if a type is a Wire cleanup function: func()
+func (s *ASTScanner) isCleanupFunc(expr ast.Expr) bool {
+	fn, ok := expr.(*ast.FuncType)
+	if !ok {
+		return false
+	}
+	return (fn.Params == nil || len(fn.Params.List) == 0) && (fn.Results == nil || len(fn.Results.List) == 0)
+}
+
 // processTypeSpec analyzes type declarations for handler interfaces and implementations
 func (s *ASTScanner) processTypeSpec(ts *ast.TypeSpec, pkg, filePath string, result *ScanResult) {
 	typeName := ts.Name.Name
@@ -269,6 +546,9 @@ func (s *ASTScanner) processTypeSpec(ts *ast.TypeSpec, pkg, filePath string, res
 			})
 		}
 	case *ast.StructType:
+		// Record personal data fields and nested types for the PII report
+		s.extractPIIFields(ts, t, pkg, filePath, result)
+
 		// Check if this could be a handler implementation
 		if s.isHandlerImplementation(typeName) {
 			result.Implementations = append(result.Implementations, HandlerImplementation{
@@ -292,7 +572,7 @@ func (s *ASTScanner) isHandlerInterface(name string, iface *ast.InterfaceType) b
 	// Must have at least one method that looks like a handler
 	for _, method := range iface.Methods.List {
 		if funcType, ok := method.Type.(*ast.FuncType); ok {
-			if s.hasFiberCtxParamInFunc(funcType) && s.returnsErrorInFunc(funcType) {
+			if s.isHandlerSignature(funcType) {
 				return true
 			}
 		}
@@ -321,37 +601,51 @@ func (s *ASTScanner) extractInterfaceMethods(iface *ast.InterfaceType) []string
 	return methods
 }
 
-// hasFiberCtxParamInFunc checks if a function type has fiber.Ctx parameter
-func (s *ASTScanner) hasFiberCtxParamInFunc(fn *ast.FuncType) bool {
-	if fn.Params == nil || len(fn.Params.List) != 1 {
-		return false
-	}
-
-	param := fn.Params.List[0]
-	switch t := param.Type.(type) {
-	case *ast.StarExpr:
-		if sel, ok := t.X.(*ast.SelectorExpr); ok {
-			if ident, ok := sel.X.(*ast.Ident); ok {
-				return (ident.Name == "fiber" && sel.Sel.Name == "Ctx") ||
-					(ident.Name == "gin" && sel.Sel.Name == "Context")
-			}
+// isHandlerSignature checks if a function type matches the handler signature of the configured framework
+// - fiber:   func(c *fiber.Ctx) error (v2) or func(c fiber.Ctx) error (v3)
+// - gin:     func(c *gin.Context)
+// - nethttp: func(w http.ResponseWriter, r *http.Request)
+// - chi:     func(w http.ResponseWriter, r *http.Request)
+func (s *ASTScanner) isHandlerSignature(fn *ast.FuncType) bool {
+	switch s.config.RouteFramework() {
+	case config.FrameworkGin:
+		return s.hasCtxParam(fn, "*gin.Context") && !s.hasResults(fn)
+	case config.FrameworkNetHTTP, config.FrameworkChi:
+		return s.hasHTTPHandlerParams(fn) && !s.hasResults(fn)
+	default:
+		// Fiber v3 passes the context as an interface value instead of a pointer
+		ctxType := "*fiber.Ctx"
+		if s.config.FiberVersion() == 3 {
+			ctxType = "fiber.Ctx"
 		}
+		return s.hasCtxParam(fn, ctxType) && s.returnsError(fn)
 	}
-	return false
 }
 
-// returnsErrorInFunc checks if a function type returns error
-func (s *ASTScanner) returnsErrorInFunc(fn *ast.FuncType) bool {
-	if fn.Results == nil || len(fn.Results.List) == 0 {
+// hasHTTPHandlerParams checks if a function type has (http.ResponseWriter, *http.Request) parameters
+func (s *ASTScanner) hasHTTPHandlerParams(fn *ast.FuncType) bool {
+	if fn.Params == nil {
 		return false
 	}
 
-	// Check last return type is error
-	lastResult := fn.Results.List[len(fn.Results.List)-1]
-	if ident, ok := lastResult.Type.(*ast.Ident); ok {
-		return ident.Name == "error"
+	// Flatten grouped parameters, e.g. (w http.ResponseWriter, r *http.Request)
+	paramTypes := s.getFieldTypes(fn.Params)
+
+	return len(paramTypes) == 2 && paramTypes[0] == "http.ResponseWriter" && paramTypes[1] == "*http.Request"
+}
+
+// hasResults checks if a function type declares any return values
+func (s *ASTScanner) hasResults(fn *ast.FuncType) bool {
+	return fn.Results != nil && len(fn.Results.List) > 0
+}
+
+// hasCtxParam checks if a function type has a single context parameter of the given type, e.g. "*fiber.Ctx"
+func (s *ASTScanner) hasCtxParam(fn *ast.FuncType, ctxType string) bool {
+	if fn.Params == nil || len(fn.Params.List) != 1 || len(fn.Params.List[0].Names) > 1 {
+		return false
 	}
-	return false
+
+	return s.getTypeString(fn.Params.List[0].Type) == ctxType
 }
 
 // associateInterfacesWithImplementations links interfaces with their implementations
@@ -390,6 +684,8 @@ func (s *ASTScanner) createInterfaceBasedHandlers(result *ScanResult) {
 					ImplementerName:  impl.StructName,    // Store implementer name
 					ReturnType:       handler.ReturnType,
 					FilePath:         handler.FilePath,
+					Line:             handler.Line,
+					Column:           handler.Column,
 					IsInterfaceBased: true,
 				}
 				newHandlers = append(newHandlers, newHandler)
@@ -415,41 +711,59 @@ func (s *ASTScanner) getReceiverTypeName(recv *ast.Field) string {
 	return ""
 }
 
-func (s *ASTScanner) hasFiberCtxParam(fn *ast.FuncDecl) bool {
-	if fn.Type.Params == nil || len(fn.Type.Params.List) != 1 {
+func (s *ASTScanner) returnsError(fn *ast.FuncType) bool {
+	if fn.Results == nil || len(fn.Results.List) == 0 {
 		return false
 	}
 
-	param := fn.Type.Params.List[0]
-
-	// Check for *fiber.Ctx or *gin.Context patterns
-	switch t := param.Type.(type) {
-	case *ast.StarExpr:
-		if sel, ok := t.X.(*ast.SelectorExpr); ok {
-			if ident, ok := sel.X.(*ast.Ident); ok {
-				return (ident.Name == "fiber" && sel.Sel.Name == "Ctx") ||
-					(ident.Name == "gin" && sel.Sel.Name == "Context")
-			}
-		}
+	// Check last return type is error
+	lastResult := fn.Results.List[len(fn.Results.List)-1]
+	if ident, ok := lastResult.Type.(*ast.Ident); ok {
+		return ident.Name == "error"
 	}
 
 	return false
 }
 
-func (s *ASTScanner) returnsError(fn *ast.FuncDecl) bool {
-	if fn.Type.Results == nil || len(fn.Type.Results.List) == 0 {
-		return false
+// getSignatureString renders the parameters and results of a func type without their names,
+// e.g. "(context.Context, ...Option) (*Client, error)"
+func (s *ASTScanner) getSignatureString(fn *ast.FuncType) string {
+	signature := "(" + strings.Join(s.getFieldTypes(fn.Params), ", ") + ")"
+
+	results := s.getFieldTypes(fn.Results)
+	switch len(results) {
+	case 0:
+		return signature
+	case 1:
+		return signature + " " + results[0]
+	default:
+		return signature + " (" + strings.Join(results, ", ") + ")"
 	}
+}
 
-	// Check last return type is error
-	lastResult := fn.Type.Results.List[len(fn.Type.Results.List)-1]
-	if ident, ok := lastResult.Type.(*ast.Ident); ok {
-		return ident.Name == "error"
+// getFieldTypes returns one type per parameter or result of a field list, even when grouped (a, b string)
+func (s *ASTScanner) getFieldTypes(fields *ast.FieldList) []string {
+	if fields == nil {
+		return nil
 	}
-
-	return false
+	var fieldTypes []string
+	for _, field := range fields.List {
+		fieldType := s.getTypeString(field.Type)
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			fieldTypes = append(fieldTypes, fieldType)
+		}
+	}
+	return fieldTypes
 }
 
+// getTypeString renders a type expression as written in the source, e.g. "map[string][]*User",
+// "func(context.Context) (*User, error)", "<-chan Event", "...Option" or "Cache[string, *User]".
+// Parameter and result names are dropped, they don't change the identity of a func type.
+// Returns "" for expressions that aren't types
 func (s *ASTScanner) getTypeString(expr ast.Expr) string {
 	switch t := expr.(type) {
 	case *ast.Ident:
@@ -458,10 +772,81 @@ func (s *ASTScanner) getTypeString(expr ast.Expr) string {
 		return "*" + s.getTypeString(t.X)
 	case *ast.SelectorExpr:
 		return s.getTypeString(t.X) + "." + t.Sel.Name
+	case *ast.ParenExpr:
+		return "(" + s.getTypeString(t.X) + ")"
 	case *ast.ArrayType:
-		return "[]" + s.getTypeString(t.Elt)
+		if t.Len == nil {
+			return "[]" + s.getTypeString(t.Elt)
+		}
+		// Fixed size arrays keep their length expression, e.g. [16]byte or [...]string
+		return "[" + types.ExprString(t.Len) + "]" + s.getTypeString(t.Elt)
 	case *ast.MapType:
 		return fmt.Sprintf("map[%s]%s", s.getTypeString(t.Key), s.getTypeString(t.Value))
+	case *ast.Ellipsis:
+		return "..." + s.getTypeString(t.Elt)
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.SEND:
+			return "chan<- " + s.getTypeString(t.Value)
+		case ast.RECV:
+			return "<-chan " + s.getTypeString(t.Value)
+		}
+		// chan (<-chan T) needs parentheses to not be read as chan<- (chan T)
+		if value, ok := t.Value.(*ast.ChanType); ok && value.Dir == ast.RECV {
+			return "chan (" + s.getTypeString(t.Value) + ")"
+		}
+		return "chan " + s.getTypeString(t.Value)
+	case *ast.FuncType:
+		return "func" + s.getSignatureString(t)
+	case *ast.IndexExpr:
+		return s.getTypeString(t.X) + "[" + s.getTypeString(t.Index) + "]"
+	case *ast.IndexListExpr:
+		args := make([]string, len(t.Indices))
+		for i, index := range t.Indices {
+			args[i] = s.getTypeString(index)
+		}
+		return s.getTypeString(t.X) + "[" + strings.Join(args, ", ") + "]"
+	case *ast.InterfaceType:
+		// Methods and embedded types, e.g. interface{Close() error; io.Reader}
+		var elems []string
+		for _, field := range t.Methods.List {
+			if fn, ok := field.Type.(*ast.FuncType); ok && len(field.Names) > 0 {
+				elems = append(elems, field.Names[0].Name+s.getSignatureString(fn))
+				continue
+			}
+			elems = append(elems, s.getTypeString(field.Type))
+		}
+		if len(elems) == 0 {
+			return "interface{}"
+		}
+		return "interface{" + strings.Join(elems, "; ") + "}"
+	case *ast.StructType:
+		// Field names and tags are part of a struct type's identity, e.g. struct{ID string `json:"id"`}
+		var fields []string
+		for _, field := range t.Fields.List {
+			var names []string
+			for _, name := range field.Names {
+				names = append(names, name.Name)
+			}
+			decl := s.getTypeString(field.Type)
+			if len(names) > 0 {
+				decl = strings.Join(names, ", ") + " " + decl
+			}
+			if field.Tag != nil {
+				decl += " " + field.Tag.Value
+			}
+			fields = append(fields, decl)
+		}
+		if len(fields) == 0 {
+			return "struct{}"
+		}
+		return "struct{" + strings.Join(fields, "; ") + "}"
+	case *ast.UnaryExpr:
+		// Type set terms of constraint interfaces, e.g. ~string
+		return t.Op.String() + s.getTypeString(t.X)
+	case *ast.BinaryExpr:
+		// Type set unions, e.g. ~int | ~int64
+		return s.getTypeString(t.X) + " " + t.Op.String() + " " + s.getTypeString(t.Y)
 	default:
 		return ""
 	}
@@ -0,0 +1,111 @@
+package scanner
+
+import (
+	"fmt"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
+)
+
+// buildContext returns the build files are scanned for: the platform set by scanning.goos and
+// scanning.goarch, defaulting to $GOOS/$GOARCH or the host, and the tags in scanning.build_tags
+func buildContext(cfg *config.Config) build.Context {
+	ctx := build.Default
+	if cfg.Scanning.GOOS != "" {
+		ctx.GOOS = cfg.Scanning.GOOS
+	}
+	if cfg.Scanning.GOARCH != "" {
+		ctx.GOARCH = cfg.Scanning.GOARCH
+	}
+
+	// Like go build, cgo is off when cross-compiling unless CGO_ENABLED says otherwise
+	if os.Getenv("CGO_ENABLED") == "" && (ctx.GOOS != build.Default.GOOS || ctx.GOARCH != build.Default.GOARCH) {
+		ctx.CgoEnabled = false
+	}
+
+	ctx.BuildTags = append([]string{}, cfg.Scanning.BuildTags...)
+	return ctx
+}
+
+// matchBuildConstraints drops the files that are not part of the build, either through a
+// //go:build line, e.g. wire.go with "wireinject", or a _GOOS/_GOARCH file name suffix.
+// Files importing "C" are dropped as well when cgo is disabled, and reported as skipped.
+// files are relative to the project root
+func matchBuildConstraints(ctx build.Context, root string, files []string) ([]string, []ScanError) {
+	ctx.OpenFile = func(path string) (io.ReadCloser, error) {
+		return os.Open(config.JoinRoot(root, path))
+	}
+
+	var matched []string
+	var errors []ScanError
+	for _, file := range files {
+		ok, err := ctx.MatchFile(filepath.Dir(file), filepath.Base(file))
+		if err != nil {
+			errors = append(errors, ScanError{
+				FilePath: file,
+				Message:  "error reading build constraints: " + err.Error(),
+				Type:     "parse_error",
+			})
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		// Unlike go build, MatchFile keeps cgo files when cgo is disabled
+		if !ctx.CgoEnabled && importsC(config.JoinRoot(root, file)) {
+			errors = append(errors, ScanError{
+				FilePath: file,
+				Message:  fmt.Sprintf("cgo file skipped, cgo is disabled for %s/%s (set CGO_ENABLED=1 to scan it)", ctx.GOOS, ctx.GOARCH),
+				Type:     "skipped",
+			})
+			continue
+		}
+		matched = append(matched, file)
+	}
+	return matched, errors
+}
+
+// importsC checks if a Go file uses cgo, files that can't be read are left to the AST scanner to report
+func importsC(file string) bool {
+	node, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ImportsOnly)
+	if err != nil {
+		return false
+	}
+	for _, spec := range node.Imports {
+		if spec.Path.Value == `"C"` {
+			return true
+		}
+	}
+	return false
+}
+
+// buildFlags returns the go command flags selecting scanning.build_tags
+func buildFlags(cfg *config.Config) []string {
+	if len(cfg.Scanning.BuildTags) == 0 {
+		return nil
+	}
+	return []string{"-tags=" + strings.Join(cfg.Scanning.BuildTags, ",")}
+}
+
+// buildEnv returns the go command environment targeting scanning.goos and scanning.goarch,
+// nil keeps the current environment
+func buildEnv(cfg *config.Config) []string {
+	if cfg.Scanning.GOOS == "" && cfg.Scanning.GOARCH == "" {
+		return nil
+	}
+	env := os.Environ()
+	if cfg.Scanning.GOOS != "" {
+		env = append(env, "GOOS="+cfg.Scanning.GOOS)
+	}
+	if cfg.Scanning.GOARCH != "" {
+		env = append(env, "GOARCH="+cfg.Scanning.GOARCH)
+	}
+	return env
+}
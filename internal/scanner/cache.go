@@ -0,0 +1,206 @@
+package scanner
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/nkaewam/taskw/internal/config"
+)
+
+// CacheFile is where the scan results of each file are kept between runs, relative to the project root
+const CacheFile = ".taskw/cache.json"
+
+// cacheFormat changes whenever cached results change shape, invalidating older caches
+const cacheFormat = 10
+
+// scanCache holds the scan results of files by path, valid as long as the file content hash matches
+// Results are kept encoded so every lookup returns a copy later scanning steps are free to modify
+type scanCache struct {
+	Format      int                   `json:"format"`
+	Fingerprint string                `json:"fingerprint"` // Scanner version and settings the results depend on
+	Files       map[string]cacheEntry `json:"files"`
+
+	root  string // Project root the cached paths are relative to
+	mu    sync.Mutex
+	dirty bool
+}
+
+// cacheEntry is the scan result of a file with a given content
+type cacheEntry struct {
+	Hash   string          `json:"hash"` // SHA-256 of the file content
+	Blob   string          `json:"blob"` // Git object ID of the file content, matched against a revision by --since
+	Result json.RawMessage `json:"result"`
+}
+
+// loadScanCache reads the cache file, starting empty when it is missing, unreadable,
+// or was written by another taskw build or with other scanning settings
+func loadScanCache(cfg *config.Config) *scanCache {
+	fingerprint := cacheFingerprint(cfg)
+	cache := &scanCache{Format: cacheFormat, Fingerprint: fingerprint, Files: make(map[string]cacheEntry), root: cfg.Root}
+
+	content, err := os.ReadFile(cfg.ResolvePath(CacheFile))
+	if err != nil {
+		return cache
+	}
+	var stored scanCache
+	if err := json.Unmarshal(content, &stored); err != nil || stored.Format != cacheFormat || stored.Fingerprint != fingerprint {
+		cache.dirty = true
+		return cache
+	}
+	if stored.Files != nil {
+		cache.Files = stored.Files
+	}
+	return cache
+}
+
+// cacheFingerprint identifies the taskw build and the settings file scan results depend on
+func cacheFingerprint(cfg *config.Config) string {
+	key := struct {
+		Build        string
+		Framework    string
+		FiberVersion int
+		Conventions  config.Conventions
+		Methods      []string
+	}{
+		Framework:    cfg.RouteFramework(),
+		FiberVersion: cfg.FiberVersion(),
+		Conventions:  cfg.Conventions,
+	}
+	// Custom methods are valid in @Router annotations
+	for method := range cfg.Generation.Routes.Methods {
+		key.Methods = append(key.Methods, strings.ToUpper(method))
+	}
+	sort.Strings(key.Methods)
+
+	// Development builds share a version, the binary's modification time tells them apart
+	if info, ok := debug.ReadBuildInfo(); ok {
+		key.Build = info.Main.Version
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" || setting.Key == "vcs.modified" {
+				key.Build += " " + setting.Value
+			}
+		}
+	}
+	if executable, err := os.Executable(); err == nil {
+		if stat, err := os.Stat(executable); err == nil {
+			key.Build += " " + stat.ModTime().UTC().String()
+		}
+	}
+
+	encoded, _ := json.Marshal(key)
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
+
+// lookup returns a copy of the cached result of a file if its content is unchanged
+func (c *scanCache) lookup(filePath, hash string) (*ScanResult, bool) {
+	c.mu.Lock()
+	entry, ok := c.Files[filePath]
+	c.mu.Unlock()
+	if !ok || entry.Hash != hash {
+		return nil, false
+	}
+	return entry.decode()
+}
+
+// lookupBlob returns a copy of the cached result of a file without reading it, if it was scanned from
+// the content of a git object. For files git reports unchanged since a revision, given their object ID there
+func (c *scanCache) lookupBlob(filePath, blob string) (*ScanResult, bool) {
+	c.mu.Lock()
+	entry, ok := c.Files[filePath]
+	c.mu.Unlock()
+	if !ok || entry.Blob == "" || entry.Blob != blob {
+		return nil, false
+	}
+	return entry.decode()
+}
+
+// decode returns a copy of the cached result
+func (e cacheEntry) decode() (*ScanResult, bool) {
+	var result ScanResult
+	if err := json.Unmarshal(e.Result, &result); err != nil {
+		return nil, false
+	}
+	return &result, true
+}
+
+// store records the scan result of a file
+// Only files inside the project are cached, scans of temporary checkouts would never be reused
+func (c *scanCache) store(filePath string, hash fileHash, result *ScanResult) {
+	if !filepath.IsLocal(filePath) {
+		return
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Files[filePath] = cacheEntry{Hash: hash.sha256, Blob: hash.blob, Result: encoded}
+	c.dirty = true
+}
+
+// save writes the cache file if results changed, dropping the entries of deleted files
+// The cache only speeds up scanning, so failing to write it is not an error
+func (c *scanCache) save() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for filePath := range c.Files {
+		if _, err := os.Stat(filepath.Join(c.root, filePath)); err != nil {
+			delete(c.Files, filePath)
+			c.dirty = true
+		}
+	}
+	if !c.dirty {
+		return
+	}
+
+	content, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+	cacheFile := filepath.Join(c.root, CacheFile)
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
+		return
+	}
+	// Write to a temporary file first so concurrent runs never read a partial cache
+	tmpFile := cacheFile + ".tmp"
+	if err := os.WriteFile(tmpFile, content, 0644); err != nil {
+		return
+	}
+	if err := os.Rename(tmpFile, cacheFile); err != nil {
+		os.Remove(tmpFile)
+		return
+	}
+	c.dirty = false
+}
+
+// fileHash identifies the content of a file
+type fileHash struct {
+	sha256 string
+	blob   string // Git object ID, the SHA-1 of the content behind a "blob <size>" header
+}
+
+// hashFile returns the hashes of a file's content
+func hashFile(filePath string) (fileHash, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fileHash{}, err
+	}
+	sum := sha256.Sum256(content)
+	blob := sha1.New()
+	fmt.Fprintf(blob, "blob %d\x00", len(content))
+	blob.Write(content)
+	return fileHash{sha256: hex.EncodeToString(sum[:]), blob: hex.EncodeToString(blob.Sum(nil))}, nil
+}
@@ -0,0 +1,127 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
+)
+
+// codeOwnersLocations are the locations GitHub searches for a CODEOWNERS file, in order
+var codeOwnersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// CodeOwners maps repository paths to their owners using CODEOWNERS rules
+type CodeOwners struct {
+	Path  string // Path of the loaded CODEOWNERS file
+	rules []codeOwnersRule
+}
+
+// codeOwnersRule is a single CODEOWNERS line
+type codeOwnersRule struct {
+	pattern string
+	matcher *regexp.Regexp
+	owners  []string
+}
+
+// findCodeOwnersFile returns the first CODEOWNERS file found in the project root, or "" if none exists
+func findCodeOwnersFile(root string) string {
+	for _, location := range codeOwnersLocations {
+		if info, err := os.Stat(config.JoinRoot(root, location)); err == nil && !info.IsDir() {
+			return location
+		}
+	}
+	return ""
+}
+
+// LoadCodeOwners parses a CODEOWNERS file at a path relative to the project root, searching the
+// standard locations when path is empty
+func LoadCodeOwners(root, path string) (*CodeOwners, error) {
+	if path == "" {
+		path = findCodeOwnersFile(root)
+		if path == "" {
+			return nil, fmt.Errorf("no CODEOWNERS file found (looked in %s)", strings.Join(codeOwnersLocations, ", "))
+		}
+	}
+
+	file, err := os.Open(config.JoinRoot(root, path))
+	if err != nil {
+		return nil, fmt.Errorf("error opening CODEOWNERS file: %w", err)
+	}
+	defer file.Close()
+
+	owners := &CodeOwners{Path: path}
+	lines := bufio.NewScanner(file)
+	for lines.Scan() {
+		line := strings.TrimSpace(lines.Text())
+
+		// Skip empty lines and comments
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		owners.rules = append(owners.rules, codeOwnersRule{
+			pattern: fields[0],
+			matcher: compileCodeOwnersPattern(fields[0]),
+			owners:  fields[1:],
+		})
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("error reading CODEOWNERS file: %w", err)
+	}
+
+	return owners, nil
+}
+
+// Owners returns the owners of a file path relative to the project root
+// The last matching rule wins; a matching rule without owners explicitly unowns the path
+func (c *CodeOwners) Owners(filePath string) []string {
+	path := strings.TrimPrefix(filepath.ToSlash(filepath.Clean(filePath)), "./")
+
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].matcher.MatchString(path) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// compileCodeOwnersPattern converts a gitignore-style CODEOWNERS pattern into a regular expression
+// - Patterns containing a slash (other than a trailing one) are anchored to the root
+// - Other patterns match at any depth
+// - A pattern matching a directory also matches everything beneath it
+func compileCodeOwnersPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasPrefix(pattern, "/") || strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	glob := strings.Trim(pattern, "/")
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("^(?:.*/)?")
+	}
+
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			expr.WriteString("[^/]*")
+		case glob[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(glob[i])))
+		}
+	}
+
+	expr.WriteString("(?:/.*)?$")
+	return regexp.MustCompile(expr.String())
+}
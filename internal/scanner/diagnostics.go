@@ -0,0 +1,89 @@
+package scanner
+
+import "sync"
+
+// Diagnostics collects the errors of the scans and the findings of the validations run while it is
+// active, so a command can leave them behind for bug reports. Scans and validations repeated within a
+// command are kept once
+type Diagnostics struct {
+	mu         sync.Mutex
+	errors     []ScanError
+	validation ValidationResult
+	seen       map[string]bool
+}
+
+var (
+	diagnosticsMu sync.Mutex
+	diagnostics   *Diagnostics // Diagnostics scan errors and findings are kept in, nil when none is running
+)
+
+// BeginDiagnostics starts collecting scan errors and validation findings, until End
+func BeginDiagnostics() *Diagnostics {
+	diagnosticsMu.Lock()
+	defer diagnosticsMu.Unlock()
+	diagnostics = &Diagnostics{seen: make(map[string]bool)}
+	return diagnostics
+}
+
+// End stops collecting and returns the scan errors and validation findings, in the order they were
+// first found
+func (d *Diagnostics) End() ([]ScanError, ValidationResult) {
+	diagnosticsMu.Lock()
+	if diagnostics == d {
+		diagnostics = nil
+	}
+	diagnosticsMu.Unlock()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.errors, d.validation
+}
+
+// runningDiagnostics returns the running diagnostics, nil when none are collected
+func runningDiagnostics() *Diagnostics {
+	diagnosticsMu.Lock()
+	defer diagnosticsMu.Unlock()
+	return diagnostics
+}
+
+// recordScanErrors keeps the errors of a scan in the running diagnostics
+func recordScanErrors(errs []ScanError) {
+	d := runningDiagnostics()
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, e := range errs {
+		key := "scan " + e.Type + " " + e.Position() + " " + e.Message
+		if !d.seen[key] {
+			d.seen[key] = true
+			d.errors = append(d.errors, e)
+		}
+	}
+}
+
+// RecordValidation keeps the findings of a validation in the running diagnostics, if any. Validations
+// are assembled from several checks, so callers record them once complete
+func RecordValidation(validation *ValidationResult) {
+	d := runningDiagnostics()
+	if d == nil || validation == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, e := range validation.Errors {
+		if key := "error " + e.String(); !d.seen[key] {
+			d.seen[key] = true
+			d.validation.Errors = append(d.validation.Errors, e)
+		}
+	}
+	for _, w := range validation.Warnings {
+		if key := "warning " + w.String(); !d.seen[key] {
+			d.seen[key] = true
+			d.validation.Warnings = append(d.validation.Warnings, w)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/pkg/annotations"
+)
+
+// Rule is a detection rule evaluated on a function by taskw explain
+type Rule struct {
+	Name   string // e.g. "receiver suffix"
+	Passed bool
+	Detail string // What the rule found, e.g. "receiver *UserController has none of the handler suffixes [Handler]"
+}
+
+// Explanation lists the detection rules evaluated on a function and what the scanner detected it as
+type Explanation struct {
+	Function string // e.g. "Handler.GetUser" or "ProvideHandler"
+	FilePath string
+	Line     int
+	Rules    []Rule
+	Handler  bool
+	Route    *RouteMapping // nil when the function isn't routed
+	Provider bool
+}
+
+// Explain reports why the functions named name in filePath are or aren't detected as handlers,
+// routes and providers. name is a function name, e.g. "GetUser", or a method qualified by its
+// receiver, e.g. "Handler.GetUser". The rules skipping the whole file come first
+func (s *Scanner) Explain(ctx context.Context, filePath, name string) ([]Explanation, error) {
+	fileRules, err := s.fileRules(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := os.ReadFile(s.config.ResolvePath(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+	file, err := parser.ParseFile(s.astScanner.fset, filePath, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file %s: %w", filePath, err)
+	}
+	directive := Rule{Name: "file directive", Passed: true, Detail: "no taskw:ignore directive above the package clause"}
+	if s.astScanner.isFileIgnored(file) {
+		directive = Rule{Name: "file directive", Detail: "a taskw:ignore directive above the package clause skips the file"}
+	}
+	fileRules = append(fileRules, directive)
+	scanned := true
+	for _, rule := range fileRules {
+		scanned = scanned && rule.Passed
+	}
+
+	receiver, function, qualified := strings.Cut(name, ".")
+	if !qualified {
+		receiver, function = "", name
+	}
+
+	var explanations []Explanation
+	var declared []string
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		receiverName := ""
+		if fn.Recv != nil && len(fn.Recv.List) == 1 {
+			receiverName = s.astScanner.getReceiverTypeName(fn.Recv.List[0])
+		}
+		if fn.Name.Name != function || (qualified && receiverName != receiver) {
+			declared = append(declared, funcDisplayName(fn.Name.Name, receiverName))
+			continue
+		}
+
+		explanation := s.astScanner.explainFunc(fn, file.Name.Name, filePath, receiverName)
+		explanation.Rules = append(append([]Rule{}, fileRules...), explanation.Rules...)
+		if !scanned {
+			explanation.Handler, explanation.Route, explanation.Provider = false, nil, false
+		}
+		explanations = append(explanations, explanation)
+	}
+
+	if len(explanations) == 0 {
+		return nil, fmt.Errorf("no function %s in %s, it declares %s", name, filePath, strings.Join(declared, ", "))
+	}
+	return explanations, nil
+}
+
+// fileRules checks the file is one of the candidate files of a scanned directory and is part of
+// the target build
+func (s *Scanner) fileRules(ctx context.Context, filePath string) ([]Rule, error) {
+	absPath, err := filepath.Abs(s.config.ResolvePath(filePath))
+	if err != nil {
+		return nil, err
+	}
+
+	scanDir := Rule{Name: "scan directory", Detail: fmt.Sprintf("not under any of paths.scan_dirs %v", s.config.Paths.ScanDirs)}
+	candidate := Rule{Name: "file filters", Detail: "skipped by .taskwignore, scanning.ignore, scanning.include, .gitignore or as a test file"}
+	for _, dir := range s.config.Paths.ScanDirs {
+		absDir, err := filepath.Abs(s.config.ResolvePath(dir))
+		if err != nil {
+			return nil, err
+		}
+		if rel, err := filepath.Rel(absDir, absPath); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		scanDir = Rule{Name: "scan directory", Passed: true, Detail: fmt.Sprintf("under paths.scan_dirs entry %q", dir)}
+
+		files, _, err := s.fileFilter.FindCandidateFiles(ctx, dir)
+		if err != nil {
+			return nil, fmt.Errorf("error finding candidate files in %s: %w", dir, err)
+		}
+		for _, file := range files {
+			if absFile, err := filepath.Abs(s.config.ResolvePath(file)); err == nil && absFile == absPath {
+				candidate = Rule{Name: "file filters", Passed: true, Detail: "a candidate file of " + dir}
+				break
+			}
+		}
+		if candidate.Passed {
+			break
+		}
+	}
+	if !scanDir.Passed {
+		candidate.Detail = "not scanned"
+	}
+	rules := []Rule{scanDir, candidate}
+
+	constraints := Rule{Name: "build constraints", Passed: true, Detail: "part of the target build"}
+	if kept, errs := matchBuildConstraints(buildContext(s.config), s.config.Root, []string{filePath}); len(kept) == 0 {
+		constraints = Rule{Name: "build constraints", Detail: "excluded from the target build by its build constraints or file name, see scanning.build_tags, goos and goarch"}
+		if len(errs) > 0 {
+			constraints.Detail = errs[0].Message
+		}
+	}
+	return append(rules, constraints), nil
+}
+
+// explainFunc evaluates the rules of processFuncDecl on a function, in the order it applies them,
+// and records what the scanner actually detects it as
+func (s *ASTScanner) explainFunc(fn *ast.FuncDecl, pkg, filePath, receiverName string) Explanation {
+	position := s.fset.Position(fn.Name.Pos())
+	explanation := Explanation{
+		Function: funcDisplayName(fn.Name.Name, receiverName),
+		FilePath: filePath,
+		Line:     position.Line,
+	}
+	add := func(name string, passed bool, detail string, args ...interface{}) bool {
+		explanation.Rules = append(explanation.Rules, Rule{Name: name, Passed: passed, Detail: fmt.Sprintf(detail, args...)})
+		return passed
+	}
+
+	if s.isIgnored(fn.Doc) {
+		add("function directive", false, "a taskw:ignore directive in the doc comment skips the function")
+		return explanation
+	}
+	add("function directive", true, "no taskw:ignore directive")
+
+	// Handler rules
+	if fn.Recv == nil {
+		if add("function handler", annotations.Has(fn.Doc, "Router"), "package-level functions are only handlers with a @Router annotation") {
+			s.explainSignature(fn.Type, add)
+		}
+	} else if add("single receiver", len(fn.Recv.List) == 1 && receiverName != "", "methods need a single named receiver type") {
+		suffixes := s.config.Conventions.HandlerSuffixes
+		if len(suffixes) == 0 {
+			suffixes = config.DefaultHandlerSuffixes
+		}
+		if suffix, ok := s.config.Conventions.HandlerSuffix(receiverName); ok {
+			add("receiver suffix", true, "receiver %s ends with handler suffix %q", receiverName, suffix)
+		} else if s.isHandlerImplementation(receiverName) {
+			add("receiver suffix", true, "receiver %s is named like a handler interface implementation", receiverName)
+		} else {
+			add("receiver suffix", false, "receiver %s has none of the handler suffixes %v (conventions.handler_suffixes) and isn't named like an implementation (*Impl)", receiverName, suffixes)
+		}
+		s.explainSignature(fn.Type, add)
+	}
+
+	if handler := s.extractHandler(fn, pkg, filePath); handler != nil {
+		explanation.Handler = true
+		explanation.Route = s.extractRoute(fn, *handler)
+		s.explainRouter(fn, add)
+	}
+
+	// Provider rules
+	if fn.Recv != nil {
+		if prefix, ok := s.config.Conventions.ProviderPrefix(fn.Name.Name); ok {
+			add("provider prefix", false, "%s starts with provider prefix %q, but methods can't be providers", fn.Name.Name, prefix)
+		}
+	} else if prefix, ok := s.config.Conventions.ProviderPrefix(fn.Name.Name); !ok {
+		prefixes := s.config.Conventions.ProviderPrefixes
+		if len(prefixes) == 0 {
+			prefixes = config.DefaultProviderPrefixes
+		}
+		add("provider prefix", false, "%s has none of the provider prefixes %v (conventions.provider_prefixes)", fn.Name.Name, prefixes)
+	} else {
+		add("provider prefix", true, "%s starts with provider prefix %q", fn.Name.Name, prefix)
+		results := s.getFieldTypes(fn.Type.Results)
+		add("provider result", len(results) > 0 && results[0] != "", "returns (%s)", strings.Join(results, ", "))
+	}
+	explanation.Provider = s.extractProvider(fn, pkg, filePath) != nil
+
+	return explanation
+}
+
+// explainSignature evaluates the handler signature of the configured framework
+func (s *ASTScanner) explainSignature(fn *ast.FuncType, add func(string, bool, string, ...interface{}) bool) {
+	params := strings.Join(s.getFieldTypes(fn.Params), ", ")
+	results := strings.Join(s.getFieldTypes(fn.Results), ", ")
+	framework := s.config.RouteFramework()
+
+	switch framework {
+	case config.FrameworkGin:
+		add("context parameter", s.hasCtxParam(fn, "*gin.Context"), "gin handlers take a single *gin.Context, found (%s)", params)
+		add("no results", !s.hasResults(fn), "gin handlers return nothing, found (%s)", results)
+	case config.FrameworkNetHTTP, config.FrameworkChi:
+		add("http parameters", s.hasHTTPHandlerParams(fn), "%s handlers take (http.ResponseWriter, *http.Request), found (%s)", framework, params)
+		add("no results", !s.hasResults(fn), "%s handlers return nothing, found (%s)", framework, results)
+	default:
+		ctxType := "*fiber.Ctx"
+		if s.config.FiberVersion() == 3 {
+			ctxType = "fiber.Ctx"
+		}
+		add("context parameter", s.hasCtxParam(fn, ctxType), "fiber v%d handlers take a single %s, found (%s)", s.config.FiberVersion(), ctxType, params)
+		add("error return", s.returnsError(fn), "fiber handlers return error, found (%s)", results)
+	}
+}
+
+// explainRouter evaluates every @Router annotation of a handler, the first valid one routes it
+func (s *ASTScanner) explainRouter(fn *ast.FuncDecl, add func(string, bool, string, ...interface{}) bool) {
+	routers := annotations.Find(fn.Doc, "Router")
+	if len(routers) == 0 {
+		add("@Router annotation", false, "no @Router annotation, the handler is found but never routed")
+		return
+	}
+
+	for _, annotation := range routers {
+		router, ok := annotations.ParseRouter(annotation.Args)
+		switch {
+		case !ok:
+			add("@Router annotation", false, "@Router %s doesn't match /path [method]", annotation.Args)
+		case !s.isValidHTTPMethod(router.Method):
+			add("@Router annotation", false, "@Router %s: %s is neither an HTTP method nor in generation.routes.methods", annotation.Args, router.Method)
+		default:
+			add("@Router annotation", true, "@Router %s routes %s %s", annotation.Args, router.Method, router.Path)
+			return
+		}
+	}
+}
+
+// funcDisplayName names a function, or a method by its receiver type, e.g. "Handler.GetUser"
+func funcDisplayName(name, receiver string) string {
+	if receiver == "" {
+		return name
+	}
+	return receiver + "." + name
+}
@@ -2,19 +2,52 @@ package scanner
 
 import (
 	"bufio"
+	"context"
+	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
 )
 
-// FileFilter handles filtering of Go files based on .taskwignore patterns
+// FileFilter handles filtering of Go files based on .taskwignore patterns and scanning.ignore/include,
+// and .gitignore patterns when scanning.respect_gitignore is set
 type FileFilter struct {
-	ignorePatterns []string
-	defaultIgnores []string
+	defaultRules     []ignoreRule // Built-in exclusions, e.g. vendor and test files
+	ignoreRules      []ignoreRule // Patterns of .taskwignore then taskw.yaml, applied last so they can re-include anything
+	configIgnores    []string     // scanning.ignore patterns, followed by scanning.include patterns negated
+	defaultIgnores   []string
+	respectGitignore bool
+	followSymlinks   bool
+	root             string // Project root scanned directories are relative to, the working directory when empty
+}
+
+// ignoreRule is a parsed .taskwignore or .gitignore pattern, following gitignore semantics
+type ignoreRule struct {
+	// segments are the slash separated parts of the pattern, "**" matching any number of them.
+	// Patterns without a slash match at any depth and start with "**"
+	segments []string
+	// negate re-includes paths excluded by earlier rules, the pattern started with "!"
+	negate bool
+	// dirOnly only matches directories, the pattern ended with "/"
+	dirOnly bool
+	// base is the project directory holding the .gitignore the pattern comes from, patterns are matched
+	// relative to it. Empty for .taskwignore patterns, which are matched relative to each scanned directory
+	base string
+}
+
+// filterPath is a path considered by the filter, split into its parts relative to the scanned directory
+// and relative to the project root
+type filterPath struct {
+	scanParts    []string
+	projectParts []string
+	isDir        bool
 }
 
 // NewFileFilter creates a new file filter and loads .taskwignore patterns
-func NewFileFilter() *FileFilter {
+func NewFileFilter(cfg *config.Config) *FileFilter {
 	filter := &FileFilter{
 		defaultIgnores: []string{
 			"vendor/**",
@@ -27,26 +60,55 @@ func NewFileFilter() *FileFilter {
 			"**/*_test.go",   // Exclude test files
 			"**/*_mock.go",   // Exclude mock files
 			"**/testdata/**", // Exclude test data
+			".*",             // Hidden files and directories, ignored by the go command
+			"_*",             // Files and directories starting with _, ignored by the go command
 		},
 	}
+	if cfg != nil {
+		filter.root = cfg.Root
+		filter.respectGitignore = cfg.Scanning.RespectGitignore
+		filter.followSymlinks = cfg.Scanning.FollowSymlinks
+		filter.configIgnores = append(filter.configIgnores, cfg.Scanning.Ignore...)
+		for _, pattern := range cfg.Scanning.Include {
+			filter.configIgnores = append(filter.configIgnores, "!"+pattern)
+		}
+	}
 
 	// Load .taskwignore patterns
 	filter.loadTaskwIgnore()
 	return filter
 }
 
-// loadTaskwIgnore reads .taskwignore file and loads ignore patterns
+// loadTaskwIgnore reads .taskwignore file and loads ignore patterns, they apply after the defaults
+// so their negations can re-include files the defaults exclude
 func (f *FileFilter) loadTaskwIgnore() {
-	f.ignorePatterns = make([]string, len(f.defaultIgnores))
-	copy(f.ignorePatterns, f.defaultIgnores)
+	f.defaultRules = nil
+	for _, pattern := range f.defaultIgnores {
+		if rule, ok := parseIgnoreRule(pattern, ""); ok {
+			f.defaultRules = append(f.defaultRules, rule)
+		}
+	}
+
+	// .taskwignore doesn't exist, use only default patterns
+	f.ignoreRules = loadIgnoreFile(config.JoinRoot(f.root, ".taskwignore"), "")
+
+	// Patterns from taskw.yaml come after the file, so they win over it
+	for _, pattern := range f.configIgnores {
+		if rule, ok := parseIgnoreRule(strings.TrimSpace(pattern), ""); ok {
+			f.ignoreRules = append(f.ignoreRules, rule)
+		}
+	}
+}
 
-	file, err := os.Open(".taskwignore")
+// loadIgnoreFile parses the patterns of an ignore file, nil when it doesn't exist
+func loadIgnoreFile(filePath, base string) []ignoreRule {
+	file, err := os.Open(filePath)
 	if err != nil {
-		// .taskwignore doesn't exist, use only default patterns
-		return
+		return nil
 	}
 	defer file.Close()
 
+	var rules []ignoreRule
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -56,200 +118,357 @@ func (f *FileFilter) loadTaskwIgnore() {
 			continue
 		}
 
-		f.ignorePatterns = append(f.ignorePatterns, line)
+		if rule, ok := parseIgnoreRule(line, base); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// parseIgnoreRule parses a gitignore-style pattern, false when nothing is left to match
+func parseIgnoreRule(pattern, base string) (ignoreRule, bool) {
+	rule := ignoreRule{base: base}
+
+	// A leading "!" negates the pattern, "\!" and "\#" escape a literal first character
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\!`) || strings.HasPrefix(pattern, `\#`) {
+		pattern = pattern[1:]
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+
+	// A slash at the beginning or in the middle anchors the pattern to its base directory,
+	// otherwise it matches at any depth
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if pattern == "" {
+		return rule, false
+	}
+
+	rule.segments = strings.Split(pattern, "/")
+	if !anchored && rule.segments[0] != "**" {
+		rule.segments = append([]string{"**"}, rule.segments...)
+	}
+	return rule, true
+}
+
+// FindCandidateFiles recursively finds all Go files that are not ignored, along with the symlinks
+// left out, reported as skipped. rootDir and the files found are relative to the project root.
+// The walk stops with ctx's error once ctx is done
+func (f *FileFilter) FindCandidateFiles(ctx context.Context, rootDir string) ([]string, []ScanError, error) {
+	// Paths are matched against .gitignore patterns relative to the project root
+	projectRoot, err := filepath.Abs(f.root)
+	if err != nil {
+		return nil, nil, err
+	}
+	absRoot, err := filepath.Abs(config.JoinRoot(projectRoot, rootDir))
+	if err != nil {
+		return nil, nil, err
+	}
+	realRoot, err := filepath.EvalSymlinks(absRoot)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w := &fileWalk{
+		ctx:         ctx,
+		filter:      f,
+		rootDir:     rootDir,
+		absRoot:     absRoot,
+		projectRoot: projectRoot,
+		visited:     map[string]string{realRoot: rootDir},
+	}
+	if f.respectGitignore {
+		w.gitRules = parentGitignores(projectRoot, absRoot)
 	}
+
+	if err := w.walk(rootDir, absRoot, realRoot); err != nil {
+		return nil, nil, err
+	}
+	// Symlinks are followed after the directory itself, so files reachable both ways keep their real path
+	for len(w.links) > 0 {
+		link := w.links[0]
+		w.links = w.links[1:]
+		if err := w.follow(link); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return w.candidates, w.skipped, nil
 }
 
-// FindCandidateFiles recursively finds all Go files that are not ignored
-func (f *FileFilter) FindCandidateFiles(rootDir string) ([]string, error) {
-	var candidates []string
+// fileWalk collects the candidate files of a scanned directory
+type fileWalk struct {
+	ctx         context.Context
+	filter      *FileFilter
+	rootDir     string
+	absRoot     string
+	projectRoot string
+	gitRules    []ignoreRule      // .gitignore rules of the directories walked so far, each applying below its own
+	visited     map[string]string // Real paths of the walked directories and files, to the path they were found at
+	links       []string          // Symlinks left to follow
+	candidates  []string
+	skipped     []ScanError
+}
 
-	err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+// walk visits the tree of the absolute dir, found at display in the scanned directory. The two differ
+// for the target of a followed symlink, whose files are reported below the symlink, and otherwise by the
+// project root. real is the resolved path of dir
+func (w *fileWalk) walk(display, dir, real string) error {
+	return filepath.Walk(dir, func(abs string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if err := w.ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, abs)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		path := filepath.Join(display, rel)
 
-		// Get relative path from root directory for pattern matching
-		relPath, err := filepath.Rel(rootDir, path)
+		candidate, err := w.candidate(path, info.IsDir())
 		if err != nil {
 			return err
 		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			w.symlink(path, abs, candidate)
+			return nil
+		}
 
-		// Skip directories that match ignore patterns
+		// Skip directories that match ignore patterns, hidden and _ directories by default.
+		// Like git, files of a skipped directory can't be re-included by a negated pattern
 		if info.IsDir() {
-			if f.shouldIgnore(relPath) {
+			if w.filter.shouldIgnore(candidate, w.gitRules) {
 				return filepath.SkipDir
 			}
+			// A directory already walked through another symlink
+			if !w.visit(filepath.Join(real, rel), path) {
+				return filepath.SkipDir
+			}
+			// Nested .gitignore files apply below their directory, after the ones above it
+			if w.filter.respectGitignore && candidate.projectParts != nil {
+				base := "."
+				if len(candidate.projectParts) > 0 {
+					base = strings.Join(candidate.projectParts, "/")
+				}
+				w.gitRules = append(w.gitRules, loadIgnoreFile(filepath.Join(abs, ".gitignore"), base)...)
+			}
 			return nil
 		}
 
-		// Only process Go files
+		// Only process Go files, assembly, C and other files of the build have no annotations
 		if !strings.HasSuffix(path, ".go") {
 			return nil
 		}
 
 		// Check if file should be ignored
-		if !f.shouldIgnore(relPath) {
-			candidates = append(candidates, path)
+		if !w.filter.shouldIgnore(candidate, w.gitRules) && w.visit(filepath.Join(real, rel), path) {
+			w.candidates = append(w.candidates, path)
 		}
 
 		return nil
 	})
-
-	return candidates, err
 }
 
-// shouldIgnore checks if a file or directory path matches any ignore pattern
-func (f *FileFilter) shouldIgnore(relPath string) bool {
-	// Normalize path separators to forward slashes for consistent matching
-	normalizedPath := filepath.ToSlash(relPath)
-
-	for _, pattern := range f.ignorePatterns {
-		if f.matchPattern(pattern, normalizedPath) {
-			return true
-		}
+// candidate splits a path found in the scanned directory for matching
+func (w *fileWalk) candidate(path string, isDir bool) (filterPath, error) {
+	relPath, err := filepath.Rel(w.rootDir, path)
+	if err != nil {
+		return filterPath{}, err
 	}
-
-	return false
+	return filterPath{
+		scanParts:    splitPath(relPath),
+		projectParts: projectParts(w.projectRoot, filepath.Join(w.absRoot, relPath)),
+		isDir:        isDir,
+	}, nil
 }
 
-// matchPattern implements basic glob pattern matching for ignore patterns
-func (f *FileFilter) matchPattern(pattern, path string) bool {
-	// Handle ** patterns (match any number of directories)
-	if strings.Contains(pattern, "**") {
-		return f.matchDoubleStarPattern(pattern, path)
+// visit records the real path of a directory or file, false when it was already walked
+func (w *fileWalk) visit(real, path string) bool {
+	if _, seen := w.visited[real]; seen {
+		return false
 	}
+	w.visited[real] = path
+	return true
+}
 
-	// Handle * patterns (match within a single directory level)
-	if strings.Contains(pattern, "*") {
-		return f.matchSingleStarPattern(pattern, path)
+// symlink queues a symlink to a directory or Go file that isn't ignored, when scanning.follow_symlinks
+// is set, and reports it as skipped otherwise. Broken symlinks are left out silently
+func (w *fileWalk) symlink(path, abs string, candidate filterPath) {
+	target, err := os.Stat(abs)
+	if err != nil {
+		return
 	}
-
-	// Exact match or directory prefix match
-	if pattern == path {
-		return true
+	candidate.isDir = target.IsDir()
+	if !candidate.isDir && !strings.HasSuffix(path, ".go") {
+		return
 	}
-
-	// Check if pattern matches as directory prefix
-	if strings.HasSuffix(pattern, "/") {
-		return strings.HasPrefix(path, pattern) || strings.HasPrefix(path+"/", pattern)
+	if w.filter.shouldIgnore(candidate, w.gitRules) {
+		return
 	}
 
-	// Check if path starts with pattern as directory
-	return strings.HasPrefix(path, pattern+"/")
+	if !w.filter.followSymlinks {
+		w.skip(path, "symlink skipped (set scanning.follow_symlinks to scan its target)")
+		return
+	}
+	w.links = append(w.links, path)
 }
 
-// matchDoubleStarPattern handles ** glob patterns
-func (f *FileFilter) matchDoubleStarPattern(pattern, path string) bool {
-	parts := strings.Split(pattern, "**")
-	if len(parts) != 2 {
-		// Complex ** patterns not supported yet, fall back to simple matching
-		return strings.Contains(path, strings.ReplaceAll(pattern, "**", ""))
+// follow walks the target of a symlink, unless it was already walked: a symlink to one of its parent
+// directories would otherwise be walked over and over
+func (w *fileWalk) follow(link string) error {
+	real, err := filepath.EvalSymlinks(config.JoinRoot(w.projectRoot, link))
+	if err != nil {
+		return nil
 	}
-
-	prefix := strings.TrimSuffix(parts[0], "/")
-	suffix := strings.TrimPrefix(parts[1], "/")
-
-	// Check prefix match
-	if prefix != "" && !strings.HasPrefix(path, prefix) {
-		return false
+	info, err := os.Stat(real)
+	if err != nil {
+		return nil
 	}
 
-	// Check suffix match
-	if suffix != "" {
-		if strings.Contains(suffix, "*") {
-			// Suffix has more wildcards, handle recursively
-			pathWithoutPrefix := path
-			if prefix != "" {
-				pathWithoutPrefix = strings.TrimPrefix(path, prefix+"/")
-			}
-			return f.matchSingleStarPattern(suffix, pathWithoutPrefix)
+	if walked, seen := w.visited[real]; seen {
+		if info.IsDir() && (walked == w.rootDir || strings.HasPrefix(link, walked+string(filepath.Separator))) {
+			w.skip(link, fmt.Sprintf("symlink skipped, it points back to %s and would loop", walked))
+		} else {
+			w.skip(link, fmt.Sprintf("symlink skipped, its target is already scanned as %s", walked))
 		}
-		return strings.HasSuffix(path, suffix)
+		return nil
 	}
+	w.visited[real] = link
 
-	return true
+	if !info.IsDir() {
+		w.candidates = append(w.candidates, link)
+		return nil
+	}
+	return w.walk(link, real, real)
 }
 
-// matchSingleStarPattern handles * glob patterns (single directory level)
-func (f *FileFilter) matchSingleStarPattern(pattern, path string) bool {
-	// Split pattern and path by slashes for directory-level matching
-	patternParts := strings.Split(pattern, "/")
-	pathParts := strings.Split(path, "/")
-
-	return f.matchParts(patternParts, pathParts)
+// skip reports a path left out of the scan
+func (w *fileWalk) skip(path, message string) {
+	w.skipped = append(w.skipped, ScanError{FilePath: path, Message: message, Type: "skipped"})
 }
 
-// matchParts matches pattern parts against path parts
-func (f *FileFilter) matchParts(patternParts, pathParts []string) bool {
-	if len(patternParts) > len(pathParts) {
-		return false
+// parentGitignores loads the .gitignore files from the project root down to a scanned directory, outer ones first.
+// Directories outside the project only use their own .gitignore
+func parentGitignores(projectRoot, dir string) []ignoreRule {
+	parts := projectParts(projectRoot, dir)
+	if parts == nil {
+		return loadIgnoreFile(filepath.Join(dir, ".gitignore"), "")
 	}
 
-	for i, patternPart := range patternParts {
-		if i >= len(pathParts) {
-			return false
-		}
-
-		if !f.matchSinglePart(patternPart, pathParts[i]) {
-			return false
-		}
+	rules := loadIgnoreFile(filepath.Join(projectRoot, ".gitignore"), ".")
+	for i := range parts {
+		base := strings.Join(parts[:i+1], "/")
+		rules = append(rules, loadIgnoreFile(filepath.Join(projectRoot, filepath.FromSlash(base), ".gitignore"), base)...)
 	}
-
-	return true
+	return rules
 }
 
-// matchSinglePart matches a single pattern part with wildcards against a path part
-func (f *FileFilter) matchSinglePart(pattern, pathPart string) bool {
-	if pattern == "*" {
-		return true
+// projectParts splits an absolute path into its parts relative to the project root,
+// empty for the root itself and nil for paths outside the project
+func projectParts(projectRoot, absPath string) []string {
+	relPath, err := filepath.Rel(projectRoot, absPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return nil
 	}
-
-	if !strings.Contains(pattern, "*") {
-		return pattern == pathPart
+	if relPath == "." {
+		return []string{}
 	}
-
-	// Handle simple * patterns within a single part
-	return f.wildcardMatch(pattern, pathPart)
+	return splitPath(relPath)
 }
 
-// wildcardMatch implements simple wildcard matching for a single pattern part
-func (f *FileFilter) wildcardMatch(pattern, str string) bool {
-	// Simple implementation for basic * wildcards
-	parts := strings.Split(pattern, "*")
+// splitPath splits a relative path into its slash separated parts
+func splitPath(relPath string) []string {
+	// Normalize path separators to forward slashes for consistent matching
+	return strings.Split(filepath.ToSlash(relPath), "/")
+}
 
-	index := 0
-	for i, part := range parts {
-		if part == "" {
-			continue
+// shouldIgnore checks if a file or directory path is ignored. The default rules apply first, then
+// .gitignore rules and .taskwignore rules last. The last matching rule decides, so a negated pattern
+// re-includes what earlier patterns excluded
+func (f *FileFilter) shouldIgnore(candidate filterPath, gitRules []ignoreRule) bool {
+	ignored := false
+	for _, rules := range [][]ignoreRule{f.defaultRules, gitRules, f.ignoreRules} {
+		for _, rule := range rules {
+			if rule.negate == ignored && rule.matches(candidate) {
+				ignored = !rule.negate
+			}
 		}
+	}
 
-		pos := strings.Index(str[index:], part)
-		if pos == -1 {
-			return false
-		}
+	return ignored
+}
 
-		// For first part, must match at beginning
-		if i == 0 && pos != 0 {
+// matches reports whether the rule matches a path
+func (r ignoreRule) matches(candidate filterPath) bool {
+	if r.dirOnly && !candidate.isDir {
+		return false
+	}
+	if r.base == "" {
+		return matchSegments(r.segments, candidate.scanParts)
+	}
+
+	// .gitignore patterns only apply below the directory of their file
+	if candidate.projectParts == nil {
+		return false
+	}
+	parts := candidate.projectParts
+	if r.base != "." {
+		baseParts := strings.Split(r.base, "/")
+		if len(parts) <= len(baseParts) || strings.Join(parts[:len(baseParts)], "/") != r.base {
 			return false
 		}
+		parts = parts[len(baseParts):]
+	}
+	return matchSegments(r.segments, parts)
+}
 
-		index += pos + len(part)
+// matchSegments matches pattern segments against path parts. "**" matches any number of parts,
+// except at the end of a pattern where it matches everything inside the directory before it,
+// other segments use path.Match syntax within a single part
+func matchSegments(segments, parts []string) bool {
+	if len(segments) == 0 {
+		return len(parts) == 0
 	}
 
-	// For last part, must match at end
-	if len(parts) > 0 && parts[len(parts)-1] != "" {
-		return strings.HasSuffix(str, parts[len(parts)-1])
+	if segments[0] == "**" {
+		if len(segments) == 1 {
+			return len(parts) > 0
+		}
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(segments[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
 	}
 
-	return true
+	if len(parts) == 0 {
+		return false
+	}
+	matched, err := path.Match(segments[0], parts[0])
+	if err != nil || !matched {
+		return false
+	}
+	return matchSegments(segments[1:], parts[1:])
 }
 
 // CreateDefaultTaskwIgnore creates a default .taskwignore file
 func (f *FileFilter) CreateDefaultTaskwIgnore() error {
 	content := `# Taskw Ignore Patterns
 # This file specifies which files and directories to ignore when scanning for handlers and providers
-# Patterns follow gitignore-style glob syntax
+# Patterns follow gitignore-style glob syntax, later patterns override earlier ones and
+# a leading ! re-includes files excluded before, e.g. !**/routes_gen.go
 
 # Dependencies and vendor code
 vendor/**
@@ -288,5 +507,5 @@ tmp/**
 # **/generated/**
 `
 
-	return os.WriteFile(".taskwignore", []byte(content), 0644)
+	return os.WriteFile(config.JoinRoot(f.root, ".taskwignore"), []byte(content), 0644)
 }
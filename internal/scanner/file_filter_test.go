@@ -0,0 +1,242 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nkaewam/taskw/internal/config"
+)
+
+func TestIgnoreRuleMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		base    string // Directory of the .gitignore, empty for .taskwignore patterns
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		// Patterns without a slash match at any depth
+		{pattern: "*.log", path: "debug.log", want: true},
+		{pattern: "*.log", path: "internal/user/debug.log", want: true},
+		{pattern: "mocks", path: "internal/mocks", isDir: true, want: true},
+		// A slash at the beginning or in the middle anchors the pattern
+		{pattern: "/gen.go", path: "gen.go", want: true},
+		{pattern: "/gen.go", path: "internal/gen.go", want: false},
+		{pattern: "internal/*.go", path: "internal/user.go", want: true},
+		{pattern: "internal/*.go", path: "cmd/internal/user.go", want: false},
+		{pattern: "*", path: "internal/user.go", want: true},
+		// "**" matches any number of directories, at the end everything inside the directory before it
+		{pattern: "internal/**/gen.go", path: "internal/gen.go", want: true},
+		{pattern: "internal/**/gen.go", path: "internal/a/b/gen.go", want: true},
+		{pattern: "vendor/**", path: "vendor/lib/lib.go", want: true},
+		{pattern: "vendor/**", path: "vendor", isDir: true, want: false},
+		// A trailing slash only matches directories
+		{pattern: "build/", path: "build", isDir: true, want: true},
+		{pattern: "build/", path: "build", want: false},
+		{pattern: "build/", path: "cmd/build", isDir: true, want: true},
+		// .gitignore patterns are relative to their directory and only apply below it
+		{pattern: "/gen.go", base: ".", path: "gen.go", want: true},
+		{pattern: "/gen.go", base: "internal", path: "internal/gen.go", want: true},
+		{pattern: "/gen.go", base: "internal", path: "gen.go", want: false},
+		{pattern: "*.go", base: "internal/user", path: "internal/order/order.go", want: false},
+		{pattern: "*.go", base: "internal/user", path: "internal/user/sub/user.go", want: true},
+		// Escaped first characters are literal
+		{pattern: `\!important.go`, path: "!important.go", want: true},
+		{pattern: `\#gen.go`, path: "#gen.go", want: true},
+	}
+
+	for _, tt := range tests {
+		rule, ok := parseIgnoreRule(tt.pattern, tt.base)
+		if !ok {
+			t.Errorf("parseIgnoreRule(%q) found nothing to match", tt.pattern)
+			continue
+		}
+		candidate := filterPath{scanParts: splitPath(tt.path), projectParts: splitPath(tt.path), isDir: tt.isDir}
+		if got := rule.matches(candidate); got != tt.want {
+			t.Errorf("%q (base %q) matches %q = %v, want %v", tt.pattern, tt.base, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseIgnoreRuleEmpty(t *testing.T) {
+	for _, pattern := range []string{"/", "!", "!/", "//"} {
+		if rule, ok := parseIgnoreRule(pattern, ""); ok {
+			t.Errorf("parseIgnoreRule(%q) = %+v, want nothing to match", pattern, rule)
+		}
+	}
+}
+
+func TestShouldIgnore(t *testing.T) {
+	tests := []struct {
+		name        string
+		taskwignore string   // Content of .taskwignore, none when empty
+		ignore      []string // scanning.ignore
+		include     []string // scanning.include
+		gitignore   []string // Patterns of the project's .gitignore
+		path        string
+		isDir       bool
+		want        bool
+	}{
+		{name: "not ignored", path: "internal/user/handler.go", want: false},
+		{name: "default test files", path: "internal/user/handler_test.go", want: true},
+		{name: "default hidden directory", path: ".cache", isDir: true, want: true},
+		{
+			name:        "negation re-includes a default",
+			taskwignore: "!**/*_test.go\n",
+			path:        "internal/user/handler_test.go",
+			want:        false,
+		},
+		{
+			name:        "later pattern wins",
+			taskwignore: "!**/*_gen.go\n**/*_gen.go\n",
+			path:        "internal/api/routes_gen.go",
+			want:        true,
+		},
+		{
+			name:        "negation after the pattern re-includes",
+			taskwignore: "**/*_gen.go\n!**/routes_gen.go\n",
+			path:        "internal/api/routes_gen.go",
+			want:        false,
+		},
+		{
+			name:        "negation of another file keeps the pattern",
+			taskwignore: "**/*_gen.go\n!**/routes_gen.go\n",
+			path:        "internal/api/dependencies_gen.go",
+			want:        true,
+		},
+		{
+			name:        "scanning.ignore applies after .taskwignore",
+			taskwignore: "!internal/legacy/**\n",
+			ignore:      []string{"internal/legacy/**"},
+			path:        "internal/legacy/handler.go",
+			want:        true,
+		},
+		{
+			name:    "scanning.include applies after scanning.ignore",
+			ignore:  []string{"internal/legacy/**"},
+			include: []string{"internal/legacy/handler.go"},
+			path:    "internal/legacy/handler.go",
+			want:    false,
+		},
+		{name: "gitignore", gitignore: []string{"generated/"}, path: "generated", isDir: true, want: true},
+		{name: "gitignore directory pattern and file", gitignore: []string{"generated/"}, path: "generated", want: false},
+		{
+			name:        ".taskwignore re-includes what .gitignore excludes",
+			taskwignore: "!internal/gen.go\n",
+			gitignore:   []string{"gen.go"},
+			path:        "internal/gen.go",
+			want:        false,
+		},
+		{
+			name:      "gitignore negation re-includes",
+			gitignore: []string{"*.go", "!keep.go"},
+			path:      "internal/keep.go",
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			if tt.taskwignore != "" {
+				if err := os.WriteFile(filepath.Join(root, ".taskwignore"), []byte(tt.taskwignore), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cfg := &config.Config{Root: root}
+			cfg.Scanning.Ignore = tt.ignore
+			cfg.Scanning.Include = tt.include
+			filter := NewFileFilter(cfg)
+
+			var gitRules []ignoreRule
+			for _, pattern := range tt.gitignore {
+				rule, ok := parseIgnoreRule(pattern, ".")
+				if !ok {
+					t.Fatalf("parseIgnoreRule(%q) found nothing to match", pattern)
+				}
+				gitRules = append(gitRules, rule)
+			}
+
+			candidate := filterPath{scanParts: splitPath(tt.path), projectParts: splitPath(tt.path), isDir: tt.isDir}
+			if got := filter.shouldIgnore(candidate, gitRules); got != tt.want {
+				t.Errorf("shouldIgnore(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindCandidateFilesGitignore(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":                     "/internal/tmp/\n*_gen.go\n!internal/api/routes_gen.go\n",
+		"internal/api/routes_gen.go":     "package api\n",
+		"internal/api/wire_gen.go":       "package api\n",
+		"internal/api/server.go":         "package api\n",
+		"internal/tmp/scratch.go":        "package tmp\n",
+		"internal/user/.gitignore":       "/legacy.go\n",
+		"internal/user/legacy.go":        "package user\n",
+		"internal/user/handler.go":       "package user\n",
+		"internal/user/legacy/legacy.go": "package legacy\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		respectGitignore bool
+		want             []string
+	}{
+		{
+			respectGitignore: false,
+			want: []string{
+				"internal/api/routes_gen.go",
+				"internal/api/server.go",
+				"internal/api/wire_gen.go",
+				"internal/tmp/scratch.go",
+				"internal/user/handler.go",
+				"internal/user/legacy/legacy.go",
+				"internal/user/legacy.go",
+			},
+		},
+		{
+			respectGitignore: true,
+			want: []string{
+				"internal/api/routes_gen.go",
+				"internal/api/server.go",
+				"internal/user/handler.go",
+				"internal/user/legacy/legacy.go",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		cfg := &config.Config{Root: root}
+		cfg.Scanning.RespectGitignore = tt.respectGitignore
+		candidates, skipped, err := NewFileFilter(cfg).FindCandidateFiles(context.Background(), "internal")
+		if err != nil {
+			t.Fatalf("FindCandidateFiles: %v", err)
+		}
+		if len(skipped) > 0 {
+			t.Errorf("FindCandidateFiles skipped %v, want nothing skipped", skipped)
+		}
+
+		got := make([]string, len(candidates))
+		for i, candidate := range candidates {
+			got[i] = filepath.ToSlash(candidate)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("respect_gitignore %v: FindCandidateFiles = \n%s\nwant\n%s",
+				tt.respectGitignore, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package scanner
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// extractModels records the package-level named types of a file, so schemas swag leaves opaque,
+// e.g. for fields typed with a struct of another package, can be completed from the scanned sources
+func (s *ASTScanner) extractModels(node *ast.File, pkg, filePath string, result *ScanResult) {
+	for _, decl := range node.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			// Generic types have no schema until instantiated
+			if !ok || ts.TypeParams != nil {
+				continue
+			}
+
+			model := Model{Name: pkg + "." + ts.Name.Name, FilePath: filePath}
+			if st, ok := ts.Type.(*ast.StructType); ok {
+				model.Fields = s.modelFields(st, pkg)
+			} else if model.Underlying = s.qualifiedFieldType(ts.Type, pkg); model.Underlying == "" {
+				continue // Interfaces, functions and channels have no JSON schema
+			}
+			result.Models = append(result.Models, model)
+		}
+	}
+}
+
+// modelFields returns the fields of a struct as they appear in JSON bodies
+func (s *ASTScanner) modelFields(st *ast.StructType, pkg string) []ModelField {
+	var fields []ModelField
+	for _, field := range st.Fields.List {
+		fieldType := s.qualifiedFieldType(field.Type, pkg)
+		if fieldType == "" || structTag(field.Tag, "swaggerignore") == "true" {
+			continue
+		}
+		required := false
+		for _, key := range []string{"binding", "validate"} {
+			for _, rule := range strings.Split(structTag(field.Tag, key), ",") {
+				required = required || rule == "required"
+			}
+		}
+
+		// Embedded fields are promoted into the parent unless renamed by their json tag
+		if len(field.Names) == 0 {
+			name, _, _ := strings.Cut(structTag(field.Tag, "json"), ",")
+			if name == "-" {
+				continue
+			}
+			fields = append(fields, ModelField{Name: name, Type: fieldType, Embedded: name == "", Required: required})
+			continue
+		}
+
+		for _, name := range field.Names {
+			jsonName := jsonFieldName(name.Name, field.Tag)
+			if !name.IsExported() || jsonName == "" {
+				continue
+			}
+			fields = append(fields, ModelField{Name: jsonName, Type: fieldType, Required: required})
+		}
+	}
+	return fields
+}
+
+// qualifiedFieldType returns a field type with named types qualified by their package,
+// e.g. []*Address -> []*user.Address and map[string]Role -> map[string]user.Role
+// Returns "" for types without a JSON schema, such as functions and channels
+func (s *ASTScanner) qualifiedFieldType(expr ast.Expr, pkg string) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if isPredeclaredType(t.Name) {
+			return t.Name
+		}
+		return pkg + "." + t.Name
+	case *ast.SelectorExpr:
+		return s.getTypeString(t)
+	case *ast.StarExpr:
+		if elem := s.qualifiedFieldType(t.X, pkg); elem != "" {
+			return "*" + elem
+		}
+	case *ast.ArrayType:
+		if elem := s.qualifiedFieldType(t.Elt, pkg); elem != "" {
+			return "[]" + elem
+		}
+	case *ast.MapType:
+		key, value := s.qualifiedFieldType(t.Key, pkg), s.qualifiedFieldType(t.Value, pkg)
+		if key != "" && value != "" {
+			return "map[" + key + "]" + value
+		}
+	case *ast.InterfaceType:
+		return "interface{}"
+	}
+	return ""
+}
+
+// structTag returns the value of a key in a field's struct tag, e.g. `binding:"required"` -> "required"
+func structTag(tag *ast.BasicLit, key string) string {
+	if tag == nil {
+		return ""
+	}
+	value, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return ""
+	}
+	return reflect.StructTag(value).Get(key)
+}
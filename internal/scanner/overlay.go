@@ -0,0 +1,146 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// RouteOverlay adds or overrides route metadata from a YAML file instead of Go comments
+type RouteOverlay struct {
+	Path   string         // Path to the overlay file
+	Routes []OverlayRoute `yaml:"routes"`
+}
+
+// OverlayRoute changes the metadata of the scanned routes matching a path and method
+type OverlayRoute struct {
+	Path           string   `yaml:"path"`            // e.g., "/users/{id}" or "/users/:id"
+	Method         string   `yaml:"method"`          // Optional, matches every method when empty
+	Middlewares    []string `yaml:"middlewares"`     // Replaces @Middleware when set
+	AddMiddlewares []string `yaml:"add_middlewares"` // Appended to the middleware chain
+	Auth           string   `yaml:"auth"`            // Auth middleware, runs before every other middleware
+	Tags           []string `yaml:"tags"`            // Replaces @Tags when set
+	AddTags        []string `yaml:"add_tags"`        // Appended to the tags
+	Line           int      `yaml:"-"`               // Line of the entry in the overlay file
+}
+
+// LoadRouteOverlay reads a routes overlay file, at a path relative to the project root
+func LoadRouteOverlay(root, path string) (*RouteOverlay, error) {
+	content, err := os.ReadFile(config.JoinRoot(root, path))
+	if err != nil {
+		return nil, fmt.Errorf("error reading routes overlay: %w", err)
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("error parsing routes overlay %s: %w", path, err)
+	}
+
+	overlay := &RouteOverlay{Path: path}
+	if err := document.Decode(overlay); err != nil {
+		return nil, fmt.Errorf("error parsing routes overlay %s: %w", path, err)
+	}
+
+	// Record entry lines so unmatched entries can be reported precisely
+	if len(document.Content) > 0 {
+		root := document.Content[0]
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value != "routes" {
+				continue
+			}
+			for j, entry := range root.Content[i+1].Content {
+				if j < len(overlay.Routes) {
+					overlay.Routes[j].Line = entry.Line
+				}
+			}
+		}
+	}
+
+	for _, route := range overlay.Routes {
+		if route.Path == "" {
+			return nil, fmt.Errorf("routes overlay %s:%d: entry is missing a path", path, route.Line)
+		}
+	}
+
+	return overlay, nil
+}
+
+// Apply merges the overlay into the scanned routes
+// Entries that match no scanned route are reported as scan errors
+func (o *RouteOverlay) Apply(result *ScanResult) {
+	for _, entry := range o.Routes {
+		matched := false
+		for i := range result.Routes {
+			if !entry.matches(result.Routes[i]) {
+				continue
+			}
+			matched = true
+			entry.applyTo(&result.Routes[i])
+		}
+
+		if !matched {
+			target := entry.Path
+			if entry.Method != "" {
+				target = strings.ToUpper(entry.Method) + " " + entry.Path
+			}
+			result.Errors = append(result.Errors, ScanError{
+				FilePath: o.Path,
+				Line:     entry.Line,
+				Message:  fmt.Sprintf("routes overlay entry %s matches no scanned route", target),
+				Type:     "overlay",
+			})
+		}
+	}
+}
+
+// matches checks if the entry targets a route, ignoring parameter names and syntax
+func (e OverlayRoute) matches(route RouteMapping) bool {
+	if e.Method != "" && !strings.EqualFold(e.Method, route.HTTPMethod) {
+		return false
+	}
+	return normalizeOverlayPath(e.Path) == normalizeOverlayPath(route.Path)
+}
+
+// applyTo overrides and extends the route metadata
+func (e OverlayRoute) applyTo(route *RouteMapping) {
+	middlewares := route.Middlewares
+	if e.Middlewares != nil {
+		middlewares = e.Middlewares
+	}
+	middlewares = append(append([]string{}, middlewares...), e.AddMiddlewares...)
+	if e.Auth != "" {
+		middlewares = append([]string{e.Auth}, removeString(middlewares, e.Auth)...)
+	}
+	route.Middlewares = middlewares
+
+	tags := route.Tags
+	if e.Tags != nil {
+		tags = e.Tags
+	}
+	route.Tags = append(append([]string{}, tags...), e.AddTags...)
+}
+
+// normalizeOverlayPath makes {param} and :param path segments comparable
+func normalizeOverlayPath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || (strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")) {
+			segments[i] = "{}"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// removeString returns values without any occurrence of value
+func removeString(values []string, value string) []string {
+	var kept []string
+	for _, v := range values {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,153 @@
+package scanner
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
+)
+
+// PackageFilter selects scanned packages by name, import name or directory, e.g. "user", "adminuser"
+// or "internal/admin/user". An empty filter selects every package
+type PackageFilter []string
+
+// Matches reports whether the package of a declaration found in filePath is selected
+func (f PackageFilter) Matches(pkg, importName, filePath string) bool {
+	if len(f) == 0 {
+		return true
+	}
+
+	dir := filepath.ToSlash(filepath.Dir(filePath))
+	for _, name := range f {
+		name = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(name), "./"), "/")
+		if name == pkg || (importName != "" && name == importName) || name == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesFile reports whether a file belongs to one of the directories of the selected packages
+func (f PackageFilter) matchesFile(dirs map[string]bool, filePath string) bool {
+	return dirs[filepath.ToSlash(filepath.Dir(filePath))]
+}
+
+// Apply returns the handlers, routes, providers and other declarations of the selected packages.
+// Models and struct fields are kept whole, since selected types can refer to any of them
+func (f PackageFilter) Apply(result *ScanResult) *ScanResult {
+	if len(f) == 0 {
+		return result
+	}
+
+	filtered := &ScanResult{
+		Handlers:  []HandlerFunction{},
+		Routes:    []RouteMapping{},
+		Providers: []ProviderFunction{},
+		Errors:    []ScanError{},
+		Structs:   result.Structs,
+		Models:    result.Models,
+	}
+
+	// Declarations without an import name are matched by the directories of the selected packages
+	dirs := make(map[string]bool)
+	for _, h := range result.Handlers {
+		if f.Matches(h.Package, h.ImportName, h.FilePath) {
+			filtered.Handlers = append(filtered.Handlers, h)
+			dirs[filepath.ToSlash(filepath.Dir(h.FilePath))] = true
+		}
+	}
+	for _, r := range result.Routes {
+		if f.Matches(r.Package, r.ImportName, r.FilePath) {
+			filtered.Routes = append(filtered.Routes, r)
+			dirs[filepath.ToSlash(filepath.Dir(r.FilePath))] = true
+		}
+	}
+	for _, r := range result.RPCs {
+		if f.Matches(r.Package, r.ImportName, r.FilePath) {
+			filtered.RPCs = append(filtered.RPCs, r)
+			dirs[filepath.ToSlash(filepath.Dir(r.FilePath))] = true
+		}
+	}
+	for _, p := range result.Providers {
+		if f.Matches(p.Package, p.ImportName, p.FilePath) {
+			filtered.Providers = append(filtered.Providers, p)
+			dirs[filepath.ToSlash(filepath.Dir(p.FilePath))] = true
+		}
+	}
+	for _, i := range result.Interfaces {
+		if f.Matches(i.Package, i.ImportName, i.FilePath) {
+			filtered.Interfaces = append(filtered.Interfaces, i)
+		}
+	}
+	for _, i := range result.Implementations {
+		if f.Matches(i.Package, i.ImportName, i.FilePath) {
+			filtered.Implementations = append(filtered.Implementations, i)
+		}
+	}
+	for _, d := range result.HandlerDefaults {
+		if f.Matches(d.Package, "", d.FilePath) || f.matchesFile(dirs, d.FilePath) {
+			filtered.HandlerDefaults = append(filtered.HandlerDefaults, d)
+		}
+	}
+	for _, v := range result.PackageVars {
+		if f.Matches(v.Package, "", v.FilePath) || f.matchesFile(dirs, v.FilePath) {
+			filtered.PackageVars = append(filtered.PackageVars, v)
+		}
+	}
+	for _, field := range result.PIIFields {
+		if f.matchesFile(dirs, field.FilePath) {
+			filtered.PIIFields = append(filtered.PIIFields, field)
+		}
+	}
+	for _, c := range result.Conflicts {
+		if f.Matches(c.Package, "", c.FilePath) {
+			filtered.Conflicts = append(filtered.Conflicts, c)
+		}
+	}
+	for _, e := range result.Errors {
+		if f.matchesFile(dirs, e.FilePath) || f.Matches("", "", e.FilePath) {
+			filtered.Errors = append(filtered.Errors, e)
+		}
+	}
+	return filtered
+}
+
+// SelectRoutePackages keeps the handlers and routes of the packages include_packages of generation.routes
+// lists and exclude_packages doesn't. Returns the number of routes left out, and the settings naming a
+// package no handler is declared in, since a misspelled one would go unnoticed
+func SelectRoutePackages(cfg config.RouteConfig, handlers []HandlerFunction, routes []RouteMapping) ([]HandlerFunction, []RouteMapping, int, []string) {
+	include := PackageFilter(cfg.IncludePackages)
+	exclude := PackageFilter(cfg.ExcludePackages)
+	if len(include) == 0 && len(exclude) == 0 {
+		return handlers, routes, 0, nil
+	}
+
+	var unmatched []string
+	for _, setting := range []struct {
+		key    string
+		filter PackageFilter
+	}{{"include_packages", include}, {"exclude_packages", exclude}} {
+		for _, name := range setting.filter {
+			declared := slices.ContainsFunc(handlers, func(handler HandlerFunction) bool {
+				return PackageFilter{name}.Matches(handler.Package, handler.ImportName, handler.FilePath)
+			})
+			if !declared {
+				unmatched = append(unmatched, fmt.Sprintf("generation.routes.%s: %q", setting.key, name))
+			}
+		}
+	}
+
+	keep := func(pkg, importName, filePath string) bool {
+		return include.Matches(pkg, importName, filePath) && (len(exclude) == 0 || !exclude.Matches(pkg, importName, filePath))
+	}
+	handlers = slices.DeleteFunc(handlers, func(handler HandlerFunction) bool {
+		return !keep(handler.Package, handler.ImportName, handler.FilePath)
+	})
+	total := len(routes)
+	routes = slices.DeleteFunc(routes, func(route RouteMapping) bool {
+		return !keep(route.Package, route.ImportName, route.FilePath)
+	})
+	return handlers, routes, total - len(routes), unmatched
+}
@@ -0,0 +1,359 @@
+package scanner
+
+import (
+	"go/ast"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
+)
+
+// majorVersionPattern matches the major version suffix of module paths, e.g., "v2"
+var majorVersionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+// typeQualifierPattern matches the package qualifiers in a type, e.g. "user" and "order" in "map[user.ID]*order.Order"
+var typeQualifierPattern = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)\.`)
+
+// fileImports returns the imports of a file, import path -> explicit name ("" for none)
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string, len(file.Imports))
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := ""
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[importPath] = name
+	}
+	return imports
+}
+
+// resolvePackageNames sets the name generated code refers to each scanned package by. Packages sharing a
+// name across directories, e.g. internal/admin/user and internal/public/user, get distinct names derived
+// from their directories ("adminuser", "publicuser"), except the one in outputDir which generated code
+// lives in. Provider types referring to them are requalified through the imports of their files;
+// references that can't be resolved to one of the packages are recorded as conflicts
+func resolvePackageNames(cfg *config.Config, result *ScanResult) {
+	// Directories of every scanned package by name, and the name of every package directory
+	dirsByName := make(map[string][]string)
+	nameByDir := make(map[string]string)
+	addPackage := func(pkg, filePath string) {
+		dir := filepath.Clean(filepath.Dir(filePath))
+		if _, seen := nameByDir[dir]; seen {
+			return
+		}
+		nameByDir[dir] = pkg
+		dirsByName[pkg] = append(dirsByName[pkg], dir)
+	}
+	for _, handler := range result.Handlers {
+		addPackage(handler.Package, handler.FilePath)
+	}
+	for _, route := range result.Routes {
+		addPackage(route.Package, route.FilePath)
+	}
+	for _, provider := range result.Providers {
+		addPackage(provider.Package, provider.FilePath)
+	}
+	for _, rpc := range result.RPCs {
+		addPackage(rpc.Package, rpc.FilePath)
+	}
+
+	importNames := make(map[string]string, len(nameByDir)) // dir -> import name
+	for name, dirs := range dirsByName {
+		sort.Strings(dirs)
+		for dir, importName := range packageAliases(name, dirs, filepath.Clean(cfg.Paths.OutputDir), dirsByName) {
+			importNames[dir] = importName
+		}
+	}
+	importNameOf := func(filePath string) string {
+		return importNames[filepath.Clean(filepath.Dir(filePath))]
+	}
+
+	// Names that couldn't be told apart, even using every parent directory
+	dirsByImportName := make(map[string][]string)
+	for dir, importName := range importNames {
+		dirsByImportName[importName] = append(dirsByImportName[importName], dir)
+	}
+	for importName, dirs := range dirsByImportName {
+		if len(dirs) < 2 {
+			continue
+		}
+		sort.Strings(dirs)
+		result.Conflicts = append(result.Conflicts, PackageConflict{
+			Package:   nameByDir[dirs[0]],
+			Dirs:      dirs,
+			Reference: importName,
+			FilePath:  dirs[0],
+		})
+	}
+
+	for i := range result.Handlers {
+		result.Handlers[i].ImportName = importNameOf(result.Handlers[i].FilePath)
+	}
+	for i := range result.Interfaces {
+		result.Interfaces[i].ImportName = importNameOf(result.Interfaces[i].FilePath)
+	}
+	for i := range result.Implementations {
+		result.Implementations[i].ImportName = importNameOf(result.Implementations[i].FilePath)
+	}
+	for i := range result.RPCs {
+		result.RPCs[i].ImportName = importNameOf(result.RPCs[i].FilePath)
+	}
+	for i := range result.Routes {
+		route := &result.Routes[i]
+		route.ImportName = importNameOf(route.FilePath)
+		if route.ImportName == route.Package {
+			continue
+		}
+		route.HandlerRef = handlerRef(route.ImportName, route.MethodName, route.IsFunction)
+		// Type-checked handler types are declared in the handler's package, e.g. "*user.Handler"
+		if route.HandlerType != "" {
+			route.HandlerType = strings.Replace(route.HandlerType, route.Package+".", route.ImportName+".", 1)
+		}
+	}
+
+	// Nothing to requalify unless a package is referred to by another name than its own
+	renamed := false
+	for dir, importName := range importNames {
+		renamed = renamed || importName != nameByDir[dir]
+	}
+
+	dirsByImportPath := make(map[string]string, len(nameByDir))
+	for dir := range nameByDir {
+		if importPath := cfg.PackageImportPath(dir); importPath != "" {
+			dirsByImportPath[importPath] = dir
+		}
+	}
+
+	for i := range result.Providers {
+		provider := &result.Providers[i]
+		provider.ImportName = importNameOf(provider.FilePath)
+		keyProviderTypes(cfg, provider, dirsByImportPath, nameByDir)
+		if !renamed {
+			continue
+		}
+
+		// Local names of the scanned packages in the provider's file. The package itself is only
+		// qualified for (Handler, error) providers, by its own name unless an import shadows it
+		localDirs := map[string][]string{provider.Package: {filepath.Clean(filepath.Dir(provider.FilePath))}}
+		shadowed := false
+		for importPath, name := range provider.Imports {
+			dir, scanned := dirsByImportPath[importPath]
+			if !scanned || name == "_" || name == "." {
+				continue
+			}
+			if name == "" {
+				name = nameByDir[dir]
+			}
+			if name == provider.Package && !shadowed {
+				localDirs[name] = nil
+				shadowed = true
+			}
+			localDirs[name] = appendUnique(localDirs[name], dir)
+		}
+
+		requalify := func(typeName string) string {
+			return typeQualifierPattern.ReplaceAllStringFunc(typeName, func(match string) string {
+				qualifier := strings.TrimSuffix(match, ".")
+				dirs := localDirs[qualifier]
+				switch {
+				case len(dirs) == 1:
+					return importNames[dirs[0]] + "."
+				case len(dirs) > 1:
+					result.Conflicts = append(result.Conflicts, PackageConflict{
+						Package:   qualifier,
+						Dirs:      dirs,
+						Reference: typeName,
+						Provider:  provider.Package + "." + provider.FunctionName,
+						FilePath:  provider.FilePath,
+						Line:      provider.Line,
+						Column:    provider.Column,
+					})
+				}
+				return match
+			})
+		}
+
+		provider.ReturnType = requalify(provider.ReturnType)
+		for j := range provider.Parameters {
+			provider.Parameters[j] = requalify(provider.Parameters[j])
+		}
+		for j := range provider.Results {
+			provider.Results[j] = requalify(provider.Results[j])
+		}
+	}
+}
+
+// keyProviderTypes sets the keys the provider's types are matched by across files, each package referred
+// to by its import path instead of the name the file imports it under, so "*usersvc.Service" in one file
+// and "*user.Service" in another are the same type. Must run before the types are requalified
+func keyProviderTypes(cfg *config.Config, provider *ProviderFunction, dirsByImportPath, nameByDir map[string]string) {
+	importPath := cfg.PackageImportPath(filepath.Dir(provider.FilePath))
+	if importPath == "" {
+		importPath = provider.ImportName
+	}
+
+	// Import paths by local name, the package itself is only qualified for (Handler, error) providers
+	localPaths := map[string]string{provider.Package: importPath}
+	for imported, name := range provider.Imports {
+		switch {
+		case name == "_" || name == ".":
+			continue
+		case name != "":
+		case dirsByImportPath[imported] != "":
+			name = nameByDir[dirsByImportPath[imported]]
+		default:
+			name = assumedPackageName(imported)
+		}
+		localPaths[name] = imported
+	}
+
+	key := func(typeName string) string {
+		keyed := typeQualifierPattern.ReplaceAllStringFunc(typeName, func(match string) string {
+			if imported, ok := localPaths[strings.TrimSuffix(match, ".")]; ok {
+				return imported + "."
+			}
+			return match
+		})
+		return QualifyType(importPath, keyed)
+	}
+
+	provider.importPath = importPath
+	provider.returnKey = key(provider.ReturnType)
+	provider.parameterKeys = make([]string, len(provider.Parameters))
+	for i, param := range provider.Parameters {
+		provider.parameterKeys[i] = key(param)
+	}
+}
+
+// assumedPackageName guesses the name of a package that wasn't scanned from its import path,
+// e.g. "github.com/acme/config" -> "config", "github.com/jackc/pgx/v5" -> "pgx"
+func assumedPackageName(importPath string) string {
+	name := path.Base(importPath)
+	if majorVersionPattern.MatchString(name) {
+		name = path.Base(path.Dir(importPath))
+	}
+	return name
+}
+
+// packageAliases names the packages sharing a name, one per directory. A single package keeps its name,
+// several are named after their directories, e.g. internal/admin/user -> "adminuser", using as many
+// trailing directories as it takes to tell them apart from each other and from other package names.
+// The package in outputDir keeps its name, since generated code is declared in it
+func packageAliases(name string, dirs []string, outputDir string, dirsByName map[string][]string) map[string]string {
+	aliases := make(map[string]string, len(dirs))
+	if len(dirs) == 1 {
+		aliases[dirs[0]] = name
+		return aliases
+	}
+
+	taken := func(alias string, dir string) bool {
+		if others, exists := dirsByName[alias]; exists && !(len(others) == 1 && others[0] == dir) {
+			return true
+		}
+		for otherDir, other := range aliases {
+			if otherDir != dir && other == alias {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, dir := range dirs {
+		if dir == outputDir {
+			aliases[dir] = name
+		}
+	}
+	for _, dir := range dirs {
+		if dir == outputDir {
+			continue
+		}
+		segments := strings.Split(filepath.ToSlash(dir), "/")
+		alias := ""
+		for n := 2; n <= len(segments)+1; n++ {
+			alias = aliasFromSegments(name, segments, n)
+			if !taken(alias, dir) {
+				break
+			}
+		}
+		aliases[dir] = alias
+	}
+	return aliases
+}
+
+// aliasFromSegments joins the last n-1 parent directories of a package with its name, lowercased
+// without separators, e.g. ("user", [internal admin user], 2) -> "adminuser"
+func aliasFromSegments(name string, segments []string, n int) string {
+	var b strings.Builder
+	parents := segments
+	if len(parents) > 0 && parents[len(parents)-1] == name {
+		parents = parents[:len(parents)-1]
+	}
+	if start := len(parents) - (n - 1); start > 0 {
+		parents = parents[start:]
+	}
+	for _, segment := range append(append([]string{}, parents...), name) {
+		for _, r := range strings.ToLower(segment) {
+			if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+				b.WriteRune(r)
+			}
+		}
+	}
+	alias := b.String()
+	if alias == "" || (alias[0] >= '0' && alias[0] <= '9') {
+		alias = "pkg" + alias
+	}
+	return alias
+}
+
+// handlerRef creates the reference a route's handler is registered with, e.g. "userHandler.GetUser"
+// for a handler method, or "health.GetHealth" for a package-level function
+func handlerRef(importName, functionName string, isFunction bool) string {
+	if isFunction {
+		return importName + "." + functionName
+	}
+	return handlerField(importName) + "." + functionName
+}
+
+// handlerField names the field the handler struct of a package is injected into, e.g. "userHandler"
+func handlerField(importName string) string {
+	return lowerFirst(importName) + "Handler"
+}
+
+// ServerField returns the field of the generated router and server the handler is bound to,
+// e.g. "userHandler", or "" for package-level functions
+func (h HandlerFunction) ServerField() string {
+	if h.IsFunction {
+		return ""
+	}
+	return handlerField(h.ImportName)
+}
+
+// HandlerFieldTypes groups the handler types of routes by the field they are bound to, in route order,
+// e.g. "userHandler" -> ["user.UserHandler"]. Only the first type of a field is injected
+func HandlerFieldTypes(routes []RouteMapping) map[string][]string {
+	fields := make(map[string][]string)
+	for _, route := range routes {
+		if route.IsFunction {
+			continue
+		}
+		field, _, _ := strings.Cut(route.HandlerRef, ".")
+		fields[field] = appendUnique(fields[field], route.ImportName+"."+route.HandlerName)
+	}
+	return fields
+}
+
+// lowerFirst lowercases the first letter of a name
+func lowerFirst(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
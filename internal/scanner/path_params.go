@@ -0,0 +1,43 @@
+package scanner
+
+import (
+	"go/ast"
+
+	"github.com/nkaewam/taskw/pkg/annotations"
+)
+
+// extractPathParams parses the @Param annotations of a handler documenting path parameters
+func (s *ASTScanner) extractPathParams(fn *ast.FuncDecl) []PathParam {
+	var params []PathParam
+	for _, param := range s.parseParams(fn) {
+		if param.In == "path" {
+			params = append(params, PathParam{Name: param.Name, Type: param.Type, Format: param.Format})
+		}
+	}
+	return params
+}
+
+// extractParams parses every @Param annotation of a handler
+func (s *ASTScanner) extractParams(fn *ast.FuncDecl) []Param {
+	var params []Param
+	for _, param := range s.parseParams(fn) {
+		params = append(params, Param{
+			Name:     param.Name,
+			In:       param.In,
+			Type:     param.Type,
+			Required: param.Required,
+		})
+	}
+	return params
+}
+
+// parseParams parses the @Param annotations of a handler
+func (s *ASTScanner) parseParams(fn *ast.FuncDecl) []annotations.Param {
+	var params []annotations.Param
+	for _, annotation := range annotations.Find(fn.Doc, "Param") {
+		if param, ok := annotations.ParseParam(annotation.Args); ok {
+			params = append(params, param)
+		}
+	}
+	return params
+}
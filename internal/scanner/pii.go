@@ -0,0 +1,139 @@
+package scanner
+
+import (
+	"go/ast"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nkaewam/taskw/pkg/annotations"
+)
+
+// defaultPIICategory is used for @PII annotations without categories
+const defaultPIICategory = "personal"
+
+var (
+	// @Success 200 {object} User "The user" and @Failure 400 {array} dto.Error
+	responseSchemaPattern = regexp.MustCompile(`(?i)^@(?:Success|Failure)\s+\S+\s+\{\w+\}\s+(\S+)`)
+	// @Param body body CreateUserRequest true "The user"
+	bodyParamPattern = regexp.MustCompile(`(?i)^@Param\s+\S+\s+body\s+(\S+)`)
+	// Field overrides of composed schemas, e.g. the "data=" of envelope.Response{data=User}
+	schemaFieldPattern = regexp.MustCompile(`\w+=`)
+	schemaTypePattern  = regexp.MustCompile(`[A-Za-z_][\w.]*`)
+)
+
+// extractPIIFields records the @PII fields of a struct and the struct types its fields reference
+// The annotation goes in the field's doc or line comment:
+// - Email string `json:"email"` // @PII email
+// - // @PII name, address
+// Annotations without categories are recorded as "personal"
+func (s *ASTScanner) extractPIIFields(ts *ast.TypeSpec, st *ast.StructType, pkg, filePath string, result *ScanResult) {
+	structName := pkg + "." + ts.Name.Name
+
+	var fieldTypes []string
+	for _, field := range st.Fields.List {
+		// Predeclared types stay unqualified and can't hold annotated fields
+		if fieldType := QualifyType(pkg, s.namedFieldType(field.Type)); strings.Contains(fieldType, ".") {
+			fieldTypes = appendUnique(fieldTypes, fieldType)
+		}
+
+		if !annotations.Has(field.Doc, "PII") && !annotations.Has(field.Comment, "PII") {
+			continue
+		}
+
+		categories := append(annotations.List(field.Doc, "PII"), annotations.List(field.Comment, "PII")...)
+		if len(categories) == 0 {
+			categories = []string{defaultPIICategory}
+		}
+
+		names := field.Names
+		if len(names) == 0 {
+			// Embedded fields are named after their type
+			names = []*ast.Ident{ast.NewIdent(strings.TrimPrefix(s.getTypeString(field.Type), "*"))}
+		}
+		for _, name := range names {
+			result.PIIFields = append(result.PIIFields, PIIField{
+				Struct:     structName,
+				Field:      name.Name,
+				JSONName:   jsonFieldName(name.Name, field.Tag),
+				Categories: categories,
+				FilePath:   filePath,
+				Line:       s.fset.Position(field.Pos()).Line,
+			})
+		}
+	}
+
+	if len(fieldTypes) > 0 {
+		result.Structs = append(result.Structs, StructFields{
+			Struct:     structName,
+			FieldTypes: fieldTypes,
+		})
+	}
+}
+
+// namedFieldType returns the named type a field holds, directly or through pointers, slices and maps
+// e.g., *Address, []Address and map[string]Address -> "Address"
+func (s *ASTScanner) namedFieldType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return s.getTypeString(t)
+	case *ast.StarExpr:
+		return s.namedFieldType(t.X)
+	case *ast.ArrayType:
+		return s.namedFieldType(t.Elt)
+	case *ast.MapType:
+		return s.namedFieldType(t.Value)
+	}
+	return ""
+}
+
+// jsonFieldName returns the name of a field in JSON bodies, "" when it is excluded with json:"-"
+func jsonFieldName(fieldName string, tag *ast.BasicLit) string {
+	if tag == nil {
+		return fieldName
+	}
+	value, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return fieldName
+	}
+
+	name, _, _ := strings.Cut(reflect.StructTag(value).Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return fieldName
+	}
+	return name
+}
+
+// extractSchemaTypes collects the types documented by the @Param body and @Success/@Failure annotations
+// of a handler, qualified with the handler package, e.g. "User" -> "user.User"
+// Composed schemas contribute every type, e.g. envelope.Response{data=[]User} -> envelope.Response, user.User
+func (s *ASTScanner) extractSchemaTypes(fn *ast.FuncDecl, pkg string) []string {
+	if fn.Doc == nil {
+		return nil
+	}
+
+	var schemas []string
+	for _, comment := range fn.Doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+
+		matches := responseSchemaPattern.FindStringSubmatch(text)
+		if matches == nil {
+			matches = bodyParamPattern.FindStringSubmatch(text)
+		}
+		if matches == nil {
+			continue
+		}
+
+		for _, typeName := range schemaTypePattern.FindAllString(schemaFieldPattern.ReplaceAllString(matches[1], ""), -1) {
+			schemas = appendUnique(schemas, QualifyType(pkg, typeName))
+		}
+	}
+
+	return schemas
+}
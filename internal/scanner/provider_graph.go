@@ -0,0 +1,295 @@
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FindProviderCycles returns every dependency cycle between providers, each starting at its
+// alphabetically first provider, e.g. [ProvideA, ProvideB, ProvideC] for A -> B -> C -> A
+// Parameters without a scanned provider are ignored, they are resolved outside taskw
+func FindProviderCycles(providers []ProviderFunction) [][]ProviderFunction {
+	byType := make(map[string]int)
+	for i, provider := range providers {
+		if _, exists := byType[provider.ReturnKey()]; !exists {
+			byType[provider.ReturnKey()] = i
+		}
+	}
+
+	// Visit providers in a stable order so reports don't change between runs
+	order := make([]int, len(providers))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return providerName(providers[order[i]]) < providerName(providers[order[j]])
+	})
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[int]int)
+	seen := make(map[string]bool)
+	var stack []int
+	var cycles [][]ProviderFunction
+
+	var visit func(index int)
+	visit = func(index int) {
+		state[index] = visiting
+		stack = append(stack, index)
+
+		provider := providers[index]
+		for i := range provider.Parameters {
+			dep, ok := byType[provider.ParameterKey(i)]
+			if !ok {
+				continue
+			}
+
+			switch state[dep] {
+			case visiting:
+				start := 0
+				for stack[start] != dep {
+					start++
+				}
+				cycle := rotateCycle(providers, stack[start:])
+				if key := cycleKey(cycle); !seen[key] {
+					seen[key] = true
+					cycles = append(cycles, cycle)
+				}
+			case 0:
+				visit(dep)
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[index] = visited
+	}
+
+	for _, index := range order {
+		if state[index] == 0 {
+			visit(index)
+		}
+	}
+
+	return cycles
+}
+
+// ProviderInit is a step of the provider initialization order
+type ProviderInit struct {
+	Provider     ProviderFunction
+	Dependencies []ProviderFunction // Scanned providers of the parameters, in parameter order
+	External     []string           // Qualified parameter types without a scanned provider
+	Transitive   int                // Number of providers this provider pulls in, directly or not
+}
+
+// OrderProviders sorts providers so every provider comes after the providers of its parameters
+// Providers are visited by name, so the order is stable between runs
+func OrderProviders(providers []ProviderFunction) ([]ProviderInit, error) {
+	if cycles := FindProviderCycles(providers); len(cycles) > 0 {
+		names := make([]string, 0, len(cycles[0])+1)
+		for _, provider := range cycles[0] {
+			names = append(names, providerName(provider))
+		}
+		names = append(names, names[0])
+		return nil, fmt.Errorf("dependency cycle %s", strings.Join(names, " → "))
+	}
+
+	byType := make(map[string]int)
+	for i, provider := range providers {
+		if _, exists := byType[provider.ReturnKey()]; !exists {
+			byType[provider.ReturnKey()] = i
+		}
+	}
+
+	roots := make([]int, len(providers))
+	for i := range roots {
+		roots[i] = i
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		return providerName(providers[roots[i]]) < providerName(providers[roots[j]])
+	})
+
+	visited := make(map[int]bool)
+	pulled := make(map[int]map[int]bool) // Provider index -> transitive dependency indexes
+	var order []ProviderInit
+
+	var visit func(index int)
+	visit = func(index int) {
+		if visited[index] {
+			return
+		}
+		visited[index] = true
+
+		provider := providers[index]
+		step := ProviderInit{Provider: provider}
+		pulled[index] = make(map[int]bool)
+		for i, param := range provider.Parameters {
+			dep, ok := byType[provider.ParameterKey(i)]
+			if !ok {
+				step.External = append(step.External, QualifyType(provider.ImportName, param))
+				continue
+			}
+
+			visit(dep)
+			step.Dependencies = append(step.Dependencies, providers[dep])
+			pulled[index][dep] = true
+			for transitive := range pulled[dep] {
+				pulled[index][transitive] = true
+			}
+		}
+		step.Transitive = len(pulled[index])
+
+		order = append(order, step)
+	}
+
+	for _, index := range roots {
+		visit(index)
+	}
+
+	return order, nil
+}
+
+// ReturnKey identifies the type the provider returns across files, with every package referred to by
+// its import path, e.g. "*github.com/acme/api/internal/user.Service". Providers that weren't scanned
+// fall back to the type qualified with ImportName
+func (p ProviderFunction) ReturnKey() string {
+	if p.returnKey != "" {
+		return p.returnKey
+	}
+	return QualifyType(p.ImportName, p.ReturnType)
+}
+
+// ParameterKey identifies the type of the i-th parameter across files, like ReturnKey
+func (p ProviderFunction) ParameterKey(i int) string {
+	if i < len(p.parameterKeys) {
+		return p.parameterKeys[i]
+	}
+	return QualifyType(p.ImportName, p.Parameters[i])
+}
+
+// PackageTypeKey identifies a type declared in the provider's package, e.g. "Handler", like ReturnKey
+func (p ProviderFunction) PackageTypeKey(typeName string) string {
+	if p.importPath != "" {
+		return QualifyType(p.importPath, typeName)
+	}
+	return QualifyType(p.ImportName, typeName)
+}
+
+// QualifyType prefixes unqualified named types with the package they were declared in
+// e.g., ("user", "*Service") -> "*user.Service", ("user", "*config.Config") -> "*config.Config",
+// ("user", "<-chan Event") -> "<-chan user.Event", ("user", "Cache[string, User]") -> "user.Cache[string, user.User]".
+// Map, func, interface and struct types are left as written
+func QualifyType(pkg, typeName string) string {
+	prefix := ""
+	base := typeName
+	for {
+		switch {
+		case strings.HasPrefix(base, "*"), strings.HasPrefix(base, "..."):
+			n := 1
+			if base[0] == '.' {
+				n = 3
+			}
+			prefix += base[:n]
+			base = base[n:]
+			continue
+		case strings.HasPrefix(base, "["):
+			// Slices and arrays, e.g. []T or [16]T
+			end := strings.Index(base, "]")
+			if end < 0 {
+				return typeName
+			}
+			prefix += base[:end+1]
+			base = base[end+1:]
+			continue
+		case strings.HasPrefix(base, "chan "), strings.HasPrefix(base, "chan<- "), strings.HasPrefix(base, "<-chan "):
+			end := strings.Index(base, " ")
+			prefix += base[:end+1]
+			base = base[end+1:]
+			continue
+		}
+		break
+	}
+
+	if strings.HasPrefix(base, "map[") || strings.HasPrefix(base, "func(") || strings.HasPrefix(base, "interface{") ||
+		strings.HasPrefix(base, "struct{") || strings.HasPrefix(base, "(") || isPredeclaredType(base) {
+		return typeName
+	}
+
+	// Generic instantiations qualify the type and each of its type arguments
+	if open := strings.Index(base, "["); open > 0 && strings.HasSuffix(base, "]") {
+		args := splitTypeList(base[open+1 : len(base)-1])
+		for i, arg := range args {
+			args[i] = QualifyType(pkg, arg)
+		}
+		return prefix + QualifyType(pkg, base[:open]) + "[" + strings.Join(args, ", ") + "]"
+	}
+
+	if strings.Contains(base, ".") {
+		return typeName
+	}
+	return prefix + pkg + "." + base
+}
+
+// splitTypeList splits a comma separated list of types at the top level, e.g. "string, map[K]V" -> ["string", "map[K]V"]
+func splitTypeList(list string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range list {
+		switch r {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(list[start:]))
+}
+
+// isPredeclaredType checks if a type name is one of Go's predeclared types
+func isPredeclaredType(name string) bool {
+	switch name {
+	case "bool", "string", "error", "any", "byte", "rune",
+		"int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+		"float32", "float64", "complex64", "complex128":
+		return true
+	}
+	return false
+}
+
+// providerName returns the package qualified name of a provider, e.g. "user.ProvideService"
+func providerName(provider ProviderFunction) string {
+	return provider.ImportName + "." + provider.FunctionName
+}
+
+// rotateCycle starts a cycle at its alphabetically first provider
+func rotateCycle(providers []ProviderFunction, indexes []int) []ProviderFunction {
+	first := 0
+	for i := range indexes {
+		if providerName(providers[indexes[i]]) < providerName(providers[indexes[first]]) {
+			first = i
+		}
+	}
+
+	cycle := make([]ProviderFunction, 0, len(indexes))
+	for i := range indexes {
+		cycle = append(cycle, providers[indexes[(first+i)%len(indexes)]])
+	}
+	return cycle
+}
+
+// cycleKey identifies a cycle regardless of where it was entered
+func cycleKey(cycle []ProviderFunction) string {
+	names := make([]string, len(cycle))
+	for i, provider := range cycle {
+		names[i] = providerName(provider)
+	}
+	return strings.Join(names, ",")
+}
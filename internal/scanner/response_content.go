@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"fmt"
+	"go/ast"
+	"regexp"
+	"strings"
+
+	"github.com/nkaewam/taskw/pkg/annotations"
+)
+
+// contentTypeAliases maps the swag MIME type aliases, plus event-stream, to their MIME types
+var contentTypeAliases = map[string]string{
+	"json":                  "application/json",
+	"xml":                   "text/xml",
+	"plain":                 "text/plain",
+	"html":                  "text/html",
+	"mpfd":                  "multipart/form-data",
+	"x-www-form-urlencoded": "application/x-www-form-urlencoded",
+	"json-api":              "application/vnd.api+json",
+	"json-stream":           "application/x-json-stream",
+	"octet-stream":          "application/octet-stream",
+	"png":                   "image/png",
+	"jpeg":                  "image/jpeg",
+	"gif":                   "image/gif",
+	"event-stream":          "text/event-stream",
+}
+
+// ContentType resolves a MIME type alias such as "json" or "event-stream"
+// Full MIME types are returned as is, unknown aliases are reported as not ok
+func ContentType(value string) (string, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if strings.Contains(value, "/") {
+		return value, true
+	}
+	mimeType, ok := contentTypeAliases[value]
+	return mimeType, ok
+}
+
+var (
+	responseAnnotationPattern = regexp.MustCompile(`(?i)^@(Success|Failure)\s+(\S+)\s+(.+)$`)
+	// The content type list follows the description: {object} User "The user" [json, xml]
+	responseContentPattern = regexp.MustCompile(`^.*?"([^"]*)"\s*\[([^\]]+)\]\s*$`)
+)
+
+// extractResponseContents parses the content types of @Success and @Failure annotations
+// Only responses declaring content types are returned, the others keep what swag generates:
+// - @Success 200 {object} User "The user" [json, xml]
+// - @Success 200 {string} string "Event stream" [event-stream]
+// - @Failure 400,422 {object} Error "Invalid input" [json, application/problem+json]
+// Unknown aliases and lists without a description are reported as scan errors
+func (s *ASTScanner) extractResponseContents(fn *ast.FuncDecl, filePath string, result *ScanResult) []ResponseContent {
+	if fn.Doc == nil {
+		return nil
+	}
+
+	var responses []ResponseContent
+	for _, comment := range fn.Doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+		matches := responseAnnotationPattern.FindStringSubmatch(text)
+		if matches == nil {
+			continue
+		}
+
+		reportError := func(message string) {
+			position := s.fset.Position(comment.Pos())
+			result.Errors = append(result.Errors, ScanError{
+				FilePath: filePath,
+				Line:     position.Line,
+				Column:   position.Column,
+				Message:  fmt.Sprintf("invalid @%s on %s: %s", matches[1], fn.Name.Name, message),
+				Type:     "annotation",
+			})
+		}
+
+		content := responseContentPattern.FindStringSubmatch(matches[3])
+		if content == nil {
+			// A trailing list without a description would be read by swag as part of the type
+			if strings.HasSuffix(matches[3], "]") && !strings.Contains(matches[3], `"`) {
+				reportError(`content types need a description before them, e.g. {object} User "OK" [json, xml]`)
+			}
+			continue
+		}
+
+		var contentTypes []string
+		valid := true
+		for _, value := range strings.Split(content[2], ",") {
+			mimeType, ok := ContentType(value)
+			if !ok {
+				reportError(fmt.Sprintf("unknown content type %q (use a MIME type or one of json, xml, plain, html, event-stream, ...)", strings.TrimSpace(value)))
+				valid = false
+				break
+			}
+			contentTypes = appendUnique(contentTypes, mimeType)
+		}
+		if !valid {
+			continue
+		}
+
+		for _, status := range strings.Split(matches[2], ",") {
+			responses = append(responses, ResponseContent{
+				Status:       strings.TrimSpace(status),
+				Description:  content[1],
+				ContentTypes: contentTypes,
+			})
+		}
+	}
+
+	return responses
+}
+
+// extractProduces resolves the @Produce annotations of a handler to MIME types
+func (s *ASTScanner) extractProduces(fn *ast.FuncDecl) []string {
+	var produces []string
+	for _, value := range annotations.List(fn.Doc, "Produce") {
+		if mimeType, ok := ContentType(value); ok {
+			produces = appendUnique(produces, mimeType)
+		}
+	}
+	return produces
+}
+
+// appendUnique appends a value unless it is already present
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
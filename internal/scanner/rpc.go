@@ -0,0 +1,120 @@
+package scanner
+
+import (
+	"fmt"
+	"go/ast"
+	"path"
+	"strings"
+
+	"github.com/nkaewam/taskw/pkg/annotations"
+)
+
+// rpcSignature describes the signature of the gRPC service methods @RPC accepts
+const rpcSignature = "func(ctx context.Context, req *pb.Request) (*pb.Response, error)"
+
+// extractRPC checks if a method annotated with @RPC is a gRPC service method, served over HTTP at
+// the path of its @Router annotation:
+//
+//	// @RPC
+//	// @Router /users/{id} [get]
+//	func (s *Server) GetUser(ctx context.Context, req *userv1.GetUserRequest) (*userv1.User, error)
+//
+// @RPC methods that can't be served are reported as warnings
+func (s *ASTScanner) extractRPC(fn *ast.FuncDecl, pkg, filePath string, result *ScanResult) *RPCMethod {
+	found := annotations.Find(fn.Doc, "RPC")
+	if len(found) == 0 {
+		return nil
+	}
+
+	position := s.fset.Position(found[0].Pos)
+	warn := func(message string) *RPCMethod {
+		result.Errors = append(result.Errors, ScanError{
+			FilePath: filePath,
+			Line:     position.Line,
+			Column:   position.Column,
+			Message:  message,
+			Type:     "warning",
+		})
+		return nil
+	}
+
+	if fn.Recv == nil || len(fn.Recv.List) != 1 {
+		return warn(fmt.Sprintf("@RPC on %s is ignored, gRPC service methods are declared on the type implementing the service", fn.Name.Name))
+	}
+	serverName := s.getReceiverTypeName(fn.Recv.List[0])
+	if serverName == "" {
+		return nil
+	}
+
+	params, results := s.getFieldTypes(fn.Type.Params), s.getFieldTypes(fn.Type.Results)
+	if len(params) != 2 || params[0] != "context.Context" || !strings.HasPrefix(params[1], "*") ||
+		len(results) != 2 || !strings.HasPrefix(results[0], "*") || results[1] != "error" {
+		return warn(fmt.Sprintf("@RPC on %s.%s is ignored, gRPC service methods have the signature %s", serverName, fn.Name.Name, rpcSignature))
+	}
+
+	// Malformed @Router lines are reported by checkAnnotations
+	for _, annotation := range annotations.Find(fn.Doc, "Router") {
+		router, ok := annotations.ParseRouter(annotation.Args)
+		if !ok || !s.isValidHTTPMethod(router.Method) {
+			continue
+		}
+
+		return &RPCMethod{
+			MethodName:   fn.Name.Name,
+			ServerName:   serverName,
+			Package:      pkg,
+			Path:         router.Path,
+			HTTPMethod:   router.Method,
+			PathParams:   routePathParams(router.Path),
+			RequestType:  s.qualifiedFieldType(fn.Type.Params.List[len(fn.Type.Params.List)-1].Type, pkg),
+			ResponseType: s.qualifiedFieldType(fn.Type.Results.List[0].Type, pkg),
+			Summary:      annotations.Text(fn.Doc, "Summary"),
+			FilePath:     filePath,
+			Line:         position.Line,
+			Column:       position.Column,
+		}
+	}
+
+	if !annotations.Has(fn.Doc, "Router") {
+		return warn(fmt.Sprintf("@RPC on %s.%s is ignored, it needs a @Router annotation giving its HTTP path, e.g. @Router /users/{id} [get]", serverName, fn.Name.Name))
+	}
+	return nil
+}
+
+// routePathParams returns the names of the parameters of a route path in order, in either
+// {param} or :param syntax, e.g. "/users/{id}/posts/:postID" -> ["id", "postID"]
+func routePathParams(routePath string) []string {
+	var params []string
+	for _, segment := range strings.Split(routePath, "/") {
+		switch {
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			params = append(params, strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}"))
+		case strings.HasPrefix(segment, ":"):
+			params = append(params, strings.TrimSuffix(strings.TrimPrefix(segment, ":"), "?"))
+		}
+	}
+	return params
+}
+
+// ImportPath returns the import path of a package qualifier of the method's request or response type,
+// e.g. "userv1" -> "github.com/acme/api/gen/user/v1". Packages imported without a name are matched by
+// their last element, or the last two joined for versioned paths, e.g. ".../user/v1" -> "userv1".
+// Returns "" when the file doesn't import the package
+func (m RPCMethod) ImportPath(qualifier string) string {
+	for importPath, name := range m.Imports {
+		if name == qualifier {
+			return importPath
+		}
+	}
+	for importPath, name := range m.Imports {
+		if name != "" {
+			continue
+		}
+		base := path.Base(importPath)
+		parent := path.Base(path.Dir(importPath))
+		if base == qualifier || parent+base == qualifier || strings.ReplaceAll(base, "-", "") == qualifier {
+			return importPath
+		}
+	}
+	return ""
+}
@@ -1,30 +1,76 @@
 package scanner
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	goscanner "go/scanner"
+	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/nkaewam/taskw/internal/config"
 )
 
 // Scanner is the main hybrid scanner that combines file filtering with AST parsing
 type Scanner struct {
-	config     *config.Config
-	astScanner *ASTScanner
-	fileFilter *FileFilter
+	config       *config.Config
+	astScanner   *ASTScanner
+	fileFilter   *FileFilter
+	typeResolver *TypeResolver
+
+	cacheOnce   sync.Once
+	cache       *scanCache      // nil when scanning.cache is disabled
+	changedDirs map[string]bool // Packages with changed files when scanning.Changed is set, nil otherwise
+	observer    ScanObserver    // nil when nothing reports progress
+}
+
+// ScanObserver is notified as a scan progresses, e.g. to draw a progress bar
+type ScanObserver interface {
+	// ScanStarted is called once the candidate files of every scanned directory are found
+	ScanStarted(files int)
+	// FileScanned is called after each file is parsed or read from the cache, with the time it took
+	// It may be called from several goroutines at once
+	FileScanned(filePath string, elapsed time.Duration)
+	// ScanFinished is called once every file is scanned
+	ScanFinished()
 }
 
 // NewScanner creates a new hybrid scanner instance
 func NewScanner(cfg *config.Config) *Scanner {
 	return &Scanner{
-		config:     cfg,
-		astScanner: NewASTScanner(),
-		fileFilter: NewFileFilter(),
+		config:       cfg,
+		astScanner:   NewASTScanner(cfg),
+		fileFilter:   NewFileFilter(cfg),
+		typeResolver: NewTypeResolver(cfg),
 	}
 }
 
-// ScanAll scans all configured directories for handlers, routes, and providers
-func (s *Scanner) ScanAll() (*ScanResult, error) {
+// SetObserver reports the progress of the following scans to observer
+func (s *Scanner) SetObserver(observer ScanObserver) {
+	s.observer = observer
+}
+
+// ScanAll scans all configured directories for handlers, routes, and providers.
+// Once ctx is done the scan stops, returning ctx's error
+func (s *Scanner) ScanAll(ctx context.Context) (*ScanResult, error) {
+	result, err := s.scanDirectories(ctx, s.config.Paths.ScanDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyRouteOverlay(result); err != nil {
+		return nil, err
+	}
+
+	recordScanErrors(result.Errors)
+	return result, nil
+}
+
+// scanDirectories scans and merges several directories, then names the packages found so that
+// packages sharing a name across directories can be told apart
+func (s *Scanner) scanDirectories(ctx context.Context, directories []string) (*ScanResult, error) {
 	result := &ScanResult{
 		Handlers:  []HandlerFunction{},
 		Routes:    []RouteMapping{},
@@ -32,9 +78,24 @@ func (s *Scanner) ScanAll() (*ScanResult, error) {
 		Errors:    []ScanError{},
 	}
 
-	// Scan all configured directories
-	for _, dir := range s.config.Paths.ScanDirs {
-		dirResult, err := s.ScanDirectory(dir)
+	// Find the files of every directory first, so progress is reported against the total
+	candidates := make([]candidateFiles, len(directories))
+	total := 0
+	for i, dir := range directories {
+		found, err := s.findCandidateFiles(ctx, dir)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning directory %s: %w", dir, err)
+		}
+		candidates[i] = found
+		total += len(found.files)
+	}
+	if s.observer != nil {
+		s.observer.ScanStarted(total)
+		defer s.observer.ScanFinished()
+	}
+
+	for i, dir := range directories {
+		dirResult, err := s.scanCandidateFiles(ctx, dir, candidates[i])
 		if err != nil {
 			return nil, fmt.Errorf("error scanning directory %s: %w", dir, err)
 		}
@@ -42,61 +103,138 @@ func (s *Scanner) ScanAll() (*ScanResult, error) {
 		// Merge results
 		result.Handlers = append(result.Handlers, dirResult.Handlers...)
 		result.Routes = append(result.Routes, dirResult.Routes...)
+		result.RPCs = append(result.RPCs, dirResult.RPCs...)
 		result.Providers = append(result.Providers, dirResult.Providers...)
+		result.Interfaces = append(result.Interfaces, dirResult.Interfaces...)
+		result.Implementations = append(result.Implementations, dirResult.Implementations...)
+		result.HandlerDefaults = append(result.HandlerDefaults, dirResult.HandlerDefaults...)
+		result.PIIFields = append(result.PIIFields, dirResult.PIIFields...)
+		result.Structs = append(result.Structs, dirResult.Structs...)
+		result.Models = append(result.Models, dirResult.Models...)
+		result.PackageVars = append(result.PackageVars, dirResult.PackageVars...)
 		result.Errors = append(result.Errors, dirResult.Errors...)
 	}
 
+	if s.cache != nil {
+		s.cache.save()
+	}
+
+	resolvePackageNames(s.config, result)
+	recordScanErrors(result.Errors)
 	return result, nil
 }
 
+// applyRouteOverlay merges route metadata maintained outside the Go sources, if configured
+func (s *Scanner) applyRouteOverlay(result *ScanResult) error {
+	if s.config.Paths.RoutesOverlay == "" {
+		return nil
+	}
+
+	overlay, err := LoadRouteOverlay(s.config.Root, s.config.Paths.RoutesOverlay)
+	if err != nil {
+		return err
+	}
+	overlay.Apply(result)
+	return nil
+}
+
+// candidateFiles are the files of a directory to parse, and the ones skipped with a reason
+type candidateFiles struct {
+	files   []string
+	skipped []ScanError
+}
+
 // ScanDirectory scans a single directory using the hybrid approach
-func (s *Scanner) ScanDirectory(directory string) (*ScanResult, error) {
+func (s *Scanner) ScanDirectory(ctx context.Context, directory string) (*ScanResult, error) {
+	candidates, err := s.findCandidateFiles(ctx, directory)
+	if err != nil {
+		return nil, err
+	}
+	return s.scanCandidateFiles(ctx, directory, candidates)
+}
+
+// findCandidateFiles finds the files of a directory to parse
+func (s *Scanner) findCandidateFiles(ctx context.Context, directory string) (candidateFiles, error) {
 	// Step 1: Use file filter to find candidate files
-	candidateFiles, err := s.fileFilter.FindCandidateFiles(directory)
+	files, skipped, err := s.fileFilter.FindCandidateFiles(ctx, directory)
 	if err != nil {
-		return nil, fmt.Errorf("error finding candidate files in %s: %w", directory, err)
+		return candidateFiles{}, fmt.Errorf("error finding candidate files in %s: %w", directory, err)
 	}
 
-	// Step 2: Parse candidate files with AST scanner (parallel processing)
-	return s.scanFilesParallel(candidateFiles), nil
+	// Step 2: Skip files excluded from the target build by their build constraints
+	files, constraintErrors := matchBuildConstraints(buildContext(s.config), s.config.Root, files)
+	return candidateFiles{files: files, skipped: append(skipped, constraintErrors...)}, nil
 }
 
-// ScanRoutes specifically scans for handlers and routes (for backwards compatibility)
-func (s *Scanner) ScanRoutes(directories []string) ([]HandlerFunction, []RouteMapping, error) {
-	var allHandlers []HandlerFunction
-	var allRoutes []RouteMapping
+// scanCandidateFiles parses the candidate files of a directory
+func (s *Scanner) scanCandidateFiles(ctx context.Context, directory string, candidates candidateFiles) (*ScanResult, error) {
+	// Step 3: Parse candidate files with AST scanner (parallel processing)
+	result, err := s.scanFilesParallel(ctx, candidates.files)
+	if err != nil {
+		return nil, err
+	}
+	result.Errors = append(result.Errors, candidates.skipped...)
 
-	for _, dir := range directories {
-		result, err := s.ScanDirectory(dir)
-		if err != nil {
-			return nil, nil, err
+	// Step 4: Apply struct-level defaults now that every file of each package has been seen
+	applyHandlerDefaults(result)
+	associateImplementations(result)
+
+	// Step 5: Replace guessed types with type-checked ones, if configured
+	if s.config.ScanMode() == config.ScanModePackages {
+		if err := s.typeResolver.Resolve(ctx, directory, result); err != nil {
+			return nil, err
 		}
+	}
 
-		allHandlers = append(allHandlers, result.Handlers...)
-		allRoutes = append(allRoutes, result.Routes...)
+	// Step 6: Fail on malformed annotations instead of continuing past them, if configured
+	if s.config.Scanning.MalformedAnnotations == config.AnnotationsError {
+		strictAnnotations(result)
 	}
 
-	return allHandlers, allRoutes, nil
+	return result, nil
 }
 
-// ScanProviders specifically scans for provider functions
-func (s *Scanner) ScanProviders(directories []string) ([]ProviderFunction, error) {
-	var allProviders []ProviderFunction
+// ScanRoutes specifically scans for handlers and routes (for backwards compatibility)
+func (s *Scanner) ScanRoutes(ctx context.Context, directories []string) ([]HandlerFunction, []RouteMapping, error) {
+	result, err := s.scanDirectories(ctx, directories)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	for _, dir := range directories {
-		result, err := s.ScanDirectory(dir)
-		if err != nil {
-			return nil, err
-		}
+	// Unmatched overlay entries are reported by ScanAll
+	overlaid := &ScanResult{Routes: result.Routes}
+	if err := s.applyRouteOverlay(overlaid); err != nil {
+		return nil, nil, err
+	}
 
-		allProviders = append(allProviders, result.Providers...)
+	return result.Handlers, overlaid.Routes, nil
+}
+
+// ScanProviders specifically scans for provider functions
+func (s *Scanner) ScanProviders(ctx context.Context, directories []string) ([]ProviderFunction, error) {
+	result, err := s.scanDirectories(ctx, directories)
+	if err != nil {
+		return nil, err
 	}
 
-	return allProviders, nil
+	return result.Providers, nil
 }
 
-// scanFilesParallel processes multiple files in parallel for better performance
-func (s *Scanner) scanFilesParallel(files []string) *ScanResult {
+// scanFilesParallel processes multiple files in parallel for better performance. Files not started
+// by the time ctx is done are left out and ctx's error is returned
+func (s *Scanner) scanFilesParallel(ctx context.Context, files []string) (*ScanResult, error) {
+	s.cacheOnce.Do(func() {
+		if s.config.Scanning.Cache {
+			s.cache = loadScanCache(s.config)
+		}
+		if s.cache != nil && s.config.Scanning.Changed != nil {
+			s.changedDirs = make(map[string]bool)
+			for _, file := range s.config.Scanning.Changed {
+				s.changedDirs[filepath.Dir(filepath.Clean(file))] = true
+			}
+		}
+	})
+
 	result := &ScanResult{
 		Handlers:  []HandlerFunction{},
 		Routes:    []RouteMapping{},
@@ -119,17 +257,20 @@ func (s *Scanner) scanFilesParallel(files []string) *ScanResult {
 			// Acquire semaphore
 			sem <- struct{}{}
 			defer func() { <-sem }()
+			if ctx.Err() != nil {
+				return
+			}
 
 			// Scan the file
-			fileResult, err := s.astScanner.ScanFile(filePath)
+			started := time.Now()
+			fileResult, err := s.scanFile(filePath)
+			if s.observer != nil {
+				s.observer.FileScanned(filePath, time.Since(started))
+			}
 			if err != nil {
 				// Add error to results but continue processing
 				mu.Lock()
-				result.Errors = append(result.Errors, ScanError{
-					FilePath: filePath,
-					Message:  err.Error(),
-					Type:     "parse_error",
-				})
+				result.Errors = append(result.Errors, parseError(filePath, err))
 				mu.Unlock()
 				return
 			}
@@ -138,14 +279,130 @@ func (s *Scanner) scanFilesParallel(files []string) *ScanResult {
 			mu.Lock()
 			result.Handlers = append(result.Handlers, fileResult.Handlers...)
 			result.Routes = append(result.Routes, fileResult.Routes...)
+			result.RPCs = append(result.RPCs, fileResult.RPCs...)
 			result.Providers = append(result.Providers, fileResult.Providers...)
+			result.Interfaces = append(result.Interfaces, fileResult.Interfaces...)
+			result.Implementations = append(result.Implementations, fileResult.Implementations...)
+			result.HandlerDefaults = append(result.HandlerDefaults, fileResult.HandlerDefaults...)
+			result.PIIFields = append(result.PIIFields, fileResult.PIIFields...)
+			result.Structs = append(result.Structs, fileResult.Structs...)
+			result.Models = append(result.Models, fileResult.Models...)
+			result.PackageVars = append(result.PackageVars, fileResult.PackageVars...)
 			result.Errors = append(result.Errors, fileResult.Errors...)
 			mu.Unlock()
 		}(file)
 	}
 
 	wg.Wait()
-	return result
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// scanFile scans a single file, reusing its cached result when the content is unchanged
+func (s *Scanner) scanFile(filePath string) (*ScanResult, error) {
+	if s.cache == nil {
+		return s.astScanner.ScanFile(filePath)
+	}
+
+	// Files of packages without changes aren't even read when their cached result was scanned from
+	// their content at the revision, which is then also their current content
+	if s.changedDirs != nil && !s.changedDirs[filepath.Dir(filepath.Clean(filePath))] {
+		if blob, ok := s.config.Scanning.Revision[filepath.ToSlash(filepath.Clean(filePath))]; ok {
+			if cached, ok := s.cache.lookupBlob(filePath, blob); ok {
+				return cached, nil
+			}
+		}
+	}
+
+	hash, err := hashFile(s.config.ResolvePath(filePath))
+	if err != nil {
+		return s.astScanner.ScanFile(filePath)
+	}
+	if cached, ok := s.cache.lookup(filePath, hash.sha256); ok {
+		return cached, nil
+	}
+
+	result, err := s.astScanner.ScanFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.store(filePath, hash, result)
+	return result, nil
+}
+
+// parseError reports a file that failed to parse at the position of its first syntax error
+func parseError(filePath string, err error) ScanError {
+	scanErr := ScanError{
+		FilePath: filePath,
+		Message:  err.Error(),
+		Type:     "parse_error",
+	}
+	var syntaxErrors goscanner.ErrorList
+	if errors.As(err, &syntaxErrors) && len(syntaxErrors) > 0 {
+		scanErr.Line = syntaxErrors[0].Pos.Line
+		scanErr.Column = syntaxErrors[0].Pos.Column
+		scanErr.Message = syntaxErrors[0].Msg
+	}
+	return scanErr
+}
+
+// applyHandlerDefaults applies struct-level @RouterPrefix and @TagsDefault annotations to the routes of each handler type
+func applyHandlerDefaults(result *ScanResult) {
+	if len(result.HandlerDefaults) == 0 {
+		return
+	}
+
+	// Handler types are identified by their package directory and type name
+	defaultsByType := make(map[string]HandlerDefaults)
+	for _, defaults := range result.HandlerDefaults {
+		defaultsByType[filepath.Join(filepath.Dir(defaults.FilePath), defaults.StructName)] = defaults
+	}
+
+	for i := range result.Routes {
+		route := &result.Routes[i]
+		defaults, exists := defaultsByType[filepath.Join(filepath.Dir(route.FilePath), route.HandlerName)]
+		if !exists {
+			continue
+		}
+
+		if defaults.RouterPrefix != "" {
+			route.Path = joinRoutePath(defaults.RouterPrefix, route.Path)
+		}
+
+		// Method-level @Tags take precedence over struct-level defaults
+		if len(route.Tags) == 0 {
+			route.Tags = append([]string{}, defaults.Tags...)
+		}
+	}
+}
+
+// associateImplementations associates handler implementations with the Handler interface of their
+// package when they are declared in different files, files are associated one at a time while parsing
+func associateImplementations(result *ScanResult) {
+	handlerInterfaces := make(map[string]string) // Package directory -> Handler interface name
+	for _, iface := range result.Interfaces {
+		if iface.InterfaceName == "Handler" {
+			handlerInterfaces[filepath.Dir(iface.FilePath)] = iface.InterfaceName
+		}
+	}
+
+	for i := range result.Implementations {
+		impl := &result.Implementations[i]
+		if impl.InterfaceName == "" {
+			impl.InterfaceName = handlerInterfaces[filepath.Dir(impl.FilePath)]
+		}
+	}
+}
+
+// joinRoutePath joins a route prefix and a route path, e.g. "/api/v1/users" + "/{id}"
+func joinRoutePath(prefix, path string) string {
+	prefix = "/" + strings.Trim(prefix, "/")
+	if path == "" || path == "/" {
+		return prefix
+	}
+	return prefix + "/" + strings.TrimPrefix(path, "/")
 }
 
 // GetStatistics returns scanning statistics for debugging
@@ -154,7 +411,7 @@ func (s *Scanner) GetStatistics(result *ScanResult) ScanStatistics {
 		HandlersFound:   len(result.Handlers),
 		RoutesFound:     len(result.Routes),
 		ProvidersFound:  len(result.Providers),
-		ErrorsFound:     len(result.Errors),
+		ErrorsFound:     len(result.Failures()),
 		PackagesScanned: s.countUniquePackages(result),
 	}
 }
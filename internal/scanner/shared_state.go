@@ -0,0 +1,139 @@
+package scanner
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// lockMethods are the methods taking a lock, handlers calling one are assumed to guard their writes
+var lockMethods = map[string]bool{
+	"Lock":     true,
+	"RLock":    true,
+	"TryLock":  true,
+	"TryRLock": true,
+}
+
+// extractPackageVars records the package-level variables of a file, requests are served concurrently
+// so handlers writing to them race with each other
+// Variables of sync and sync/atomic types synchronize themselves and are left out
+func (s *ASTScanner) extractPackageVars(node *ast.File, pkg, filePath string, result *ScanResult) {
+	for _, decl := range node.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok || s.isSynchronizedType(vs) {
+				continue
+			}
+			for _, name := range vs.Names {
+				if name.Name == "_" {
+					continue
+				}
+				position := s.fset.Position(name.Pos())
+				result.PackageVars = append(result.PackageVars, PackageVar{
+					Name:     name.Name,
+					Package:  pkg,
+					FilePath: filePath,
+					Line:     position.Line,
+					Column:   position.Column,
+				})
+			}
+		}
+	}
+}
+
+// isSynchronizedType checks if variables are declared or initialized with a sync or sync/atomic type,
+// e.g. var mu sync.Mutex or var hits = &atomic.Int64{}
+func (s *ASTScanner) isSynchronizedType(vs *ast.ValueSpec) bool {
+	typeName := s.getTypeString(vs.Type)
+	if vs.Type == nil && len(vs.Values) == 1 {
+		value := vs.Values[0]
+		if unary, ok := value.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+			value = unary.X
+		}
+		if lit, ok := value.(*ast.CompositeLit); ok {
+			typeName = s.getTypeString(lit.Type)
+		}
+	}
+	typeName = strings.TrimLeft(typeName, "*")
+	return strings.HasPrefix(typeName, "sync.") || strings.HasPrefix(typeName, "atomic.")
+}
+
+// sharedWrites returns the writes of a handler to variables it doesn't declare itself, e.g. cache[id] = user
+// or hits++, which are package-level variables unless they come from an import
+// Handlers taking a lock or using sync/atomic are assumed to synchronize and report nothing
+func (s *ASTScanner) sharedWrites(fn *ast.FuncDecl, fileScope *ast.Scope) []SharedWrite {
+	if fn.Body == nil {
+		return nil
+	}
+
+	var writes []SharedWrite
+	synchronized := false
+	record := func(expr ast.Expr) {
+		ident := writtenIdent(expr)
+		// Identifiers resolved within the file are either package-level or declared by the handler
+		if ident == nil || ident.Name == "_" || (ident.Obj != nil && fileScope.Lookup(ident.Name) != ident.Obj) {
+			return
+		}
+		position := s.fset.Position(ident.Pos())
+		writes = append(writes, SharedWrite{Variable: ident.Name, Line: position.Line, Column: position.Column})
+	}
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.AssignStmt:
+			if x.Tok != token.DEFINE {
+				for _, lhs := range x.Lhs {
+					record(lhs)
+				}
+			}
+		case *ast.IncDecStmt:
+			record(x.X)
+		case *ast.RangeStmt:
+			if x.Tok == token.ASSIGN {
+				record(x.Key)
+				record(x.Value)
+			}
+		case *ast.CallExpr:
+			switch fun := x.Fun.(type) {
+			case *ast.Ident:
+				if (fun.Name == "delete" || fun.Name == "clear") && fun.Obj == nil && len(x.Args) > 0 {
+					record(x.Args[0])
+				}
+			case *ast.SelectorExpr:
+				if pkg, ok := fun.X.(*ast.Ident); lockMethods[fun.Sel.Name] || (ok && pkg.Name == "atomic" && pkg.Obj == nil) {
+					synchronized = true
+				}
+			}
+		}
+		return true
+	})
+
+	if synchronized {
+		return nil
+	}
+	return writes
+}
+
+// writtenIdent returns the variable an assignment target writes to, e.g. cache for cache[id].Name
+func writtenIdent(expr ast.Expr) *ast.Ident {
+	for {
+		switch x := expr.(type) {
+		case *ast.Ident:
+			return x
+		case *ast.IndexExpr:
+			expr = x.X
+		case *ast.SelectorExpr:
+			expr = x.X
+		case *ast.StarExpr:
+			expr = x.X
+		case *ast.ParenExpr:
+			expr = x.X
+		default:
+			return nil
+		}
+	}
+}
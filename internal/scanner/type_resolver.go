@@ -0,0 +1,367 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"golang.org/x/tools/go/packages"
+)
+
+// TypeResolver refines AST scan results with type-checked packages loaded through go/packages.
+// The per-file parser only sees the syntax of a single file, so it can't resolve renamed imports,
+// aliases, generics or which struct satisfies a handler interface declared in another file
+type TypeResolver struct {
+	config *config.Config
+}
+
+// NewTypeResolver creates a new type resolver
+func NewTypeResolver(cfg *config.Config) *TypeResolver {
+	return &TypeResolver{config: cfg}
+}
+
+// Resolve type-checks the packages under directory and rewrites the provider signatures,
+// handler types and interface implementations found in them with their resolved forms.
+// Packages that fail to type-check keep their AST results and are reported as scan errors
+func (r *TypeResolver) Resolve(ctx context.Context, directory string, result *ScanResult) error {
+	cfg := &packages.Config{
+		Context: ctx, // Cancelling ctx stops go list
+		Dir:     r.config.Root,
+		// Dependencies are type-checked from source, export data depends on the Go version that wrote it
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+			packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+		// Type-check the same build the AST scan matched files against
+		BuildFlags: buildFlags(r.config),
+		Env:        buildEnv(r.config),
+	}
+	pkgs, err := packages.Load(cfg, packagePattern(directory))
+	if err != nil {
+		return fmt.Errorf("error loading packages in %s: %w", directory, err)
+	}
+
+	// Generated files in output_dir are stale until the next generate, their errors are expected
+	outputDir, _ := filepath.Abs(r.config.ResolvePath(r.config.Paths.OutputDir))
+
+	// Scan results identify packages by the directory of their files
+	byDir := make(map[string]*packages.Package)
+	for _, pkg := range pkgs {
+		if len(pkg.GoFiles) == 0 || pkg.Types == nil {
+			continue
+		}
+		dir := filepath.Dir(pkg.GoFiles[0])
+		if len(pkg.Errors) > 0 {
+			switch {
+			case dir == outputDir:
+			case usesCgo(pkg):
+				// cgo packages need a C toolchain to type check, a missing one is not an error in the scanned code
+				result.Errors = append(result.Errors, typeError(r.config.Root, dir, pkg.Errors[0], "skipped",
+					fmt.Sprintf("type checking cgo package %s skipped, using AST results: %s", pkg.PkgPath, pkg.Errors[0].Msg)))
+			default:
+				result.Errors = append(result.Errors, typeError(r.config.Root, dir, pkg.Errors[0], "type_error",
+					fmt.Sprintf("type checking %s failed, using AST results: %s", pkg.PkgPath, pkg.Errors[0].Msg)))
+			}
+			continue
+		}
+		byDir[dir] = pkg
+	}
+
+	lookup := func(filePath string) *packages.Package {
+		absPath, err := filepath.Abs(r.config.ResolvePath(filePath))
+		if err != nil {
+			return nil
+		}
+		return byDir[filepath.Dir(absPath)]
+	}
+
+	for i := range result.Providers {
+		if pkg := lookup(result.Providers[i].FilePath); pkg != nil {
+			r.resolveProvider(&result.Providers[i], pkg.Types)
+		}
+	}
+	r.resolveHandlers(result, lookup)
+
+	return nil
+}
+
+// resolveProvider replaces the parameter and result types of a provider with their type-checked forms
+func (r *TypeResolver) resolveProvider(provider *ProviderFunction, pkg *types.Package) {
+	fn, ok := pkg.Scope().Lookup(provider.FunctionName).(*types.Func)
+	if !ok {
+		return
+	}
+	sig := fn.Type().(*types.Signature)
+	if sig.Results().Len() == 0 {
+		return
+	}
+
+	qualifier := localQualifier(pkg, provider.Imports)
+
+	var parameters []string
+	for i := 0; i < sig.Params().Len(); i++ {
+		paramType := sig.Params().At(i).Type()
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			parameters = append(parameters, "..."+typeString(paramType.(*types.Slice).Elem(), qualifier))
+			continue
+		}
+		parameters = append(parameters, typeString(paramType, qualifier))
+	}
+
+	var results []string
+	for i := 0; i < sig.Results().Len(); i++ {
+		resultType := sig.Results().At(i).Type()
+		if isCleanupSignature(resultType) {
+			results = append(results, "func()")
+			continue
+		}
+		results = append(results, typeString(resultType, qualifier))
+	}
+
+	provider.Parameters = parameters
+	provider.Results = results
+	provider.ReturnType = results[0]
+	provider.ReturnsError = len(results) > 1 && results[len(results)-1] == "error"
+	provider.HasCleanup = len(results) > 1 && results[1] == "func()"
+
+	// Same qualification of (Handler, error) providers as the AST scanner
+	if provider.ReturnType == "Handler" && provider.ReturnsError {
+		provider.ReturnType = pkg.Name() + ".Handler"
+	}
+}
+
+// resolveHandlers matches handler receivers against the handler interfaces of their package with
+// types.Implements, replacing the per-file association of the AST scanner, and records the type
+// each route's handler is injected as
+func (r *TypeResolver) resolveHandlers(result *ScanResult, lookup func(filePath string) *packages.Package) {
+	// Interface-based handlers and implementations of resolved packages are rebuilt below
+	var handlers []HandlerFunction
+	for _, handler := range result.Handlers {
+		if handler.IsInterfaceBased && lookup(handler.FilePath) != nil {
+			continue
+		}
+		handlers = append(handlers, handler)
+	}
+	var implementations []HandlerImplementation
+	for _, impl := range result.Implementations {
+		if lookup(impl.FilePath) == nil {
+			implementations = append(implementations, impl)
+		}
+	}
+
+	// implementedInterface returns the handler interface a receiver satisfies, if any
+	implementedInterface := func(pkg *packages.Package, receiver string) (HandlerInterface, bool) {
+		named, ok := lookupNamed(pkg.Types, receiver)
+		if !ok {
+			return HandlerInterface{}, false
+		}
+		for _, iface := range result.Interfaces {
+			if iface.InterfaceName == receiver || lookup(iface.FilePath) != pkg {
+				continue
+			}
+			ifaceNamed, ok := lookupNamed(pkg.Types, iface.InterfaceName)
+			if !ok {
+				continue
+			}
+			ifaceType, ok := ifaceNamed.Underlying().(*types.Interface)
+			if ok && types.Implements(types.NewPointer(named), ifaceType) {
+				return iface, true
+			}
+		}
+		return HandlerInterface{}, false
+	}
+
+	var interfaceHandlers []HandlerFunction
+	implemented := make(map[string]bool)
+	for _, handler := range handlers {
+		if handler.IsFunction || handler.IsInterfaceBased {
+			continue
+		}
+		pkg := lookup(handler.FilePath)
+		if pkg == nil {
+			continue
+		}
+		iface, ok := implementedInterface(pkg, handler.HandlerName)
+		if !ok {
+			continue
+		}
+
+		key := pkg.PkgPath + "." + handler.HandlerName
+		if !implemented[key] {
+			implemented[key] = true
+			implementations = append(implementations, HandlerImplementation{
+				StructName:    handler.HandlerName,
+				Package:       handler.Package,
+				InterfaceName: iface.InterfaceName,
+				Methods:       iface.Methods,
+				FilePath:      handler.FilePath,
+			})
+		}
+
+		interfaceHandlers = append(interfaceHandlers, HandlerFunction{
+			FunctionName:     handler.FunctionName,
+			Package:          handler.Package,
+			HandlerName:      iface.InterfaceName,
+			ImplementerName:  handler.HandlerName,
+			ReturnType:       handler.ReturnType,
+			FilePath:         handler.FilePath,
+			IsInterfaceBased: true,
+		})
+	}
+	result.Handlers = append(handlers, interfaceHandlers...)
+	result.Implementations = implementations
+
+	// Routes of interface implementations are injected as the interface, others as their receiver type
+	for i := range result.Routes {
+		route := &result.Routes[i]
+		if route.IsFunction {
+			continue
+		}
+		pkg := lookup(route.FilePath)
+		if pkg == nil {
+			continue
+		}
+		if iface, ok := implementedInterface(pkg, route.HandlerName); ok {
+			route.HandlerType = pkg.Name + "." + iface.InterfaceName
+			continue
+		}
+		named, ok := lookupNamed(pkg.Types, route.HandlerName)
+		if !ok {
+			continue
+		}
+		method, _, _ := types.LookupFieldOrMethod(types.NewPointer(named), false, pkg.Types, route.MethodName)
+		if fn, ok := method.(*types.Func); ok {
+			route.HandlerType = typeString(fn.Type().(*types.Signature).Recv().Type(), packageQualifier)
+		}
+	}
+}
+
+// packagePattern returns the go/packages pattern matching every package under a directory
+func packagePattern(directory string) string {
+	dir := filepath.ToSlash(filepath.Clean(directory))
+	if filepath.IsAbs(directory) || strings.HasPrefix(dir, "../") {
+		return dir + "/..."
+	}
+	if dir == "." {
+		return "./..."
+	}
+	return "./" + dir + "/..."
+}
+
+// lookupNamed returns the named type declared in a package under name
+func lookupNamed(pkg *types.Package, name string) (*types.Named, bool) {
+	typeName, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, false
+	}
+	named, ok := types.Unalias(typeName.Type()).(*types.Named)
+	return named, ok
+}
+
+// typeError reports a package that failed to type check at the position of its first error,
+// e.g. "internal/user/service.go:12:9" relative to the project root, or at its directory if the error has none
+func typeError(root, dir string, pkgErr packages.Error, errType, message string) ScanError {
+	scanErr := ScanError{FilePath: dir, Message: message, Type: errType}
+
+	// Positions are formatted as "file:line:column", "file:line" or "file"
+	parts := strings.Split(pkgErr.Pos, ":")
+	var numbers []int
+	for len(parts) > 1 && len(numbers) < 2 {
+		n, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+			break
+		}
+		numbers = append([]int{n}, numbers...)
+		parts = parts[:len(parts)-1]
+	}
+	if len(numbers) == 0 {
+		return scanErr
+	}
+
+	// Scan results are relative to the project root, the working directory without one
+	scanErr.FilePath = strings.Join(parts, ":")
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+	if rel, err := filepath.Rel(root, scanErr.FilePath); err == nil && !strings.HasPrefix(rel, "..") {
+		scanErr.FilePath = rel
+	}
+	scanErr.Line = numbers[0]
+	if len(numbers) > 1 {
+		scanErr.Column = numbers[1]
+	}
+	return scanErr
+}
+
+// usesCgo checks if any file of a package imports "C"
+func usesCgo(pkg *packages.Package) bool {
+	for _, file := range pkg.GoFiles {
+		if importsC(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// localQualifier renders types of the package itself unqualified, as written in its own files,
+// and types of other packages with the name the file imports them under, e.g. "Service" and
+// "*user.Repository", or "*adminuser.Service" for a package imported as adminuser
+func localQualifier(pkg *types.Package, imports map[string]string) types.Qualifier {
+	return func(other *types.Package) string {
+		if other == pkg {
+			return ""
+		}
+		if name := imports[other.Path()]; name != "" && name != "_" && name != "." {
+			return name
+		}
+		return other.Name()
+	}
+}
+
+// packageQualifier renders every type with its package name, e.g. "*user.Handler"
+func packageQualifier(other *types.Package) string {
+	return other.Name()
+}
+
+// typeString renders a type with aliases resolved, e.g. "map[string][]*user.User". Func types are
+// rendered without parameter names like the AST scanner does, e.g. "func(context.Context) error"
+func typeString(t types.Type, qualifier types.Qualifier) string {
+	t = types.Unalias(t)
+	sig, ok := t.(*types.Signature)
+	if !ok {
+		return types.TypeString(t, qualifier)
+	}
+
+	params := make([]string, sig.Params().Len())
+	for i := range params {
+		paramType := sig.Params().At(i).Type()
+		if sig.Variadic() && i == len(params)-1 {
+			params[i] = "..." + typeString(paramType.(*types.Slice).Elem(), qualifier)
+			continue
+		}
+		params[i] = typeString(paramType, qualifier)
+	}
+	results := make([]string, sig.Results().Len())
+	for i := range results {
+		results[i] = typeString(sig.Results().At(i).Type(), qualifier)
+	}
+
+	signature := "func(" + strings.Join(params, ", ") + ")"
+	switch len(results) {
+	case 0:
+		return signature
+	case 1:
+		return signature + " " + results[0]
+	default:
+		return signature + " (" + strings.Join(results, ", ") + ")"
+	}
+}
+
+// isCleanupSignature checks if a type is a Wire cleanup function: func()
+func isCleanupSignature(t types.Type) bool {
+	sig, ok := types.Unalias(t).(*types.Signature)
+	return ok && sig.Params().Len() == 0 && sig.Results().Len() == 0
+}
@@ -1,38 +1,224 @@
 package scanner
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
 // HandlerFunction represents a Fiber handler function found in the codebase
 type HandlerFunction struct {
 	FunctionName     string // e.g., "GetUser"
 	Package          string // e.g., "user"
+	ImportName       string // Name generated code refers to the package by, e.g. "adminuser" when another scanned package is named user
 	HandlerName      string // e.g., "UserHandler" (interface name if using interface pattern)
 	ImplementerName  string // e.g., "HandlerImpl" (only for interface pattern)
 	ReturnType       string // Always "error" for Fiber handlers
 	FilePath         string // Path to the file containing this handler
+	Line             int    // Line of the function name
+	Column           int    // Column of the function name
 	IsInterfaceBased bool   // true if this handler uses interface + implementation pattern
+	IsFunction       bool   // true for package-level functions, registered directly instead of via a handler struct
+
+	SharedWrites []SharedWrite // Writes to variables the handler doesn't declare, unless it takes a lock
+}
+
+// SharedWrite is a write of a handler to a variable it doesn't declare, e.g. cache[id] = user
+type SharedWrite struct {
+	Variable string // e.g., "cache"
+	Line     int
+	Column   int
 }
 
 // RouteMapping represents a @Router annotation mapping
 type RouteMapping struct {
-	MethodName string // e.g., "GetUser"
-	Path       string // e.g., "/users/:id"
-	HTTPMethod string // e.g., "GET", "POST", "PUT", "DELETE"
-	HandlerRef string // e.g., "userHandler.GetUser"
-	Package    string // Package name for import resolution
+	MethodName  string            // e.g., "GetUser"
+	Path        string            // e.g., "/users/:id"
+	RouterPath  string            // Path as written in @Router, before @RouterPrefix is applied
+	HTTPMethod  string            // e.g., "GET", "POST", "PUT", "DELETE"
+	HandlerRef  string            // e.g., "userHandler.GetUser", or "health.GetHealth" for package-level functions
+	HandlerName string            // e.g., "Handler" (receiver type of the handler method, empty for functions)
+	HandlerType string            // Type-checked type the handler is injected as, e.g. "*user.Handler" (packages scanning mode only)
+	IsFunction  bool              // true if the handler is a package-level function
+	Package     string            // Package name for import resolution
+	ImportName  string            // Name generated code refers to the package by, differs from Package when it is shared
+	Middlewares []string          // e.g., ["auth", "audit"] from @Middleware annotations
+	Tags        []string          // e.g., ["users"] from @Tags or inherited @TagsDefault
+	Scrub       []string          // e.g., ["password", "token"] from @Scrub, redacted from recorded fixtures
+	SLOs        []SLOTarget       // Latency budgets from @SLO annotations
+	Produces    []string          // MIME types from @Produce, e.g. ["application/json", "text/xml"]
+	Responses   []ResponseContent // @Success/@Failure responses declaring content types
+	PII         []string          // Personal data categories from @PII, e.g. ["email", "name"]
+	Schemas     []string          // Types of the @Param body and @Success/@Failure schemas, e.g. ["user.User"]
+	PathParams  []PathParam       // Path parameters documented with @Param, in declaration order
+	Params      []Param           // Every parameter documented with @Param, in declaration order
+	Summary     string            // e.g., "Get a user" from @Summary
+	Description string            // Text of the @Description annotations, one line each
+	OperationID string            // e.g., "getUser" from @ID, the operationId of the swagger spec
+	Deprecated  bool              // true if the route is marked with @Deprecated
+	FilePath    string            // Path to the file containing the handler
+	Line        int               // Line of the @Router annotation
+	Column      int               // Column of the @Router annotation
+}
+
+// RouteID identifies the route in telemetry: its @ID, or the package, handler and method name
+// without one, e.g. "user.Handler.GetUser", or "health.GetHealth" for package-level functions
+func (r RouteMapping) RouteID() string {
+	if r.OperationID != "" {
+		return r.OperationID
+	}
+	if r.IsFunction {
+		return r.Package + "." + r.MethodName
+	}
+	return r.Package + "." + r.HandlerName + "." + r.MethodName
+}
+
+// DocLines returns the @Summary and @Description lines of the route, for generated comments
+func (r RouteMapping) DocLines() []string {
+	var lines []string
+	if r.Summary != "" {
+		lines = append(lines, r.Summary)
+	}
+	if r.Description != "" {
+		lines = append(lines, strings.Split(r.Description, "\n")...)
+	}
+	return lines
+}
+
+// RPCMethod represents a gRPC service method annotated with @RPC, served over HTTP by the generated
+// gateway at the path of its @Router annotation, e.g.
+// func (s *Server) GetUser(ctx context.Context, req *userv1.GetUserRequest) (*userv1.User, error)
+type RPCMethod struct {
+	MethodName   string   // e.g., "GetUser"
+	ServerName   string   // Receiver type implementing the service, e.g. "Server"
+	Package      string   // e.g., "user"
+	ImportName   string   // Name generated code refers to the package by, differs from Package when it is shared
+	Path         string   // e.g., "/users/{id}"
+	HTTPMethod   string   // e.g., "GET"
+	PathParams   []string // Parameters of the path in order, e.g. ["id"]
+	RequestType  string   // e.g., "*userv1.GetUserRequest"
+	ResponseType string   // e.g., "*userv1.User"
+	Summary      string   // e.g., "Get a user" from @Summary
+	FilePath     string   // Path to the file containing the method
+	Line         int      // Line of the @RPC annotation
+	Column       int      // Column of the @RPC annotation
+
+	Imports map[string]string // Imports of the declaring file, import path -> explicit name ("" for none)
+
+	importPath    string   // Import path of the package, ImportName outside the module
+	returnKey     string   // ReturnType keyed by import paths, see ReturnKey
+	parameterKeys []string // Parameters keyed by import paths, see ParameterKey
+}
+
+// ResponseContent represents a @Success or @Failure response declaring its content types, e.g.
+// @Success 200 {object} User "The user" [json, xml]
+type ResponseContent struct {
+	Status       string   // e.g., "200" or "default"
+	Description  string   // e.g., "The user"
+	ContentTypes []string // MIME types, e.g. ["application/json", "text/xml"]
+}
+
+// Param represents a parameter documented with @Param, e.g.
+// @Param limit query int false "Page size"
+type Param struct {
+	Name     string // e.g., "limit"
+	In       string // e.g., "query", "path", "header", "body" or "formData"
+	Type     string // e.g., "int", or the schema of a body parameter such as "user.CreateRequest"
+	Required bool
+}
+
+// PathParam represents a path parameter documented with @Param, e.g.
+// @Param id path string true "User ID" format(uuid)
+type PathParam struct {
+	Name   string // e.g., "id"
+	Type   string // e.g., "string" or "uuid.UUID"
+	Format string // e.g., "uuid", from a format(...) attribute
+}
+
+// IsUUID checks if the parameter is documented as a UUID, by type or format
+func (p PathParam) IsUUID() bool {
+	switch strings.ToLower(p.Type) {
+	case "uuid", "uuid.uuid":
+		return true
+	}
+	return strings.EqualFold(p.Format, "uuid")
+}
+
+// SLOTarget represents a latency budget from an @SLO annotation, e.g., p99=200ms
+type SLOTarget struct {
+	Percentile string        // e.g., "p99"
+	Quantile   float64       // e.g., 0.99
+	Threshold  time.Duration // e.g., 200ms
 }
 
 // ProviderFunction represents a Wire provider function
 type ProviderFunction struct {
 	FunctionName string   // e.g., "ProvideUserService"
+	BaseName     string   // Function name without the provider prefix, e.g., "UserService"
 	Package      string   // e.g., "user"
+	ImportName   string   // Name generated code refers to the package by, differs from Package when it is shared
 	ReturnType   string   // e.g., "*UserService"
+	Results      []string // Full return tuple, e.g., ["*DB", "func()", "error"]
+	ReturnsError bool     // true if the last result is error, e.g. (T, error) or (T, func(), error)
+	HasCleanup   bool     // true if the provider returns a cleanup function, e.g. (T, func(), error)
 	Parameters   []string // Parameter types for dependency resolution
 	FilePath     string   // Path to the file containing this provider
+	Line         int      // Line of the provider name
+	Column       int      // Column of the provider name
+	ChaosWrap    bool     // true if annotated with @ChaosWrap, wrapped with failure injection in chaos builds
+	Envs         []string // Environments from "@Provider env=dev,test", empty when generated for every environment
+	Doc          string   // First paragraph of the doc comment without annotations, e.g. "ProvideUserService creates the user service"
+
+	Imports map[string]string // Imports of the declaring file, import path -> explicit name ("" for none)
+
+	importPath    string   // Import path of the package, ImportName outside the module
+	returnKey     string   // ReturnType keyed by import paths, see ReturnKey
+	parameterKeys []string // Parameters keyed by import paths, see ParameterKey
+}
+
+// InEnv reports whether the provider is generated for an environment, untagged providers are for every one
+func (p ProviderFunction) InEnv(env string) bool {
+	return len(p.Envs) == 0 || slices.Contains(p.Envs, env)
+}
+
+// sharesEnv reports whether two providers are generated together in some environment
+func (p ProviderFunction) sharesEnv(other ProviderFunction) bool {
+	if len(p.Envs) == 0 || len(other.Envs) == 0 {
+		return true
+	}
+	for _, env := range p.Envs {
+		if slices.Contains(other.Envs, env) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProvidersForEnv returns the providers generated for an environment and the number left out
+func ProvidersForEnv(providers []ProviderFunction, env string) ([]ProviderFunction, int) {
+	var kept []ProviderFunction
+	for _, provider := range providers {
+		if provider.InEnv(env) {
+			kept = append(kept, provider)
+		}
+	}
+	return kept, len(providers) - len(kept)
+}
+
+// DocLines returns the lines of the provider's doc comment, for generated comments
+func (p ProviderFunction) DocLines() []string {
+	if p.Doc == "" {
+		return nil
+	}
+	return strings.Split(p.Doc, "\n")
 }
 
 // HandlerInterface represents a handler interface definition
 type HandlerInterface struct {
 	InterfaceName string   // e.g., "Handler"
 	Package       string   // e.g., "user"
+	ImportName    string   // Name generated code refers to the package by, differs from Package when it is shared
 	Methods       []string // e.g., ["GetUser", "CreateUser"]
 	FilePath      string   // Path to the file containing this interface
 }
@@ -41,25 +227,146 @@ type HandlerInterface struct {
 type HandlerImplementation struct {
 	StructName    string   // e.g., "HandlerImpl"
 	Package       string   // e.g., "user"
+	ImportName    string   // Name generated code refers to the package by, differs from Package when it is shared
 	InterfaceName string   // e.g., "Handler" (the interface it implements)
 	Methods       []string // e.g., ["GetUser", "CreateUser"]
 	FilePath      string   // Path to the file containing this struct
 }
 
+// HandlerDefaults represents struct-level annotations inherited by all routes of a handler type
+type HandlerDefaults struct {
+	StructName   string   // e.g., "Handler"
+	Package      string   // e.g., "user"
+	RouterPrefix string   // e.g., "/api/v1/users" from @RouterPrefix
+	Tags         []string // e.g., ["users"] from @TagsDefault
+	FilePath     string   // Path to the file containing the type declaration
+}
+
+// PIIField represents a struct field annotated with @PII, e.g.
+// Email string `json:"email"` // @PII email
+type PIIField struct {
+	Struct     string   // Qualified struct name, e.g., "user.User"
+	Field      string   // e.g., "Email"
+	JSONName   string   // Name in request/response bodies, e.g., "email"
+	Categories []string // e.g., ["email"]
+	FilePath   string   // Path to the file containing the struct
+	Line       int      // Line of the field declaration
+}
+
+// StructFields records the struct types referenced by the fields of a scanned struct,
+// so personal data in nested request/response types can be traced back to endpoints
+type StructFields struct {
+	Struct     string   // Qualified struct name, e.g., "user.User"
+	FieldTypes []string // Qualified field types, e.g., ["user.Address"]
+}
+
+// Model is a package-level named type of the scanned sources, e.g.
+// type User struct { Address *models.Address `json:"address"` }
+type Model struct {
+	Name       string       // Qualified name, e.g. "user.User"
+	Underlying string       // Qualified underlying type of non-struct types, e.g. "string" or "[]user.Role", empty for structs
+	Fields     []ModelField // Struct fields as they appear in JSON bodies, in declaration order
+	FilePath   string
+}
+
+// ModelField is a struct field of a Model
+type ModelField struct {
+	Name     string // Name in JSON bodies, e.g. "address", empty for promoted embedded fields
+	Type     string // Qualified field type, e.g. "*models.Address", "[]string" or "map[string]user.Role"
+	Embedded bool   // true for embedded structs whose fields are promoted into the parent
+	Required bool   // true if tagged binding:"required" or validate:"required"
+}
+
+// PackageVar is a package-level variable handlers may share across concurrent requests, e.g.
+// var cache = map[string]*User{}
+type PackageVar struct {
+	Name     string // e.g., "cache"
+	Package  string // e.g., "user"
+	FilePath string // Path to the file declaring the variable
+	Line     int
+	Column   int
+}
+
 // ScanResult aggregates all scanning results
 type ScanResult struct {
 	Handlers        []HandlerFunction
 	Routes          []RouteMapping
+	RPCs            []RPCMethod // gRPC methods served over HTTP by the gateway
 	Providers       []ProviderFunction
 	Interfaces      []HandlerInterface      // Handler interfaces found
 	Implementations []HandlerImplementation // Handler implementations found
+	HandlerDefaults []HandlerDefaults       // Struct-level route defaults found
+	PIIFields       []PIIField              // Struct fields annotated with @PII
+	Structs         []StructFields          // Field types of scanned structs
+	Models          []Model                 // Package-level named types, for completing swagger schemas
+	PackageVars     []PackageVar            // Package-level variables not of sync or sync/atomic types
+	Conflicts       []PackageConflict       // References to a shared package name that can't be resolved
 	Errors          []ScanError
 }
 
+// PackageConflict is a package name shared by several scanned packages that can't be told apart,
+// either because no distinct import names can be derived from their directories, or because a
+// provider type refers to the name without saying which of the packages it means
+type PackageConflict struct {
+	Package   string   // Shared package name, e.g. "user"
+	Dirs      []string // Directories of the packages sharing the name
+	Reference string   // Type as scanned, e.g. "*user.Service", or the import name they would share
+	Provider  string   // e.g., "order.ProvideService", empty if no distinct import names can be derived
+	FilePath  string
+	Line      int
+	Column    int
+}
+
 // ScanError represents an error encountered during scanning
 type ScanError struct {
 	FilePath string
 	Line     int
+	Column   int
 	Message  string
-	Type     string // "handler", "route", "provider"
+	Type     string // "parse_error", "type_error", "annotation", "overlay", "warning" or "skipped"
+
+	Suggestion string `json:",omitempty"` // Corrected annotation line for warnings, e.g. "@Router /users [get]"
+}
+
+// Position returns where the error was found, e.g. "internal/user/handler.go:12:2"
+func (e ScanError) Position() string {
+	return formatPosition(e.FilePath, e.Line, e.Column)
+}
+
+// Skipped checks if the error reports a file or package left out on purpose rather than a failure,
+// e.g. a cgo file while cgo is disabled for the target platform
+func (e ScanError) Skipped() bool {
+	return e.Type == "skipped"
+}
+
+// Warning checks if the error reports an annotation the scan continued past, e.g. a @Router with a
+// misspelled method, rather than a failure
+func (e ScanError) Warning() bool {
+	return e.Type == "warning"
+}
+
+// Failures returns the errors that are neither skipped files or packages nor warnings
+func (r *ScanResult) Failures() []ScanError {
+	var failures []ScanError
+	for _, e := range r.Errors {
+		if !e.Skipped() && !e.Warning() {
+			failures = append(failures, e)
+		}
+	}
+	return failures
+}
+
+// formatPosition renders a source position the way the go command does, "file:line:column",
+// leaving out the parts that are unknown, e.g. "file:line" or just "file"
+func formatPosition(filePath string, line, column int) string {
+	switch {
+	case filePath == "":
+		return ""
+	case line == 0:
+		return filePath
+	case column == 0:
+		return fmt.Sprintf("%s:%d", filePath, line)
+	default:
+		return fmt.Sprintf("%s:%d:%d", filePath, line, column)
+	}
 }
@@ -2,7 +2,14 @@ package scanner
 
 import (
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
 	"strings"
+
+	"github.com/nkaewam/taskw/internal/config"
 )
 
 // ValidationResult contains validation errors and warnings
@@ -17,8 +24,9 @@ type ValidationError struct {
 	Message  string
 	FilePath string
 	Line     int
-	Handler  *HandlerFunction
-	Route    *RouteMapping
+	Column   int
+	Handler  *HandlerFunction `json:"-"`
+	Route    *RouteMapping    `json:"-"`
 }
 
 // ValidationWarning represents a validation warning that might cause issues
@@ -26,15 +34,39 @@ type ValidationWarning struct {
 	Type     string
 	Message  string
 	FilePath string
-	Handler  *HandlerFunction
+	Line     int
+	Column   int
+	Handler  *HandlerFunction `json:"-"`
+}
+
+// String renders the error the way compilers do, e.g.
+// "internal/user/handler.go:12:1: duplicate_route: Duplicate route found: GET /users"
+func (e ValidationError) String() string {
+	if position := formatPosition(e.FilePath, e.Line, e.Column); position != "" {
+		return fmt.Sprintf("%s: %s: %s", position, e.Type, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Type, e.Message)
+}
+
+// String renders the warning the way compilers do, e.g.
+// "internal/user/cache.go:5:6: unused_provider: Provider user.ProvideCache returns *user.Cache, ..."
+func (w ValidationWarning) String() string {
+	if position := formatPosition(w.FilePath, w.Line, w.Column); position != "" {
+		return fmt.Sprintf("%s: %s: %s", position, w.Type, w.Message)
+	}
+	return fmt.Sprintf("%s: %s", w.Type, w.Message)
 }
 
 // Validator validates scan results for common issues
-type Validator struct{}
+type Validator struct {
+	conventions config.Conventions
+}
 
-// NewValidator creates a new validator instance
-func NewValidator() *Validator {
-	return &Validator{}
+// NewValidator creates a new validator instance checking names against the given conventions
+func NewValidator(conventions config.Conventions) *Validator {
+	return &Validator{
+		conventions: conventions,
+	}
 }
 
 // ValidateScanResult validates handlers, routes, and providers for common issues
@@ -53,6 +85,24 @@ func (v *Validator) ValidateScanResult(result *ScanResult) *ValidationResult {
 	// Validate handler-route matching
 	v.validateHandlerRouteMatching(result.Handlers, result.Routes, validationResult)
 
+	// Validate handlers don't write shared package state without synchronization
+	v.validateSharedState(result.Handlers, result.PackageVars, validationResult)
+
+	// Validate provider return signatures
+	v.validateProviders(result.Providers, validationResult)
+
+	// Validate each type has a single provider
+	v.ValidateDuplicateProviders(result.Providers, validationResult)
+
+	// Validate the provider graph has no cycles
+	v.ValidateProviderCycles(result.Providers, validationResult)
+
+	// Validate packages sharing a name can be told apart
+	v.ValidatePackageConflicts(result.Conflicts, validationResult)
+
+	// Validate each handler field is bound to a single handler type
+	v.ValidateHandlerFields(result.Routes, validationResult)
+
 	return validationResult
 }
 
@@ -70,9 +120,12 @@ func (v *Validator) validateRoutes(routes []RouteMapping, result *ValidationResu
 		if len(duplicates) > 1 {
 			for _, dup := range duplicates {
 				result.Errors = append(result.Errors, ValidationError{
-					Type:    "duplicate_route",
-					Message: fmt.Sprintf("Duplicate route found: %s", key),
-					Route:   &dup,
+					Type:     "duplicate_route",
+					Message:  fmt.Sprintf("Duplicate route found: %s", key),
+					FilePath: dup.FilePath,
+					Line:     dup.Line,
+					Column:   dup.Column,
+					Route:    &dup,
 				})
 			}
 		}
@@ -82,9 +135,12 @@ func (v *Validator) validateRoutes(routes []RouteMapping, result *ValidationResu
 	for _, route := range routes {
 		if err := v.validateRoutePattern(route); err != nil {
 			result.Errors = append(result.Errors, ValidationError{
-				Type:    "invalid_route_pattern",
-				Message: err.Error(),
-				Route:   &route,
+				Type:     "invalid_route_pattern",
+				Message:  err.Error(),
+				FilePath: route.FilePath,
+				Line:     route.Line,
+				Column:   route.Column,
+				Route:    &route,
 			})
 		}
 	}
@@ -93,12 +149,18 @@ func (v *Validator) validateRoutes(routes []RouteMapping, result *ValidationResu
 // validateHandlers checks handler function signatures and naming conventions
 func (v *Validator) validateHandlers(handlers []HandlerFunction, result *ValidationResult) {
 	for _, handler := range handlers {
-		// Check naming conventions
-		if !strings.HasSuffix(handler.HandlerName, "Handler") {
+		// Check naming conventions, package-level functions have no handler struct
+		if _, ok := v.conventions.HandlerSuffix(handler.HandlerName); !ok && !handler.IsFunction {
+			suffixes := v.conventions.HandlerSuffixes
+			if len(suffixes) == 0 {
+				suffixes = config.DefaultHandlerSuffixes
+			}
 			result.Warnings = append(result.Warnings, ValidationWarning{
 				Type:     "naming_convention",
-				Message:  fmt.Sprintf("Handler struct %s should end with 'Handler'", handler.HandlerName),
+				Message:  fmt.Sprintf("Handler struct %s should end with '%s'", handler.HandlerName, strings.Join(suffixes, "' or '")),
 				FilePath: handler.FilePath,
+				Line:     handler.Line,
+				Column:   handler.Column,
 				Handler:  &handler,
 			})
 		}
@@ -109,6 +171,8 @@ func (v *Validator) validateHandlers(handlers []HandlerFunction, result *Validat
 				Type:     "test_function",
 				Message:  fmt.Sprintf("Function %s appears to be a test function but was detected as a handler", handler.FunctionName),
 				FilePath: handler.FilePath,
+				Line:     handler.Line,
+				Column:   handler.Column,
 				Handler:  &handler,
 			})
 		}
@@ -122,12 +186,12 @@ func (v *Validator) validateHandlerRouteMatching(handlers []HandlerFunction, rou
 
 	// Build lookup maps
 	for _, handler := range handlers {
-		key := fmt.Sprintf("%s.%s", handler.Package, handler.FunctionName)
+		key := fmt.Sprintf("%s.%s", handler.ImportName, handler.FunctionName)
 		handlerMap[key] = handler
 	}
 
 	for _, route := range routes {
-		key := fmt.Sprintf("%s.%s", route.Package, route.MethodName)
+		key := fmt.Sprintf("%s.%s", route.ImportName, route.MethodName)
 		routeMap[key] = route
 	}
 
@@ -138,6 +202,8 @@ func (v *Validator) validateHandlerRouteMatching(handlers []HandlerFunction, rou
 				Type:     "handler_without_route",
 				Message:  fmt.Sprintf("Handler function %s.%s found but no @Router annotation", handler.Package, handler.FunctionName),
 				FilePath: handler.FilePath,
+				Line:     handler.Line,
+				Column:   handler.Column,
 				Handler:  &handler,
 			})
 		}
@@ -147,9 +213,47 @@ func (v *Validator) validateHandlerRouteMatching(handlers []HandlerFunction, rou
 	for key, route := range routeMap {
 		if _, exists := handlerMap[key]; !exists {
 			result.Errors = append(result.Errors, ValidationError{
-				Type:    "route_without_handler",
-				Message: fmt.Sprintf("@Router annotation found for %s.%s but no corresponding handler function", route.Package, route.MethodName),
-				Route:   &route,
+				Type:     "route_without_handler",
+				Message:  fmt.Sprintf("@Router annotation found for %s.%s but no corresponding handler function", route.Package, route.MethodName),
+				FilePath: route.FilePath,
+				Line:     route.Line,
+				Column:   route.Column,
+				Route:    &route,
+			})
+		}
+	}
+}
+
+// validateSharedState warns about handlers writing package-level variables without taking a lock,
+// requests are served concurrently so the writes likely race
+// The check is a heuristic: a lock taken anywhere in the handler, or held by a function it calls, silences or escapes it
+func (v *Validator) validateSharedState(handlers []HandlerFunction, vars []PackageVar, result *ValidationResult) {
+	declared := make(map[string]PackageVar)
+	for _, packageVar := range vars {
+		declared[filepath.Dir(packageVar.FilePath)+"\x00"+packageVar.Name] = packageVar
+	}
+
+	for _, handler := range handlers {
+		reported := make(map[string]bool)
+		for _, write := range handler.SharedWrites {
+			packageVar, ok := declared[filepath.Dir(handler.FilePath)+"\x00"+write.Variable]
+			if !ok || reported[write.Variable] {
+				continue
+			}
+			reported[write.Variable] = true
+
+			name := handler.Package + "." + handler.FunctionName
+			if !handler.IsFunction {
+				name = handler.Package + "." + handler.HandlerName + "." + handler.FunctionName
+			}
+			result.Warnings = append(result.Warnings, ValidationWarning{
+				Type: "shared_state",
+				Message: fmt.Sprintf("Handler %s writes package variable %s (declared at %s) without synchronization, concurrent requests race on it: guard it with a sync.Mutex or use sync/atomic",
+					name, write.Variable, formatPosition(packageVar.FilePath, packageVar.Line, packageVar.Column)),
+				FilePath: handler.FilePath,
+				Line:     write.Line,
+				Column:   write.Column,
+				Handler:  &handler,
 			})
 		}
 	}
@@ -200,6 +304,348 @@ func (v *Validator) validateRoutePattern(route RouteMapping) error {
 	return nil
 }
 
+// validateProviders checks that provider return tuples are supported by Wire:
+// T, (T, error), (T, func()) or (T, func(), error)
+func (v *Validator) validateProviders(providers []ProviderFunction, result *ValidationResult) {
+	for _, provider := range providers {
+		results := provider.Results
+		valid := len(results) == 1 ||
+			(len(results) == 2 && (results[1] == "error" || results[1] == "func()")) ||
+			(len(results) == 3 && results[1] == "func()" && results[2] == "error")
+		if valid {
+			continue
+		}
+
+		result.Errors = append(result.Errors, ValidationError{
+			Type:     "invalid_provider_signature",
+			Message:  fmt.Sprintf("Provider %s.%s returns (%s), expected T, (T, error), (T, func()) or (T, func(), error)", provider.Package, provider.FunctionName, strings.Join(results, ", ")),
+			FilePath: provider.FilePath,
+			Line:     provider.Line,
+			Column:   provider.Column,
+		})
+	}
+}
+
+// ValidateDuplicateProviders reports types returned by more than one provider of the same environment,
+// which Wire only rejects once the injector is generated
+func (v *Validator) ValidateDuplicateProviders(providers []ProviderFunction, result *ValidationResult) {
+	byType := make(map[string][]ProviderFunction)
+	var types []string
+	for _, provider := range providers {
+		key := provider.ReturnKey()
+		if _, exists := byType[key]; !exists {
+			types = append(types, key)
+		}
+		byType[key] = append(byType[key], provider)
+	}
+
+	for _, key := range types {
+		// Providers tagged for different environments are never generated together
+		var duplicates []ProviderFunction
+		for i, provider := range byType[key] {
+			for j, other := range byType[key] {
+				if i != j && provider.sharesEnv(other) {
+					duplicates = append(duplicates, provider)
+					break
+				}
+			}
+		}
+		if len(duplicates) < 2 {
+			continue
+		}
+
+		typeName := QualifyType(duplicates[0].ImportName, duplicates[0].ReturnType)
+		locations := make([]string, len(duplicates))
+		for i, provider := range duplicates {
+			locations[i] = fmt.Sprintf("%s (%s)", providerName(provider), formatPosition(provider.FilePath, provider.Line, provider.Column))
+		}
+
+		result.Errors = append(result.Errors, ValidationError{
+			Type:     "duplicate_provider",
+			Message:  fmt.Sprintf("Type %s is provided by more than one provider: %s", typeName, strings.Join(locations, ", ")),
+			FilePath: duplicates[1].FilePath,
+			Line:     duplicates[1].Line,
+			Column:   duplicates[1].Column,
+		})
+	}
+}
+
+// ValidateProviderCycles reports providers that depend on themselves through their parameters,
+// which Wire only rejects with a hard to read error
+func (v *Validator) ValidateProviderCycles(providers []ProviderFunction, result *ValidationResult) {
+	for _, cycle := range FindProviderCycles(providers) {
+		names := make([]string, 0, len(cycle)+1)
+		var files []string
+		seenFiles := make(map[string]bool)
+		for _, provider := range cycle {
+			names = append(names, providerName(provider))
+			if !seenFiles[provider.FilePath] {
+				seenFiles[provider.FilePath] = true
+				files = append(files, provider.FilePath)
+			}
+		}
+		names = append(names, names[0])
+
+		result.Errors = append(result.Errors, ValidationError{
+			Type:     "dependency_cycle",
+			Message:  fmt.Sprintf("Dependency cycle %s (files: %s)", strings.Join(names, " → "), strings.Join(files, ", ")),
+			FilePath: cycle[0].FilePath,
+			Line:     cycle[0].Line,
+			Column:   cycle[0].Column,
+		})
+	}
+}
+
+// ValidatePackageConflicts reports references to a package name shared by several scanned packages
+// that can't be resolved to one of them, generated code would refer to the wrong package otherwise
+func (v *Validator) ValidatePackageConflicts(conflicts []PackageConflict, result *ValidationResult) {
+	for _, conflict := range conflicts {
+		message := fmt.Sprintf("Packages named %s in %s can't be given distinct names, they would all be imported as %s, rename one of them", conflict.Package, strings.Join(conflict.Dirs, ", "), conflict.Reference)
+		if conflict.Provider != "" {
+			message = fmt.Sprintf("Provider %s refers to %s, which can be any of the packages named %s in %s",
+				conflict.Provider, conflict.Reference, conflict.Package, strings.Join(conflict.Dirs, ", "))
+		}
+
+		result.Errors = append(result.Errors, ValidationError{
+			Type:     "ambiguous_package",
+			Message:  message,
+			FilePath: conflict.FilePath,
+			Line:     conflict.Line,
+			Column:   conflict.Column,
+		})
+	}
+}
+
+// ValidateHandlerFields reports handler types bound to the same router field, e.g. two handler structs
+// declared in one package. Only the first type is injected, the routes of the others would call methods on it
+func (v *Validator) ValidateHandlerFields(routes []RouteMapping, result *ValidationResult) {
+	fields := HandlerFieldTypes(routes)
+	reported := make(map[string]bool)
+	for _, route := range routes {
+		if route.IsFunction {
+			continue
+		}
+		field, _, _ := strings.Cut(route.HandlerRef, ".")
+		types := fields[field]
+		typeName := route.ImportName + "." + route.HandlerName
+		if len(types) < 2 || typeName == types[0] || reported[typeName] {
+			continue
+		}
+		reported[typeName] = true
+
+		result.Errors = append(result.Errors, ValidationError{
+			Type: "duplicate_handler_field",
+			Message: fmt.Sprintf("Handlers %s and %s would both be bound to field %s, which only holds %s: move %s into its own package",
+				types[0], typeName, field, types[0], typeName),
+			FilePath: route.FilePath,
+			Line:     route.Line,
+			Column:   route.Column,
+			Route:    &route,
+		})
+	}
+}
+
+// ValidateInterfaceBindings warns about providers returning a handler implementation the scanner didn't
+// associate with a handler interface, while its package declares some. Dependency generation doesn't guess
+// which of them to bind, so a parameter of the interface type has no provider
+func (v *Validator) ValidateInterfaceBindings(result *ScanResult, validation *ValidationResult) {
+	for _, provider := range result.Providers {
+		structName := strings.TrimPrefix(provider.ReturnType, "*")
+		providerDir := filepath.Dir(provider.FilePath)
+
+		for _, impl := range result.Implementations {
+			if impl.InterfaceName != "" || impl.StructName != structName || filepath.Dir(impl.FilePath) != providerDir {
+				continue
+			}
+
+			var candidates []string
+			for _, iface := range result.Interfaces {
+				if filepath.Dir(iface.FilePath) == providerDir {
+					candidates = appendUnique(candidates, iface.InterfaceName)
+				}
+			}
+			if len(candidates) == 0 {
+				continue
+			}
+
+			validation.Warnings = append(validation.Warnings, ValidationWarning{
+				Type: "ambiguous_binding",
+				Message: fmt.Sprintf("Provider %s returns %s, which isn't associated with any of the interfaces %s of its package, so none is bound to it: name the interface Handler, or bind it by hand",
+					providerName(provider), QualifyType(provider.ImportName, provider.ReturnType), strings.Join(candidates, ", ")),
+				FilePath: provider.FilePath,
+				Line:     provider.Line,
+				Column:   provider.Column,
+			})
+		}
+	}
+}
+
+// ValidateUnusedProviders warns about providers whose return type no other provider, handler route
+// or server consumes, they only add dead wiring to the generated dependency set
+// Providers declared in outputDir build the server itself and are never reported, and the struct
+// fields and function parameters declared there (e.g. a Server struct or an injector) count as consumers.
+// outputDir is relative to the project root
+func (v *Validator) ValidateUnusedProviders(result *ScanResult, root, outputDir string, validation *ValidationResult) {
+	consumed := make(map[string]bool)
+	for _, typeName := range serverConsumedTypes(config.JoinRoot(root, outputDir)) {
+		consumed[typeName] = true
+	}
+	for _, provider := range result.Providers {
+		for i := range provider.Parameters {
+			consumed[provider.ParameterKey(i)] = true
+		}
+	}
+
+	// The generated router takes one handler per package with routes
+	for _, route := range result.Routes {
+		if route.IsFunction {
+			continue
+		}
+		consumed["*"+route.ImportName+"."+route.HandlerName] = true
+		consumed["*"+route.ImportName+".Handler"] = true
+		consumed[route.ImportName+".Handler"] = true
+	}
+	for _, impl := range result.Implementations {
+		consumed["*"+impl.ImportName+"."+impl.StructName] = true
+	}
+	// So does the generated gateway, one service implementation per package with @RPC methods
+	for _, rpc := range result.RPCs {
+		consumed["*"+rpc.ImportName+"."+rpc.ServerName] = true
+	}
+
+	serverDir := filepath.Clean(outputDir)
+	for _, provider := range result.Providers {
+		if filepath.Clean(filepath.Dir(provider.FilePath)) == serverDir {
+			continue
+		}
+
+		typeName := QualifyType(provider.ImportName, provider.ReturnType)
+		if consumed[typeName] || consumed[provider.ReturnKey()] {
+			continue
+		}
+
+		validation.Warnings = append(validation.Warnings, ValidationWarning{
+			Type:     "unused_provider",
+			Message:  fmt.Sprintf("Provider %s returns %s, which no provider, handler or server consumes", providerName(provider), typeName),
+			FilePath: provider.FilePath,
+			Line:     provider.Line,
+			Column:   provider.Column,
+		})
+	}
+}
+
+// ValidatePartialGeneration warns about handlers that partial generation wires but never routes, or
+// routes but never wires: with generation.routes disabled, or their package left out by include_packages
+// or exclude_packages, their providers still build them into the dependency set, and with
+// generation.dependencies disabled their @Provider functions are ignored and must be wired by hand
+func (v *Validator) ValidatePartialGeneration(result *ScanResult, generation config.Generation, validation *ValidationResult) {
+	if !generation.Routes.Enabled && !generation.Dependencies.Enabled {
+		return
+	}
+
+	include := PackageFilter(generation.Routes.IncludePackages)
+	exclude := PackageFilter(generation.Routes.ExcludePackages)
+	routed := make(map[string]int)
+	filtered := make(map[string]int)
+	for _, route := range result.Routes {
+		if route.IsFunction {
+			continue
+		}
+		typeName := route.ImportName + "." + route.HandlerName
+		if include.Matches(route.Package, route.ImportName, route.FilePath) &&
+			(len(exclude) == 0 || !exclude.Matches(route.Package, route.ImportName, route.FilePath)) {
+			routed[typeName]++
+		} else {
+			filtered[typeName]++
+		}
+	}
+
+	for _, provider := range result.Providers {
+		typeName := strings.TrimPrefix(QualifyType(provider.ImportName, provider.ReturnType), "*")
+		var message string
+		switch {
+		case !generation.Dependencies.Enabled && generation.Routes.Enabled && routed[typeName] > 0:
+			message = fmt.Sprintf("Handler %s has %d route(s) registered, but generation.dependencies is disabled so its provider %s is not wired: provide it by hand or enable dependencies",
+				typeName, routed[typeName], providerName(provider))
+		case !generation.Dependencies.Enabled:
+			continue
+		case !generation.Routes.Enabled && routed[typeName]+filtered[typeName] > 0:
+			message = fmt.Sprintf("Provider %s wires handler %s, but generation.routes is disabled so none of its %d route(s) are registered",
+				providerName(provider), typeName, routed[typeName]+filtered[typeName])
+		case generation.Routes.Enabled && routed[typeName] == 0 && filtered[typeName] > 0:
+			message = fmt.Sprintf("Provider %s wires handler %s, but generation.routes leaves its package out so none of its %d route(s) are registered",
+				providerName(provider), typeName, filtered[typeName])
+		default:
+			continue
+		}
+
+		validation.Warnings = append(validation.Warnings, ValidationWarning{
+			Type:     "partial_generation",
+			Message:  message,
+			FilePath: provider.FilePath,
+			Line:     provider.Line,
+			Column:   provider.Column,
+		})
+	}
+}
+
+// serverConsumedTypes returns the qualified struct field and function parameter types declared in the
+// server package, build constrained files such as wire injectors included
+func serverConsumedTypes(dir string) []string {
+	fset := token.NewFileSet()
+	packages, err := parser.ParseDir(fset, dir, func(info fs.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil
+	}
+
+	typeScanner := &ASTScanner{fset: fset}
+	var types []string
+	for pkgName, pkg := range packages {
+		for _, file := range pkg.Files {
+			ast.Inspect(file, func(n ast.Node) bool {
+				var fields *ast.FieldList
+				switch x := n.(type) {
+				case *ast.StructType:
+					fields = x.Fields
+				case *ast.FuncType:
+					fields = x.Params
+				}
+				if fields == nil {
+					return true
+				}
+				for _, field := range fields.List {
+					if typeName := typeScanner.getTypeString(field.Type); typeName != "" {
+						types = append(types, QualifyType(pkgName, typeName))
+					}
+				}
+				return true
+			})
+		}
+	}
+	return types
+}
+
+// ValidateRouteOwners reports routes declared in files that no CODEOWNERS rule assigns to an owner
+func (v *Validator) ValidateRouteOwners(routes []RouteMapping, owners *CodeOwners, result *ValidationResult) {
+	for i := range routes {
+		route := routes[i]
+		if len(owners.Owners(route.FilePath)) > 0 {
+			continue
+		}
+
+		result.Errors = append(result.Errors, ValidationError{
+			Type:     "unowned_route",
+			Message:  fmt.Sprintf("Route %s %s in %s has no owner in %s", route.HTTPMethod, route.Path, route.FilePath, owners.Path),
+			FilePath: route.FilePath,
+			Line:     route.Line,
+			Column:   route.Column,
+			Route:    &route,
+		})
+	}
+}
+
 // HasErrors returns true if there are validation errors
 func (vr *ValidationResult) HasErrors() bool {
 	return len(vr.Errors) > 0
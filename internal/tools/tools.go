@@ -0,0 +1,150 @@
+// Package tools describes the external commands taskw runs: how to find them, which version is
+// installed and how to install them
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Tool is an external command taskw runs
+type Tool struct {
+	Name    string // Command name, e.g. "wire"
+	Package string // Package go install builds the command from, empty when it can't be installed with go
+	Purpose string // What taskw runs it for
+	Manual  string // How to install the command when it has no Package
+}
+
+// External commands, in the order doctor checks them
+var (
+	Go = Tool{
+		Name:    "go",
+		Purpose: "builds and scans the project",
+		Manual:  "Install Go from https://go.dev/dl/",
+	}
+	Task = Tool{
+		Name:    "task",
+		Package: "github.com/go-task/task/v3/cmd/task",
+		Purpose: "runs the Taskfile.yml tasks of projects created by taskw init",
+	}
+	Wire = Tool{
+		Name:    "wire",
+		Package: "github.com/google/wire/cmd/wire",
+		Purpose: "generates wire_gen.go from the generated provider sets",
+	}
+	Swag = Tool{
+		Name:    "swag",
+		Package: "github.com/swaggo/swag/cmd/swag",
+		Purpose: "generates the swagger documentation",
+	}
+	Air = Tool{
+		Name:    "air",
+		Package: "github.com/air-verse/air",
+		Purpose: "live reload, optional since taskw dev rebuilds and restarts the server itself",
+	}
+	Git = Tool{
+		Name:    "git",
+		Purpose: "finds changed packages for --since and --changed, writes release notes and initial commits",
+		Manual:  "Install git from https://git-scm.com/downloads",
+	}
+)
+
+// Path returns where the command is found in PATH, empty when it isn't
+func (t Tool) Path() string {
+	path, err := exec.LookPath(t.Name)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// Available reports whether the command is found in PATH
+func (t Tool) Available() bool {
+	return t.Path() != ""
+}
+
+// Version returns the version of the installed command, e.g. "go1.23.4" or "v0.6.0", empty when it is
+// missing or doesn't tell. Commands built with go install are asked through their build information
+func (t Tool) Version() string {
+	path := t.Path()
+	if path == "" {
+		return ""
+	}
+	if t.Name == Go.Name {
+		output, err := exec.Command(path, "env", "GOVERSION").Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(output))
+	}
+	if t.Package == "" {
+		output, err := exec.Command(path, "--version").Output()
+		if err != nil {
+			return ""
+		}
+		line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+		return line
+	}
+
+	output, err := exec.Command("go", "version", "-m", path).Output()
+	if err != nil {
+		return ""
+	}
+	return moduleVersion(output)
+}
+
+// moduleVersion returns the version of the main module in the output of go version -m
+func moduleVersion(output []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 3 && fields[0] == "mod" {
+			return fields[2]
+		}
+	}
+	return ""
+}
+
+// InstallHint returns the command or instructions installing the tool
+func (t Tool) InstallHint() string {
+	if t.Package == "" {
+		return t.Manual
+	}
+	return fmt.Sprintf("go install %s@latest", t.Package)
+}
+
+// Run runs the command with args and returns its combined output. The command is killed once ctx
+// is done, ctx's error is returned then
+func (t Tool) Run(ctx context.Context, args ...string) ([]byte, error) {
+	return t.RunIn(ctx, "", args...)
+}
+
+// RunIn runs the command like Run, in dir instead of the working directory when dir isn't empty
+func (t Tool) RunIn(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, t.Name, args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return output, ctx.Err()
+	}
+	return output, err
+}
+
+// Install installs the latest version of the command with go install, stopping once ctx is done
+func (t Tool) Install(ctx context.Context) error {
+	if t.Package == "" {
+		return fmt.Errorf("%s can't be installed with go install: %s", t.Name, t.Manual)
+	}
+	output, err := Go.Run(ctx, "install", t.Package+"@latest")
+	if ctx.Err() != nil {
+		return fmt.Errorf("go install %s@latest stopped: %w", t.Package, err)
+	}
+	if err != nil {
+		return fmt.Errorf("go install %s@latest failed: %w\nOutput: %s", t.Package, err, output)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Package annotations parses the taskw and swag annotations of Go doc comments, e.g.
+// "// @Router /users/{id} [get]", into a model other tools such as linters and docs
+// portals can build on without duplicating taskw's parsing rules.
+//
+// Annotations are matched the way taskw reads them: one per comment line, at the start of
+// the line, with case-insensitive names.
+package annotations
+
+import (
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// Annotation is one "@Name args" line of a doc comment
+type Annotation struct {
+	Name string // As written, without the @, e.g. "Router"
+	Args string // Text following the name without surrounding whitespace, e.g. "/users/{id} [get]"
+
+	// Start of the comment line holding the annotation, token.NoPos for lines given to Parse. Lines of
+	// /* */ comments in files with CRLF line endings point up to one byte early per preceding line
+	Pos token.Pos
+}
+
+// Is reports whether the annotation has the given name, ignoring case
+func (a Annotation) Is(name string) bool {
+	return strings.EqualFold(a.Name, name)
+}
+
+// annotationPattern matches an annotation at the start of a comment line, e.g. "@x-codeSamples file"
+var annotationPattern = regexp.MustCompile(`^@([A-Za-z][\w-]*)(.*)$`)
+
+// line is the text of a comment line and the position it starts at
+type line struct {
+	text string
+	pos  token.Pos
+}
+
+// commentLines splits a doc comment into lines, without comment markers and surrounding whitespace.
+// Lines of /* */ comments lose a leading "*" as well
+func commentLines(doc *ast.CommentGroup) []line {
+	if doc == nil {
+		return nil
+	}
+
+	var lines []line
+	for _, comment := range doc.List {
+		if text, ok := strings.CutPrefix(comment.Text, "//"); ok {
+			lines = append(lines, line{text: strings.TrimSpace(text), pos: comment.Pos()})
+			continue
+		}
+
+		text := strings.TrimSuffix(strings.TrimPrefix(comment.Text, "/*"), "*/")
+		offset := len("/*")
+		for _, raw := range strings.Split(text, "\n") {
+			trimmed := strings.TrimSpace(raw)
+			lines = append(lines, line{
+				text: strings.TrimSpace(strings.TrimPrefix(trimmed, "*")),
+				pos:  comment.Pos() + token.Pos(offset),
+			})
+			offset += len(raw) + 1
+		}
+	}
+	return lines
+}
+
+// Lines returns the text of every line of a doc comment, without comment markers and surrounding
+// whitespace. Lines of /* */ comments lose a leading "*" as well
+func Lines(doc *ast.CommentGroup) []string {
+	var texts []string
+	for _, line := range commentLines(doc) {
+		texts = append(texts, line.text)
+	}
+	return texts
+}
+
+// Parse returns the annotation a comment line starts with, the line being the text returned by Lines
+func Parse(line string) (Annotation, bool) {
+	matches := annotationPattern.FindStringSubmatch(line)
+	if matches == nil {
+		return Annotation{}, false
+	}
+	return Annotation{Name: matches[1], Args: strings.TrimSpace(matches[2])}, true
+}
+
+// All returns the annotations of a doc comment in order
+func All(doc *ast.CommentGroup) []Annotation {
+	var all []Annotation
+	for _, line := range commentLines(doc) {
+		if annotation, ok := Parse(line.text); ok {
+			annotation.Pos = line.pos
+			all = append(all, annotation)
+		}
+	}
+	return all
+}
+
+// Find returns the annotations of a doc comment with the given name, ignoring case
+func Find(doc *ast.CommentGroup, name string) []Annotation {
+	var found []Annotation
+	for _, annotation := range All(doc) {
+		if annotation.Is(name) {
+			found = append(found, annotation)
+		}
+	}
+	return found
+}
+
+// Has reports whether a doc comment holds an annotation, with or without arguments, e.g. @Provider
+func Has(doc *ast.CommentGroup, name string) bool {
+	return len(Find(doc, name)) > 0
+}
+
+// Text returns the arguments of the first annotation with arguments, e.g. the summary of @Summary
+func Text(doc *ast.CommentGroup, name string) string {
+	for _, annotation := range Find(doc, name) {
+		if annotation.Args != "" {
+			return annotation.Args
+		}
+	}
+	return ""
+}
+
+// List collects the comma or space separated values of every annotation with the given name, e.g.
+// "@Middleware auth, audit" and "@Middleware ratelimit" give [auth audit ratelimit]
+func List(doc *ast.CommentGroup, name string) []string {
+	var values []string
+	for _, annotation := range Find(doc, name) {
+		values = append(values, SplitList(annotation.Args)...)
+	}
+	return values
+}
+
+// SplitList splits the arguments of a list annotation on commas and whitespace
+func SplitList(args string) []string {
+	return strings.FieldsFunc(args, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+}
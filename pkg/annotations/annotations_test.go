@@ -0,0 +1,207 @@
+package annotations
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// parseDoc returns the doc comment of the first function in src, with the file set positions refer to
+func parseDoc(t *testing.T, src string) (*ast.CommentGroup, *token.FileSet) {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "handler.go", "package user\n\n"+src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parsing source: %v", err)
+	}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			return fn.Doc, fset
+		}
+	}
+	t.Fatal("no function in source")
+	return nil, nil
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		line string
+		want Annotation
+		ok   bool
+	}{
+		{line: "@Router /users [get]", want: Annotation{Name: "Router", Args: "/users [get]"}, ok: true},
+		{line: "@provider", want: Annotation{Name: "provider"}, ok: true},
+		{line: "@Summary   Get a user  ", want: Annotation{Name: "Summary", Args: "Get a user"}, ok: true},
+		{line: "@x-codeSamples file", want: Annotation{Name: "x-codeSamples", Args: "file"}, ok: true},
+		{line: "GetUser returns a user", ok: false},
+		{line: "see @Router", ok: false},
+		{line: "@", ok: false},
+		{line: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Parse(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("Parse(%q) = %+v, %v, want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDocComment(t *testing.T) {
+	doc, fset := parseDoc(t, `// GetUser returns a user.
+//
+// @Summary Get a user
+// @Tags users, admin
+// @tags audit
+// @Middleware auth   ratelimit
+// @Deprecated
+// @Router /users/{id} [get]
+func GetUser() {}
+`)
+
+	if got := Text(doc, "summary"); got != "Get a user" {
+		t.Errorf("Text(Summary) = %q, want %q", got, "Get a user")
+	}
+	if got := Text(doc, "Description"); got != "" {
+		t.Errorf("Text(Description) = %q, want empty", got)
+	}
+	if got, want := List(doc, "Tags"), []string{"users", "admin", "audit"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List(Tags) = %v, want %v", got, want)
+	}
+	if got, want := List(doc, "Middleware"), []string{"auth", "ratelimit"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List(Middleware) = %v, want %v", got, want)
+	}
+	if !Has(doc, "Deprecated") || Has(doc, "Provider") {
+		t.Errorf("Has(Deprecated) = %v, Has(Provider) = %v, want true, false", Has(doc, "Deprecated"), Has(doc, "Provider"))
+	}
+
+	routers := Find(doc, "Router")
+	if len(routers) != 1 {
+		t.Fatalf("Find(Router) found %d annotations, want 1", len(routers))
+	}
+	if line := fset.Position(routers[0].Pos).Line; line != 10 {
+		t.Errorf("@Router position is on line %d, want 10", line)
+	}
+	if got := len(All(doc)); got != 6 {
+		t.Errorf("All found %d annotations, want 6", got)
+	}
+}
+
+func TestBlockComment(t *testing.T) {
+	doc, fset := parseDoc(t, `/**
+ * GetUser returns a user.
+ * @Router /users/{id} [get]
+ */
+func GetUser() {}
+`)
+
+	routers := Find(doc, "Router")
+	if len(routers) != 1 || routers[0].Args != "/users/{id} [get]" {
+		t.Fatalf("Find(Router) = %+v, want one @Router /users/{id} [get]", routers)
+	}
+	if position := fset.Position(routers[0].Pos); position.Line != 5 {
+		t.Errorf("@Router position is on line %d, want 5", position.Line)
+	}
+}
+
+func TestNilDoc(t *testing.T) {
+	if Lines(nil) != nil || All(nil) != nil || Has(nil, "Router") || Text(nil, "Summary") != "" || List(nil, "Tags") != nil {
+		t.Error("a missing doc comment should hold no annotations")
+	}
+}
+
+func TestParseRouter(t *testing.T) {
+	tests := []struct {
+		args string
+		want Router
+		ok   bool
+	}{
+		{args: "/users/{id} [get]", want: Router{Path: "/users/{id}", Method: "GET"}, ok: true},
+		{args: `"/users/{id}" [post]`, want: Router{Path: "/users/{id}", Method: "POST"}, ok: true},
+		{args: "/users/{id} delete", want: Router{Path: "/users/{id}", Method: "DELETE"}, ok: true},
+		{args: "/users [ purge ]", want: Router{Path: "/users", Method: "PURGE"}, ok: true},
+		{args: "/users [get] trailing", want: Router{Path: "/users", Method: "GET"}, ok: true},
+		{args: "/users", ok: false},
+		{args: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseRouter(tt.args)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseRouter(%q) = %+v, %v, want %+v, %v", tt.args, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseParam(t *testing.T) {
+	tests := []struct {
+		args string
+		want Param
+		ok   bool
+	}{
+		{
+			args: `id path string true "User ID" format(uuid)`,
+			want: Param{Name: "id", In: "path", Type: "string", Required: true, Description: "User ID", Format: "uuid"},
+			ok:   true,
+		},
+		{
+			args: `limit Query int false "Page size"`,
+			want: Param{Name: "limit", In: "query", Type: "int", Description: "Page size"},
+			ok:   true,
+		},
+		{
+			args: `body body user.CreateRequest true "The user"`,
+			want: Param{Name: "body", In: "body", Type: "user.CreateRequest", Required: true, Description: "The user"},
+			ok:   true,
+		},
+		{
+			args: `avatar formdata file true "Avatar"`,
+			want: Param{Name: "avatar", In: "formData", Type: "file", Required: true, Description: "Avatar"},
+			ok:   true,
+		},
+		{
+			args: `id path uuid.UUID "User ID"`,
+			want: Param{Name: "id", In: "path", Type: "uuid.UUID", Description: "User ID"},
+			ok:   true,
+		},
+		{args: "id path", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseParam(tt.args)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseParam(%q) = %+v, %v, want %+v, %v", tt.args, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseSLO(t *testing.T) {
+	tests := []struct {
+		value string
+		want  SLO
+		err   bool
+	}{
+		{value: "p99=200ms", want: SLO{Percentile: "p99", Quantile: 0.99, Threshold: 200 * time.Millisecond}},
+		{value: "P99.9=1s", want: SLO{Percentile: "p99.9", Quantile: 0.999, Threshold: time.Second}},
+		{value: "p50=0s", err: true},
+		{value: "p0=1s", err: true},
+		{value: "p99=fast", err: true},
+		{value: "99=1s", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSLO(tt.value)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ParseSLO(%q) = %+v, %v, want %+v, error %v", tt.value, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	if got, want := SplitList("auth,audit  ratelimit,\tcors"), []string{"auth", "audit", "ratelimit", "cors"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SplitList = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,121 @@
+package annotations
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{"@Router /users [get]", "@provider", "@x-codeSamples file", "@", "@@Router", "@Router\t/users\t[get]", "@Summary 日本語"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, line string) {
+		annotation, ok := Parse(line)
+		if !ok {
+			return
+		}
+		if annotation.Name == "" || annotation.Args != strings.TrimSpace(annotation.Args) {
+			t.Fatalf("Parse(%q) = %+v, want a name and trimmed arguments", line, annotation)
+		}
+
+		// Formatting the annotation again gives the same annotation
+		formatted := "@" + annotation.Name
+		if annotation.Args != "" {
+			formatted += " " + annotation.Args
+		}
+		if again, ok := Parse(formatted); !ok || again != annotation {
+			t.Fatalf("Parse(%q) = %+v, %v, want %+v", formatted, again, ok, annotation)
+		}
+	})
+}
+
+func FuzzParseRouter(f *testing.F) {
+	for _, seed := range []string{"/users/{id} [get]", `"/users/{id}" [post]`, "/users delete", "/users [gett]", "/users [get,post]", `"" [get]`, "[get] /users"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, args string) {
+		router, ok := ParseRouter(args)
+		if !ok {
+			return
+		}
+		if router.Method != strings.ToUpper(router.Method) || router.Method != strings.TrimSpace(router.Method) {
+			t.Fatalf("ParseRouter(%q) = %+v, want an uppercase trimmed method", args, router)
+		}
+	})
+}
+
+func FuzzParseParam(f *testing.F) {
+	for _, seed := range []string{`id path string true "User ID" format(uuid)`, `limit query int false "Page size"`, `id path uuid.UUID "User ID"`, `a b c d e`, `x formData file`} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, args string) {
+		param, ok := ParseParam(args)
+		if !ok {
+			return
+		}
+		if param.Name == "" || param.In == "" || param.Type == "" {
+			t.Fatalf("ParseParam(%q) = %+v, want a name, location and type", args, param)
+		}
+		if param.In != strings.ToLower(param.In) && param.In != "formData" {
+			t.Fatalf("ParseParam(%q) = %+v, want a lowercase location", args, param)
+		}
+	})
+}
+
+func FuzzParseSLO(f *testing.F) {
+	for _, seed := range []string{"p99=200ms", "p99.9=1s", "p50=0s", "p100=1s", "p99=-1s", "p99.=1s"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		slo, err := ParseSLO(value)
+		if err != nil {
+			return
+		}
+		if slo.Threshold <= 0 || slo.Quantile <= 0 || slo.Quantile >= 1 {
+			t.Fatalf("ParseSLO(%q) = %+v, want a positive threshold and a quantile between 0 and 1", value, slo)
+		}
+	})
+}
+
+func FuzzAll(f *testing.F) {
+	for _, seed := range []string{"// @Router /users [get]\n// @Tags users", "/*\n * @Router /users [get]\n */", "/** @Summary x */", "//@provider"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, comment string) {
+		src := "package p\n\n" + comment + "\nfunc F() {}\n"
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "fuzz.go", src, parser.ParseComments)
+		if err != nil {
+			return
+		}
+
+		var doc *ast.CommentGroup
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok {
+				doc = fn.Doc
+			}
+		}
+
+		// Every annotation points into the comment it was found in. Comment text drops carriage
+		// returns, so the text at the position is only checked without them
+		for _, annotation := range All(doc) {
+			if annotation.Pos < doc.Pos() || annotation.Pos >= doc.End() {
+				t.Fatalf("annotation %+v at %d is outside its doc comment [%d, %d)", annotation, annotation.Pos, doc.Pos(), doc.End())
+			}
+			if strings.Contains(comment, "\r") {
+				continue
+			}
+			if line := src[fset.Position(annotation.Pos).Offset:]; !strings.Contains(line, "@"+annotation.Name) {
+				t.Fatalf("annotation %+v doesn't start after its position", annotation)
+			}
+		}
+	})
+}
@@ -0,0 +1,53 @@
+package annotations
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Param is a parsed @Param annotation, e.g.
+// @Param id path string true "User ID" format(uuid)
+type Param struct {
+	Name        string // e.g. "id"
+	In          string // Lowercase, e.g. "query", "path", "header", "body" or "formData"
+	Type        string // e.g. "int", or the schema of a body parameter such as "user.CreateRequest"
+	Required    bool
+	Description string // Without quotes, e.g. "User ID"
+	Format      string // From a format(...) attribute, e.g. "uuid"
+}
+
+var (
+	// name, in and type, then the optional required flag and the rest of the line
+	paramPattern = regexp.MustCompile(`^(\S+)\s+(\S+)\s+(\S+)(?:\s+([^\s"]+))?(.*)$`)
+	// The quoted description following the required flag
+	paramDescriptionPattern = regexp.MustCompile(`^\s*"([^"]*)"`)
+	// Swag attributes following the description, e.g. format(uuid)
+	paramFormatPattern = regexp.MustCompile(`(?i)\bformat\(([^)]*)\)`)
+)
+
+// ParseParam parses the arguments of a @Param annotation. Name, location and type are required,
+// the other fields are empty when missing
+func ParseParam(args string) (Param, bool) {
+	matches := paramPattern.FindStringSubmatch(args)
+	if matches == nil {
+		return Param{}, false
+	}
+
+	param := Param{
+		Name:     matches[1],
+		In:       strings.ToLower(matches[2]),
+		Type:     matches[3],
+		Required: strings.EqualFold(matches[4], "true"),
+	}
+	// formData keeps its capital D, as swag only accepts that spelling
+	if param.In == "formdata" {
+		param.In = "formData"
+	}
+	if description := paramDescriptionPattern.FindStringSubmatch(matches[5]); description != nil {
+		param.Description = description[1]
+	}
+	if format := paramFormatPattern.FindStringSubmatch(matches[5]); format != nil {
+		param.Format = strings.TrimSpace(format[1])
+	}
+	return param, true
+}
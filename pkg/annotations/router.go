@@ -0,0 +1,38 @@
+package annotations
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Router is a parsed @Router annotation
+type Router struct {
+	Path   string // e.g. "/users/{id}"
+	Method string // Uppercase, e.g. "GET". Not checked against the known HTTP methods
+}
+
+// routerPatterns are the @Router argument formats, tried in order
+var routerPatterns = []*regexp.Regexp{
+	// Standard format: /path [method]
+	regexp.MustCompile(`^([^\s\[\]]+)\s+\[([^\]]+)\]`),
+	// Quoted path format: "/path" [method]
+	regexp.MustCompile(`^"([^"]+)"\s+\[([^\]]+)\]`),
+	// Alternative format: /path method
+	regexp.MustCompile(`^(\S+)\s+([A-Za-z]+)(?:\s|$)`),
+}
+
+// ParseRouter parses the arguments of a @Router annotation, in any of the formats swag accepts:
+// - /users/{id} [get]
+// - "/users/{id}" [get]
+// - /users/{id} get
+func ParseRouter(args string) (Router, bool) {
+	for _, pattern := range routerPatterns {
+		if matches := pattern.FindStringSubmatch(args); matches != nil {
+			return Router{
+				Path:   strings.Trim(matches[1], `"'`),
+				Method: strings.ToUpper(strings.TrimSpace(matches[2])),
+			}, true
+		}
+	}
+	return Router{}, false
+}
@@ -0,0 +1,45 @@
+package annotations
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SLO is one latency target of a @SLO annotation, e.g. p99=200ms
+type SLO struct {
+	Percentile string        // e.g. "p99"
+	Quantile   float64       // e.g. 0.99
+	Threshold  time.Duration // e.g. 200ms
+}
+
+// sloPattern matches a latency target, e.g. p99=200ms or p99.9=1s
+var sloPattern = regexp.MustCompile(`^p(\d{1,2}(?:\.\d+)?)=(\S+)$`)
+
+// ParseSLO parses one value of a @SLO annotation, the values being split with SplitList:
+// - @SLO p99=200ms
+// - @SLO p95=100ms, p99.9=1s
+func ParseSLO(value string) (SLO, error) {
+	matches := sloPattern.FindStringSubmatch(strings.ToLower(value))
+	if matches == nil {
+		return SLO{}, fmt.Errorf("invalid SLO target %q (expected e.g. p99=200ms)", value)
+	}
+
+	percentile, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil || percentile <= 0 {
+		return SLO{}, fmt.Errorf("invalid SLO percentile in %q (expected e.g. p99=200ms)", value)
+	}
+	threshold, err := time.ParseDuration(matches[2])
+	if err != nil || threshold <= 0 {
+		return SLO{}, fmt.Errorf("invalid SLO threshold in %q (expected e.g. p99=200ms)", value)
+	}
+
+	return SLO{
+		Percentile: "p" + matches[1],
+		Quantile:   math.Round(percentile*1e4) / 1e6, // Avoid float noise, e.g. p99.9 -> 0.999
+		Threshold:  threshold,
+	}, nil
+}
@@ -0,0 +1,212 @@
+// Package gen runs taskw's generators on a project, writing the files "taskw generate" writes, for
+// tools such as build scripts and IDE plugins that would otherwise shell out to the CLI:
+//
+//	result, err := gen.Generate(ctx, gen.Options{Steps: []string{gen.StepRoutes, gen.StepDeps}})
+//	if err != nil {
+//		return err
+//	}
+//	for _, path := range result.Written {
+//		fmt.Println("generated", path)
+//	}
+//
+// The project is found and configured the way the CLI does, from its taskw.yaml files, and the steps
+// are the ones taskw generate all runs. The steps running external tools, swagger documentation (swag)
+// and wire_gen.go (wire), run them when they are found in PATH and warn otherwise, they are never
+// installed.
+//
+// Configured paths are resolved against the project root and external tools run there, the working
+// directory is left alone. Like the CLI, a run holds the project lock in .taskw/generate.lock, so it
+// waits for a taskw command generating code in the same project to finish.
+package gen
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/nkaewam/taskw/internal/cli/lock"
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/generator"
+	"github.com/nkaewam/taskw/internal/pipeline"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// Steps Options.Steps selects, named after the subcommands of taskw generate
+const (
+	StepRoutes    = pipeline.StepRoutes
+	StepSwaggerUI = pipeline.StepSwaggerUI
+	StepGateway   = pipeline.StepGateway
+	StepServer    = pipeline.StepServer
+	StepDeps      = pipeline.StepDeps
+	StepPkgDocs   = pipeline.StepPkgDocs
+	StepParams    = pipeline.StepParams
+	StepPorts     = pipeline.StepPorts
+	StepRecording = pipeline.StepRecording
+	StepAlerts    = pipeline.StepAlerts
+	StepEnvelope  = pipeline.StepEnvelope
+	StepPII       = pipeline.StepPII
+	StepRedirects = pipeline.StepRedirects
+	StepSwagger   = pipeline.StepSwagger
+)
+
+// Steps lists the steps in the order they run, the order of taskw generate all
+var Steps = pipeline.Steps
+
+// ErrEdited reports a generated file edited by hand since taskw wrote it, which isn't overwritten
+// without Options.Force. Test for it with errors.Is
+var ErrEdited = generator.ErrEdited
+
+// ErrLocked reports that another taskw run kept holding the project lock for Options.LockTimeout.
+// Test for it with errors.Is
+var ErrLocked = lock.ErrLocked
+
+// Options configures a generation run
+type Options struct {
+	// Dir is where the project is looked for, walking up to the outermost taskw.yaml or to go.mod.
+	// Empty for the working directory
+	Dir string
+
+	// Steps to run, e.g. []string{StepRoutes, StepDeps}. Empty runs every step, steps disabled in
+	// taskw.yaml are skipped either way
+	Steps []string
+
+	// Packages limits per-package files, such as doc.go and the path parameter helpers, to these
+	// packages, by name, import name or directory. Empty for every package
+	Packages []string
+
+	// Env is the environment to generate providers for, generation.dependencies.env when empty
+	Env string
+
+	// DryRun writes nothing, the generated files that differ from the ones on disk are returned in
+	// Result.Changes instead
+	DryRun bool
+
+	// Force overwrites generated files edited by hand instead of failing with ErrEdited
+	Force bool
+
+	// LockTimeout is how long to wait for another taskw run generating code in the project before
+	// failing with ErrLocked, 30 seconds when zero. A negative timeout fails right away
+	LockTimeout time.Duration
+}
+
+// Result is what a generation run wrote
+type Result struct {
+	Written  []string // Paths of the files written, relative to the project root, in the order they were written
+	Changes  []Change // With Options.DryRun, the generated files whose content differs from the files on disk
+	Warnings []string // e.g. unused providers, rendered like compiler diagnostics
+}
+
+// Change is a generated file whose content differs from the file on disk
+type Change struct {
+	Path    string // Relative to the project root
+	Existed bool   // False when the file doesn't exist on disk yet
+	Old     []byte
+	New     []byte
+}
+
+// Generate runs the selected steps on the project Options.Dir belongs to. Files written by a run
+// that fails, or is stopped by ctx, are restored to their previous content
+func Generate(ctx context.Context, opts Options) (*Result, error) {
+	if err := pipeline.CheckSteps(opts.Steps); err != nil {
+		return nil, err
+	}
+
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+	cfg, err := config.Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Env != "" {
+		cfg.Generation.Dependencies.Env = opts.Env
+	}
+	cfg.Generation.Packages = opts.Packages
+	cfg.Generation.Only = opts.Steps
+
+	timeout := opts.LockTimeout
+	if timeout == 0 {
+		timeout = lock.DefaultTimeout
+	}
+	projectLock, err := lock.Acquire(ctx, cfg.Root, timeout, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer projectLock.Release()
+
+	report := &collector{}
+	runner := pipeline.NewRunner(cfg, scanner.NewScanner(cfg), report, pathTools{})
+	result := &Result{}
+	if opts.DryRun {
+		err = preview(ctx, runner, opts.Force, result)
+	} else {
+		// The pipeline restores the files of a failed run
+		recording := generator.NewRecording()
+		runner.SetOutput(&generator.Output{Force: opts.Force, Recording: recording})
+		if err = runner.GenerateAll(ctx); err == nil {
+			result.Written = recording.Paths()
+		}
+	}
+	result.Warnings = report.warnings
+	if err != nil {
+		if len(report.errors) > 0 {
+			return nil, fmt.Errorf("%w\n%s", err, strings.Join(report.errors, "\n"))
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// preview runs the pipeline without writing anything, keeping the files that would change in result
+func preview(ctx context.Context, runner *pipeline.Runner, force bool, result *Result) error {
+	preview := generator.NewPreview()
+	runner.SetOutput(&generator.Output{Force: force, Preview: preview})
+	if err := runner.GenerateAll(ctx); err != nil {
+		return err
+	}
+	changes, err := preview.Changes()
+	for _, change := range changes {
+		result.Changes = append(result.Changes, Change(change))
+	}
+	return err
+}
+
+// collector keeps the warnings and errors the steps report, leaving out their progress
+type collector struct {
+	warnings []string
+	errors   []string
+}
+
+func (c *collector) ShowSpinner(message string) func(completedMessage string) {
+	return func(string) {}
+}
+
+func (c *collector) Infof(format string, args ...interface{})   {}
+func (c *collector) Detailf(format string, args ...interface{}) {}
+
+func (c *collector) Warnf(format string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+func (c *collector) Errorf(format string, args ...interface{}) {
+	c.errors = append(c.errors, fmt.Sprintf(format, args...))
+}
+
+// pathTools runs wire and swag when they are found in PATH, and never installs them
+type pathTools struct{}
+
+func (pathTools) IsCommandAvailable(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+func (pathTools) InstallSwag(ctx context.Context) error {
+	return fmt.Errorf("swag not found in PATH")
+}
+
+func (pathTools) InstallWire(ctx context.Context) error {
+	return fmt.Errorf("wire not found in PATH")
+}
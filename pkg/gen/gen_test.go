@@ -0,0 +1,450 @@
+package gen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nkaewam/taskw/internal/cli/lock"
+)
+
+// writeProject writes a project with a user package into a temporary directory and returns its root
+func writeProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":     "module example.com/shop\n\ngo 1.22\n",
+		"taskw.yaml": "paths:\n  scan_dirs: [\"./internal\"]\n  output_dir: \"./internal/api\"\n",
+		"internal/user/handler.go": `package user
+
+import "github.com/gofiber/fiber/v2"
+
+type Handler struct{}
+
+// ProvideHandler creates the user handler
+func ProvideHandler() *Handler { return &Handler{} }
+
+// @Router /users/{id} [get]
+func (h *Handler) GetUser(c *fiber.Ctx) error { return nil }
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+var (
+	routesFile       = filepath.Join("internal", "api", "routes_gen.go")
+	dependenciesFile = filepath.Join("internal", "api", "dependencies_gen.go")
+)
+
+func TestGenerate(t *testing.T) {
+	root := writeProject(t)
+
+	result, err := Generate(context.Background(), Options{Dir: root, Steps: []string{StepRoutes, StepDeps}})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if want := []string{routesFile, dependenciesFile}; !slices.Equal(result.Written, want) {
+		t.Errorf("Written = %v, want %v", result.Written, want)
+	}
+
+	routes, err := os.ReadFile(filepath.Join(root, routesFile))
+	if err != nil {
+		t.Fatalf("reading generated routes: %v", err)
+	}
+	if !strings.Contains(string(routes), `Get("/users/:id"`) {
+		t.Errorf("routes_gen.go doesn't register GET /users/:id:\n%s", routes)
+	}
+}
+
+func TestGenerateSteps(t *testing.T) {
+	root := writeProject(t)
+
+	result, err := Generate(context.Background(), Options{Dir: root, Steps: []string{StepRoutes}})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if want := []string{routesFile}; !slices.Equal(result.Written, want) {
+		t.Errorf("Written = %v, want %v", result.Written, want)
+	}
+	if _, err := os.Stat(filepath.Join(root, dependenciesFile)); !os.IsNotExist(err) {
+		t.Errorf("dependencies_gen.go written without the deps step")
+	}
+
+	if _, err := Generate(context.Background(), Options{Dir: root, Steps: []string{"wire"}}); err == nil {
+		t.Error("Generate with the wire step succeeded, want an unknown step error")
+	}
+}
+
+func TestGenerateDryRun(t *testing.T) {
+	root := writeProject(t)
+
+	result, err := Generate(context.Background(), Options{Dir: root, Steps: []string{StepRoutes}, DryRun: true})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if len(result.Written) != 0 {
+		t.Errorf("Written = %v, want nothing", result.Written)
+	}
+	if len(result.Changes) != 1 || result.Changes[0].Path != routesFile || result.Changes[0].Existed {
+		t.Fatalf("Changes = %+v, want a new %s", result.Changes, routesFile)
+	}
+	if _, err := os.Stat(filepath.Join(root, routesFile)); !os.IsNotExist(err) {
+		t.Errorf("routes_gen.go written by a dry run")
+	}
+
+	// Once generated, a dry run finds nothing to change
+	if _, err := Generate(context.Background(), Options{Dir: root, Steps: []string{StepRoutes}}); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	result, err = Generate(context.Background(), Options{Dir: root, Steps: []string{StepRoutes}, DryRun: true})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if len(result.Changes) != 0 {
+		t.Errorf("Changes = %+v, want none", result.Changes)
+	}
+}
+
+func TestGenerateParallel(t *testing.T) {
+	// Runs on different projects share nothing, a dry run doesn't capture the files of the other run
+	dryRoot, writeRoot := writeProject(t), writeProject(t)
+	const runs = 4
+	errs := make(chan error, 2*runs)
+	for i := 0; i < runs; i++ {
+		go func() {
+			result, err := Generate(context.Background(), Options{Dir: dryRoot, Steps: []string{StepRoutes}, DryRun: true})
+			if err == nil && (len(result.Changes) != 1 || result.Changes[0].Path != routesFile) {
+				err = fmt.Errorf("dry run Changes = %+v, want a new %s", result.Changes, routesFile)
+			}
+			errs <- err
+		}()
+		go func() {
+			result, err := Generate(context.Background(), Options{Dir: writeRoot, Steps: []string{StepRoutes}})
+			if err == nil && !slices.Equal(result.Written, []string{routesFile}) {
+				err = fmt.Errorf("Written = %v, want [%s]", result.Written, routesFile)
+			}
+			errs <- err
+		}()
+	}
+	for i := 0; i < 2*runs; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dryRoot, routesFile)); !os.IsNotExist(err) {
+		t.Errorf("routes_gen.go written by a dry run")
+	}
+	if _, err := os.Stat(filepath.Join(writeRoot, routesFile)); err != nil {
+		t.Errorf("routes_gen.go not written: %v", err)
+	}
+}
+
+func TestGenerateEdited(t *testing.T) {
+	root := writeProject(t)
+	if _, err := Generate(context.Background(), Options{Dir: root, Steps: []string{StepRoutes}}); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	path := filepath.Join(root, routesFile)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, append(content, "// edited\n"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Generate(context.Background(), Options{Dir: root, Steps: []string{StepRoutes}}); !errors.Is(err, ErrEdited) {
+		t.Errorf("Generate over an edited file = %v, want ErrEdited", err)
+	}
+	if _, err := Generate(context.Background(), Options{Dir: root, Steps: []string{StepRoutes}, Force: true}); err != nil {
+		t.Errorf("Generate with Force: %v", err)
+	}
+}
+
+func TestGeneratePorts(t *testing.T) {
+	root := writeProject(t)
+	files := map[string]string{
+		"taskw.yaml": "paths:\n  scan_dirs: [\"./internal\"]\n  output_dir: \"./internal/api\"\ngeneration:\n  ports:\n    enabled: true\n",
+		"internal/user/handler.go": `package user
+
+import "github.com/gofiber/fiber/v2"
+
+type Handler struct {
+	service *Service
+}
+
+// @Router /users/{id} [get]
+func (h *Handler) GetUser(c *fiber.Ctx) error {
+	_, err := h.service.Find(c.Context(), c.Params("id"))
+	return err
+}
+`,
+		"internal/user/service.go": `package user
+
+import "context"
+
+type User struct{}
+
+type Service struct{}
+
+func (s *Service) Find(ctx context.Context, id string) (*User, error) { return nil, nil }
+
+func (s *Service) Delete(ctx context.Context, id string) error { return nil }
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	portsFile := filepath.Join("internal", "user", "ports_gen.go")
+	result, err := Generate(context.Background(), Options{Dir: root, Steps: []string{StepPorts}})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if want := []string{portsFile}; !slices.Equal(result.Written, want) {
+		t.Fatalf("Written = %v, want %v", result.Written, want)
+	}
+
+	ports, err := os.ReadFile(filepath.Join(root, portsFile))
+	if err != nil {
+		t.Fatalf("reading generated ports: %v", err)
+	}
+	if !strings.Contains(string(ports), "Find(ctx context.Context, id string) (*User, error)") {
+		t.Errorf("ports_gen.go doesn't declare the Find method the handler calls:\n%s", ports)
+	}
+	// Methods the handler doesn't call are left out
+	if strings.Contains(string(ports), "Delete(") {
+		t.Errorf("ports_gen.go declares Delete, which the handler doesn't call:\n%s", ports)
+	}
+}
+
+func TestGenerateRouteStyle(t *testing.T) {
+	root := writeProject(t)
+	config := "paths:\n  scan_dirs: [\"./internal\"]\n  output_dir: \"./internal/api\"\ngeneration:\n  routes:\n    style: function\n"
+	if err := os.WriteFile(filepath.Join(root, "taskw.yaml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Generate(context.Background(), Options{Dir: root, Steps: []string{StepRoutes}}); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	routes, err := os.ReadFile(filepath.Join(root, routesFile))
+	if err != nil {
+		t.Fatalf("reading generated routes: %v", err)
+	}
+	for _, want := range []string{
+		"func RegisterRoutes(app *fiber.App, deps *RouteDeps) {",
+		`app.Get("/users/:id", deps.UserHandler.GetUser)`,
+	} {
+		if !strings.Contains(string(routes), want) {
+			t.Errorf("routes_gen.go doesn't contain %q:\n%s", want, routes)
+		}
+	}
+	if strings.Contains(string(routes), "type Router struct") {
+		t.Errorf("routes_gen.go declares the Router of the router style:\n%s", routes)
+	}
+}
+
+func TestGenerateLocked(t *testing.T) {
+	root := writeProject(t)
+	projectLock, err := lock.Acquire(context.Background(), root, 0, nil)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	// Run from a subdirectory, the lock is the one of the project root
+	dir := filepath.Join(root, "internal", "user")
+	if _, err := Generate(context.Background(), Options{Dir: dir, Steps: []string{StepRoutes}, LockTimeout: -1}); !errors.Is(err, ErrLocked) {
+		t.Errorf("Generate while the project is locked = %v, want ErrLocked", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, routesFile)); !os.IsNotExist(err) {
+		t.Errorf("routes_gen.go written while the project is locked")
+	}
+
+	if err := projectLock.Release(); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if _, err := Generate(context.Background(), Options{Dir: dir, Steps: []string{StepRoutes}}); err != nil {
+		t.Errorf("Generate once the lock is released: %v", err)
+	}
+}
+
+func TestGenerateCancelled(t *testing.T) {
+	root := writeProject(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Generate(ctx, Options{Dir: root}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Generate with a cancelled context = %v, want context.Canceled", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, routesFile)); !os.IsNotExist(err) {
+		t.Errorf("routes_gen.go written by a cancelled run")
+	}
+}
+
+// compile builds the generated project requiring modules, e.g. "go.uber.org/fx v1.24.0"
+// The test is skipped when they can't be downloaded
+func compile(t *testing.T, root string, modules ...string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("compiling the generated code is slow")
+	}
+
+	args := []string{"mod", "download"}
+	for _, module := range modules {
+		args = append(args, strings.Replace(module, " ", "@", 1))
+	}
+	download := exec.Command("go", args...)
+	download.Dir = root
+	if output, err := download.CombinedOutput(); err != nil {
+		t.Skipf("dependencies of the generated code unavailable: %v\n%s", err, output)
+	}
+
+	vet := exec.Command("go", "vet", "./...")
+	vet.Dir = root
+	vet.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+	if output, err := vet.CombinedOutput(); err != nil {
+		t.Fatalf("generated code doesn't compile: %v\n%s", err, output)
+	}
+}
+
+func TestGenerateCompiles(t *testing.T) {
+	const (
+		fiberModule = "github.com/gofiber/fiber/v2 v2.52.9"
+		fxModule    = "go.uber.org/fx v1.24.0"
+	)
+	app := `package server
+
+import "github.com/gofiber/fiber/v2"
+
+// ProvideApp creates the Fiber app
+func ProvideApp() *fiber.App { return fiber.New() }
+`
+	logger := `package server
+
+import "log/slog"
+
+// ProvideLogger creates the logger
+func ProvideLogger() *slog.Logger { return slog.Default() }
+`
+	server := `package api
+
+import "example.com/shop/internal/user"
+
+type Server struct {
+	userHandler *user.Handler
+}
+
+// ProvideServer creates the server
+func ProvideServer(userHandler *user.Handler) *Server { return &Server{userHandler: userHandler} }
+`
+
+	tests := []struct {
+		name    string
+		routes  string // generation.routes settings
+		backend string
+		files   map[string]string
+		want    map[string]string // Generated file -> expected content
+	}{
+		{
+			name:    "server style",
+			routes:  "style: server",
+			backend: "plain",
+			files:   map[string]string{"internal/api/server.go": server},
+			want: map[string]string{
+				routesFile:       "func (s *Server) RegisterHandlers(app *fiber.App) {",
+				dependenciesFile: "func BuildServer() (*Server, error) {",
+			},
+		},
+		{
+			name:    "register_func",
+			routes:  "style: function\n    register_func: Mount",
+			backend: "plain",
+			want: map[string]string{
+				routesFile:       "func Mount(app *fiber.App, deps *RouteDeps) {",
+				dependenciesFile: "func BuildServer() (*RouteDeps, error) {",
+			},
+		},
+		{
+			name:    "fx register call",
+			routes:  "style: function\n    register_func: Mount",
+			backend: "fx",
+			files:   map[string]string{"internal/server/app.go": app, "internal/server/logger.go": logger},
+			want: map[string]string{
+				dependenciesFile: `logger.Error("fiber app stopped", "error", err)`,
+			},
+		},
+		{
+			name:    "fx server style",
+			routes:  "style: server",
+			backend: "fx",
+			files:   map[string]string{"internal/server/app.go": app, "internal/api/server.go": server},
+			want: map[string]string{
+				dependenciesFile: "server.RegisterHandlers(app)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := writeProject(t)
+			modules := []string{fiberModule}
+			if tt.backend == "fx" {
+				modules = append(modules, fxModule)
+			}
+			files := map[string]string{
+				"go.mod": "module example.com/shop\n\ngo 1.22\n\nrequire (\n\t" +
+					strings.Join(modules, "\n\t") + "\n)\n",
+				"taskw.yaml": "paths:\n  scan_dirs: [\"./internal\"]\n  output_dir: \"./internal/api\"\ngeneration:\n  routes:\n    " +
+					tt.routes + "\n  dependencies:\n    backend: " + tt.backend + "\n",
+			}
+			for name, content := range tt.files {
+				files[name] = content
+			}
+			for name, content := range files {
+				path := filepath.Join(root, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if _, err := Generate(context.Background(), Options{Dir: root, Steps: []string{StepRoutes, StepDeps}}); err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+			for file, want := range tt.want {
+				content, err := os.ReadFile(filepath.Join(root, file))
+				if err != nil {
+					t.Fatalf("reading %s: %v", file, err)
+				}
+				if !strings.Contains(string(content), want) {
+					t.Errorf("%s doesn't contain %q:\n%s", file, want, content)
+				}
+			}
+
+			compile(t, root, modules...)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Package scan runs taskw's analysis of a project, the handlers, routes, providers and gRPC methods
+// found in its sources and the problems taskw reports about them, for tools such as build scripts and
+// IDE plugins that would otherwise run "taskw scan --format json" and parse its output.
+//
+// The project is found and configured the way the CLI does, from its taskw.yaml files:
+//
+//	result, err := scan.Scan(ctx, scan.Options{Dir: "services/billing"})
+//	if err != nil {
+//		return err
+//	}
+//	for _, route := range result.Routes {
+//		fmt.Println(route.Method, route.Path, route.ID)
+//	}
+//
+// Configured paths are resolved against the project root, the working directory is left alone.
+package scan
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// Options configures a scan
+type Options struct {
+	// Dir is where the project is looked for, walking up to the outermost taskw.yaml or to go.mod.
+	// Empty for the working directory
+	Dir string
+
+	// NoCache re-parses every file instead of reusing the results cached in .taskw/cache.json
+	NoCache bool
+
+	// Packages keeps the results of these packages only, by name, import name or directory,
+	// e.g. "user", "adminuser" or "internal/admin/user". Empty keeps every package
+	Packages []string
+}
+
+// Result is what a scan found. Positions are relative to Root
+type Result struct {
+	Root   string // Absolute path of the project root
+	Module string // Go module of the project, e.g. "github.com/acme/api"
+
+	Handlers    []Handler
+	Routes      []Route
+	Providers   []Provider
+	RPCs        []RPC
+	Diagnostics []Diagnostic // Files that failed to parse, malformed annotations and skipped files
+
+	config *config.Config
+	raw    *scanner.ScanResult
+}
+
+// Scan finds the project Options.Dir belongs to and scans the directories of its paths.scan_dirs.
+// Once ctx is done the scan stops, returning an error wrapping ctx's
+func Scan(ctx context.Context, opts Options) (*Result, error) {
+	cfg, err := loadConfig(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if opts.NoCache {
+		cfg.Scanning.Cache = false
+	}
+
+	raw, err := scanner.NewScanner(cfg).ScanAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error scanning %s: %w", cfg.Root, err)
+	}
+	raw = scanner.PackageFilter(opts.Packages).Apply(raw)
+
+	return newResult(cfg, raw), nil
+}
+
+// loadConfig loads the configuration of the project dir belongs to, the working directory when empty
+func loadConfig(dir string) (*config.Config, error) {
+	if dir == "" {
+		dir = "."
+	}
+	return config.Load(dir)
+}
+
+// Validate checks the result the way taskw scan does, e.g. for duplicate routes, handlers without a
+// route and unused providers, and returns the findings, errors first. Whether warnings fail a build
+// is left to the caller, the CLI goes by validation.fail_on
+func (r *Result) Validate() ([]Diagnostic, error) {
+	validator := scanner.NewValidator(r.config.Conventions)
+	validation := validator.ValidateScanResult(r.raw)
+	validator.ValidateInterfaceBindings(r.raw, validation)
+	validator.ValidateUnusedProviders(r.raw, r.config.Root, r.config.Paths.OutputDir, validation)
+	validator.ValidatePartialGeneration(r.raw, r.config.Generation, validation)
+
+	if r.config.Ownership.RequireOwners {
+		owners, err := scanner.LoadCodeOwners(r.config.Root, r.config.Ownership.CodeownersFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading code owners: %w", err)
+		}
+		validator.ValidateRouteOwners(r.raw.Routes, owners, validation)
+	}
+
+	diagnostics := make([]Diagnostic, 0, len(validation.Errors)+len(validation.Warnings))
+	for _, e := range validation.Errors {
+		diagnostics = append(diagnostics, Diagnostic{
+			Severity: SeverityError,
+			Kind:     e.Type,
+			Message:  e.Message,
+			Position: Position{File: e.FilePath, Line: e.Line, Column: e.Column},
+		})
+	}
+	for _, w := range validation.Warnings {
+		diagnostics = append(diagnostics, Diagnostic{
+			Severity: SeverityWarning,
+			Kind:     w.Type,
+			Message:  w.Message,
+			Position: Position{File: w.FilePath, Line: w.Line, Column: w.Column},
+		})
+	}
+	return diagnostics, nil
+}
+
+// Failed reports whether a file failed to parse or type-check, in which case the result misses
+// its declarations
+func (r *Result) Failed() bool {
+	return len(r.raw.Failures()) > 0
+}
@@ -0,0 +1,204 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeProject writes a project with a user package into a temporary directory and returns its root
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	files["go.mod"] = "module example.com/shop\n\ngo 1.22\n"
+	files["taskw.yaml"] = "paths:\n  scan_dirs: [\"./internal\"]\n  output_dir: \"./internal/api\"\n"
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+const userHandler = `package user
+
+import "github.com/gofiber/fiber/v2"
+
+type Handler struct{ service *Service }
+
+type Service struct{}
+
+// ProvideService creates the user service
+func ProvideService() *Service { return &Service{} }
+
+// ProvideHandler creates the user handler
+func ProvideHandler(service *Service) *Handler { return &Handler{service: service} }
+
+// GetUser returns a user
+// @Summary Get a user
+// @Param id path string true "User ID"
+// @Router /users/{id} [get]
+func (h *Handler) GetUser(c *fiber.Ctx) error { return nil }
+`
+
+func TestScan(t *testing.T) {
+	root := writeProject(t, map[string]string{"internal/user/handler.go": userHandler})
+
+	result, err := Scan(context.Background(), Options{Dir: filepath.Join(root, "internal", "user"), NoCache: true})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	if result.Module != "example.com/shop" {
+		t.Errorf("Module = %q, want example.com/shop", result.Module)
+	}
+	if want, _ := filepath.EvalSymlinks(root); result.Root != root && result.Root != want {
+		t.Errorf("Root = %q, want %q", result.Root, root)
+	}
+
+	wantRoutes := []Route{{
+		ID:       "user.Handler.GetUser",
+		Method:   "GET",
+		Path:     "/users/{id}",
+		Package:  "user",
+		Receiver: "Handler",
+		Handler:  "GetUser",
+		Summary:  "Get a user",
+		Params:   []Param{{Name: "id", In: "path", Type: "string", Required: true}},
+		Position: Position{File: filepath.Join("internal", "user", "handler.go"), Line: 18, Column: 1},
+	}}
+	routes := result.Routes
+	for i := range routes {
+		routes[i].Tags, routes[i].Middlewares = nil, nil
+	}
+	if !reflect.DeepEqual(routes, wantRoutes) {
+		t.Errorf("Routes = %+v, want %+v", routes, wantRoutes)
+	}
+
+	if len(result.Handlers) != 1 || result.Handlers[0].Receiver != "Handler" || result.Handlers[0].Name != "GetUser" {
+		t.Errorf("Handlers = %+v, want user.Handler.GetUser", result.Handlers)
+	}
+
+	var providers []string
+	for _, provider := range result.Providers {
+		providers = append(providers, provider.Name+" "+provider.Returns)
+	}
+	if want := []string{"ProvideService *Service", "ProvideHandler *Handler"}; !sameElements(providers, want) {
+		t.Errorf("Providers = %v, want %v", providers, want)
+	}
+
+	if result.Failed() {
+		t.Errorf("Failed() = true, diagnostics: %v", result.Diagnostics)
+	}
+
+	// The working directory is restored
+	if wd, _ := os.Getwd(); wd == root {
+		t.Errorf("working directory left at the project root")
+	}
+}
+
+func TestScanPackages(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		"internal/user/handler.go":  userHandler,
+		"internal/order/handler.go": "package order\n\nimport \"github.com/gofiber/fiber/v2\"\n\ntype Handler struct{}\n\n// @Router /orders [get]\nfunc (h *Handler) ListOrders(c *fiber.Ctx) error { return nil }\n",
+	})
+
+	result, err := Scan(context.Background(), Options{Dir: root, NoCache: true, Packages: []string{"order"}})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(result.Routes) != 1 || result.Routes[0].ID != "order.Handler.ListOrders" {
+		t.Errorf("Routes = %+v, want only order.Handler.ListOrders", result.Routes)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		"internal/user/handler.go": userHandler,
+		"internal/user/legacy.go":  "package user\n\nimport \"github.com/gofiber/fiber/v2\"\n\n// @Router /users/{id} [get]\nfunc (h *Handler) GetLegacyUser(c *fiber.Ctx) error { return nil }\n\n// @Router /users [bogus]\nfunc (h *Handler) ListUsers(c *fiber.Ctx) error { return nil }\n",
+	})
+
+	result, err := Scan(context.Background(), Options{Dir: root, NoCache: true})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	diagnostics, err := result.Validate()
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if !hasDiagnostic(diagnostics, SeverityError, "duplicate_route") {
+		t.Errorf("Validate() = %v, want a duplicate_route error", diagnostics)
+	}
+	if !hasDiagnostic(result.Diagnostics, SeverityWarning, "warning") {
+		t.Errorf("Diagnostics = %v, want a warning for the unknown method", result.Diagnostics)
+	}
+}
+
+func TestScanCancelled(t *testing.T) {
+	root := writeProject(t, map[string]string{"internal/user/handler.go": userHandler})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Scan(ctx, Options{Dir: root, NoCache: true}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Scan with a cancelled context = %v, want context.Canceled", err)
+	}
+}
+
+func TestScanWithoutProject(t *testing.T) {
+	if _, err := Scan(context.Background(), Options{Dir: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("Scan of a missing directory succeeded")
+	}
+}
+
+func TestPositionString(t *testing.T) {
+	tests := []struct {
+		position Position
+		want     string
+	}{
+		{Position{}, ""},
+		{Position{File: "handler.go"}, "handler.go"},
+		{Position{File: "handler.go", Line: 12}, "handler.go:12"},
+		{Position{File: "handler.go", Line: 12, Column: 2}, "handler.go:12:2"},
+	}
+	for _, tt := range tests {
+		if got := tt.position.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.position, got, tt.want)
+		}
+	}
+}
+
+func hasDiagnostic(diagnostics []Diagnostic, severity, kind string) bool {
+	for _, d := range diagnostics {
+		if d.Severity == severity && d.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func sameElements(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	seen := make(map[string]int)
+	for _, s := range got {
+		seen[s]++
+	}
+	for _, s := range want {
+		seen[s]--
+	}
+	for _, n := range seen {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
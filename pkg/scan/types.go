@@ -0,0 +1,203 @@
+package scan
+
+import (
+	"fmt"
+
+	"github.com/nkaewam/taskw/internal/config"
+	"github.com/nkaewam/taskw/internal/scanner"
+)
+
+// Severities of diagnostics
+const (
+	SeverityError   = "error"   // A file that failed to parse, or a problem failing generation
+	SeverityWarning = "warning" // Something the scan continued past, e.g. a @Router with a misspelled method
+	SeverityInfo    = "info"    // A file or package left out on purpose, e.g. a cgo file while cgo is disabled
+)
+
+// Position is where a declaration or diagnostic is found in the sources
+type Position struct {
+	File   string // Relative to the project root, e.g. "internal/user/handler.go"
+	Line   int    // 0 when unknown
+	Column int    // 0 when unknown
+}
+
+// String renders the position the way the go command does, e.g. "internal/user/handler.go:12:2",
+// leaving out the parts that are unknown
+func (p Position) String() string {
+	switch {
+	case p.File == "":
+		return ""
+	case p.Line == 0:
+		return p.File
+	case p.Column == 0:
+		return fmt.Sprintf("%s:%d", p.File, p.Line)
+	default:
+		return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+	}
+}
+
+// Handler is a function serving routes, a method of a handler type or a package-level function
+type Handler struct {
+	Package  string // e.g. "user"
+	Receiver string // Handler type the method is declared on, e.g. "Handler", empty for package-level functions
+	Name     string // e.g. "GetUser"
+	Position Position
+}
+
+// Route is a route declared with a @Router annotation
+type Route struct {
+	ID          string   // Route ID in telemetry, the @ID or e.g. "user.Handler.GetUser"
+	Method      string   // e.g. "GET"
+	Path        string   // Including the @RouterPrefix of the handler type, e.g. "/api/v1/users/{id}"
+	Package     string   // e.g. "user"
+	Receiver    string   // Handler type, empty for package-level functions
+	Handler     string   // Handler function, e.g. "GetUser"
+	Summary     string   // From @Summary
+	Description string   // From @Description, one line per annotation
+	Tags        []string // From @Tags, or @TagsDefault of the handler type
+	Middlewares []string // From @Middleware
+	Params      []Param  // From @Param, in declaration order
+	Deprecated  bool     // Marked with @Deprecated
+	Position    Position // Of the @Router annotation
+}
+
+// Param is a parameter of a route documented with @Param
+type Param struct {
+	Name     string // e.g. "limit"
+	In       string // "query", "path", "header", "body" or "formData"
+	Type     string // e.g. "int", or the schema of a body parameter such as "user.CreateRequest"
+	Required bool
+}
+
+// Provider is a constructor function the generated dependency injection setup calls
+type Provider struct {
+	Package    string   // e.g. "user"
+	Name       string   // e.g. "ProvideService"
+	Returns    string   // Type provided, e.g. "*user.Service"
+	Parameters []string // Types of the dependencies, e.g. ["*user.Repository"]
+	Errors     bool     // Returns an error as its last result
+	Cleanup    bool     // Returns a cleanup function, e.g. (T, func(), error)
+	Envs       []string // From "@Provider env=dev,test", empty when provided in every environment
+	Position   Position
+}
+
+// RPC is a gRPC service method annotated with @RPC, served over HTTP by the generated gateway
+type RPC struct {
+	Package  string // e.g. "user"
+	Server   string // Type implementing the service, e.g. "Server"
+	Name     string // e.g. "GetUser"
+	Method   string // HTTP method of its @Router annotation, e.g. "GET"
+	Path     string // e.g. "/users/{id}"
+	Request  string // e.g. "*userv1.GetUserRequest"
+	Response string // e.g. "*userv1.User"
+	Position Position
+}
+
+// Diagnostic is a problem found while scanning or validating
+type Diagnostic struct {
+	Severity   string // SeverityError, SeverityWarning or SeverityInfo
+	Kind       string // e.g. "parse_error", "annotation" or "duplicate_route"
+	Message    string
+	Suggestion string // Corrected annotation line, e.g. "@Router /users [get]", empty when there is none
+	Position   Position
+}
+
+// String renders the diagnostic the way compilers do, e.g.
+// "internal/user/handler.go:12:1: duplicate_route: Duplicate route found: GET /users"
+func (d Diagnostic) String() string {
+	if position := d.Position.String(); position != "" {
+		return fmt.Sprintf("%s: %s: %s", position, d.Kind, d.Message)
+	}
+	return fmt.Sprintf("%s: %s", d.Kind, d.Message)
+}
+
+// newResult converts a scan result into the public model
+func newResult(cfg *config.Config, raw *scanner.ScanResult) *Result {
+	result := &Result{
+		Root:   cfg.Root,
+		Module: cfg.Project.Module,
+		config: cfg,
+		raw:    raw,
+	}
+
+	for _, h := range raw.Handlers {
+		handler := Handler{
+			Package:  h.Package,
+			Name:     h.FunctionName,
+			Position: Position{File: h.FilePath, Line: h.Line, Column: h.Column},
+		}
+		if !h.IsFunction {
+			handler.Receiver = h.ImplementerName
+			if handler.Receiver == "" {
+				handler.Receiver = h.HandlerName
+			}
+		}
+		result.Handlers = append(result.Handlers, handler)
+	}
+
+	for _, r := range raw.Routes {
+		route := Route{
+			ID:          r.RouteID(),
+			Method:      r.HTTPMethod,
+			Path:        r.Path,
+			Package:     r.Package,
+			Receiver:    r.HandlerName,
+			Handler:     r.MethodName,
+			Summary:     r.Summary,
+			Description: r.Description,
+			Tags:        r.Tags,
+			Middlewares: r.Middlewares,
+			Deprecated:  r.Deprecated,
+			Position:    Position{File: r.FilePath, Line: r.Line, Column: r.Column},
+		}
+		for _, p := range r.Params {
+			route.Params = append(route.Params, Param{Name: p.Name, In: p.In, Type: p.Type, Required: p.Required})
+		}
+		result.Routes = append(result.Routes, route)
+	}
+
+	for _, p := range raw.Providers {
+		result.Providers = append(result.Providers, Provider{
+			Package:    p.Package,
+			Name:       p.FunctionName,
+			Returns:    p.ReturnType,
+			Parameters: p.Parameters,
+			Errors:     p.ReturnsError,
+			Cleanup:    p.HasCleanup,
+			Envs:       p.Envs,
+			Position:   Position{File: p.FilePath, Line: p.Line, Column: p.Column},
+		})
+	}
+
+	for _, m := range raw.RPCs {
+		result.RPCs = append(result.RPCs, RPC{
+			Package:  m.Package,
+			Server:   m.ServerName,
+			Name:     m.MethodName,
+			Method:   m.HTTPMethod,
+			Path:     m.Path,
+			Request:  m.RequestType,
+			Response: m.ResponseType,
+			Position: Position{File: m.FilePath, Line: m.Line, Column: m.Column},
+		})
+	}
+
+	for _, e := range raw.Errors {
+		severity := SeverityError
+		switch {
+		case e.Warning():
+			severity = SeverityWarning
+		case e.Skipped():
+			severity = SeverityInfo
+		}
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{
+			Severity:   severity,
+			Kind:       e.Type,
+			Message:    e.Message,
+			Suggestion: e.Suggestion,
+			Position:   Position{File: e.FilePath, Line: e.Line, Column: e.Column},
+		})
+	}
+
+	return result
+}
@@ -64,7 +64,6 @@ func TestProjectInitialization(t *testing.T) {
 			"internal/api/server.go",
 			"internal/api/wire.go",
 			"internal/health/handler.go",
-			".air.toml",
 			"Taskfile.yml",
 			"taskw.yaml",
 			"go.mod",
@@ -0,0 +1,162 @@
+package e2e
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// exitStale is the exit code of generate --check when generated files are out of date
+const exitStale = 8
+
+// TestGenerateBareCommand tests the documented bare "taskw generate" forms, which run "generate all"
+func TestGenerateBareCommand(t *testing.T) {
+	// Setup: Create temporary directory for test
+	projectDir := filepath.Join(os.TempDir(), "taskw-e2e-generate-test")
+	if err := os.RemoveAll(projectDir); err != nil {
+		t.Fatalf("Failed to clean test directory: %v", err)
+	}
+	defer os.RemoveAll(projectDir) // Cleanup
+
+	taskwBin := getTaskwBinary(t)
+
+	handlerPath := filepath.Join(projectDir, "internal", "user", "handler.go")
+	routesPath := filepath.Join(projectDir, "internal", "api", "routes_gen.go")
+	files := map[string]string{
+		"go.mod":     "module example.com/shop\n\ngo 1.22\n",
+		"taskw.yaml": "paths:\n  scan_dirs: [\"./internal\"]\n  output_dir: \"./internal/api\"\n",
+		"internal/user/handler.go": `package user
+
+import "github.com/gofiber/fiber/v2"
+
+// Handler handles user requests
+type Handler struct{}
+
+// ProvideHandler creates the user handler
+func ProvideHandler() *Handler { return &Handler{} }
+
+// GetUser returns a user
+// @Router /users/{id} [get]
+func (h *Handler) GetUser(c *fiber.Ctx) error { return nil }
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(projectDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	// run runs taskw in the project, installing swag is left to fail right away instead of reaching the network
+	run := func(args ...string) (string, error) {
+		cmd := exec.Command(taskwBin, args...)
+		cmd.Dir = projectDir
+		cmd.Env = append(os.Environ(), "GOPROXY=off")
+		output, err := cmd.CombinedOutput()
+		return string(output), err
+	}
+
+	// staleHandler changes the method of the route, so the generated routes are out of date
+	staleHandler := func(t *testing.T, from, to string) {
+		content, err := os.ReadFile(handlerPath)
+		if err != nil {
+			t.Fatalf("Failed to read handler: %v", err)
+		}
+		updated := strings.Replace(string(content), "["+from+"]", "["+to+"]", 1)
+		if err := os.WriteFile(handlerPath, []byte(updated), 0644); err != nil {
+			t.Fatalf("Failed to write handler: %v", err)
+		}
+	}
+
+	t.Run("01_generate_routes", func(t *testing.T) {
+		if output, err := run("generate", "all", "--only", "routes,deps"); err != nil {
+			t.Fatalf("taskw generate all failed: %v\nOutput: %s", err, output)
+		}
+		if _, err := os.Stat(routesPath); err != nil {
+			t.Fatalf("Generated routes not found: %v", err)
+		}
+	})
+
+	t.Run("02_check_up_to_date", func(t *testing.T) {
+		if output, err := run("generate", "--check"); err != nil {
+			t.Fatalf("taskw generate --check failed on up to date files: %v\nOutput: %s", err, output)
+		}
+	})
+
+	t.Run("03_check_stale", func(t *testing.T) {
+		staleHandler(t, "get", "put")
+
+		output, err := run("generate", "--check")
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != exitStale {
+			t.Fatalf("taskw generate --check on stale files = %v, want exit code %d\nOutput: %s", err, exitStale, output)
+		}
+		if !strings.Contains(output, "+\tar.app.Put(") {
+			t.Errorf("taskw generate --check doesn't show the stale route in its diff\nOutput: %s", output)
+		}
+	})
+
+	t.Run("04_dry_run", func(t *testing.T) {
+		before, err := os.ReadFile(routesPath)
+		if err != nil {
+			t.Fatalf("Failed to read generated routes: %v", err)
+		}
+
+		output, err := run("generate", "--dry-run")
+		if err != nil {
+			t.Fatalf("taskw generate --dry-run failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "--- a/internal/api/routes_gen.go") || !strings.Contains(output, "+\tar.app.Put(") {
+			t.Errorf("taskw generate --dry-run doesn't print the diff of the routes\nOutput: %s", output)
+		}
+
+		after, err := os.ReadFile(routesPath)
+		if err != nil {
+			t.Fatalf("Failed to read generated routes: %v", err)
+		}
+		if string(after) != string(before) {
+			t.Errorf("taskw generate --dry-run rewrote %s", routesPath)
+		}
+	})
+
+	t.Run("05_since_revision", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available")
+		}
+		for _, args := range [][]string{
+			{"init", "-q"},
+			{"add", "-A"},
+			{"-c", "user.name=taskw", "-c", "user.email=taskw@example.com", "commit", "-q", "-m", "initial"},
+		} {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = projectDir
+			if output, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git %s failed: %v\nOutput: %s", args[0], err, output)
+			}
+		}
+		staleHandler(t, "put", "post")
+
+		if output, err := run("generate", "--since", "HEAD"); err != nil {
+			t.Fatalf("taskw generate --since HEAD failed: %v\nOutput: %s", err, output)
+		}
+		routes, err := os.ReadFile(routesPath)
+		if err != nil {
+			t.Fatalf("Failed to read generated routes: %v", err)
+		}
+		if !strings.Contains(string(routes), "ar.app.Post(") {
+			t.Errorf("taskw generate --since HEAD didn't regenerate the changed route\nRoutes: %s", routes)
+		}
+
+		if output, err := run("generate", "--since", "no-such-revision"); err == nil {
+			t.Errorf("taskw generate --since with an unknown revision succeeded\nOutput: %s", output)
+		}
+	})
+
+	t.Logf("✅ Bare generate e2e test completed successfully")
+}